@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
+)
+
+// Показатели условий алертов
+const (
+	AlertPrice  = "price"  // Цена закрытия последней свечи
+	AlertRSI    = "rsi"    // RSI за Period свечей
+	AlertSMA    = "sma"    // SMA за Period свечей
+	AlertEMA    = "ema"    // EMA за Period свечей
+	AlertChange = "change" // Изменение цены за Period свечей, %
+	AlertVolume = "volume" // Объем последней свечи
+)
+
+// alertDefaultPeriods период показателя, если он не указан (rsi - rsi14)
+var alertDefaultPeriods = map[string]int{
+	AlertRSI:    14,
+	AlertSMA:    20,
+	AlertEMA:    20,
+	AlertChange: 1,
+}
+
+// alertMaxPeriod максимальный период показателя в условии
+const alertMaxPeriod = 500
+
+// alertPattern условие алерта: [показатель] оператор значение[%]
+var alertPattern = regexp.MustCompile(`^(?:([a-z]+)(\d*)(d?))?\s*(>=|<=|>|<)\s*(-?\d+(?:[.,]\d+)?)\s*(%?)$`)
+
+// AlertCondition условие алерта: показатель, оператор и порог, например
+// "price > 300", "rsi14 < 30", "change1d > 5%"
+type AlertCondition struct {
+	Metric   string  // Один из Alert*
+	Period   int     // Период индикатора или число свечей для change (0 - для price и volume)
+	Operator string  // >, <, >= или <=
+	Value    float64 // Порог (для change - в процентах)
+}
+
+// ParseAlertCondition разбирает условие алерта. Показатель можно опустить -
+// тогда сравнивается цена: "> 300" то же, что "price > 300".
+func ParseAlertCondition(text string) (AlertCondition, error) {
+	match := alertPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(text)))
+	if match == nil {
+		return AlertCondition{}, fmt.Errorf("неверное условие %q: ожидается [показатель] >|<|>=|<= значение", text)
+	}
+
+	cond := AlertCondition{Metric: match[1], Operator: match[4]}
+	if cond.Metric == "" {
+		cond.Metric = AlertPrice
+	}
+
+	switch cond.Metric {
+	case AlertPrice, AlertVolume:
+		if match[2] != "" || match[3] != "" {
+			return AlertCondition{}, fmt.Errorf("у показателя %s нет периода", cond.Metric)
+		}
+	case AlertRSI, AlertSMA, AlertEMA, AlertChange:
+		if match[3] != "" && cond.Metric != AlertChange {
+			return AlertCondition{}, fmt.Errorf("период %s задается числом свечей, например %s14", cond.Metric, cond.Metric)
+		}
+		cond.Period = alertDefaultPeriods[cond.Metric]
+		if match[2] != "" {
+			period, err := strconv.Atoi(match[2])
+			if err != nil || period <= 0 || period > alertMaxPeriod {
+				return AlertCondition{}, fmt.Errorf("период %s должен быть от 1 до %d", cond.Metric, alertMaxPeriod)
+			}
+			cond.Period = period
+		}
+	default:
+		return AlertCondition{}, fmt.Errorf("неизвестный показатель %q: доступны price, rsi, sma, ema, change, volume", cond.Metric)
+	}
+
+	if match[6] == "%" && cond.Metric != AlertChange {
+		return AlertCondition{}, fmt.Errorf("значение в процентах допустимо только для change")
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(match[5], ",", ".", 1), 64)
+	if err != nil {
+		return AlertCondition{}, fmt.Errorf("неверное значение %q", match[5])
+	}
+	cond.Value = value
+
+	return cond, nil
+}
+
+// String условие в каноническом виде, которое снова разбирается ParseAlertCondition
+func (c AlertCondition) String() string {
+	return fmt.Sprintf("%s %s %s", c.MetricName(), c.Operator, c.formatValue())
+}
+
+// MetricName показатель с периодом: price, rsi14, change1d
+func (c AlertCondition) MetricName() string {
+	switch c.Metric {
+	case AlertPrice, AlertVolume:
+		return c.Metric
+	case AlertChange:
+		return fmt.Sprintf("%s%dd", c.Metric, c.Period)
+	default:
+		return fmt.Sprintf("%s%d", c.Metric, c.Period)
+	}
+}
+
+// formatValue порог без лишних нулей, для change - со знаком процента
+func (c AlertCondition) formatValue() string {
+	value := strconv.FormatFloat(c.Value, 'f', -1, 64)
+	if c.Metric == AlertChange {
+		value += "%"
+	}
+	return value
+}
+
+// Bars сколько свечей нужно для расчета показателя. RSI и EMA берутся с запасом,
+// чтобы сгладить влияние начала ряда.
+func (c AlertCondition) Bars() int {
+	switch c.Metric {
+	case AlertRSI, AlertEMA:
+		return c.Period * 4
+	case AlertSMA:
+		return c.Period
+	case AlertChange:
+		return c.Period + 1
+	default:
+		return 1
+	}
+}
+
+// Evaluate рассчитывает показатель по свечам (от старых к новым) и проверяет
+// условие. Возвращает текущее значение показателя и выполнено ли условие.
+func (c AlertCondition) Evaluate(candles []api.Candle) (float64, bool, error) {
+	if len(candles) == 0 {
+		return 0, false, fmt.Errorf("нет свечей")
+	}
+
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+	}
+	last := candles[len(candles)-1]
+
+	var value float64
+	var ok bool
+	switch c.Metric {
+	case AlertPrice:
+		value, ok = last.Close, true
+	case AlertVolume:
+		value, ok = last.Volume, true
+	case AlertRSI:
+		value, ok = indicators.Last(indicators.RSI(closes, c.Period))
+	case AlertSMA:
+		value, ok = indicators.Last(indicators.SMA(closes, c.Period))
+	case AlertEMA:
+		value, ok = indicators.Last(indicators.EMA(closes, c.Period))
+	case AlertChange:
+		if len(closes) > c.Period && closes[len(closes)-1-c.Period] > 0 {
+			base := closes[len(closes)-1-c.Period]
+			value, ok = (last.Close-base)/base*100, true
+		}
+	default:
+		return 0, false, fmt.Errorf("неизвестный показатель %q", c.Metric)
+	}
+	if !ok {
+		return 0, false, fmt.Errorf("недостаточно свечей для %s: %d", c.MetricName(), len(candles))
+	}
+
+	switch c.Operator {
+	case ">":
+		return value, value > c.Value, nil
+	case "<":
+		return value, value < c.Value, nil
+	case ">=":
+		return value, value >= c.Value, nil
+	case "<=":
+		return value, value <= c.Value, nil
+	}
+	return value, false, fmt.Errorf("неизвестный оператор %q", c.Operator)
+}
@@ -0,0 +1,105 @@
+package analysis_test
+
+import (
+	"math"
+	"testing"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+)
+
+func TestParseAlertCondition(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string // Каноническое условие; пусто - ожидается ошибка
+		metric  string
+		period  int
+		value   float64
+		wantErr bool
+	}{
+		{text: "> 300", want: "price > 300", metric: analysis.AlertPrice, value: 300},
+		{text: "price>=299.5", want: "price >= 299.5", metric: analysis.AlertPrice, value: 299.5},
+		{text: "RSI14 < 30", want: "rsi14 < 30", metric: analysis.AlertRSI, period: 14, value: 30},
+		{text: "rsi <= 25", want: "rsi14 <= 25", metric: analysis.AlertRSI, period: 14, value: 25},
+		{text: "change1d > 5%", want: "change1d > 5%", metric: analysis.AlertChange, period: 1, value: 5},
+		{text: "change5d < -3,5%", want: "change5d < -3.5%", metric: analysis.AlertChange, period: 5, value: -3.5},
+		{text: "sma50 > 250", want: "sma50 > 250", metric: analysis.AlertSMA, period: 50, value: 250},
+		{text: "volume > 1000000", want: "volume > 1000000", metric: analysis.AlertVolume, value: 1000000},
+		{text: "macd > 0", wantErr: true},
+		{text: "price = 300", wantErr: true},
+		{text: "price > 5%", wantErr: true},
+		{text: "rsi0 < 30", wantErr: true},
+		{text: "price14 > 300", wantErr: true},
+		{text: "300", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cond, err := analysis.ParseAlertCondition(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseAlertCondition() = %+v, want error", cond)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAlertCondition() error = %v", err)
+			}
+			if cond.String() != tt.want || cond.Metric != tt.metric || cond.Period != tt.period || cond.Value != tt.value {
+				t.Errorf("ParseAlertCondition() = %+v (%s), want %s", cond, cond, tt.want)
+			}
+
+			// Каноническая форма разбирается в то же условие
+			again, err := analysis.ParseAlertCondition(cond.String())
+			if err != nil || again != cond {
+				t.Errorf("ParseAlertCondition(%q) = %+v, %v, want %+v", cond.String(), again, err, cond)
+			}
+		})
+	}
+}
+
+func TestAlertConditionEvaluate(t *testing.T) {
+	candles := make([]api.Candle, 0, 30)
+	for i := 0; i < 30; i++ {
+		candles = append(candles, api.Candle{Close: 100 + float64(i), Volume: 1000})
+	}
+	// Последняя свеча: рост на 10% за день
+	candles[29].Close = candles[28].Close * 1.1
+
+	tests := []struct {
+		text    string
+		value   float64
+		met     bool
+		wantErr bool
+	}{
+		{text: "price > 130", value: 140.8, met: true},
+		{text: "price < 130", value: 140.8, met: false},
+		{text: "change1d >= 10%", value: 10, met: true},
+		{text: "volume <= 1000", value: 1000, met: true},
+		{text: "rsi14 > 70", value: 100, met: true},
+		{text: "sma50 > 100", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cond, err := analysis.ParseAlertCondition(tt.text)
+			if err != nil {
+				t.Fatalf("ParseAlertCondition() error = %v", err)
+			}
+
+			value, met, err := cond.Evaluate(candles)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Evaluate() = %.2f, want error", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if math.Abs(value-tt.value) > 1e-6 || met != tt.met {
+				t.Errorf("Evaluate() = %.4f, %v, want %.4f, %v", value, met, tt.value, tt.met)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// BacktestConfig параметры бэктеста
+type BacktestConfig struct {
+	InitialCapital float64 // Начальный капитал, ₽
+	Commission     float64 // Комиссия за сделку, доля от оборота (0.0005 = 0.05%)
+	AllowShort     bool    // Открывать короткие позиции
+}
+
+// DefaultBacktestConfig возвращает параметры бэктеста по умолчанию
+func DefaultBacktestConfig() BacktestConfig {
+	return BacktestConfig{
+		InitialCapital: 100000,
+		Commission:     0.0005,
+		AllowShort:     true,
+	}
+}
+
+// Trade сделка бэктеста. Позиция может состоять из нескольких юнитов:
+// EntryPrice - цена первого юнита, AvgPrice - средняя цена входа.
+type Trade struct {
+	Direction  string // "long" или "short"
+	System     string // Система "Черепах", по которой открыта позиция
+	EntryDate  time.Time
+	EntryPrice float64
+	AvgPrice   float64
+	ExitDate   time.Time
+	ExitPrice  float64
+	StopLoss   float64 // Стоп всей позиции (подтягивается при добавлении юнитов)
+	Quantity   float64
+	Units      int
+	PnL        float64 // Результат с учетом комиссий, ₽
+	PnLPercent float64 // Результат в процентах от стоимости позиции
+	ExitReason string  // "stop", "exit_breakout", "end"
+	Bars       int     // Длительность в свечах
+}
+
+// EquityPoint значение капитала на дату
+type EquityPoint struct {
+	Date   time.Time
+	Equity float64
+}
+
+// BacktestResult результат бэктеста
+type BacktestResult struct {
+	Instrument     string
+	From           time.Time
+	To             time.Time
+	InitialCapital float64
+	FinalEquity    float64
+	TotalReturn    float64 // %
+	CAGR           float64 // %
+	MaxDrawdown    float64 // %
+	WinRate        float64 // %
+	ProfitFactor   float64 // +Inf, если убыточных сделок нет
+	Wins           int
+	Losses         int
+	AvgWin         float64
+	AvgLoss        float64
+	Trades         []Trade
+	Equity         []EquityPoint
+}
+
+// openPosition открытая позиция бэктеста с состоянием пирамидинга
+type openPosition struct {
+	*Trade
+	n       float64 // N (ATR) на момент входа
+	unit    float64 // Размер юнита, бумаг
+	nextAdd float64 // Цена добавления следующего юнита
+}
+
+// Backtester прогоняет стратегию "Черепах" по истории свеча за свечой
+type Backtester struct {
+	strategy *TurtleStrategy
+	config   BacktestConfig
+}
+
+// NewBacktester создает бэктестер для стратегии
+func NewBacktester(strategy *TurtleStrategy, config BacktestConfig) *Backtester {
+	if config.InitialCapital <= 0 {
+		config.InitialCapital = DefaultBacktestConfig().InitialCapital
+	}
+	return &Backtester{
+		strategy: strategy,
+		config:   config,
+	}
+}
+
+// Backtest загружает дневные свечи за период [from, to] с запасом на разогрев
+// индикаторов и прогоняет по ним стратегию
+func (bt *Backtester) Backtest(ctx context.Context, instrument string, from, to time.Time) (*BacktestResult, error) {
+	warmupFrom := from.AddDate(0, 0, -bt.warmupBars()*2)
+
+	candles, err := bt.strategy.source.GetCandles(ctx, instrument, "24",
+		warmupFrom.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения свечей: %w", err)
+	}
+
+	return bt.Run(instrument, candles, from)
+}
+
+// Run прогоняет стратегию по свечам. Сделки открываются только на свечах,
+// начинающихся не раньше from; более ранние свечи используются для разогрева.
+// Вход и выход по сигналу исполняются по цене закрытия свечи, стоп - по цене
+// стопа или открытия, если цена открылась за стопом. Юниты добавляются внутри
+// свечи при проходе цены на PyramidStep N, стоп переносится за последним юнитом.
+func (bt *Backtester) Run(instrument string, candles []api.Candle, from time.Time) (*BacktestResult, error) {
+	window := bt.warmupBars()
+	if len(candles) <= window {
+		return nil, fmt.Errorf("недостаточно данных для бэктеста: %d свечей, нужно больше %d", len(candles), window)
+	}
+
+	start := window - 1
+	for start < len(candles) && candles[start].Begin.Before(from) {
+		start++
+	}
+	if start >= len(candles) {
+		return nil, fmt.Errorf("нет свечей в периоде бэктеста")
+	}
+
+	result := &BacktestResult{
+		Instrument:     instrument,
+		From:           candles[start].Begin,
+		To:             candles[len(candles)-1].Begin,
+		InitialCapital: bt.config.InitialCapital,
+	}
+
+	capital := bt.config.InitialCapital
+	history := bt.strategy.historyBars()
+	var position *openPosition
+	entryIdx := 0
+
+	for i := start; i < len(candles); i++ {
+		bar := candles[i]
+
+		var signals []Signal
+		analyzed := false
+		// find ищет сигнал по типу; system ограничивает систему, пустая - любая
+		find := func(signalType, system string) (Signal, bool) {
+			if !analyzed {
+				analyzed = true
+				from := bt.strategy.mathUtils.MaxInt(0, i-history+1)
+				signals, _ = bt.strategy.AnalyzeCandles(instrument, candles[from:i+1])
+			}
+			for _, signal := range signals {
+				if signal.SignalType == signalType && (system == "" || signal.System == system) {
+					return signal, true
+				}
+			}
+			return Signal{}, false
+		}
+
+		// Сопровождение открытой позиции: сначала стоп, затем сигнал выхода
+		if position != nil {
+			exitPrice, reason := 0.0, ""
+			switch position.Direction {
+			case "long":
+				if bar.Low <= position.StopLoss {
+					exitPrice, reason = math.Min(bar.Open, position.StopLoss), "stop"
+				} else if _, ok := find("exit_long", position.System); ok {
+					exitPrice, reason = bar.Close, "exit_breakout"
+				}
+			case "short":
+				if bar.High >= position.StopLoss {
+					exitPrice, reason = math.Max(bar.Open, position.StopLoss), "stop"
+				} else if _, ok := find("exit_short", position.System); ok {
+					exitPrice, reason = bar.Close, "exit_breakout"
+				}
+			}
+
+			if reason != "" {
+				capital += bt.closeTrade(position.Trade, bar.Begin, exitPrice, reason, i-entryIdx)
+				result.Trades = append(result.Trades, *position.Trade)
+				position = nil
+			} else {
+				bt.addUnits(position, bar, capital)
+			}
+		} else {
+			// Вход по прорыву
+			if signal, ok := find("entry_long", ""); ok {
+				position = bt.openTrade("long", signal, bar.Begin, capital)
+			} else if signal, ok := find("entry_short", ""); ok && bt.config.AllowShort {
+				position = bt.openTrade("short", signal, bar.Begin, capital)
+			}
+			if position != nil {
+				entryIdx = i
+			}
+		}
+
+		equity := capital
+		if position != nil {
+			equity += unrealizedPnL(position.Trade, bar.Close)
+		}
+		result.Equity = append(result.Equity, EquityPoint{Date: bar.Begin, Equity: equity})
+	}
+
+	// Незакрытая позиция закрывается по последней цене
+	if position != nil {
+		last := candles[len(candles)-1]
+		capital += bt.closeTrade(position.Trade, last.Begin, last.Close, "end", len(candles)-1-entryIdx)
+		result.Trades = append(result.Trades, *position.Trade)
+		result.Equity[len(result.Equity)-1].Equity = capital
+	}
+
+	result.FinalEquity = capital
+	summarize(result)
+
+	return result, nil
+}
+
+// warmupBars число свечей, необходимых стратегии для расчета уровней
+func (bt *Backtester) warmupBars() int {
+	return bt.strategy.minCandles()
+}
+
+// openTrade открывает первый юнит с риском riskPerTrade от текущего капитала.
+// Возвращает nil, если размер юнита меньше одной бумаги.
+func (bt *Backtester) openTrade(direction string, signal Signal, date time.Time, capital float64) *openPosition {
+	riskPerUnit := math.Abs(signal.Price - signal.StopLoss)
+	if riskPerUnit == 0 || signal.Price <= 0 {
+		return nil
+	}
+
+	unit := math.Floor(capital * bt.strategy.riskPerTrade / riskPerUnit)
+	// Без плеча: стоимость позиции не превышает капитал
+	quantity := math.Min(unit, math.Floor(capital/signal.Price))
+	if quantity < 1 {
+		return nil
+	}
+
+	n := riskPerUnit / bt.strategy.atrMultiplier
+	step := bt.strategy.rules.PyramidStep * n
+	if direction == "short" {
+		step = -step
+	}
+
+	return &openPosition{
+		Trade: &Trade{
+			Direction:  direction,
+			System:     signal.System,
+			EntryDate:  date,
+			EntryPrice: signal.Price,
+			AvgPrice:   signal.Price,
+			StopLoss:   signal.StopLoss,
+			Quantity:   quantity,
+			Units:      1,
+		},
+		n:       n,
+		unit:    unit,
+		nextAdd: signal.Price + step,
+	}
+}
+
+// addUnits добавляет юниты, пока цена свечи проходит очередной шаг пирамидинга,
+// и переносит стоп всей позиции на atrMultiplier N от цены последнего юнита
+func (bt *Backtester) addUnits(position *openPosition, bar api.Candle, capital float64) {
+	rules := bt.strategy.rules
+	step := rules.PyramidStep * position.n
+	stop := position.n * bt.strategy.atrMultiplier
+
+	for position.Units < rules.MaxUnits {
+		var fill float64
+		if position.Direction == "long" {
+			if bar.High < position.nextAdd {
+				return
+			}
+			fill = math.Max(bar.Open, position.nextAdd)
+		} else {
+			if bar.Low > position.nextAdd {
+				return
+			}
+			fill = math.Min(bar.Open, position.nextAdd)
+		}
+
+		// Без плеча: стоимость всей позиции не превышает капитал
+		quantity := math.Min(position.unit, math.Floor((capital-position.AvgPrice*position.Quantity)/fill))
+		if quantity < 1 {
+			return
+		}
+
+		position.AvgPrice = (position.AvgPrice*position.Quantity + fill*quantity) / (position.Quantity + quantity)
+		position.Quantity += quantity
+		position.Units++
+
+		if position.Direction == "long" {
+			position.StopLoss = math.Max(position.StopLoss, fill-stop)
+			position.nextAdd = fill + step
+		} else {
+			position.StopLoss = math.Min(position.StopLoss, fill+stop)
+			position.nextAdd = fill - step
+		}
+	}
+}
+
+// closeTrade закрывает позицию и возвращает ее результат с учетом комиссий
+func (bt *Backtester) closeTrade(trade *Trade, date time.Time, price float64, reason string, bars int) float64 {
+	trade.ExitDate = date
+	trade.ExitPrice = price
+	trade.ExitReason = reason
+	trade.Bars = bars
+
+	commission := (trade.AvgPrice + price) * trade.Quantity * bt.config.Commission
+	trade.PnL = unrealizedPnL(trade, price) - commission
+	trade.PnLPercent = trade.PnL / (trade.AvgPrice * trade.Quantity) * 100
+
+	return trade.PnL
+}
+
+// unrealizedPnL результат позиции по текущей цене без комиссий
+func unrealizedPnL(trade *Trade, price float64) float64 {
+	if trade.Direction == "short" {
+		return (trade.AvgPrice - price) * trade.Quantity
+	}
+	return (price - trade.AvgPrice) * trade.Quantity
+}
+
+// summarize рассчитывает итоговые показатели бэктеста
+func summarize(result *BacktestResult) {
+	result.TotalReturn = (result.FinalEquity/result.InitialCapital - 1) * 100
+
+	years := result.To.Sub(result.From).Hours() / 24 / 365.25
+	if years > 0 && result.FinalEquity > 0 {
+		result.CAGR = (math.Pow(result.FinalEquity/result.InitialCapital, 1/years) - 1) * 100
+	}
+
+	peak := result.InitialCapital
+	for _, point := range result.Equity {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		if drawdown := (peak - point.Equity) / peak * 100; drawdown > result.MaxDrawdown {
+			result.MaxDrawdown = drawdown
+		}
+	}
+
+	var grossProfit, grossLoss float64
+	for _, trade := range result.Trades {
+		if trade.PnL > 0 {
+			result.Wins++
+			grossProfit += trade.PnL
+		} else {
+			result.Losses++
+			grossLoss += -trade.PnL
+		}
+	}
+
+	if len(result.Trades) > 0 {
+		result.WinRate = float64(result.Wins) / float64(len(result.Trades)) * 100
+	}
+	if result.Wins > 0 {
+		result.AvgWin = grossProfit / float64(result.Wins)
+	}
+	if result.Losses > 0 {
+		result.AvgLoss = grossLoss / float64(result.Losses)
+	}
+
+	switch {
+	case grossLoss > 0:
+		result.ProfitFactor = grossProfit / grossLoss
+	case grossProfit > 0:
+		result.ProfitFactor = math.Inf(1)
+	}
+}
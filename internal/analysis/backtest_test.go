@@ -0,0 +1,178 @@
+package analysis_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+)
+
+// newTestBacktester бэктестер с параметрами 20/20/10, ATR 14 и стопом 2 ATR
+func newTestBacktester(allowShort bool) *analysis.Backtester {
+	strategy := analysis.NewTurtleStrategy(nil, 20, 20, 10, 14, 2.0, 0.01)
+	return analysis.NewBacktester(strategy, analysis.BacktestConfig{
+		InitialCapital: 100000,
+		AllowShort:     allowShort,
+	})
+}
+
+func TestBacktesterExitBreakout(t *testing.T) {
+	// Боковик, прорыв вверх, тренд и разворот до пробоя 10-дневного минимума
+	closes := rangeSeries(30, 100, 101)
+	closes = append(closes, linearSeries(31, 105, 1)...)
+	closes = append(closes, linearSeries(12, 134, -1)...)
+
+	result, err := newTestBacktester(false).Run("SBER", fakefetcher.Series(closes), time.Time{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(result.Trades) != 1 {
+		t.Fatalf("trades = %d, want 1: %+v", len(result.Trades), result.Trades)
+	}
+	trade := result.Trades[0]
+	if trade.Direction != "long" || trade.EntryPrice != 105 {
+		t.Errorf("trade = %s @ %.2f, want long @ 105", trade.Direction, trade.EntryPrice)
+	}
+	if trade.ExitReason != "exit_breakout" {
+		t.Errorf("ExitReason = %s, want exit_breakout", trade.ExitReason)
+	}
+	if trade.PnL <= 0 {
+		t.Errorf("PnL = %.2f, want profit", trade.PnL)
+	}
+
+	if result.WinRate != 100 || !math.IsInf(result.ProfitFactor, 1) {
+		t.Errorf("WinRate = %.1f, ProfitFactor = %v, want 100 and +Inf", result.WinRate, result.ProfitFactor)
+	}
+	if math.Abs(result.FinalEquity-(100000+trade.PnL)) > 1e-6 {
+		t.Errorf("FinalEquity = %.2f, want %.2f", result.FinalEquity, 100000+trade.PnL)
+	}
+	if result.MaxDrawdown <= 0 {
+		t.Error("MaxDrawdown should reflect the pullback after the peak")
+	}
+	if len(result.Equity) == 0 || result.Equity[len(result.Equity)-1].Equity != result.FinalEquity {
+		t.Error("equity curve should end at final equity")
+	}
+}
+
+func TestBacktesterPyramiding(t *testing.T) {
+	closes := rangeSeries(30, 100, 101)
+	closes = append(closes, linearSeries(31, 105, 1)...)
+	closes = append(closes, linearSeries(12, 134, -1)...)
+
+	// Риск 0.2% на юнит, чтобы все 4 юнита помещались в капитал без плеча
+	strategy := analysis.NewTurtleStrategy(nil, 20, 20, 10, 14, 2.0, 0.002)
+	backtester := analysis.NewBacktester(strategy, analysis.BacktestConfig{InitialCapital: 100000})
+
+	result, err := backtester.Run("SBER", fakefetcher.Series(closes), time.Time{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(result.Trades) != 1 {
+		t.Fatalf("trades = %d, want 1: %+v", len(result.Trades), result.Trades)
+	}
+	trade := result.Trades[0]
+	if trade.Units != 4 {
+		t.Errorf("Units = %d, want 4", trade.Units)
+	}
+	if trade.EntryPrice != 105 || trade.AvgPrice <= trade.EntryPrice {
+		t.Errorf("entry = %.2f, avg = %.2f, want adds above first unit", trade.EntryPrice, trade.AvgPrice)
+	}
+	// Стоп подтянут за последним юнитом и выше первоначального (105 - 2N)
+	if trade.StopLoss <= 104 {
+		t.Errorf("StopLoss = %.2f, want trailed above 104", trade.StopLoss)
+	}
+	if trade.AvgPrice*trade.Quantity > 100000 {
+		t.Errorf("position value = %.0f exceeds capital", trade.AvgPrice*trade.Quantity)
+	}
+	if trade.ExitReason != "exit_breakout" || trade.PnL <= 0 {
+		t.Errorf("exit = %s with PnL %.2f, want profitable exit_breakout", trade.ExitReason, trade.PnL)
+	}
+}
+
+func TestBacktesterSkipAfterWin(t *testing.T) {
+	closes := winningBreakoutSeries()
+
+	tests := []struct {
+		name   string
+		rules  analysis.TurtleRules
+		trades int
+	}{
+		{"System 1 skips second breakout", analysis.TurtleRules{System: analysis.TurtleSystem1, SkipAfterWin: true}, 1},
+		{"Without skip rule", analysis.TurtleRules{System: analysis.TurtleSystem1}, 2},
+		{"System 2 takes skipped breakout", analysis.TurtleRules{System: analysis.TurtleSystemBoth, SkipAfterWin: true}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := analysis.NewTurtleStrategy(nil, 20, 20, 10, 14, 2.0, 0.01).WithRules(tt.rules)
+			result, err := analysis.NewBacktester(strategy, analysis.BacktestConfig{}).Run("SBER", fakefetcher.Series(closes), time.Time{})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if len(result.Trades) != tt.trades {
+				t.Fatalf("trades = %d, want %d: %+v", len(result.Trades), tt.trades, result.Trades)
+			}
+			if first := result.Trades[0]; first.System != analysis.TurtleSystem1 || first.PnL <= 0 {
+				t.Errorf("first trade = System %s with PnL %.2f, want winning System 1", first.System, first.PnL)
+			}
+			if tt.trades == 2 && tt.rules.System == analysis.TurtleSystemBoth && result.Trades[1].System != analysis.TurtleSystem2 {
+				t.Errorf("second trade System = %s, want 2", result.Trades[1].System)
+			}
+		})
+	}
+}
+
+func TestBacktesterStopLoss(t *testing.T) {
+	closes := rangeSeries(30, 100, 101)
+	closes = append(closes, 105, 90)
+
+	result, err := newTestBacktester(false).Run("SBER", fakefetcher.Series(closes), time.Time{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(result.Trades) != 1 {
+		t.Fatalf("trades = %d, want 1", len(result.Trades))
+	}
+	trade := result.Trades[0]
+	if trade.ExitReason != "stop" || trade.ExitPrice != trade.StopLoss {
+		t.Errorf("exit = %s @ %.2f, want stop @ %.2f", trade.ExitReason, trade.ExitPrice, trade.StopLoss)
+	}
+	if trade.PnL >= 0 || result.WinRate != 0 || result.Losses != 1 {
+		t.Errorf("PnL = %.2f, WinRate = %.1f, want a single loss", trade.PnL, result.WinRate)
+	}
+
+	// Риск сделки ограничен 1% капитала
+	if loss := -trade.PnL; loss > 100000*0.01*1.05 {
+		t.Errorf("loss = %.2f, want about 1%% of capital", loss)
+	}
+}
+
+func TestBacktesterRespectsStartDate(t *testing.T) {
+	closes := rangeSeries(30, 100, 101)
+	closes = append(closes, 105, 104, 104.5)
+	candles := fakefetcher.Series(closes)
+
+	// Прорыв раньше даты начала не открывает сделку
+	result, err := newTestBacktester(true).Run("SBER", candles, candles[len(candles)-1].Begin)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(result.Trades) != 0 {
+		t.Errorf("trades = %d, want 0", len(result.Trades))
+	}
+	if result.TotalReturn != 0 {
+		t.Errorf("TotalReturn = %.2f, want 0", result.TotalReturn)
+	}
+}
+
+func TestBacktesterNotEnoughData(t *testing.T) {
+	if _, err := newTestBacktester(true).Run("SBER", fakefetcher.Series(rangeSeries(10, 100, 101)), time.Time{}); err == nil {
+		t.Fatal("Run() should fail with 10 candles")
+	}
+}
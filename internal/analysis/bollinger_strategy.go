@@ -0,0 +1,238 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
+)
+
+// Режимы стратегии Боллинджера
+const (
+	BollingerReversion = "reversion" // Возврат к средней от внешней полосы
+	BollingerSqueeze   = "squeeze"   // Пробой после сжатия волатильности
+	BollingerBoth      = "both"      // Оба режима
+)
+
+// squeezeRecency сколько последних свечей сжатие считается актуальным для пробоя
+const squeezeRecency = 5
+
+// BollingerStrategy реализует стратегию на полосах Боллинджера: возврат к средней
+// от внешней полосы с подтверждением RSI и пробой после сжатия полос
+type BollingerStrategy struct {
+	source CandleSource
+	config BollingerConfig
+}
+
+// BollingerConfig конфигурация стратегии Боллинджера
+type BollingerConfig struct {
+	Timeframe             string
+	Mode                  string  // reversion, squeeze или both
+	Period                int     // Период SMA
+	StdDev                float64 // Ширина полос в стандартных отклонениях
+	RSIPeriod             int
+	RSIOverbought         float64
+	RSIOversold           float64
+	SqueezeLookback       int // Ширина полос на минимуме за N свечей - сжатие
+	AtrPeriod             int
+	RiskPerTrade          float64
+	StopLossATRMultiplier float64
+	TakeProfitRatio       float64
+}
+
+// NewBollingerStrategy создает новую стратегию Боллинджера
+func NewBollingerStrategy(source CandleSource, config BollingerConfig) *BollingerStrategy {
+	return &BollingerStrategy{
+		source: source,
+		config: config,
+	}
+}
+
+// Name короткое имя стратегии
+func (bs *BollingerStrategy) Name() string {
+	return "bollinger"
+}
+
+// Title название стратегии для пользователя
+func (bs *BollingerStrategy) Title() string {
+	return "Bollinger"
+}
+
+// Params текущие параметры стратегии
+func (bs *BollingerStrategy) Params() []Param {
+	params := []Param{
+		{Name: "Режим", Value: bs.modeText()},
+		{Name: "Полосы", Value: fmt.Sprintf("SMA%d ± %.1fσ", bs.config.Period, bs.config.StdDev)},
+	}
+	if bs.reversion() {
+		params = append(params, Param{Name: "RSI", Value: fmt.Sprintf("%d (%.0f / %.0f)",
+			bs.config.RSIPeriod, bs.config.RSIOversold, bs.config.RSIOverbought)})
+	}
+	if bs.squeeze() {
+		params = append(params, Param{Name: "Сжатие", Value: fmt.Sprintf("минимум ширины за %d свечей", bs.config.SqueezeLookback)})
+	}
+	return append(params,
+		Param{Name: "Таймфрейм", Value: bs.config.Timeframe},
+		Param{Name: "Стоп-лосс", Value: fmt.Sprintf("%.1fxATR%d", bs.config.StopLossATRMultiplier, bs.config.AtrPeriod)},
+		Param{Name: "Тейк-профит", Value: fmt.Sprintf("1:%.1f", bs.config.TakeProfitRatio)},
+		Param{Name: "Риск на сделку", Value: fmt.Sprintf("%.1f%%", bs.config.RiskPerTrade*100)},
+	)
+}
+
+// Timeframe таймфрейм анализа
+func (bs *BollingerStrategy) Timeframe() string {
+	return bs.config.Timeframe
+}
+
+// HistoryDays сколько календарных дней истории нужно для анализа
+func (bs *BollingerStrategy) HistoryDays() int {
+	return bs.minCandles() * 2
+}
+
+// minCandles минимальное число свечей для расчета полос, RSI и окна сжатия
+func (bs *BollingerStrategy) minCandles() int {
+	mathUtils := &MathUtils{}
+	candles := mathUtils.MaxInt(bs.config.Period, mathUtils.MaxInt(bs.config.RSIPeriod, bs.config.AtrPeriod)+1) + 1
+	if bs.squeeze() {
+		candles = mathUtils.MaxInt(candles, bs.config.Period+bs.config.SqueezeLookback+squeezeRecency)
+	}
+	return candles
+}
+
+// reversion включен ли режим возврата к средней
+func (bs *BollingerStrategy) reversion() bool {
+	return bs.config.Mode == BollingerReversion || bs.config.Mode == BollingerBoth
+}
+
+// squeeze включен ли режим пробоя после сжатия
+func (bs *BollingerStrategy) squeeze() bool {
+	return bs.config.Mode == BollingerSqueeze || bs.config.Mode == BollingerBoth
+}
+
+// modeText режим стратегии для пользователя
+func (bs *BollingerStrategy) modeText() string {
+	switch bs.config.Mode {
+	case BollingerReversion:
+		return "возврат к средней"
+	case BollingerSqueeze:
+		return "пробой после сжатия"
+	case BollingerBoth:
+		return "возврат к средней + пробой после сжатия"
+	default:
+		return bs.config.Mode
+	}
+}
+
+// AnalyzeInstrument загружает свечи и анализирует инструмент по стратегии Боллинджера
+func (bs *BollingerStrategy) AnalyzeInstrument(ctx context.Context, instrument string) ([]Signal, error) {
+	return AnalyzeWith(ctx, bs.source, bs, instrument)
+}
+
+// Analyze анализирует свечи инструмента по стратегии Боллинджера
+func (bs *BollingerStrategy) Analyze(ctx context.Context, instrument string, candles []api.Candle) ([]Signal, error) {
+	if !bs.reversion() && !bs.squeeze() {
+		return nil, fmt.Errorf("неизвестный режим стратегии Боллинджера: %s", bs.config.Mode)
+	}
+	if bs.config.Period <= 1 || bs.config.StdDev <= 0 {
+		return nil, fmt.Errorf("неверные параметры полос Боллинджера: %d/%.1f", bs.config.Period, bs.config.StdDev)
+	}
+	if len(candles) < bs.minCandles() {
+		return nil, fmt.Errorf("недостаточно данных для анализа")
+	}
+
+	highs, lows, closes, _, dates := candleSeries(candles)
+	upper, middle, lower := indicators.Bollinger(closes, bs.config.Period, bs.config.StdDev)
+	atr := indicators.ATR(highs, lows, closes, bs.config.AtrPeriod)
+
+	current := len(closes) - 1
+	price := closes[current]
+	bands := fmt.Sprintf("Полосы: %.2f / %.2f / %.2f", upper[current], middle[current], lower[current])
+
+	var signals []Signal
+	addSignal := func(signalType, reason string) {
+		signal := Signal{
+			Instrument: instrument,
+			SignalType: signalType,
+			Price:      price,
+			Reason:     reason + "\n" + bands,
+			Timestamp:  dates[current],
+		}
+		applyATRRisk(&signal, atr[current], bs.config.StopLossATRMultiplier, bs.config.TakeProfitRatio, bs.config.RiskPerTrade)
+		signals = append(signals, signal)
+	}
+
+	// Пробой после сжатия: закрытие за полосой, недавно ширина полос была минимальной
+	if bs.squeeze() {
+		if squeezeIdx, ok := bs.findSqueeze(upper, middle, lower, current); ok {
+			bandwidth := bandWidth(upper, middle, lower, squeezeIdx) * 100
+			switch {
+			case price > upper[current]:
+				addSignal("entry_long", fmt.Sprintf(
+					"Пробой вверх после сжатия: закрытие %.2f выше верхней полосы %.2f\n"+
+						"Ширина полос %.2f%% - минимум за %d свечей (%s)",
+					price, upper[current], bandwidth, bs.config.SqueezeLookback, dates[squeezeIdx].Format("02.01.2006")))
+			case price < lower[current]:
+				addSignal("entry_short", fmt.Sprintf(
+					"Пробой вниз после сжатия: закрытие %.2f ниже нижней полосы %.2f\n"+
+						"Ширина полос %.2f%% - минимум за %d свечей (%s)",
+					price, lower[current], bandwidth, bs.config.SqueezeLookback, dates[squeezeIdx].Format("02.01.2006")))
+			}
+		}
+	}
+
+	// Возврат к средней: касание внешней полосы с подтверждением RSI.
+	// Если на свече уже есть пробой, касание полосы - его часть, а не разворот.
+	if bs.reversion() && len(signals) == 0 {
+		rsi, ok := indicators.Last(indicators.RSI(closes, bs.config.RSIPeriod))
+		switch {
+		case ok && lows[current] <= lower[current] && rsi <= bs.config.RSIOversold:
+			addSignal("entry_long", fmt.Sprintf(
+				"Касание нижней полосы: минимум %.2f ≤ %.2f, RSI %.1f ≤ %.0f (перепроданность)\n"+
+					"Цель - возврат к средней линии %.2f",
+				lows[current], lower[current], rsi, bs.config.RSIOversold, middle[current]))
+		case ok && highs[current] >= upper[current] && rsi >= bs.config.RSIOverbought:
+			addSignal("entry_short", fmt.Sprintf(
+				"Касание верхней полосы: максимум %.2f ≥ %.2f, RSI %.1f ≥ %.0f (перекупленность)\n"+
+					"Цель - возврат к средней линии %.2f",
+				highs[current], upper[current], rsi, bs.config.RSIOverbought, middle[current]))
+		}
+	}
+
+	return signals, nil
+}
+
+// findSqueeze ищет среди предыдущих squeezeRecency свечей ту, на которой ширина
+// полос была минимальной за SqueezeLookback свечей и меньше максимальной
+func (bs *BollingerStrategy) findSqueeze(upper, middle, lower []float64, current int) (int, bool) {
+	for idx := current - 1; idx >= current-squeezeRecency && idx >= 0; idx-- {
+		width := bandWidth(upper, middle, lower, idx)
+		if math.IsNaN(width) || idx-bs.config.SqueezeLookback+1 < 0 {
+			continue
+		}
+
+		// Ширина не должна быть постоянной: при неизменной волатильности сжатия нет
+		lowest, widest := true, width
+		for j := idx - bs.config.SqueezeLookback + 1; j < idx; j++ {
+			other := bandWidth(upper, middle, lower, j)
+			if math.IsNaN(other) || other < width {
+				lowest = false
+				break
+			}
+			widest = math.Max(widest, other)
+		}
+		if lowest && widest > width {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// bandWidth относительная ширина полос Боллинджера (upper - lower) / middle
+func bandWidth(upper, middle, lower []float64, idx int) float64 {
+	if middle[idx] == 0 {
+		return math.NaN()
+	}
+	return (upper[idx] - lower[idx]) / middle[idx]
+}
@@ -0,0 +1,181 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
+)
+
+// Параметры оценки уверенности сигнала
+const (
+	confidenceChannel = 20 // Канал для расстояния пробоя и средний объем, свечей
+	confidenceATR     = 14 // Период ATR для расстояния пробоя
+	confidenceTrend   = 50 // Период EMA для согласованности с трендом
+	confidenceRSI     = 14 // Период RSI
+)
+
+// Веса факторов уверенности, в сумме 1
+const (
+	weightBreakout = 0.30
+	weightVolume   = 0.25
+	weightTrend    = 0.25
+	weightRSI      = 0.20
+)
+
+// confidenceFactor оценка одного фактора уверенности
+type confidenceFactor struct {
+	score  float64 // Оценка 0..1
+	detail string  // Значение фактора в читаемом виде
+}
+
+// ScoreConfidence оценивает уверенность сигнала на вход по свечам, на которых он
+// получен (последняя свеча - текущая), заполняет Signal.Confidence (0-100%) и
+// дописывает разбор в причину. Факторы: расстояние пробоя канала в ATR, объем
+// относительно среднего, согласованность с трендом EMA и RSI. Фактор, для
+// которого не хватает данных, получает нейтральную оценку 0.5.
+func ScoreConfidence(signal *Signal, candles []api.Candle) {
+	direction := signalDirection(signal.SignalType)
+	if direction == 0 || len(candles) == 0 {
+		return
+	}
+
+	highs, lows, closes, volumes, _ := candleSeries(candles)
+	factors := []confidenceFactor{
+		breakoutFactor(highs, lows, closes, direction),
+		volumeFactor(volumes),
+		trendFactor(closes, direction),
+		rsiFactor(closes, direction),
+	}
+	weights := []float64{weightBreakout, weightVolume, weightTrend, weightRSI}
+
+	score := 0.0
+	details := make([]string, len(factors))
+	for i, factor := range factors {
+		score += factor.score * weights[i]
+		details[i] = factor.detail
+	}
+
+	signal.Confidence = math.Round(score * 100)
+	signal.Reason += fmt.Sprintf("\nУверенность %.0f%%: %s", signal.Confidence, strings.Join(details, ", "))
+}
+
+// signalDirection направление сигнала на вход: 1 - покупка, -1 - продажа, 0 - не вход
+func signalDirection(signalType string) float64 {
+	switch signalType {
+	case "entry_long":
+		return 1
+	case "entry_short":
+		return -1
+	default:
+		return 0
+	}
+}
+
+// breakoutFactor расстояние закрытия за границей канала предыдущих свечей в ATR:
+// 1 ATR внутри канала - 0, на границе - 0.5, 1 ATR за границей - 1
+func breakoutFactor(highs, lows, closes []float64, direction float64) confidenceFactor {
+	current := len(closes) - 1
+	factor := confidenceFactor{score: 0.5, detail: "пробой н/д"}
+	if current < confidenceChannel {
+		return factor
+	}
+
+	atr, ok := indicators.Last(indicators.ATR(highs[:current], lows[:current], closes[:current], confidenceATR))
+	if !ok || atr <= 0 {
+		return factor
+	}
+
+	upper, _, lower := indicators.Donchian(highs[:current], lows[:current], confidenceChannel)
+	distance := (closes[current] - upper[current-1]) / atr
+	if direction < 0 {
+		distance = (lower[current-1] - closes[current]) / atr
+	}
+
+	factor.score = clamp01((distance + 1) / 2)
+	factor.detail = fmt.Sprintf("пробой %+.1f ATR", distance)
+	return factor
+}
+
+// volumeFactor объем текущей свечи относительно среднего за предыдущие свечи:
+// 0.5x и меньше - 0, 2x и больше - 1
+func volumeFactor(volumes []float64) confidenceFactor {
+	current := len(volumes) - 1
+	factor := confidenceFactor{score: 0.5, detail: "объем н/д"}
+	if current < confidenceChannel {
+		return factor
+	}
+
+	average, ok := indicators.Last(indicators.SMA(volumes[:current], confidenceChannel))
+	if !ok || average <= 0 {
+		return factor
+	}
+
+	ratio := volumes[current] / average
+	factor.score = clamp01((ratio - 0.5) / 1.5)
+	factor.detail = fmt.Sprintf("объем %.1fx", ratio)
+	return factor
+}
+
+// trendFactor согласованность с трендом: цена по сторону сделки от EMA и наклон
+// EMA в сторону сделки дают по 0.5
+func trendFactor(closes []float64, direction float64) confidenceFactor {
+	factor := confidenceFactor{score: 0.5, detail: "тренд н/д"}
+
+	ema := indicators.EMA(closes, confidenceTrend)
+	current := len(closes) - 1
+	if current < 1 || math.IsNaN(ema[current]) || math.IsNaN(ema[current-1]) {
+		return factor
+	}
+
+	factor.score = 0
+	var agreed []string
+	if (closes[current]-ema[current])*direction > 0 {
+		factor.score += 0.5
+		agreed = append(agreed, "цена")
+	}
+	if (ema[current]-ema[current-1])*direction > 0 {
+		factor.score += 0.5
+		agreed = append(agreed, "наклон")
+	}
+
+	switch len(agreed) {
+	case 2:
+		factor.detail = fmt.Sprintf("по тренду EMA%d", confidenceTrend)
+	case 1:
+		factor.detail = fmt.Sprintf("EMA%d: совпадает %s", confidenceTrend, agreed[0])
+	default:
+		factor.detail = fmt.Sprintf("против тренда EMA%d", confidenceTrend)
+	}
+	return factor
+}
+
+// rsiFactor импульс RSI в сторону сделки без перегрева: для покупки RSI 50-70 - 1,
+// 30 и ниже или 85 и выше - 0; для продажи зеркально
+func rsiFactor(closes []float64, direction float64) confidenceFactor {
+	factor := confidenceFactor{score: 0.5, detail: "RSI н/д"}
+
+	rsi, ok := indicators.Last(indicators.RSI(closes, confidenceRSI))
+	if !ok {
+		return factor
+	}
+
+	value := rsi
+	if direction < 0 {
+		value = 100 - rsi
+	}
+	if value <= 70 {
+		factor.score = clamp01((value - 30) / 20)
+	} else {
+		factor.score = clamp01((85 - value) / 15)
+	}
+	factor.detail = fmt.Sprintf("RSI %.0f", rsi)
+	return factor
+}
+
+// clamp01 ограничивает значение отрезком [0, 1]
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,57 @@
+package analysis_test
+
+import (
+	"strings"
+	"testing"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+)
+
+func TestScoreConfidence(t *testing.T) {
+	// Ровный рост, затем закрытие далеко за каналом на тройном объеме
+	closes := append(linearSeries(80, 100, 0.5), 145)
+	breakout := fakefetcher.Series(closes)
+	breakout[len(breakout)-1].Volume *= 3
+
+	tests := []struct {
+		name       string
+		signalType string
+		closes     int // Сколько свечей передать (0 - все)
+		confidence float64
+		detail     string
+	}{
+		// Пробой 1, объем 1, тренд 1, RSI 100 - перегрев 0: 30 + 25 + 25
+		{"Long with trend", "entry_long", 0, 80, "по тренду EMA50"},
+		// Против пробоя, тренда и RSI, только объем: 25
+		{"Short against trend", "entry_short", 0, 25, "против тренда EMA50"},
+		// Данных нет ни для одного фактора - все нейтральные
+		{"Short history", "entry_long", 10, 50, "пробой н/д"},
+		{"Exit signal", "exit_long", 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candles := breakout
+			if tt.closes > 0 {
+				candles = candles[len(candles)-tt.closes:]
+			}
+
+			signal := analysis.Signal{SignalType: tt.signalType, Price: 145, Reason: "Сигнал"}
+			analysis.ScoreConfidence(&signal, candles)
+
+			if signal.Confidence != tt.confidence {
+				t.Errorf("Confidence = %.0f, want %.0f (%s)", signal.Confidence, tt.confidence, signal.Reason)
+			}
+			if tt.detail == "" {
+				if signal.Reason != "Сигнал" {
+					t.Errorf("Reason = %q, want unchanged", signal.Reason)
+				}
+				return
+			}
+			if !strings.Contains(signal.Reason, "Уверенность") || !strings.Contains(signal.Reason, tt.detail) {
+				t.Errorf("Reason = %q, want confidence breakdown with %q", signal.Reason, tt.detail)
+			}
+		})
+	}
+}
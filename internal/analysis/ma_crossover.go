@@ -4,14 +4,17 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
 )
 
 // MACrossoverStrategy реализует стратегию пересечения скользящих средних
 type MACrossoverStrategy struct {
-	apiClient  *api.APIClient
+	source     CandleSource
 	config     MACrossoverConfig
 	indicators *TechnicalIndicators
 	mathUtils  *MathUtils // ДОБАВЛЯЕМ
@@ -37,10 +40,14 @@ type MACrossoverConfig struct {
 	}
 
 	Filters struct {
-		TrendFilter   string
-		RSIFilter     bool
-		RSIOverbought int
-		RSIOversold   int
+		TrendFilter    string // none, sma50, sma200, ema50, ema_slope, adx или список через запятую
+		RSIFilter      bool
+		RSIOverbought  int
+		RSIOversold    int
+		EMASlopePeriod int     // Период EMA для фильтра ema_slope
+		EMASlopeBars   int     // За сколько свечей измеряется наклон EMA
+		ADXPeriod      int     // Период ADX для фильтра adx
+		ADXThreshold   float64 // Минимальный ADX для входа
 	}
 }
 
@@ -53,29 +60,63 @@ type TechnicalIndicators struct {
 	RSI      []float64
 	Volumes  []float64
 	Prices   []float64
+	Highs    []float64
+	Lows     []float64
 	Dates    []time.Time
 }
 
 // NewMACrossoverStrategy создает новую стратегию MA Crossover
-func NewMACrossoverStrategy(apiClient *api.APIClient, config MACrossoverConfig) *MACrossoverStrategy {
+func NewMACrossoverStrategy(source CandleSource, config MACrossoverConfig) *MACrossoverStrategy {
 	return &MACrossoverStrategy{
-		apiClient: apiClient,
+		source:    source,
 		config:    config,
 		mathUtils: &MathUtils{}, // ДОБАВЛЯЕМ
 	}
 }
 
-// Analyze анализирует инструмент по стратегии MA Crossover
-func (m *MACrossoverStrategy) Analyze(ctx context.Context, instrument string) ([]Signal, error) {
-	// Получаем свечи за нужный период
-	to := time.Now().Format("2006-01-02")
-	from := time.Now().AddDate(0, 0, -m.config.SlowPeriod*3).Format("2006-01-02")
+// Name короткое имя стратегии
+func (m *MACrossoverStrategy) Name() string {
+	return "ma_crossover"
+}
 
-	candles, err := m.apiClient.GetCandles(ctx, instrument, m.config.Timeframe, from, to)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка получения свечей: %w", err)
+// Title название стратегии для пользователя
+func (m *MACrossoverStrategy) Title() string {
+	return "MA Crossover"
+}
+
+// Params текущие параметры стратегии
+func (m *MACrossoverStrategy) Params() []Param {
+	maType := "SMA"
+	if m.config.UseEMA {
+		maType = "EMA"
 	}
+	return []Param{
+		{Name: "Тип MA", Value: maType},
+		{Name: "Быстрая / медленная MA", Value: fmt.Sprintf("%d / %d", m.config.FastPeriod, m.config.SlowPeriod)},
+		{Name: "Таймфрейм", Value: m.config.Timeframe},
+		{Name: "Стоп-лосс", Value: fmt.Sprintf("%.1fxATR", m.config.StopLossATRMultiplier)},
+		{Name: "Тейк-профит", Value: fmt.Sprintf("1:%.1f", m.config.TakeProfitRatio)},
+		{Name: "Риск на сделку", Value: fmt.Sprintf("%.1f%%", m.config.RiskPerTrade*100)},
+	}
+}
+
+// Timeframe таймфрейм анализа
+func (m *MACrossoverStrategy) Timeframe() string {
+	return m.config.Timeframe
+}
 
+// HistoryDays сколько календарных дней истории нужно для анализа
+func (m *MACrossoverStrategy) HistoryDays() int {
+	return m.mathUtils.MaxInt(m.config.SlowPeriod, m.trendFilterCandles()) * 3
+}
+
+// AnalyzeInstrument загружает свечи и анализирует инструмент по стратегии MA Crossover
+func (m *MACrossoverStrategy) AnalyzeInstrument(ctx context.Context, instrument string) ([]Signal, error) {
+	return AnalyzeWith(ctx, m.source, m, instrument)
+}
+
+// Analyze анализирует свечи инструмента по стратегии MA Crossover
+func (m *MACrossoverStrategy) Analyze(ctx context.Context, instrument string, candles []api.Candle) ([]Signal, error) {
 	// Преобразуем свечи в массивы
 	prices, highs, lows, closes, volumes, dates := m.parseCandles(candles)
 
@@ -96,58 +137,12 @@ func (m *MACrossoverStrategy) Analyze(ctx context.Context, instrument string) ([
 }
 
 // parseCandles преобразует свечи в массивы
-func (m *MACrossoverStrategy) parseCandles(candles []map[string]interface{}) (
+func (m *MACrossoverStrategy) parseCandles(candles []api.Candle) (
 	prices, highs, lows, closes, volumes []float64,
 	dates []time.Time,
 ) {
-	for _, candle := range candles {
-		// Цена закрытия
-		if closeVal, ok := candle["close"].(float64); ok {
-			closes = append(closes, closeVal)
-			prices = append(prices, closeVal)
-		} else if closeVal, ok := candle["close"].(int); ok {
-			closes = append(closes, float64(closeVal))
-			prices = append(prices, float64(closeVal))
-		}
-
-		// Максимум
-		if highVal, ok := candle["high"].(float64); ok {
-			highs = append(highs, highVal)
-		} else if highVal, ok := candle["high"].(int); ok {
-			highs = append(highs, float64(highVal))
-		}
-
-		// Минимум
-		if lowVal, ok := candle["low"].(float64); ok {
-			lows = append(lows, lowVal)
-		} else if lowVal, ok := candle["low"].(int); ok {
-			lows = append(lows, float64(lowVal))
-		}
-
-		// Объем
-		if volumeVal, ok := candle["volume"].(float64); ok {
-			volumes = append(volumes, volumeVal)
-		} else if volumeVal, ok := candle["volume"].(int); ok {
-			volumes = append(volumes, float64(volumeVal))
-		} else {
-			volumes = append(volumes, 0)
-		}
-
-		// Дата
-		if beginVal, ok := candle["begin"].(string); ok {
-			if t, err := time.Parse("2006-01-02 15:04:05", beginVal); err == nil {
-				dates = append(dates, t)
-			} else if t, err := time.Parse("2006-01-02", beginVal); err == nil {
-				dates = append(dates, t)
-			} else {
-				dates = append(dates, time.Now())
-			}
-		} else {
-			dates = append(dates, time.Now())
-		}
-	}
-
-	return prices, highs, lows, closes, volumes, dates
+	highs, lows, closes, volumes, dates = candleSeries(candles)
+	return closes, highs, lows, closes, volumes, dates
 }
 
 // calculateIndicators рассчитывает все технические индикаторы
@@ -157,182 +152,34 @@ func (m *MACrossoverStrategy) calculateIndicators(
 ) {
 	m.indicators = &TechnicalIndicators{
 		Prices:  prices,
+		Highs:   highs,
+		Lows:    lows,
 		Volumes: volumes,
 		Dates:   dates,
 	}
 
 	// Рассчитываем скользящие средние
 	if m.config.UseEMA {
-		m.indicators.FastMA = m.calculateEMA(closes, m.config.FastPeriod)
-		m.indicators.SlowMA = m.calculateEMA(closes, m.config.SlowPeriod)
+		m.indicators.FastMA = indicators.EMA(closes, m.config.FastPeriod)
+		m.indicators.SlowMA = indicators.EMA(closes, m.config.SlowPeriod)
 		if m.config.SignalPeriod > 0 {
-			m.indicators.SignalMA = m.calculateEMA(closes, m.config.SignalPeriod)
+			m.indicators.SignalMA = indicators.EMA(closes, m.config.SignalPeriod)
 		}
 	} else {
-		m.indicators.FastMA = m.calculateSMA(closes, m.config.FastPeriod)
-		m.indicators.SlowMA = m.calculateSMA(closes, m.config.SlowPeriod)
+		m.indicators.FastMA = indicators.SMA(closes, m.config.FastPeriod)
+		m.indicators.SlowMA = indicators.SMA(closes, m.config.SlowPeriod)
 		if m.config.SignalPeriod > 0 {
-			m.indicators.SignalMA = m.calculateSMA(closes, m.config.SignalPeriod)
+			m.indicators.SignalMA = indicators.SMA(closes, m.config.SignalPeriod)
 		}
 	}
 
 	// Рассчитываем ATR для стоп-лосса
-	m.indicators.ATR = m.calculateATR(highs, lows, closes, 14)
+	m.indicators.ATR = indicators.ATR(highs, lows, closes, 14)
 
 	// Рассчитываем RSI если нужен фильтр
 	if m.config.Filters.RSIFilter {
-		m.indicators.RSI = m.calculateRSI(closes, 14)
-	}
-}
-
-// calculateSMA рассчитывает простую скользящую среднюю
-func (m *MACrossoverStrategy) calculateSMA(data []float64, period int) []float64 {
-	if len(data) < period {
-		return make([]float64, len(data))
-	}
-
-	sma := make([]float64, len(data))
-	for i := period - 1; i < len(data); i++ {
-		sum := 0.0
-		for j := i - period + 1; j <= i; j++ {
-			sum += data[j]
-		}
-		sma[i] = sum / float64(period)
+		m.indicators.RSI = indicators.RSI(closes, 14)
 	}
-
-	// Заполняем начало NaN значениями
-	for i := 0; i < period-1; i++ {
-		sma[i] = data[i]
-	}
-
-	return sma
-}
-
-// calculateEMA рассчитывает экспоненциальную скользящую среднюю
-func (m *MACrossoverStrategy) calculateEMA(data []float64, period int) []float64 {
-	if len(data) < period {
-		return make([]float64, len(data))
-	}
-
-	ema := make([]float64, len(data))
-	multiplier := 2.0 / (float64(period) + 1.0)
-
-	// Первое значение EMA = SMA
-	sum := 0.0
-	for i := 0; i < period; i++ {
-		sum += data[i]
-	}
-	ema[period-1] = sum / float64(period)
-
-	// Расчет остальных EMA
-	for i := period; i < len(data); i++ {
-		ema[i] = (data[i]-ema[i-1])*multiplier + ema[i-1]
-	}
-
-	// Заполняем начало
-	for i := 0; i < period-1; i++ {
-		ema[i] = data[i]
-	}
-
-	return ema
-}
-
-// calculateATR рассчитывает Average True Range
-func (m *MACrossoverStrategy) calculateATR(highs, lows, closes []float64, period int) []float64 {
-	if len(highs) < period+1 {
-		return make([]float64, len(highs))
-	}
-
-	tr := make([]float64, len(highs))
-	atr := make([]float64, len(highs))
-
-	// Расчет True Range
-	for i := 1; i < len(highs); i++ {
-		hl := highs[i] - lows[i]
-		hcp := math.Abs(highs[i] - closes[i-1])
-		lcp := math.Abs(lows[i] - closes[i-1])
-		tr[i] = math.Max(hl, math.Max(hcp, lcp))
-	}
-
-	// Первое значение ATR = среднее первых period TR
-	sum := 0.0
-	for i := 1; i <= period; i++ {
-		sum += tr[i]
-	}
-	atr[period] = sum / float64(period)
-
-	// Расчет остальных ATR по формуле Уайлдера
-	for i := period + 1; i < len(highs); i++ {
-		atr[i] = (atr[i-1]*float64(period-1) + tr[i]) / float64(period)
-	}
-
-	// Заполняем начало
-	for i := 0; i <= period; i++ {
-		atr[i] = tr[i]
-	}
-
-	return atr
-}
-
-// calculateRSI рассчитывает Relative Strength Index
-func (m *MACrossoverStrategy) calculateRSI(closes []float64, period int) []float64 {
-	if len(closes) < period+1 {
-		return make([]float64, len(closes))
-	}
-
-	rsi := make([]float64, len(closes))
-	gains := make([]float64, len(closes))
-	losses := make([]float64, len(closes))
-
-	// Расчет прибылей и убытков
-	for i := 1; i < len(closes); i++ {
-		change := closes[i] - closes[i-1]
-		if change > 0 {
-			gains[i] = change
-			losses[i] = 0
-		} else {
-			gains[i] = 0
-			losses[i] = -change
-		}
-	}
-
-	// Первые средние значения
-	avgGain := 0.0
-	avgLoss := 0.0
-	for i := 1; i <= period; i++ {
-		avgGain += gains[i]
-		avgLoss += losses[i]
-	}
-	avgGain /= float64(period)
-	avgLoss /= float64(period)
-
-	// Первое значение RSI
-	if avgLoss == 0 {
-		rsi[period] = 100
-	} else {
-		rs := avgGain / avgLoss
-		rsi[period] = 100 - (100 / (1 + rs))
-	}
-
-	// Расчет остальных RSI
-	for i := period + 1; i < len(closes); i++ {
-		avgGain = (avgGain*float64(period-1) + gains[i]) / float64(period)
-		avgLoss = (avgLoss*float64(period-1) + losses[i]) / float64(period)
-
-		if avgLoss == 0 {
-			rsi[i] = 100
-		} else {
-			rs := avgGain / avgLoss
-			rsi[i] = 100 - (100 / (1 + rs))
-		}
-	}
-
-	// Заполняем начало
-	for i := 0; i < period; i++ {
-		rsi[i] = 50
-	}
-
-	return rsi
 }
 
 // analyzeSignals анализирует и формирует торговые сигналы
@@ -352,11 +199,6 @@ func (m *MACrossoverStrategy) analyzeSignals(
 	currentDate := dates[currentIdx]
 	currentVolume := volumes[currentIdx]
 
-	// Проверяем фильтры
-	if !m.checkFilters(currentIdx) {
-		return signals, nil
-	}
-
 	// Анализируем пересечения
 	crossSignals := m.analyzeCrossovers(instrument, currentIdx, currentPrice, currentDate)
 	signals = append(signals, crossSignals...)
@@ -376,68 +218,227 @@ func (m *MACrossoverStrategy) analyzeSignals(
 	return signals, nil
 }
 
-// checkFilters проверяет все фильтры
-func (m *MACrossoverStrategy) checkFilters(currentIdx int) bool {
-	// Фильтр по тренду
-	if !m.checkTrendFilter(currentIdx) {
-		return false
+// Параметры фильтров тренда по умолчанию
+const (
+	defaultEMASlopePeriod = 50
+	defaultEMASlopeBars   = 5
+	defaultADXPeriod      = 14
+	defaultADXThreshold   = 20.0
+)
+
+// trendFilters список фильтров тренда из конфигурации ("sma200,adx" -> [sma200 adx])
+func (m *MACrossoverStrategy) trendFilters() []string {
+	var filters []string
+	for _, name := range strings.Split(m.config.Filters.TrendFilter, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && name != "none" {
+			filters = append(filters, name)
+		}
 	}
+	return filters
+}
+
+// trendFilterCandles сколько свечей нужно фильтрам тренда
+func (m *MACrossoverStrategy) trendFilterCandles() int {
+	candles := 0
+	for _, name := range m.trendFilters() {
+		switch {
+		case name == "ema_slope":
+			candles = m.mathUtils.MaxInt(candles, m.emaSlopePeriod()+m.emaSlopeBars())
+		case name == "adx":
+			candles = m.mathUtils.MaxInt(candles, 2*m.adxPeriod())
+		default:
+			if _, period, ok := parseMAFilter(name); ok {
+				candles = m.mathUtils.MaxInt(candles, period)
+			}
+		}
+	}
+	return candles
+}
+
+// checkFilters проверяет все фильтры для сигнала в направлении long (или short).
+// Возвращает признак прохождения и пояснения для причины сигнала.
+func (m *MACrossoverStrategy) checkFilters(currentIdx int, long bool) (bool, []string) {
+	passed, notes := m.checkTrendFilter(currentIdx, long)
 
 	// Фильтр по RSI
-	if !m.checkRSIFilter(currentIdx) {
-		return false
+	rsiPassed, rsiNote := m.checkRSIFilter(currentIdx, long)
+	if rsiNote != "" {
+		notes = append(notes, rsiNote)
 	}
 
-	return true
+	return passed && rsiPassed, notes
 }
 
-// checkTrendFilter проверяет фильтр по тренду
-func (m *MACrossoverStrategy) checkTrendFilter(currentIdx int) bool {
-	if m.config.Filters.TrendFilter == "none" {
-		return true
+// checkTrendFilter проверяет фильтры тренда: лонг только по тренду вверх,
+// шорт только по тренду вниз
+func (m *MACrossoverStrategy) checkTrendFilter(currentIdx int, long bool) (bool, []string) {
+	prices := m.indicators.Prices
+	price := prices[currentIdx]
+
+	passed := true
+	var notes []string
+	check := func(ok bool, note string) {
+		mark := "✅"
+		if !ok {
+			mark = "❌"
+			passed = false
+		}
+		notes = append(notes, mark+" "+note)
 	}
 
-	//price := m.indicators.Prices[currentIdx]
+	for _, name := range m.trendFilters() {
+		switch name {
+		case "ema_slope":
+			ema := indicators.EMA(prices, m.emaSlopePeriod())
+			from := currentIdx - m.emaSlopeBars()
+			if from < 0 || math.IsNaN(ema[from]) || math.IsNaN(ema[currentIdx]) {
+				check(false, fmt.Sprintf("Наклон EMA%d: недостаточно данных", m.emaSlopePeriod()))
+				continue
+			}
+			slope := (ema[currentIdx]/ema[from] - 1) * 100
+			direction := "растет"
+			if slope <= 0 {
+				direction = "падает"
+			}
+			check(slope > 0 == long, fmt.Sprintf("Наклон EMA%d за %d свечей: %+.2f%% (%s)",
+				m.emaSlopePeriod(), m.emaSlopeBars(), slope, direction))
+
+		case "adx":
+			adx, plusDI, minusDI := indicators.ADX(m.indicators.Highs, m.indicators.Lows, prices, m.adxPeriod())
+			if math.IsNaN(adx[currentIdx]) {
+				check(false, fmt.Sprintf("ADX%d: недостаточно данных", m.adxPeriod()))
+				continue
+			}
+			strong := adx[currentIdx] >= m.adxThreshold()
+			aligned := plusDI[currentIdx] > minusDI[currentIdx] == long
+			check(strong && aligned, fmt.Sprintf("ADX%d: %.1f (порог %.0f), +DI %.1f / -DI %.1f",
+				m.adxPeriod(), adx[currentIdx], m.adxThreshold(), plusDI[currentIdx], minusDI[currentIdx]))
+
+		default:
+			maType, period, ok := parseMAFilter(name)
+			if !ok {
+				check(false, fmt.Sprintf("Неизвестный фильтр тренда: %s", name))
+				continue
+			}
 
-	switch m.config.Filters.TrendFilter {
-	case "sma50":
-		if len(m.indicators.Prices) >= 50 {
-			sma50 := m.calculateSMA(m.indicators.Prices, 50)
-			if currentIdx < len(sma50) {
-				// Для лонга: цена выше SMA50 (восходящий тренд)
-				// Для шорта: цена ниже SMA50 (нисходящий тренд)
-				// Пока пропускаем, т.к. не знаем тип сигнала
-				return true
+			var ma []float64
+			if maType == "EMA" {
+				ma = indicators.EMA(prices, period)
+			} else {
+				ma = indicators.SMA(prices, period)
 			}
-		}
-	case "sma200":
-		if len(m.indicators.Prices) >= 200 {
-			sma200 := m.calculateSMA(m.indicators.Prices, 200)
-			if currentIdx < len(sma200) {
-				return true
+			if math.IsNaN(ma[currentIdx]) {
+				check(false, fmt.Sprintf("%s%d: недостаточно данных", maType, period))
+				continue
+			}
+
+			// Для лонга: цена выше MA (восходящий тренд), для шорта: ниже (нисходящий)
+			position := "выше"
+			if price <= ma[currentIdx] {
+				position = "ниже"
 			}
+			check(price > ma[currentIdx] == long, fmt.Sprintf("Цена %.2f %s %s%d (%.2f)",
+				price, position, maType, period, ma[currentIdx]))
 		}
 	}
 
-	return true
+	return passed, notes
 }
 
-// checkRSIFilter проверяет фильтр по RSI
-func (m *MACrossoverStrategy) checkRSIFilter(currentIdx int) bool {
-	if !m.config.Filters.RSIFilter || len(m.indicators.RSI) == 0 {
-		return true
+// checkRSIFilter проверяет фильтр по RSI: для входа в лонг RSI не должен быть
+// в зоне перекупленности, для входа в шорт - в зоне перепроданности
+func (m *MACrossoverStrategy) checkRSIFilter(currentIdx int, long bool) (bool, string) {
+	if !m.config.Filters.RSIFilter {
+		return true, ""
 	}
 
-	if currentIdx >= len(m.indicators.RSI) {
-		return true
+	if currentIdx >= len(m.indicators.RSI) || math.IsNaN(m.indicators.RSI[currentIdx]) {
+		return false, "❌ RSI: недостаточно данных"
+	}
+
+	rsi := m.indicators.RSI[currentIdx]
+	if long {
+		if rsi < float64(m.config.Filters.RSIOverbought) {
+			return true, fmt.Sprintf("✅ RSI %.1f ниже уровня перекупленности %d", rsi, m.config.Filters.RSIOverbought)
+		}
+		return false, fmt.Sprintf("❌ RSI %.1f в зоне перекупленности (≥ %d)", rsi, m.config.Filters.RSIOverbought)
 	}
 
-	//rsi := m.indicators.RSI[currentIdx]
+	if rsi > float64(m.config.Filters.RSIOversold) {
+		return true, fmt.Sprintf("✅ RSI %.1f выше уровня перепроданности %d", rsi, m.config.Filters.RSIOversold)
+	}
+	return false, fmt.Sprintf("❌ RSI %.1f в зоне перепроданности (≤ %d)", rsi, m.config.Filters.RSIOversold)
+}
 
-	// Для входа в лонг: RSI не должен быть в зоне перекупленности
-	// Для входа в шорт: RSI не должен быть в зоне перепроданности
-	// Пока пропускаем, конкретная проверка будет в analyzeCrossovers
-	return true
+// parseMAFilter разбирает фильтр вида sma200 или ema50
+func parseMAFilter(name string) (string, int, bool) {
+	var maType string
+	switch {
+	case strings.HasPrefix(name, "sma"):
+		maType = "SMA"
+	case strings.HasPrefix(name, "ema"):
+		maType = "EMA"
+	default:
+		return "", 0, false
+	}
+
+	period, err := strconv.Atoi(name[3:])
+	if err != nil || period <= 0 {
+		return "", 0, false
+	}
+	return maType, period, true
+}
+
+// emaSlopePeriod период EMA для фильтра наклона
+func (m *MACrossoverStrategy) emaSlopePeriod() int {
+	if m.config.Filters.EMASlopePeriod > 0 {
+		return m.config.Filters.EMASlopePeriod
+	}
+	return defaultEMASlopePeriod
+}
+
+// emaSlopeBars за сколько свечей измеряется наклон EMA
+func (m *MACrossoverStrategy) emaSlopeBars() int {
+	if m.config.Filters.EMASlopeBars > 0 {
+		return m.config.Filters.EMASlopeBars
+	}
+	return defaultEMASlopeBars
+}
+
+// adxPeriod период ADX для фильтра силы тренда
+func (m *MACrossoverStrategy) adxPeriod() int {
+	if m.config.Filters.ADXPeriod > 0 {
+		return m.config.Filters.ADXPeriod
+	}
+	return defaultADXPeriod
+}
+
+// adxThreshold минимальное значение ADX для входа
+func (m *MACrossoverStrategy) adxThreshold() float64 {
+	if m.config.Filters.ADXThreshold > 0 {
+		return m.config.Filters.ADXThreshold
+	}
+	return defaultADXThreshold
+}
+
+// filteredSignal формирует сигнал по пересечению с учетом фильтров. Если фильтры
+// не пройдены, возвращается информационный сигнал no_signal с причиной отказа.
+func (m *MACrossoverStrategy) filteredSignal(signal Signal, currentIdx int, crossName string) Signal {
+	passed, notes := m.checkFilters(currentIdx, signal.SignalType == "entry_long")
+	if len(notes) == 0 {
+		return signal
+	}
+
+	if passed {
+		signal.Reason += "\n\n🔎 ФИЛЬТРЫ:\n" + strings.Join(notes, "\n")
+		return signal
+	}
+
+	signal.SignalType = "no_signal"
+	signal.Reason = fmt.Sprintf("⛔ %s отфильтровано\n\n%s\n\n🔎 ФИЛЬТРЫ:\n%s",
+		crossName, signal.Reason, strings.Join(notes, "\n"))
+	return signal
 }
 
 // analyzeCrossovers анализирует пересечения скользящих средних
@@ -469,28 +470,23 @@ func (m *MACrossoverStrategy) analyzeCrossovers(
 
 		// Проверяем подтверждение
 		if m.checkConfirmation(currentIdx, "golden") {
-			// Проверяем RSI фильтр для лонга
-			if !m.config.Filters.RSIFilter ||
-				(len(m.indicators.RSI) > currentIdx &&
-					m.indicators.RSI[currentIdx] < float64(m.config.Filters.RSIOverbought)) {
-
-				distance := ((fastNow - slowNow) / slowNow) * 100
-				reason := fmt.Sprintf("Золотое пересечение: SMA%d (%.2f) пересекает SMA%d (%.2f) снизу вверх\n"+
-					"Текущая цена: %.2f | Расстояние между MA: %.2f%%\n"+
-					"Сигнал подтвержден %d свечами",
-					m.config.FastPeriod, fastNow,
-					m.config.SlowPeriod, slowNow,
-					currentPrice, distance,
-					m.config.CrossoverTypes.RequireConfirmation)
-
-				signals = append(signals, Signal{
-					Instrument: instrument,
-					SignalType: "entry_long",
-					Price:      currentPrice,
-					Reason:     reason,
-					Timestamp:  currentDate,
-				})
-			}
+			distance := ((fastNow - slowNow) / slowNow) * 100
+			reason := fmt.Sprintf("Золотое пересечение: SMA%d (%.2f) пересекает SMA%d (%.2f) снизу вверх\n"+
+				"Текущая цена: %.2f | Расстояние между MA: %.2f%%\n"+
+				"Сигнал подтвержден %d свечами",
+				m.config.FastPeriod, fastNow,
+				m.config.SlowPeriod, slowNow,
+				currentPrice, distance,
+				m.config.CrossoverTypes.RequireConfirmation)
+
+			// Фильтры тренда и RSI для лонга
+			signals = append(signals, m.filteredSignal(Signal{
+				Instrument: instrument,
+				SignalType: "entry_long",
+				Price:      currentPrice,
+				Reason:     reason,
+				Timestamp:  currentDate,
+			}, currentIdx, "Золотое пересечение"))
 		}
 	}
 
@@ -501,28 +497,23 @@ func (m *MACrossoverStrategy) analyzeCrossovers(
 
 		// Проверяем подтверждение
 		if m.checkConfirmation(currentIdx, "death") {
-			// Проверяем RSI фильтр для шорта
-			if !m.config.Filters.RSIFilter ||
-				(len(m.indicators.RSI) > currentIdx &&
-					m.indicators.RSI[currentIdx] > float64(m.config.Filters.RSIOversold)) {
-
-				distance := ((slowNow - fastNow) / fastNow) * 100
-				reason := fmt.Sprintf("Мертвое пересечение: SMA%d (%.2f) пересекает SMA%d (%.2f) сверху вниз\n"+
-					"Текущая цена: %.2f | Расстояние между MA: %.2f%%\n"+
-					"Сигнал подтвержден %d свечами",
-					m.config.FastPeriod, fastNow,
-					m.config.SlowPeriod, slowNow,
-					currentPrice, distance,
-					m.config.CrossoverTypes.RequireConfirmation)
-
-				signals = append(signals, Signal{
-					Instrument: instrument,
-					SignalType: "entry_short",
-					Price:      currentPrice,
-					Reason:     reason,
-					Timestamp:  currentDate,
-				})
-			}
+			distance := ((slowNow - fastNow) / fastNow) * 100
+			reason := fmt.Sprintf("Мертвое пересечение: SMA%d (%.2f) пересекает SMA%d (%.2f) сверху вниз\n"+
+				"Текущая цена: %.2f | Расстояние между MA: %.2f%%\n"+
+				"Сигнал подтвержден %d свечами",
+				m.config.FastPeriod, fastNow,
+				m.config.SlowPeriod, slowNow,
+				currentPrice, distance,
+				m.config.CrossoverTypes.RequireConfirmation)
+
+			// Фильтры тренда и RSI для шорта
+			signals = append(signals, m.filteredSignal(Signal{
+				Instrument: instrument,
+				SignalType: "entry_short",
+				Price:      currentPrice,
+				Reason:     reason,
+				Timestamp:  currentDate,
+			}, currentIdx, "Мертвое пересечение"))
 		}
 	}
 
@@ -616,55 +607,6 @@ func (m *MACrossoverStrategy) calculateRiskManagement(signal *Signal, currentIdx
 		return
 	}
 
-	currentATR := m.indicators.ATR[currentIdx]
-
-	switch signal.SignalType {
-	case "entry_long":
-		// Стоп-лосс ниже текущей цены на ATR * множитель
-		stopLoss := signal.Price - (currentATR * m.config.StopLossATRMultiplier)
-		takeProfit := signal.Price + (currentATR * m.config.StopLossATRMultiplier * m.config.TakeProfitRatio)
-
-		signal.StopLoss = stopLoss
-		signal.TakeProfit = takeProfit
-
-		// Расчет размера позиции
-		riskAmount := 100000.0 * m.config.RiskPerTrade // Предполагаем счет 100k
-		riskPerShare := signal.Price - stopLoss
-		if riskPerShare > 0 {
-			signal.PositionSize = riskAmount / riskPerShare
-		}
-
-		signal.Reason += fmt.Sprintf("\n\n🎯 УПРАВЛЕНИЕ РИСКАМИ:\n"+
-			"• Стоп-лосс: %.2f (%.1f%%)\n"+
-			"• Тейк-профит: %.2f (риск:прибыль = 1:%.1f)\n"+
-			"• Размер позиции: %.0f шт.\n"+
-			"• ATR: %.2f (текущая волатильность)",
-			stopLoss, ((signal.Price-stopLoss)/signal.Price)*100,
-			takeProfit, m.config.TakeProfitRatio,
-			signal.PositionSize, currentATR)
-
-	case "entry_short":
-		// Стоп-лосс выше текущей цены на ATR * множитель
-		stopLoss := signal.Price + (currentATR * m.config.StopLossATRMultiplier)
-		takeProfit := signal.Price - (currentATR * m.config.StopLossATRMultiplier * m.config.TakeProfitRatio)
-
-		signal.StopLoss = stopLoss
-		signal.TakeProfit = takeProfit
-
-		// Расчет размера позиции
-		riskAmount := 100000.0 * m.config.RiskPerTrade
-		riskPerShare := stopLoss - signal.Price
-		if riskPerShare > 0 {
-			signal.PositionSize = riskAmount / riskPerShare
-		}
-
-		signal.Reason += fmt.Sprintf("\n\n🎯 УПРАВЛЕНИЕ РИСКАМИ:\n"+
-			"• Стоп-лосс: %.2f (%.1f%%)\n"+
-			"• Тейк-профит: %.2f (риск:прибыль = 1:%.1f)\n"+
-			"• Размер позиции: %.0f шт.\n"+
-			"• ATR: %.2f (текущая волатильность)",
-			stopLoss, ((stopLoss-signal.Price)/signal.Price)*100,
-			takeProfit, m.config.TakeProfitRatio,
-			signal.PositionSize, currentATR)
-	}
+	applyATRRisk(signal, m.indicators.ATR[currentIdx],
+		m.config.StopLossATRMultiplier, m.config.TakeProfitRatio, m.config.RiskPerTrade)
 }
@@ -0,0 +1,252 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
+)
+
+// MACDStrategy реализует стратегию MACD: пересечения сигнальной и нулевой линии
+// и дивергенции гистограммы с ценой
+type MACDStrategy struct {
+	source CandleSource
+	config MACDConfig
+}
+
+// MACDConfig конфигурация стратегии MACD
+type MACDConfig struct {
+	Timeframe             string
+	FastPeriod            int
+	SlowPeriod            int
+	SignalPeriod          int
+	AtrPeriod             int
+	RiskPerTrade          float64
+	StopLossATRMultiplier float64
+	TakeProfitRatio       float64
+
+	SignalCross        bool // Пересечение MACD и сигнальной линии
+	ZeroCross          bool // Пересечение MACD и нулевой линии
+	Divergence         bool // Дивергенция гистограммы и цены
+	DivergenceLookback int  // Окно поиска дивергенции (свечей)
+}
+
+// macdEvent событие MACD на текущей свече
+type macdEvent struct {
+	bullish bool
+	reason  string
+}
+
+// NewMACDStrategy создает новую стратегию MACD
+func NewMACDStrategy(source CandleSource, config MACDConfig) *MACDStrategy {
+	return &MACDStrategy{
+		source: source,
+		config: config,
+	}
+}
+
+// Name короткое имя стратегии
+func (m *MACDStrategy) Name() string {
+	return "macd"
+}
+
+// Title название стратегии для пользователя
+func (m *MACDStrategy) Title() string {
+	return "MACD"
+}
+
+// Params текущие параметры стратегии
+func (m *MACDStrategy) Params() []Param {
+	var types []string
+	if m.config.SignalCross {
+		types = append(types, "сигнальная линия")
+	}
+	if m.config.ZeroCross {
+		types = append(types, "нулевая линия")
+	}
+	if m.config.Divergence {
+		types = append(types, fmt.Sprintf("дивергенция (%d свечей)", m.config.DivergenceLookback))
+	}
+	if len(types) == 0 {
+		types = append(types, "нет")
+	}
+
+	return []Param{
+		{Name: "MACD", Value: fmt.Sprintf("%d / %d / %d", m.config.FastPeriod, m.config.SlowPeriod, m.config.SignalPeriod)},
+		{Name: "Сигналы", Value: strings.Join(types, ", ")},
+		{Name: "Таймфрейм", Value: m.config.Timeframe},
+		{Name: "Стоп-лосс", Value: fmt.Sprintf("%.1fxATR%d", m.config.StopLossATRMultiplier, m.config.AtrPeriod)},
+		{Name: "Тейк-профит", Value: fmt.Sprintf("1:%.1f", m.config.TakeProfitRatio)},
+		{Name: "Риск на сделку", Value: fmt.Sprintf("%.1f%%", m.config.RiskPerTrade*100)},
+	}
+}
+
+// Timeframe таймфрейм анализа
+func (m *MACDStrategy) Timeframe() string {
+	return m.config.Timeframe
+}
+
+// HistoryDays сколько календарных дней истории нужно для анализа с учетом таймфрейма
+func (m *MACDStrategy) HistoryDays() int {
+	return HistoryDaysFor(m.config.Timeframe, m.minCandles()+m.config.DivergenceLookback)
+}
+
+// minCandles минимальное число свечей: разогрев сигнальной линии и предыдущая свеча для пересечений
+func (m *MACDStrategy) minCandles() int {
+	return m.config.SlowPeriod + m.config.SignalPeriod
+}
+
+// AnalyzeInstrument загружает свечи и анализирует инструмент по стратегии MACD
+func (m *MACDStrategy) AnalyzeInstrument(ctx context.Context, instrument string) ([]Signal, error) {
+	return AnalyzeWith(ctx, m.source, m, instrument)
+}
+
+// Analyze анализирует свечи инструмента по стратегии MACD. Бычьи события дают
+// сигнал на покупку и выход из продажи, медвежьи - на продажу и выход из покупки.
+func (m *MACDStrategy) Analyze(ctx context.Context, instrument string, candles []api.Candle) ([]Signal, error) {
+	if m.config.FastPeriod <= 0 || m.config.SlowPeriod <= m.config.FastPeriod || m.config.SignalPeriod <= 0 {
+		return nil, fmt.Errorf("неверные периоды MACD: %d/%d/%d",
+			m.config.FastPeriod, m.config.SlowPeriod, m.config.SignalPeriod)
+	}
+	if len(candles) < m.minCandles() {
+		return nil, fmt.Errorf("недостаточно данных для анализа")
+	}
+
+	highs, lows, closes, _, dates := candleSeries(candles)
+	macd, signalLine, histogram := indicators.MACD(closes, m.config.FastPeriod, m.config.SlowPeriod, m.config.SignalPeriod)
+	atr := indicators.ATR(highs, lows, closes, m.config.AtrPeriod)
+
+	current := len(closes) - 1
+
+	var events []macdEvent
+	if m.config.SignalCross {
+		events = append(events, m.signalCross(macd, signalLine, current)...)
+	}
+	if m.config.ZeroCross {
+		events = append(events, m.zeroCross(macd, current)...)
+	}
+	if m.config.Divergence {
+		events = append(events, m.divergence(closes, histogram, current)...)
+	}
+
+	// Объединяем события одного направления в один сигнал
+	var bullish, bearish []string
+	for _, event := range events {
+		if event.bullish {
+			bullish = append(bullish, event.reason)
+		} else {
+			bearish = append(bearish, event.reason)
+		}
+	}
+
+	price := closes[current]
+	state := fmt.Sprintf("MACD: %.2f | Сигнальная: %.2f | Гистограмма: %.2f",
+		macd[current], signalLine[current], histogram[current])
+
+	newSignal := func(signalType string, reasons []string) Signal {
+		return Signal{
+			Instrument: instrument,
+			SignalType: signalType,
+			Price:      price,
+			Reason:     strings.Join(reasons, "\n") + "\n" + state,
+			Timestamp:  dates[current],
+		}
+	}
+
+	var signals []Signal
+	if len(bullish) > 0 {
+		entry := newSignal("entry_long", bullish)
+		applyATRRisk(&entry, atr[current], m.config.StopLossATRMultiplier, m.config.TakeProfitRatio, m.config.RiskPerTrade)
+		signals = append(signals, entry, newSignal("exit_short", bullish))
+	}
+	if len(bearish) > 0 {
+		entry := newSignal("entry_short", bearish)
+		applyATRRisk(&entry, atr[current], m.config.StopLossATRMultiplier, m.config.TakeProfitRatio, m.config.RiskPerTrade)
+		signals = append(signals, entry, newSignal("exit_long", bearish))
+	}
+
+	return signals, nil
+}
+
+// signalCross пересечение линии MACD и сигнальной линии на текущей свече
+func (m *MACDStrategy) signalCross(macd, signalLine []float64, current int) []macdEvent {
+	prev := current - 1
+
+	switch {
+	case macd[prev] <= signalLine[prev] && macd[current] > signalLine[current]:
+		return []macdEvent{{bullish: true, reason: fmt.Sprintf(
+			"MACD (%.2f) пересекла сигнальную линию (%.2f) снизу вверх", macd[current], signalLine[current])}}
+	case macd[prev] >= signalLine[prev] && macd[current] < signalLine[current]:
+		return []macdEvent{{bullish: false, reason: fmt.Sprintf(
+			"MACD (%.2f) пересекла сигнальную линию (%.2f) сверху вниз", macd[current], signalLine[current])}}
+	}
+	return nil
+}
+
+// zeroCross пересечение линии MACD и нуля (смена тренда быстрой и медленной EMA)
+func (m *MACDStrategy) zeroCross(macd []float64, current int) []macdEvent {
+	prev := current - 1
+
+	switch {
+	case macd[prev] <= 0 && macd[current] > 0:
+		return []macdEvent{{bullish: true, reason: fmt.Sprintf(
+			"MACD пересекла нулевую линию снизу вверх: EMA%d выше EMA%d", m.config.FastPeriod, m.config.SlowPeriod)}}
+	case macd[prev] >= 0 && macd[current] < 0:
+		return []macdEvent{{bullish: false, reason: fmt.Sprintf(
+			"MACD пересекла нулевую линию сверху вниз: EMA%d ниже EMA%d", m.config.FastPeriod, m.config.SlowPeriod)}}
+	}
+	return nil
+}
+
+// divergence ищет дивергенцию гистограммы MACD и цены. Окно DivergenceLookback
+// делится пополам: бычья дивергенция - новый минимум цены во второй половине при
+// более высоком минимуме гистограммы ниже нуля (медвежья - зеркально). Сигнал
+// подается, только если экстремум свежий (не старше 2 свечей) и гистограмма
+// уже развернулась.
+func (m *MACDStrategy) divergence(closes, histogram []float64, current int) []macdEvent {
+	lookback := m.config.DivergenceLookback
+	if lookback < 4 || current-lookback+1 < 0 {
+		return nil
+	}
+
+	half := lookback / 2
+	priorFrom, recentFrom := current-lookback+1, current-half+1
+	turnedUp := histogram[current] > histogram[current-1]
+	turnedDown := histogram[current] < histogram[current-1]
+
+	extremum := func(from, to int, better func(a, b float64) bool) int {
+		idx := from
+		for i := from + 1; i <= to; i++ {
+			if better(closes[i], closes[idx]) {
+				idx = i
+			}
+		}
+		return idx
+	}
+	lower := func(a, b float64) bool { return a < b }
+	higher := func(a, b float64) bool { return a > b }
+
+	var events []macdEvent
+
+	priorLow, recentLow := extremum(priorFrom, recentFrom-1, lower), extremum(recentFrom, current, lower)
+	if closes[recentLow] < closes[priorLow] &&
+		histogram[priorLow] < 0 && histogram[recentLow] > histogram[priorLow] &&
+		recentLow >= current-2 && turnedUp {
+		events = append(events, macdEvent{bullish: true, reason: fmt.Sprintf(
+			"Бычья дивергенция: цена обновила минимум (%.2f < %.2f), гистограмма MACD - нет (%.2f > %.2f)",
+			closes[recentLow], closes[priorLow], histogram[recentLow], histogram[priorLow])})
+	}
+
+	priorHigh, recentHigh := extremum(priorFrom, recentFrom-1, higher), extremum(recentFrom, current, higher)
+	if closes[recentHigh] > closes[priorHigh] &&
+		histogram[priorHigh] > 0 && histogram[recentHigh] < histogram[priorHigh] &&
+		recentHigh >= current-2 && turnedDown {
+		events = append(events, macdEvent{bullish: false, reason: fmt.Sprintf(
+			"Медвежья дивергенция: цена обновила максимум (%.2f > %.2f), гистограмма MACD - нет (%.2f < %.2f)",
+			closes[recentHigh], closes[priorHigh], histogram[recentHigh], histogram[priorHigh])})
+	}
+
+	return events
+}
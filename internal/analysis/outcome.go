@@ -0,0 +1,88 @@
+package analysis
+
+import (
+	"math"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// Исходы сигнала на вход
+const (
+	OutcomeTakeProfit = "take_profit" // Достигнут тейк-профит
+	OutcomeStopLoss   = "stop_loss"   // Сработал стоп-лосс
+	OutcomeExitSignal = "exit_signal" // Сигнал выхода или противоположный вход
+	OutcomeTimeout    = "timeout"     // Ни стоп, ни тейк не достигнуты за отведенное время
+)
+
+// Outcome исход сигнала на вход
+type Outcome struct {
+	Result    string    // Один из Outcome*
+	ExitPrice float64   // Цена выхода
+	ExitTime  time.Time // Свеча выхода
+	RMultiple float64   // Результат в единицах начального риска (|цена - стоп|)
+}
+
+// EvaluateOutcome проверяет сигнал на вход по свечам, появившимся после него:
+// первая свеча, задевшая стоп-лосс или тейк-профит, закрывает сигнал по уровню
+// (или по открытию при гэпе за уровень). Если свеча задевает оба уровня,
+// считается, что сначала сработал стоп. Свеча, начавшаяся после deadline,
+// закрывает сигнал по таймауту по цене закрытия предыдущей свечи. Второе
+// значение false - исход еще не определен.
+func EvaluateOutcome(signal Signal, candles []api.Candle, deadline time.Time) (Outcome, bool) {
+	direction := signalDirection(signal.SignalType)
+	if direction == 0 || signal.Price <= 0 {
+		return Outcome{}, false
+	}
+
+	lastClose, lastTime := signal.Price, signal.Timestamp
+	for _, candle := range candles {
+		if !candle.Begin.After(signal.Timestamp) {
+			continue
+		}
+		if !deadline.IsZero() && candle.Begin.After(deadline) {
+			return newOutcome(signal, OutcomeTimeout, lastClose, lastTime), true
+		}
+
+		if signal.StopLoss > 0 {
+			if direction > 0 && candle.Low <= signal.StopLoss {
+				return newOutcome(signal, OutcomeStopLoss, math.Min(candle.Open, signal.StopLoss), candle.Begin), true
+			}
+			if direction < 0 && candle.High >= signal.StopLoss {
+				return newOutcome(signal, OutcomeStopLoss, math.Max(candle.Open, signal.StopLoss), candle.Begin), true
+			}
+		}
+		if signal.TakeProfit > 0 {
+			if direction > 0 && candle.High >= signal.TakeProfit {
+				return newOutcome(signal, OutcomeTakeProfit, math.Max(candle.Open, signal.TakeProfit), candle.Begin), true
+			}
+			if direction < 0 && candle.Low <= signal.TakeProfit {
+				return newOutcome(signal, OutcomeTakeProfit, math.Min(candle.Open, signal.TakeProfit), candle.Begin), true
+			}
+		}
+
+		lastClose, lastTime = candle.Close, candle.Begin
+	}
+
+	return Outcome{}, false
+}
+
+// newOutcome исход сигнала с расчетом R-мультипликатора
+func newOutcome(signal Signal, result string, exitPrice float64, exitTime time.Time) Outcome {
+	return Outcome{
+		Result:    result,
+		ExitPrice: exitPrice,
+		ExitTime:  exitTime,
+		RMultiple: RMultiple(signal.SignalType, signal.Price, signal.StopLoss, exitPrice),
+	}
+}
+
+// RMultiple результат сделки в единицах начального риска: (выход - вход) / |вход - стоп|
+// с учетом направления. Без стопа риск не определен и результат равен 0.
+func RMultiple(signalType string, entry, stopLoss, exit float64) float64 {
+	risk := math.Abs(entry - stopLoss)
+	if stopLoss <= 0 || risk == 0 {
+		return 0
+	}
+	return signalDirection(signalType) * (exit - entry) / risk
+}
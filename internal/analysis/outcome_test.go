@@ -0,0 +1,96 @@
+package analysis_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+)
+
+func TestEvaluateOutcome(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// candle дневная свеча через day дней после сигнала
+	candle := func(day int, open, high, low, close float64) api.Candle {
+		return api.Candle{Begin: start.AddDate(0, 0, day), Open: open, High: high, Low: low, Close: close}
+	}
+
+	long := analysis.Signal{SignalType: "entry_long", Price: 100, StopLoss: 95, TakeProfit: 110, Timestamp: start}
+	short := analysis.Signal{SignalType: "entry_short", Price: 100, StopLoss: 105, TakeProfit: 90, Timestamp: start}
+
+	tests := []struct {
+		name     string
+		signal   analysis.Signal
+		candles  []api.Candle
+		deadline time.Time
+		result   string
+		exit     float64
+		r        float64
+	}{
+		{
+			name:    "Long take profit",
+			signal:  long,
+			candles: []api.Candle{candle(0, 90, 120, 80, 100), candle(1, 100, 104, 99, 103), candle(2, 103, 111, 102, 109)},
+			result:  analysis.OutcomeTakeProfit, exit: 110, r: 2,
+		},
+		{
+			name:    "Long stop first when both hit",
+			signal:  long,
+			candles: []api.Candle{candle(1, 100, 112, 94, 105)},
+			result:  analysis.OutcomeStopLoss, exit: 95, r: -1,
+		},
+		{
+			name:    "Long gap below stop",
+			signal:  long,
+			candles: []api.Candle{candle(1, 90, 92, 88, 91)},
+			result:  analysis.OutcomeStopLoss, exit: 90, r: -2,
+		},
+		{
+			name:    "Short take profit",
+			signal:  short,
+			candles: []api.Candle{candle(1, 99, 101, 95, 96), candle(2, 96, 97, 89, 90)},
+			result:  analysis.OutcomeTakeProfit, exit: 90, r: 2,
+		},
+		{
+			name:     "Timeout at last close",
+			signal:   long,
+			candles:  []api.Candle{candle(1, 100, 102, 98, 101), candle(2, 101, 103, 100, 102.5), candle(10, 102, 103, 101, 102)},
+			deadline: start.AddDate(0, 0, 5),
+			result:   analysis.OutcomeTimeout, exit: 102.5, r: 0.5,
+		},
+		{
+			name:    "Still open",
+			signal:  long,
+			candles: []api.Candle{candle(1, 100, 102, 98, 101)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, ok := analysis.EvaluateOutcome(tt.signal, tt.candles, tt.deadline)
+			if tt.result == "" {
+				if ok {
+					t.Fatalf("outcome = %+v, want none", outcome)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("outcome not determined, want %s", tt.result)
+			}
+			if outcome.Result != tt.result || outcome.ExitPrice != tt.exit || math.Abs(outcome.RMultiple-tt.r) > 1e-9 {
+				t.Errorf("outcome = %+v, want %s at %.2f (%.2fR)", outcome, tt.result, tt.exit, tt.r)
+			}
+		})
+	}
+}
+
+func TestRMultiple(t *testing.T) {
+	if r := analysis.RMultiple("entry_short", 100, 104, 92); r != 2 {
+		t.Errorf("short RMultiple = %.2f, want 2", r)
+	}
+	if r := analysis.RMultiple("entry_long", 100, 0, 120); r != 0 {
+		t.Errorf("RMultiple without stop = %.2f, want 0", r)
+	}
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import "math"
+
+// DefaultEquity размер счета по умолчанию, ₽
+const DefaultEquity = 100000.0
+
+// Account параметры счета для расчета размера позиции
+type Account struct {
+	Equity             float64 // Размер счета, ₽ (0 - DefaultEquity)
+	MaxPositionPercent float64 // Максимальная стоимость позиции, % от счета (0 - без ограничения)
+}
+
+// DefaultAccount счет по умолчанию без ограничения доли позиции
+func DefaultAccount() Account {
+	return Account{Equity: DefaultEquity}
+}
+
+// SizePosition рассчитывает размер позиции сигнала на вход: риск RiskPerTrade
+// от счета, деленный на расстояние до стопа, с ограничением MaxPositionPercent
+// счета и округлением вниз до целых лотов. Заполняет PositionSize, Lots,
+// LotSize и Cost; сигналы без стопа или риска не меняются. При lotSize <= 0
+// размер лота неизвестен: PositionSize считается в целых бумагах, Lots и Cost
+// остаются пустыми, а сигнал помечается LotSizeUnknown.
+func (a Account) SizePosition(signal *Signal, lotSize int) {
+	if signal.RiskPerTrade <= 0 || signal.StopLoss <= 0 || signal.Price <= 0 {
+		return
+	}
+
+	riskPerShare := math.Abs(signal.Price - signal.StopLoss)
+	if riskPerShare == 0 {
+		return
+	}
+
+	equity := a.Equity
+	if equity <= 0 {
+		equity = DefaultEquity
+	}
+
+	shares := equity * signal.RiskPerTrade / riskPerShare
+	if a.MaxPositionPercent > 0 {
+		shares = math.Min(shares, equity*a.MaxPositionPercent/100/signal.Price)
+	}
+
+	if lotSize <= 0 {
+		signal.LotSize, signal.Lots, signal.Cost = 0, 0, 0
+		signal.LotSizeUnknown = true
+		signal.PositionSize = math.Floor(shares)
+		return
+	}
+
+	signal.LotSize = lotSize
+	signal.LotSizeUnknown = false
+	signal.Lots = int(math.Floor(shares / float64(lotSize)))
+	signal.PositionSize = float64(signal.Lots * lotSize)
+	signal.Cost = signal.PositionSize * signal.Price
+}
+
+// Sized рассчитан ли для сигнала размер позиции (в том числе без известного лота)
+func (s Signal) Sized() bool {
+	return s.LotSize > 0 || s.LotSizeUnknown
+}
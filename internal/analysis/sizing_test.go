@@ -0,0 +1,55 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"telegram-bot-moex/internal/analysis"
+)
+
+func TestAccountSizePosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		account analysis.Account
+		lotSize int
+		lots    int
+		shares  float64
+	}{
+		// Риск 2% от 100 000 = 2000₽, риск на бумагу 5₽ -> 400 бумаг
+		{"Default account", analysis.DefaultAccount(), 1, 400, 400},
+		// 400 бумаг при лоте 30 -> 13 лотов (390 бумаг)
+		{"Round down to lots", analysis.DefaultAccount(), 30, 13, 390},
+		{"Larger equity", analysis.Account{Equity: 1000000}, 10, 400, 4000},
+		// Не более 10% счета: 10 000₽ / 100₽ = 100 бумаг
+		{"Max position percent", analysis.Account{Equity: 100000, MaxPositionPercent: 10}, 10, 10, 100},
+		{"Lot larger than position", analysis.DefaultAccount(), 1000, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal := analysis.Signal{SignalType: "entry_long", Price: 100, StopLoss: 95, RiskPerTrade: 0.02}
+			tt.account.SizePosition(&signal, tt.lotSize)
+
+			if signal.Lots != tt.lots || signal.PositionSize != tt.shares {
+				t.Fatalf("size = %d lots / %.0f shares, want %d / %.0f", signal.Lots, signal.PositionSize, tt.lots, tt.shares)
+			}
+			if signal.Cost != tt.shares*100 {
+				t.Errorf("Cost = %.0f, want %.0f", signal.Cost, tt.shares*100)
+			}
+		})
+	}
+
+	// Неизвестный лот: только число бумаг, без лотов и стоимости
+	unknown := analysis.Signal{SignalType: "entry_long", Price: 100, StopLoss: 95, RiskPerTrade: 0.02}
+	analysis.DefaultAccount().SizePosition(&unknown, 10)
+	analysis.DefaultAccount().SizePosition(&unknown, 0)
+	if !unknown.LotSizeUnknown || !unknown.Sized() || unknown.PositionSize != 400 || unknown.Lots != 0 || unknown.LotSize != 0 || unknown.Cost != 0 {
+		t.Errorf("unknown lot size: %+v", unknown)
+	}
+
+	// Сигнал без риска (выход) не меняется
+	exit := analysis.Signal{SignalType: "exit_long", Price: 100}
+	analysis.DefaultAccount().SizePosition(&exit, 10)
+	if exit.Lots != 0 || exit.LotSize != 0 || exit.Cost != 0 || exit.Sized() {
+		t.Errorf("exit signal sized: %+v", exit)
+	}
+}
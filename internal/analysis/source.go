@@ -0,0 +1,25 @@
+package analysis
+
+import (
+	"context"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// CandleSource источник свечей для стратегий (API клиент, кэш и т.д.)
+type CandleSource interface {
+	GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]api.Candle, error)
+}
+
+// DataSource источник рыночных данных: свечи, список инструментов и их описание.
+// Реализуется клиентом MOEX Fetcher и клиентом MOEX ISS.
+type DataSource interface {
+	CandleSource
+	GetInstruments(ctx context.Context) ([]string, error)
+	GetInstrumentInfo(ctx context.Context, instrument string) (*api.Instrument, error)
+}
+
+var (
+	_ DataSource = (*api.APIClient)(nil)
+	_ DataSource = (*api.ISSClient)(nil)
+)
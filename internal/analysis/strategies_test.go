@@ -0,0 +1,736 @@
+package analysis_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+)
+
+// rangeSeries строит боковик: закрытия чередуются между low и high
+func rangeSeries(n int, low, high float64) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = low
+		if i%2 == 1 {
+			closes[i] = high
+		}
+	}
+	return closes
+}
+
+// linearSeries строит n закрытий от start с шагом step
+func linearSeries(n int, start, step float64) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = start + step*float64(i)
+	}
+	return closes
+}
+
+// newSource поднимает заглушку MOEX Fetcher с дневными свечами SBER
+func newSource(t *testing.T, closes []float64) *api.APIClient {
+	t.Helper()
+	fetcher := fakefetcher.New(t, fakefetcher.WithCandles("SBER", "24", fakefetcher.Series(closes)))
+	return api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+}
+
+// signalTypes возвращает типы сигналов
+func signalTypes(signals []analysis.Signal) map[string]analysis.Signal {
+	types := make(map[string]analysis.Signal, len(signals))
+	for _, signal := range signals {
+		types[signal.SignalType] = signal
+	}
+	return types
+}
+
+func TestTurtleStrategySignals(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		want   string
+		absent []string
+	}{
+		{
+			name:   "Breakout up",
+			closes: append(rangeSeries(60, 100, 101), 110),
+			want:   "entry_long",
+			absent: []string{"entry_short", "exit_long"},
+		},
+		{
+			name:   "Breakout down",
+			closes: append(rangeSeries(60, 100, 101), 90),
+			want:   "entry_short",
+			absent: []string{"entry_long", "exit_short"},
+		},
+		{
+			name:   "Inside range",
+			closes: append(rangeSeries(60, 100, 101), 100.5),
+			want:   "no_signal",
+			absent: []string{"entry_long", "entry_short"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := analysis.NewTurtleStrategy(newSource(t, tt.closes), 20, 20, 10, 14, 2.0, 0.01)
+
+			signals, err := strategy.AnalyzeInstrument(context.Background(), "SBER")
+			if err != nil {
+				t.Fatalf("AnalyzeInstrument() error = %v", err)
+			}
+
+			types := signalTypes(signals)
+			signal, ok := types[tt.want]
+			if !ok {
+				t.Fatalf("signals = %v, want %s", types, tt.want)
+			}
+			for _, absent := range tt.absent {
+				if _, ok := types[absent]; ok {
+					t.Errorf("unexpected %s signal", absent)
+				}
+			}
+
+			last := tt.closes[len(tt.closes)-1]
+			if signal.Price != last {
+				t.Errorf("Price = %.2f, want %.2f", signal.Price, last)
+			}
+			switch tt.want {
+			case "entry_long":
+				if signal.StopLoss >= signal.Price {
+					t.Errorf("long stop = %.2f above price %.2f", signal.StopLoss, signal.Price)
+				}
+			case "entry_short":
+				if signal.StopLoss <= signal.Price {
+					t.Errorf("short stop = %.2f below price %.2f", signal.StopLoss, signal.Price)
+				}
+			}
+			if tt.want != "no_signal" {
+				// Выход по обратному прорыву, фиксированного тейк-профита нет
+				if signal.TakeProfit != 0 || signal.System != analysis.TurtleSystem1 {
+					t.Errorf("TakeProfit = %.2f, System = %q, want 0 and System 1", signal.TakeProfit, signal.System)
+				}
+				if len(signal.Pyramid) != 3 {
+					t.Fatalf("pyramid = %d levels, want 3", len(signal.Pyramid))
+				}
+				// Каждый юнит дальше по тренду, стоп подтягивается за ним
+				prevPrice, prevStop := signal.Price, signal.StopLoss
+				for _, add := range signal.Pyramid {
+					if (add.Price-prevPrice)*(signal.Price-signal.StopLoss) <= 0 ||
+						(add.StopLoss-prevStop)*(signal.Price-signal.StopLoss) <= 0 {
+						t.Errorf("unit %d @ %.2f stop %.2f does not trail %.2f/%.2f", add.Unit, add.Price, add.StopLoss, prevPrice, prevStop)
+					}
+					prevPrice, prevStop = add.Price, add.StopLoss
+				}
+			}
+		})
+	}
+}
+
+func TestTurtleStrategyNotEnoughData(t *testing.T) {
+	strategy := analysis.NewTurtleStrategy(newSource(t, rangeSeries(5, 100, 101)), 20, 20, 10, 14, 2.0, 0.01)
+
+	if _, err := strategy.AnalyzeInstrument(context.Background(), "SBER"); err == nil {
+		t.Fatal("AnalyzeInstrument() should fail with 5 candles")
+	}
+}
+
+// winningBreakoutSeries прибыльный прорыв System 1 (вход 105, выход по 10-дневному
+// минимуму на 123), боковик и новый прорыв на 140 выше 55-дневного максимума
+func winningBreakoutSeries() []float64 {
+	closes := rangeSeries(60, 100, 101)
+	closes = append(closes, linearSeries(31, 105, 1)...)
+	closes = append(closes, linearSeries(12, 134, -1)...)
+	closes = append(closes, rangeSeries(25, 124, 125)...)
+	return append(closes, 140)
+}
+
+func TestTurtleStrategySystems(t *testing.T) {
+	// 20-дневный максимум 101 пробит, 55-дневный максимум 121 - нет
+	breakout20 := append(rangeSeries(30, 120, 121), rangeSeries(40, 100, 101)...)
+	breakout20 = append(breakout20, 110)
+
+	tests := []struct {
+		name   string
+		closes []float64
+		rules  analysis.TurtleRules
+		want   bool // Ожидается вход в лонг
+		system string
+		reason string
+	}{
+		{
+			name:   "System 1 breakout",
+			closes: breakout20,
+			rules:  analysis.TurtleRules{System: analysis.TurtleSystem1},
+			want:   true,
+			system: analysis.TurtleSystem1,
+		},
+		{
+			name:   "System 2 ignores 20-day breakout",
+			closes: breakout20,
+			rules:  analysis.TurtleRules{System: analysis.TurtleSystem2},
+		},
+		{
+			name:   "Skip after winning breakout",
+			closes: winningBreakoutSeries(),
+			rules:  analysis.TurtleRules{System: analysis.TurtleSystem1, SkipAfterWin: true},
+			reason: "Прорыв System 1 пропускается",
+		},
+		{
+			name:   "No skip rule",
+			closes: winningBreakoutSeries(),
+			rules:  analysis.TurtleRules{System: analysis.TurtleSystem1},
+			want:   true,
+			system: analysis.TurtleSystem1,
+		},
+		{
+			name:   "System 2 takes skipped breakout",
+			closes: winningBreakoutSeries(),
+			rules:  analysis.TurtleRules{System: analysis.TurtleSystemBoth, SkipAfterWin: true},
+			want:   true,
+			system: analysis.TurtleSystem2,
+			reason: "System 2. Прорыв входа для лонга",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := analysis.NewTurtleStrategy(newSource(t, tt.closes), 20, 20, 10, 14, 2.0, 0.01).WithRules(tt.rules)
+
+			signals, err := strategy.AnalyzeInstrument(context.Background(), "SBER")
+			if err != nil {
+				t.Fatalf("AnalyzeInstrument() error = %v", err)
+			}
+
+			types := signalTypes(signals)
+			if _, ok := types["entry_short"]; ok {
+				t.Error("unexpected entry_short signal")
+			}
+			entry, ok := types["entry_long"]
+			if ok != tt.want {
+				t.Fatalf("signals = %v, want entry_long: %v", types, tt.want)
+			}
+			if ok && entry.System != tt.system {
+				t.Errorf("System = %q, want %q", entry.System, tt.system)
+			}
+
+			var reasons []string
+			for _, signal := range signals {
+				reasons = append(reasons, signal.Reason)
+			}
+			if reason := strings.Join(reasons, "\n"); !strings.Contains(reason, tt.reason) {
+				t.Errorf("reason = %q, want %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMACrossoverStrategySignals(t *testing.T) {
+	var config analysis.MACrossoverConfig
+	config.Timeframe = "24"
+	config.FastPeriod = 5
+	config.SlowPeriod = 20
+	config.StopLossATRMultiplier = 2
+	config.TakeProfitRatio = 2
+	config.RiskPerTrade = 0.01
+	config.CrossoverTypes.GoldenCross = true
+	config.CrossoverTypes.DeathCross = true
+	config.Filters.TrendFilter = "none"
+
+	tests := []struct {
+		name   string
+		closes []float64
+		want   string
+	}{
+		{
+			name:   "Golden cross",
+			closes: append(linearSeries(60, 200, -1), 240),
+			want:   "entry_long",
+		},
+		{
+			name:   "Death cross",
+			closes: append(linearSeries(60, 100, 1), 60),
+			want:   "entry_short",
+		},
+		{
+			name:   "Steady trend",
+			closes: linearSeries(60, 100, 1),
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := analysis.NewMACrossoverStrategy(newSource(t, tt.closes), config)
+
+			signals, err := strategy.AnalyzeInstrument(context.Background(), "SBER")
+			if err != nil {
+				t.Fatalf("AnalyzeInstrument() error = %v", err)
+			}
+
+			if tt.want == "" {
+				if len(signals) != 0 {
+					t.Fatalf("signals = %v, want none", signalTypes(signals))
+				}
+				return
+			}
+			if len(signals) != 1 || signals[0].SignalType != tt.want {
+				t.Fatalf("signals = %v, want single %s", signalTypes(signals), tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	source := newSource(t, append(rangeSeries(60, 100, 101), 110))
+	enabled := true
+
+	registry := analysis.NewRegistry()
+	if err := registry.Register(analysis.Registration{
+		Name:    "turtle",
+		Title:   "Черепахи",
+		Enabled: func() bool { return enabled },
+		New: func() analysis.Strategy {
+			return analysis.NewTurtleStrategy(source, 20, 20, 10, 14, 2.0, 0.01)
+		},
+	}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err := registry.Register(analysis.Registration{Name: "turtle", New: func() analysis.Strategy { return nil }}); err == nil {
+		t.Error("Register() should reject duplicate name")
+	}
+	if err := registry.Register(analysis.Registration{Name: "empty"}); err == nil {
+		t.Error("Register() should reject registration without constructor")
+	}
+
+	reg, ok := registry.Get("turtle")
+	if !ok {
+		t.Fatal("Get(turtle) not found")
+	}
+	if len(registry.Enabled()) != 1 {
+		t.Errorf("Enabled() = %d strategies, want 1", len(registry.Enabled()))
+	}
+
+	strategy := reg.New()
+	if strategy.Name() != "turtle" || len(strategy.Params()) == 0 {
+		t.Errorf("strategy = %s with %d params", strategy.Name(), len(strategy.Params()))
+	}
+
+	signals, err := analysis.AnalyzeWith(context.Background(), source, strategy, "SBER")
+	if err != nil {
+		t.Fatalf("AnalyzeWith() error = %v", err)
+	}
+	if _, ok := signalTypes(signals)["entry_long"]; !ok {
+		t.Errorf("signals = %v, want entry_long", signalTypes(signals))
+	}
+
+	enabled = false
+	if len(registry.Enabled()) != 0 {
+		t.Error("Enabled() should skip disabled strategy")
+	}
+}
+
+// macdConfig конфигурация MACD 12/26/9 с выбранными типами сигналов
+func macdConfig(signalCross, zeroCross, divergence bool) analysis.MACDConfig {
+	return analysis.MACDConfig{
+		Timeframe:             "24",
+		FastPeriod:            12,
+		SlowPeriod:            26,
+		SignalPeriod:          9,
+		AtrPeriod:             14,
+		RiskPerTrade:          0.01,
+		StopLossATRMultiplier: 2,
+		TakeProfitRatio:       2,
+		SignalCross:           signalCross,
+		ZeroCross:             zeroCross,
+		Divergence:            divergence,
+		DivergenceLookback:    30,
+	}
+}
+
+// mirror отражает ряд относительно уровня: рост становится падением
+func mirror(closes []float64, level float64) []float64 {
+	mirrored := make([]float64, len(closes))
+	for i, c := range closes {
+		mirrored[i] = 2*level - c
+	}
+	return mirrored
+}
+
+// acceleratingDecline ускоряющееся падение: MACD снижается и остается ниже сигнальной линии
+func acceleratingDecline(n int) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = 300 - 0.02*float64(i*i)
+	}
+	return closes
+}
+
+// divergenceSeries резкое падение до 100, отскок и медленное снижение до нового
+// минимума 98 с разворотом вверх на последней свече
+func divergenceSeries() []float64 {
+	var closes []float64
+	for i := 0; i < 50; i++ {
+		closes = append(closes, 150)
+	}
+	for i := 1; i <= 10; i++ {
+		closes = append(closes, 150-5*float64(i))
+	}
+	for i := 1; i <= 8; i++ {
+		closes = append(closes, 100+1.5*float64(i))
+	}
+	for i := 1; i <= 14; i++ {
+		closes = append(closes, 112-float64(i))
+	}
+	return append(closes, 98.5)
+}
+
+func TestMACDStrategySignals(t *testing.T) {
+	decline := acceleratingDecline(80)
+	crossUp := append(decline, decline[len(decline)-1]+15)
+
+	tests := []struct {
+		name   string
+		config analysis.MACDConfig
+		closes []float64
+		want   []string
+		reason string
+	}{
+		{
+			name:   "Signal line cross up",
+			config: macdConfig(true, false, false),
+			closes: crossUp,
+			want:   []string{"entry_long", "exit_short"},
+			reason: "сигнальную линию",
+		},
+		{
+			name:   "Signal line cross down",
+			config: macdConfig(true, false, false),
+			closes: mirror(crossUp, 300),
+			want:   []string{"entry_short", "exit_long"},
+			reason: "сигнальную линию",
+		},
+		{
+			name:   "No cross in steady decline",
+			config: macdConfig(true, true, false),
+			closes: decline,
+		},
+		{
+			name:   "Bullish divergence",
+			config: macdConfig(false, false, true),
+			closes: divergenceSeries(),
+			want:   []string{"entry_long", "exit_short"},
+			reason: "Бычья дивергенция",
+		},
+		{
+			name:   "Bearish divergence",
+			config: macdConfig(false, false, true),
+			closes: mirror(divergenceSeries(), 150),
+			want:   []string{"entry_short", "exit_long"},
+			reason: "Медвежья дивергенция",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := analysis.NewMACDStrategy(newSource(t, tt.closes), tt.config)
+
+			signals, err := strategy.AnalyzeInstrument(context.Background(), "SBER")
+			if err != nil {
+				t.Fatalf("AnalyzeInstrument() error = %v", err)
+			}
+
+			types := signalTypes(signals)
+			if len(types) != len(tt.want) {
+				t.Fatalf("signals = %v, want %v", types, tt.want)
+			}
+			for _, want := range tt.want {
+				signal, ok := types[want]
+				if !ok {
+					t.Fatalf("signals = %v, want %s", types, want)
+				}
+				if !strings.Contains(signal.Reason, tt.reason) {
+					t.Errorf("%s reason = %q, want %q", want, signal.Reason, tt.reason)
+				}
+			}
+
+			if entry, ok := types["entry_long"]; ok && (entry.StopLoss >= entry.Price || entry.TakeProfit <= entry.Price) {
+				t.Errorf("long stop/take = %.2f/%.2f around price %.2f", entry.StopLoss, entry.TakeProfit, entry.Price)
+			}
+			if entry, ok := types["entry_short"]; ok && (entry.StopLoss <= entry.Price || entry.TakeProfit >= entry.Price) {
+				t.Errorf("short stop/take = %.2f/%.2f around price %.2f", entry.StopLoss, entry.TakeProfit, entry.Price)
+			}
+		})
+	}
+}
+
+func TestMACDStrategyZeroCross(t *testing.T) {
+	// Падение, затем рост: MACD один раз переходит через ноль снизу вверх
+	closes := linearSeries(60, 200, -1)
+	closes = append(closes, linearSeries(40, 141, 1.5)...)
+
+	macd, _, _ := indicators.MACD(closes, 12, 26, 9)
+	strategy := analysis.NewMACDStrategy(nil, macdConfig(false, true, false))
+
+	crosses := 0
+	for n := 35; n <= len(closes); n++ {
+		signals, err := strategy.Analyze(context.Background(), "SBER", fakefetcher.Series(closes[:n]))
+		if err != nil {
+			t.Fatalf("Analyze(%d candles) error = %v", n, err)
+		}
+
+		crossed := macd[n-2] <= 0 && macd[n-1] > 0
+		if _, ok := signalTypes(signals)["entry_long"]; ok != crossed {
+			t.Errorf("%d candles: entry_long = %v, MACD %.2f -> %.2f", n, ok, macd[n-2], macd[n-1])
+		}
+		if crossed {
+			crosses++
+		}
+	}
+	if crosses != 1 {
+		t.Errorf("zero crosses = %d, want 1", crosses)
+	}
+}
+
+func TestMACDStrategyNotEnoughData(t *testing.T) {
+	strategy := analysis.NewMACDStrategy(newSource(t, linearSeries(20, 100, 1)), macdConfig(true, true, true))
+
+	if _, err := strategy.AnalyzeInstrument(context.Background(), "SBER"); err == nil {
+		t.Fatal("AnalyzeInstrument() should fail with 20 candles")
+	}
+}
+
+func TestMACDStrategyHistoryDays(t *testing.T) {
+	// 26 + 9 свечей разогрева и 30 свечей поиска дивергенции
+	for timeframe, want := range map[string]int{
+		"24": 107, // 65 торговых дней с выходными и праздниками
+		"60": 9,   // 65 часовых свечей - меньше 5 сессий
+		"10": 4,
+	} {
+		config := macdConfig(true, true, true)
+		config.Timeframe = timeframe
+		if got := analysis.NewMACDStrategy(nil, config).HistoryDays(); got != want {
+			t.Errorf("HistoryDays(%s) = %d, want %d", timeframe, got, want)
+		}
+	}
+}
+
+// bollingerConfig конфигурация полос 20/2 с RSI 14 (30/70) и окном сжатия 60 свечей
+func bollingerConfig(mode string) analysis.BollingerConfig {
+	return analysis.BollingerConfig{
+		Timeframe:             "24",
+		Mode:                  mode,
+		Period:                20,
+		StdDev:                2,
+		RSIPeriod:             14,
+		RSIOverbought:         70,
+		RSIOversold:           30,
+		SqueezeLookback:       60,
+		AtrPeriod:             14,
+		RiskPerTrade:          0.01,
+		StopLossATRMultiplier: 2,
+		TakeProfitRatio:       2,
+	}
+}
+
+func TestBollingerStrategySignals(t *testing.T) {
+	// Широкий боковик, затем сжатие и пробой
+	squeezed := func(last float64) []float64 {
+		closes := append(rangeSeries(80, 90, 110), rangeSeries(30, 100, 100.5)...)
+		return append(closes, last)
+	}
+	// Боковик и резкое падение: касание нижней полосы при низком RSI
+	selloff := append(rangeSeries(80, 100, 101), linearSeries(6, 99, -2)...)
+
+	tests := []struct {
+		name   string
+		mode   string
+		closes []float64
+		want   string
+		reason string
+	}{
+		{
+			name:   "Squeeze breakout up",
+			mode:   analysis.BollingerSqueeze,
+			closes: squeezed(103),
+			want:   "entry_long",
+			reason: "Пробой вверх после сжатия",
+		},
+		{
+			name:   "Squeeze breakout down",
+			mode:   analysis.BollingerBoth,
+			closes: squeezed(97),
+			want:   "entry_short",
+			reason: "Пробой вниз после сжатия",
+		},
+		{
+			name:   "Breakout without squeeze",
+			mode:   analysis.BollingerSqueeze,
+			closes: append(rangeSeries(110, 90, 110), 130),
+		},
+		{
+			name:   "Lower band touch",
+			mode:   analysis.BollingerReversion,
+			closes: selloff,
+			want:   "entry_long",
+			reason: "Касание нижней полосы",
+		},
+		{
+			name:   "Upper band touch",
+			mode:   analysis.BollingerReversion,
+			closes: mirror(selloff, 100),
+			want:   "entry_short",
+			reason: "Касание верхней полосы",
+		},
+		{
+			name:   "Inside bands",
+			mode:   analysis.BollingerBoth,
+			closes: rangeSeries(110, 100, 101),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := analysis.NewBollingerStrategy(newSource(t, tt.closes), bollingerConfig(tt.mode))
+
+			signals, err := strategy.AnalyzeInstrument(context.Background(), "SBER")
+			if err != nil {
+				t.Fatalf("AnalyzeInstrument() error = %v", err)
+			}
+
+			if tt.want == "" {
+				if len(signals) != 0 {
+					t.Fatalf("signals = %v, want none", signalTypes(signals))
+				}
+				return
+			}
+			if len(signals) != 1 || signals[0].SignalType != tt.want {
+				t.Fatalf("signals = %v, want single %s", signalTypes(signals), tt.want)
+			}
+
+			signal := signals[0]
+			if !strings.Contains(signal.Reason, tt.reason) {
+				t.Errorf("reason = %q, want %q", signal.Reason, tt.reason)
+			}
+			if signal.StopLoss == 0 || signal.TakeProfit == 0 || signal.PositionSize == 0 {
+				t.Errorf("stop/take/size = %.2f/%.2f/%.0f, want risk management", signal.StopLoss, signal.TakeProfit, signal.PositionSize)
+			}
+		})
+	}
+}
+
+func TestBollingerStrategyNotEnoughData(t *testing.T) {
+	strategy := analysis.NewBollingerStrategy(newSource(t, rangeSeries(40, 100, 101)), bollingerConfig(analysis.BollingerSqueeze))
+
+	if _, err := strategy.AnalyzeInstrument(context.Background(), "SBER"); err == nil {
+		t.Fatal("AnalyzeInstrument() should fail without squeeze window")
+	}
+}
+
+// maConfig конфигурация MA Crossover 5/20 с фильтром тренда
+func maConfig(trendFilter string) analysis.MACrossoverConfig {
+	var config analysis.MACrossoverConfig
+	config.Timeframe = "24"
+	config.FastPeriod = 5
+	config.SlowPeriod = 20
+	config.StopLossATRMultiplier = 2
+	config.TakeProfitRatio = 2
+	config.RiskPerTrade = 0.01
+	config.CrossoverTypes.GoldenCross = true
+	config.CrossoverTypes.DeathCross = true
+	config.Filters.TrendFilter = trendFilter
+	config.Filters.RSIOverbought = 70
+	config.Filters.RSIOversold = 30
+	return config
+}
+
+func TestMACrossoverFilters(t *testing.T) {
+	golden := append(linearSeries(60, 200, -1), 240)
+	death := append(linearSeries(60, 100, 1), 60)
+
+	tests := []struct {
+		name    string
+		filter  string
+		rsi     bool
+		closes  []float64
+		want    string
+		reasons []string
+	}{
+		{
+			name:    "Long above SMA50",
+			filter:  "sma50",
+			closes:  golden,
+			want:    "entry_long",
+			reasons: []string{"✅ Цена 240.00 выше SMA50"},
+		},
+		{
+			name:    "Short below EMA50 with ADX",
+			filter:  "ema50, adx",
+			closes:  death,
+			want:    "entry_short",
+			reasons: []string{"✅ Цена 60.00 ниже EMA50", "✅ ADX14"},
+		},
+		{
+			name:    "Long against falling EMA",
+			filter:  "ema_slope",
+			closes:  golden,
+			want:    "no_signal",
+			reasons: []string{"Золотое пересечение отфильтровано", "❌ Наклон EMA50 за 5 свечей"},
+		},
+		{
+			name:    "Short against rising EMA",
+			filter:  "sma50,ema_slope",
+			closes:  death,
+			want:    "no_signal",
+			reasons: []string{"Мертвое пересечение отфильтровано", "✅ Цена", "❌ Наклон EMA50"},
+		},
+		{
+			name:    "Long in overbought RSI",
+			filter:  "none",
+			rsi:     true,
+			closes:  golden,
+			want:    "no_signal",
+			reasons: []string{"❌ RSI", "в зоне перекупленности"},
+		},
+		{
+			name:    "Short in oversold RSI",
+			filter:  "sma50",
+			rsi:     true,
+			closes:  death,
+			want:    "no_signal",
+			reasons: []string{"✅ Цена 60.00 ниже SMA50", "❌ RSI", "в зоне перепроданности"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := maConfig(tt.filter)
+			config.Filters.RSIFilter = tt.rsi
+			strategy := analysis.NewMACrossoverStrategy(newSource(t, tt.closes), config)
+
+			signals, err := strategy.AnalyzeInstrument(context.Background(), "SBER")
+			if err != nil {
+				t.Fatalf("AnalyzeInstrument() error = %v", err)
+			}
+			if len(signals) != 1 || signals[0].SignalType != tt.want {
+				t.Fatalf("signals = %v, want single %s", signalTypes(signals), tt.want)
+			}
+
+			signal := signals[0]
+			for _, reason := range tt.reasons {
+				if !strings.Contains(signal.Reason, reason) {
+					t.Errorf("reason = %q, want %q", signal.Reason, reason)
+				}
+			}
+			if tt.want == "no_signal" && (signal.StopLoss != 0 || signal.PositionSize != 0) {
+				t.Errorf("filtered signal has stop %.2f and size %.0f", signal.StopLoss, signal.PositionSize)
+			}
+		})
+	}
+}
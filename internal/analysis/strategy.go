@@ -0,0 +1,171 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// Strategy общий интерфейс торговых стратегий. Стратегия не загружает данные сама:
+// свечи за HistoryDays дней по Timeframe передаются в Analyze, последняя свеча - текущая.
+type Strategy interface {
+	// Name короткое имя стратегии (используется в командах и конфигурации)
+	Name() string
+	// Title название стратегии для пользователя
+	Title() string
+	// Params текущие параметры стратегии для отображения
+	Params() []Param
+	// Timeframe таймфрейм свечей для анализа
+	Timeframe() string
+	// HistoryDays сколько календарных дней истории нужно для анализа
+	HistoryDays() int
+	// Analyze формирует сигналы по свечам инструмента
+	Analyze(ctx context.Context, instrument string, candles []api.Candle) ([]Signal, error)
+}
+
+// sessionHours примерная длительность торговой сессии MOEX с вечерней сессией
+const sessionHours = 14
+
+// HistoryDaysFor сколько календарных дней истории нужно для bars свечей таймфрейма
+// с учетом выходных и неполных сессий
+func HistoryDaysFor(timeframe string, bars int) int {
+	duration := api.TimeframeDuration(timeframe)
+	if duration >= 24*time.Hour {
+		return int(float64(bars)*duration.Hours()/24*1.5) + 10
+	}
+
+	barsPerDay := float64(sessionHours*time.Hour) / float64(duration)
+	return int(float64(bars)/barsPerDay*1.5) + 3
+}
+
+// Param параметр стратегии
+type Param struct {
+	Name  string // Описание параметра
+	Value string // Значение в читаемом виде
+}
+
+// AnalyzeWith загружает свечи инструмента из источника, анализирует их стратегией
+// и оценивает уверенность сигналов на вход
+func AnalyzeWith(ctx context.Context, source CandleSource, strategy Strategy, instrument string) ([]Signal, error) {
+	to := time.Now().Format("2006-01-02")
+	from := time.Now().AddDate(0, 0, -strategy.HistoryDays()).Format("2006-01-02")
+
+	candles, err := source.GetCandles(ctx, instrument, strategy.Timeframe(), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения свечей: %w", err)
+	}
+
+	signals, err := strategy.Analyze(ctx, instrument, candles)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range signals {
+		ScoreConfidence(&signals[i], candles)
+	}
+	return signals, nil
+}
+
+// Registration описание стратегии в реестре
+type Registration struct {
+	Name     string          // Короткое имя, совпадает с Strategy.Name()
+	Title    string          // Название для пользователя
+	Schedule string          // Расписание фонового анализа по умолчанию (cron, MSK)
+	Enabled  func() bool     // Включена ли стратегия в текущей конфигурации
+	New      func() Strategy // Создает стратегию с актуальными параметрами
+}
+
+// Registry реестр стратегий. Новые стратегии регистрируются один раз и сразу
+// участвуют в сканировании, уведомлениях и командах /scan и /strategies.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]Registration
+	order   []string
+}
+
+// NewRegistry создает пустой реестр стратегий
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]Registration),
+	}
+}
+
+// Register добавляет стратегию в реестр
+func (r *Registry) Register(reg Registration) error {
+	if reg.Name == "" {
+		return fmt.Errorf("не указано имя стратегии")
+	}
+	if reg.New == nil {
+		return fmt.Errorf("стратегия %s: не указан конструктор", reg.Name)
+	}
+	if reg.Title == "" {
+		reg.Title = reg.Name
+	}
+	if reg.Enabled == nil {
+		reg.Enabled = func() bool { return true }
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[reg.Name]; exists {
+		return fmt.Errorf("стратегия %s уже зарегистрирована", reg.Name)
+	}
+
+	r.entries[reg.Name] = reg
+	r.order = append(r.order, reg.Name)
+	return nil
+}
+
+// Get возвращает описание стратегии по имени
+func (r *Registry) Get(name string) (Registration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reg, ok := r.entries[name]
+	return reg, ok
+}
+
+// List возвращает все стратегии в порядке регистрации
+func (r *Registry) List() []Registration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]Registration, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.entries[name])
+	}
+	return list
+}
+
+// Enabled возвращает включенные стратегии в порядке регистрации
+func (r *Registry) Enabled() []Registration {
+	var list []Registration
+	for _, reg := range r.List() {
+		if reg.Enabled() {
+			list = append(list, reg)
+		}
+	}
+	return list
+}
+
+// Names возвращает отсортированные имена зарегистрированных стратегий
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := append([]string(nil), r.order...)
+	sort.Strings(names)
+	return names
+}
+
+var (
+	_ Strategy = (*TurtleStrategy)(nil)
+	_ Strategy = (*MACrossoverStrategy)(nil)
+	_ Strategy = (*MACDStrategy)(nil)
+	_ Strategy = (*BollingerStrategy)(nil)
+)
@@ -4,23 +4,75 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/indicators"
 )
 
+// Системы "Черепах"
+const (
+	TurtleSystem1    = "1"    // Прорыв entry_breakout_days (20), выход по exit_breakout_days (10)
+	TurtleSystem2    = "2"    // Прорыв 55 дней, выход по 20-дневному прорыву
+	TurtleSystemBoth = "both" // Обе системы: System 2 ловит прорывы, пропущенные System 1
+)
+
+// turtleSkipHistory сколько свечей истории сверх минимума нужно для правила
+// пропуска прорыва System 1 после прибыльной сделки
+const turtleSkipHistory = 120
+
 // TurtleStrategy реализует стратегию "Черепах"
 type TurtleStrategy struct {
-	apiClient         *api.APIClient
+	source            CandleSource
 	lookbackPeriod    int
 	entryBreakoutDays int
 	exitBreakoutDays  int
 	atrPeriod         int
 	atrMultiplier     float64
 	riskPerTrade      float64
+	rules             TurtleRules
 	mathUtils         *MathUtils // ДОБАВЛЯЕМ
 }
 
+// TurtleRules правила "Черепах": выбор системы, пропуск прорыва после
+// прибыльной сделки и пирамидинг юнитами по N (ATR)
+type TurtleRules struct {
+	System           string  // TurtleSystem1, TurtleSystem2 или TurtleSystemBoth
+	System2EntryDays int     // Дней для прорыва входа System 2
+	System2ExitDays  int     // Дней для прорыва выхода System 2
+	SkipAfterWin     bool    // Пропускать прорыв System 1, если предыдущий был прибыльным
+	MaxUnits         int     // Максимум юнитов на инструмент
+	PyramidStep      float64 // Шаг добавления юнита, в N
+}
+
+// DefaultTurtleRules классические правила: System 1 с пропуском после
+// прибыльного прорыва, до 4 юнитов с добавлением через 0.5N
+func DefaultTurtleRules() TurtleRules {
+	return TurtleRules{
+		System:           TurtleSystem1,
+		System2EntryDays: 55,
+		System2ExitDays:  20,
+		SkipAfterWin:     true,
+		MaxUnits:         4,
+		PyramidStep:      0.5,
+	}
+}
+
+// turtleSystem уровни прорыва одной системы
+type turtleSystem struct {
+	name      string
+	entryDays int
+	exitDays  int
+}
+
+// PyramidLevel уровень добавления юнита
+type PyramidLevel struct {
+	Unit     int     // Номер юнита (2, 3, ...)
+	Price    float64 // Цена добавления
+	StopLoss float64 // Стоп всей позиции после добавления (2N от цены юнита)
+}
+
 // Signal представляет торговый сигнал
 type Signal struct {
 	Instrument   string
@@ -28,192 +80,294 @@ type Signal struct {
 	Price        float64
 	StopLoss     float64
 	TakeProfit   float64
-	PositionSize float64
-	Reason       string // Детальное описание условий
+	PositionSize float64 // Размер позиции, бумаг (целое число лотов)
+	Reason       string  // Детальное описание условий
 	Timestamp    time.Time
+
+	RiskPerTrade float64 // Риск на сделку, доля счета (для пересчета размера под счет)
+	LotSize      int     // Бумаг в лоте
+	Lots         int     // Размер позиции в лотах
+	Cost         float64 // Стоимость позиции, ₽
+
+	LotSizeUnknown bool // Размер лота неизвестен: Lots и Cost не рассчитаны
+
+	Confidence float64 // Уверенность сигнала на вход, 0-100% (ScoreConfidence)
+
+	System  string         // Система "Черепах" ("1" или "2"), для других стратегий пусто
+	Pyramid []PyramidLevel // Уровни добавления юнитов с подтянутыми стопами
 }
 
-// NewTurtleStrategy создает новую стратегию "Черепах"
-func NewTurtleStrategy(apiClient *api.APIClient, lookbackPeriod, entryBreakoutDays, exitBreakoutDays, atrPeriod int, atrMultiplier, riskPerTrade float64) *TurtleStrategy {
+// NewTurtleStrategy создает новую стратегию "Черепах" с классическими правилами
+func NewTurtleStrategy(source CandleSource, lookbackPeriod, entryBreakoutDays, exitBreakoutDays, atrPeriod int, atrMultiplier, riskPerTrade float64) *TurtleStrategy {
 	return &TurtleStrategy{
-		apiClient:         apiClient,
+		source:            source,
 		lookbackPeriod:    lookbackPeriod,
 		entryBreakoutDays: entryBreakoutDays,
 		exitBreakoutDays:  exitBreakoutDays,
 		atrPeriod:         atrPeriod,
 		atrMultiplier:     atrMultiplier,
 		riskPerTrade:      riskPerTrade,
+		rules:             DefaultTurtleRules(),
 		mathUtils:         &MathUtils{}, // ДОБАВЛЯЕМ
 	}
 }
 
+// WithRules задает правила системы. Незаполненные поля берутся из DefaultTurtleRules.
+func (ts *TurtleStrategy) WithRules(rules TurtleRules) *TurtleStrategy {
+	defaults := DefaultTurtleRules()
+	if rules.System == "" {
+		rules.System = defaults.System
+	}
+	if rules.System2EntryDays <= 0 {
+		rules.System2EntryDays = defaults.System2EntryDays
+	}
+	if rules.System2ExitDays <= 0 {
+		rules.System2ExitDays = defaults.System2ExitDays
+	}
+	if rules.MaxUnits <= 0 {
+		rules.MaxUnits = defaults.MaxUnits
+	}
+	if rules.PyramidStep <= 0 {
+		rules.PyramidStep = defaults.PyramidStep
+	}
+
+	ts.rules = rules
+	return ts
+}
+
+// Name короткое имя стратегии
+func (ts *TurtleStrategy) Name() string {
+	return "turtle"
+}
+
+// Title название стратегии для пользователя
+func (ts *TurtleStrategy) Title() string {
+	return "Черепахи"
+}
+
+// Params текущие параметры стратегии
+func (ts *TurtleStrategy) Params() []Param {
+	var systems []string
+	for _, system := range ts.systems() {
+		systems = append(systems, fmt.Sprintf("System %s (%d/%d)", system.name, system.entryDays, system.exitDays))
+	}
+
+	params := []Param{
+		{Name: "Система", Value: strings.Join(systems, " + ")},
+		{Name: "Период анализа", Value: fmt.Sprintf("%d дней", ts.lookbackPeriod)},
+		{Name: "ATR (N)", Value: fmt.Sprintf("%d, стоп %.1fN", ts.atrPeriod, ts.atrMultiplier)},
+		{Name: "Риск на юнит", Value: fmt.Sprintf("%.1f%%", ts.riskPerTrade*100)},
+		{Name: "Пирамидинг", Value: fmt.Sprintf("до %d юнитов через %.1fN", ts.rules.MaxUnits, ts.rules.PyramidStep)},
+	}
+	if ts.usesSystem1() && ts.rules.SkipAfterWin {
+		params = append(params, Param{Name: "Пропуск", Value: "прорыв System 1 после прибыльного"})
+	}
+	return params
+}
+
+// Timeframe таймфрейм анализа (дневные свечи)
+func (ts *TurtleStrategy) Timeframe() string {
+	return "24"
+}
+
+// HistoryDays сколько календарных дней истории нужно для анализа
+func (ts *TurtleStrategy) HistoryDays() int {
+	return ts.historyBars() * 2
+}
+
+// systems активные системы в порядке приоритета
+func (ts *TurtleStrategy) systems() []turtleSystem {
+	system1 := turtleSystem{name: TurtleSystem1, entryDays: ts.entryBreakoutDays, exitDays: ts.exitBreakoutDays}
+	system2 := turtleSystem{name: TurtleSystem2, entryDays: ts.rules.System2EntryDays, exitDays: ts.rules.System2ExitDays}
+
+	switch ts.rules.System {
+	case TurtleSystem2:
+		return []turtleSystem{system2}
+	case TurtleSystemBoth:
+		return []turtleSystem{system1, system2}
+	default:
+		return []turtleSystem{system1}
+	}
+}
+
+// usesSystem1 участвует ли System 1 в анализе
+func (ts *TurtleStrategy) usesSystem1() bool {
+	return ts.rules.System != TurtleSystem2
+}
+
+// minCandles минимальное число свечей для расчета уровней и ATR
+func (ts *TurtleStrategy) minCandles() int {
+	bars := ts.mathUtils.MaxInt(ts.lookbackPeriod, ts.atrPeriod+2)
+	for _, system := range ts.systems() {
+		bars = ts.mathUtils.MaxInt(bars, ts.mathUtils.MaxInt(system.entryDays, system.exitDays)+1)
+	}
+	return bars
+}
+
+// historyBars сколько свечей истории передавать в анализ: с правилом пропуска
+// нужны предыдущие прорывы System 1
+func (ts *TurtleStrategy) historyBars() int {
+	if ts.usesSystem1() && ts.rules.SkipAfterWin {
+		return ts.minCandles() + turtleSkipHistory
+	}
+	return ts.minCandles()
+}
+
+// Analyze формирует сигналы по свечам инструмента (реализация Strategy)
+func (ts *TurtleStrategy) Analyze(ctx context.Context, instrument string, candles []api.Candle) ([]Signal, error) {
+	return ts.AnalyzeCandles(instrument, candles)
+}
+
 // AnalyzeInstrument анализирует инструмент и возвращает сигналы с детальной информацией
 func (ts *TurtleStrategy) AnalyzeInstrument(ctx context.Context, instrument string) ([]Signal, error) {
 	// Получаем свечи за нужный период
-	to := time.Now().Format("2006-01-02")
-	from := time.Now().AddDate(0, 0, -ts.lookbackPeriod*2).Format("2006-01-02")
+	return AnalyzeWith(ctx, ts.source, ts, instrument)
+}
 
-	candles, err := ts.apiClient.GetCandles(ctx, instrument, "24", from, to)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка получения свечей: %w", err)
-	}
+// turtleLevels уровни прорыва системы на текущей свече
+type turtleLevels struct {
+	system                                                 turtleSystem
+	entryHigh, entryLow, exitHigh, exitLow                 float64
+	entryHighDate, entryLowDate, exitHighDate, exitLowDate time.Time
+}
 
-	if len(candles) < ts.lookbackPeriod {
+// AnalyzeCandles формирует сигналы по уже загруженным свечам (последняя свеча - текущая).
+// Вход - по прорыву одной из активных систем, стоп - atrMultiplier N, размер юнита -
+// риск на сделку, деленный на расстояние до стопа; выход - по обратному прорыву системы.
+func (ts *TurtleStrategy) AnalyzeCandles(instrument string, candles []api.Candle) ([]Signal, error) {
+	if len(candles) < ts.minCandles() || len(candles) < 2 {
 		return nil, fmt.Errorf("недостаточно данных для анализа")
 	}
 
 	// Конвертируем свечи в удобный формат
-	prices := make([]float64, len(candles))
-	highs := make([]float64, len(candles))
-	lows := make([]float64, len(candles))
-	closes := make([]float64, len(candles))
-	dates := make([]time.Time, len(candles))
-
-	for i, candle := range candles {
-		if closeVal, ok := candle["close"].(float64); ok {
-			closes[i] = closeVal
-			prices[i] = closeVal
-		}
-		if highVal, ok := candle["high"].(float64); ok {
-			highs[i] = highVal
-		}
-		if lowVal, ok := candle["low"].(float64); ok {
-			lows[i] = lowVal
-		}
-		if beginVal, ok := candle["begin"].(string); ok {
-			if t, err := time.Parse("2006-01-02 15:04:05", beginVal); err == nil {
-				dates[i] = t
-			}
+	highs, lows, closes, _, dates := candleSeries(candles)
+
+	// Уровни прорыва строятся по предыдущим N свечам, без текущей:
+	// иначе максимум периода всегда включает текущую цену
+	prevHighs, prevLows, prevDates := highs[:len(highs)-1], lows[:len(lows)-1], dates[:len(dates)-1]
+
+	var levels []turtleLevels
+	for _, system := range ts.systems() {
+		l := turtleLevels{
+			system:    system,
+			entryHigh: ts.calculateBreakout(prevHighs, system.entryDays, "high"),
+			entryLow:  ts.calculateBreakout(prevLows, system.entryDays, "low"),
+			exitHigh:  ts.calculateBreakout(prevHighs, system.exitDays, "high"),
+			exitLow:   ts.calculateBreakout(prevLows, system.exitDays, "low"),
 		}
+
+		// Находим даты прорывов
+		l.entryHighDate = ts.findBreakoutDate(prevDates, prevHighs, l.entryHigh, system.entryDays)
+		l.entryLowDate = ts.findBreakoutDate(prevDates, prevLows, l.entryLow, system.entryDays)
+		l.exitHighDate = ts.findBreakoutDate(prevDates, prevHighs, l.exitHigh, system.exitDays)
+		l.exitLowDate = ts.findBreakoutDate(prevDates, prevLows, l.exitLow, system.exitDays)
+		levels = append(levels, l)
 	}
 
-	// Рассчитываем показатели
-	entryBreakoutHigh := ts.calculateBreakout(highs, ts.entryBreakoutDays, "high")
-	entryBreakoutLow := ts.calculateBreakout(lows, ts.entryBreakoutDays, "low")
-	exitBreakoutHigh := ts.calculateBreakout(highs, ts.exitBreakoutDays, "high")
-	exitBreakoutLow := ts.calculateBreakout(lows, ts.exitBreakoutDays, "low")
+	atrSeries := indicators.ATR(highs, lows, closes, ts.atrPeriod)
 	atr := ts.calculateATR(highs, lows, closes)
 
-	// Находим даты прорывов
-	entryBreakoutHighDate := ts.findBreakoutDate(dates, highs, entryBreakoutHigh, ts.entryBreakoutDays)
-	entryBreakoutLowDate := ts.findBreakoutDate(dates, lows, entryBreakoutLow, ts.entryBreakoutDays)
-	exitBreakoutHighDate := ts.findBreakoutDate(dates, highs, exitBreakoutHigh, ts.exitBreakoutDays)
-	exitBreakoutLowDate := ts.findBreakoutDate(dates, lows, exitBreakoutLow, ts.exitBreakoutDays)
-
 	currentPrice := closes[len(closes)-1]
 	currentDate := dates[len(dates)-1]
 
-	var signals []Signal
-
-	// Формируем детальную информацию о прорывах
-	entryHighInfo := fmt.Sprintf("Прорыв входа для лонга: %.2f (дата установки: %s)",
-		entryBreakoutHigh, entryBreakoutHighDate.Format("02.01.2006"))
-	entryLowInfo := fmt.Sprintf("Прорыв входа для шорта: %.2f (дата установки: %s)",
-		entryBreakoutLow, entryBreakoutLowDate.Format("02.01.2006"))
-	exitHighInfo := fmt.Sprintf("Прорыв выхода для шорта: %.2f (дата установки: %s)",
-		exitBreakoutHigh, exitBreakoutHighDate.Format("02.01.2006"))
-	exitLowInfo := fmt.Sprintf("Прорыв выхода для лонга: %.2f (дата установки: %s)",
-		exitBreakoutLow, exitBreakoutLowDate.Format("02.01.2006"))
-
-	// Проверяем сигналы на вход с детальной информацией
-	if currentPrice >= entryBreakoutHigh {
-		// Сигнал на вход в длинную позицию
-		stopLoss := currentPrice - (atr * ts.atrMultiplier)
-		takeProfit := currentPrice + (2 * (currentPrice - stopLoss))
-		positionSize := ts.calculatePositionSize(currentPrice, stopLoss)
-
-		distance := currentPrice - entryBreakoutHigh
-		distancePercent := (distance / entryBreakoutHigh) * 100
-
-		reason := fmt.Sprintf("%s\n"+
-			"Текущая цена: %.2f > %.2f (превышение на %.2f₽ / %.2f%%)\n"+
-			"ATR: %.2f | Стоп-лосс: %.2f (%.2f%% от цены)\n"+
-			"Тейк-профит: %.2f (риск:прибыль = 1:2)",
-			entryHighInfo, currentPrice, entryBreakoutHigh, distance, distancePercent,
-			atr, stopLoss, ((currentPrice-stopLoss)/currentPrice)*100, takeProfit)
-
-		signals = append(signals, Signal{
-			Instrument:   instrument,
-			SignalType:   "entry_long",
-			Price:        currentPrice,
-			StopLoss:     stopLoss,
-			TakeProfit:   takeProfit,
-			PositionSize: positionSize,
-			Reason:       reason,
-			Timestamp:    time.Now(),
-		})
+	// Правило пропуска: прорыв System 1 после прибыльного прорыва не торгуется
+	var notes []string
+	skipSystem1 := false
+	if ts.usesSystem1() && ts.rules.SkipAfterWin {
+		if won, entryIdx, found := ts.lastSystem1Trade(highs, lows, closes, atrSeries); found && won {
+			skipSystem1 = true
+			notes = append(notes, fmt.Sprintf("⏭ Прорыв System 1 пропускается: предыдущий прорыв (%s) был прибыльным",
+				dates[entryIdx].Format("02.01.2006")))
+		}
 	}
 
-	if currentPrice <= entryBreakoutLow {
-		// Сигнал на вход в короткую позицию
-		stopLoss := currentPrice + (atr * ts.atrMultiplier)
-		takeProfit := currentPrice - (2 * (stopLoss - currentPrice))
-		positionSize := ts.calculatePositionSize(currentPrice, stopLoss)
-
-		distance := entryBreakoutLow - currentPrice
-		distancePercent := (distance / entryBreakoutLow) * 100
+	var signals []Signal
 
-		reason := fmt.Sprintf("%s\n"+
-			"Текущая цена: %.2f < %.2f (недобор на %.2f₽ / %.2f%%)\n"+
-			"ATR: %.2f | Стоп-лосс: %.2f (%.2f%% от цены)\n"+
-			"Тейк-профит: %.2f (риск:прибыль = 1:2)",
-			entryLowInfo, currentPrice, entryBreakoutLow, distance, distancePercent,
-			atr, stopLoss, ((stopLoss-currentPrice)/currentPrice)*100, takeProfit)
+	// Проверяем сигналы на вход: не более одного на направление, System 1 в приоритете
+	longEntered, shortEntered := false, false
+	for _, l := range levels {
+		system := l.system
+		skipped := skipSystem1 && system.name == TurtleSystem1
+
+		if !longEntered && currentPrice > l.entryHigh {
+			if skipped {
+				notes = append(notes, fmt.Sprintf("System 1: цена %.2f выше %.2f, вход в лонг пропущен", currentPrice, l.entryHigh))
+			} else {
+				info := fmt.Sprintf("System %s. Прорыв входа для лонга: %.2f (дата установки: %s)",
+					system.name, l.entryHigh, l.entryHighDate.Format("02.01.2006"))
+				signals = append(signals, ts.entrySignal(instrument, "entry_long", system, info, currentPrice, l.entryHigh, atr, currentDate))
+				longEntered = true
+			}
+		}
 
-		signals = append(signals, Signal{
-			Instrument:   instrument,
-			SignalType:   "entry_short",
-			Price:        currentPrice,
-			StopLoss:     stopLoss,
-			TakeProfit:   takeProfit,
-			PositionSize: positionSize,
-			Reason:       reason,
-			Timestamp:    time.Now(),
-		})
+		if !shortEntered && currentPrice < l.entryLow {
+			if skipped {
+				notes = append(notes, fmt.Sprintf("System 1: цена %.2f ниже %.2f, вход в шорт пропущен", currentPrice, l.entryLow))
+			} else {
+				info := fmt.Sprintf("System %s. Прорыв входа для шорта: %.2f (дата установки: %s)",
+					system.name, l.entryLow, l.entryLowDate.Format("02.01.2006"))
+				signals = append(signals, ts.entrySignal(instrument, "entry_short", system, info, currentPrice, l.entryLow, atr, currentDate))
+				shortEntered = true
+			}
+		}
 	}
 
-	// Проверяем сигналы на выход с детальной информацией
-	if currentPrice <= exitBreakoutLow {
-		distance := exitBreakoutLow - currentPrice
-		distancePercent := (distance / exitBreakoutLow) * 100
-
-		reason := fmt.Sprintf("%s\n"+
-			"Текущая цена: %.2f < %.2f (недобор на %.2f₽ / %.2f%%)\n"+
-			"Время удержания уровня: %d дней",
-			exitLowInfo, currentPrice, exitBreakoutLow, distance, distancePercent,
-			int(currentDate.Sub(exitBreakoutLowDate).Hours()/24))
+	// Проверяем сигналы на выход: у каждой системы свой уровень выхода
+	for _, l := range levels {
+		if currentPrice < l.exitLow {
+			distance := l.exitLow - currentPrice
+			distancePercent := (distance / l.exitLow) * 100
+
+			reason := fmt.Sprintf("System %s. Прорыв выхода для лонга: %.2f (дата установки: %s)\n"+
+				"Текущая цена: %.2f < %.2f (недобор на %.2f₽ / %.2f%%)\n"+
+				"Время удержания уровня: %d дней",
+				l.system.name, l.exitLow, l.exitLowDate.Format("02.01.2006"),
+				currentPrice, l.exitLow, distance, distancePercent,
+				int(currentDate.Sub(l.exitLowDate).Hours()/24))
+
+			signals = append(signals, Signal{
+				Instrument: instrument,
+				SignalType: "exit_long",
+				Price:      currentPrice,
+				Reason:     reason,
+				Timestamp:  currentDate,
+				System:     l.system.name,
+			})
+		}
 
-		signals = append(signals, Signal{
-			Instrument: instrument,
-			SignalType: "exit_long",
-			Price:      currentPrice,
-			Reason:     reason,
-			Timestamp:  time.Now(),
-		})
+		if currentPrice > l.exitHigh {
+			distance := currentPrice - l.exitHigh
+			distancePercent := (distance / l.exitHigh) * 100
+
+			reason := fmt.Sprintf("System %s. Прорыв выхода для шорта: %.2f (дата установки: %s)\n"+
+				"Текущая цена: %.2f > %.2f (превышение на %.2f₽ / %.2f%%)\n"+
+				"Время удержания уровня: %d дней",
+				l.system.name, l.exitHigh, l.exitHighDate.Format("02.01.2006"),
+				currentPrice, l.exitHigh, distance, distancePercent,
+				int(currentDate.Sub(l.exitHighDate).Hours()/24))
+
+			signals = append(signals, Signal{
+				Instrument: instrument,
+				SignalType: "exit_short",
+				Price:      currentPrice,
+				Reason:     reason,
+				Timestamp:  currentDate,
+				System:     l.system.name,
+			})
+		}
 	}
 
-	if currentPrice >= exitBreakoutHigh {
-		distance := currentPrice - exitBreakoutHigh
-		distancePercent := (distance / exitBreakoutHigh) * 100
-
-		reason := fmt.Sprintf("%s\n"+
-			"Текущая цена: %.2f > %.2f (превышение на %.2f₽ / %.2f%%)\n"+
-			"Время удержания уровня: %d дней",
-			exitHighInfo, currentPrice, exitBreakoutHigh, distance, distancePercent,
-			int(currentDate.Sub(exitBreakoutHighDate).Hours()/24))
-
-		signals = append(signals, Signal{
-			Instrument: instrument,
-			SignalType: "exit_short",
-			Price:      currentPrice,
-			Reason:     reason,
-			Timestamp:  time.Now(),
-		})
+	// Пропущенный вход поясняем в сигналах на выход
+	if len(signals) > 0 && len(notes) > 0 {
+		for i := range signals {
+			signals[i].Reason += "\n" + strings.Join(notes, "\n")
+		}
 	}
 
 	// Если сигналов нет, все равно возвращаем информацию о текущих уровнях
 	if len(signals) == 0 {
-		reason := fmt.Sprintf("Анализ инструмента %s\n\n"+
+		l := levels[0]
+		reason := fmt.Sprintf("Анализ инструмента %s (System %s)\n\n"+
 			"📊 ТЕКУЩИЕ УРОВНИ:\n"+
 			"• Цена: %.2f\n"+
 			"• ATR: %.2f (волатильность)\n\n"+
@@ -227,32 +381,162 @@ func (ts *TurtleStrategy) AnalyzeInstrument(ctx context.Context, instrument stri
 			"• До входа в лонг: %.2f (%.2f%%)\n"+
 			"• До входа в шорт: %.2f (%.2f%%)\n"+
 			"• До выхода из лонг: %.2f (%.2f%%)\n"+
-			"• До выхода из шорт: %.2f (%.2f%%)\n\n"+
-			"💡 РЕКОМЕНДАЦИЯ: Ожидание пробоя уровней",
-			instrument,
+			"• До выхода из шорт: %.2f (%.2f%%)\n\n",
+			instrument, l.system.name,
 			currentPrice,
 			atr,
-			entryBreakoutHigh, entryBreakoutHighDate.Format("02.01"),
-			entryBreakoutLow, entryBreakoutLowDate.Format("02.01"),
-			exitBreakoutLow, exitBreakoutLowDate.Format("02.01"),
-			exitBreakoutHigh, exitBreakoutHighDate.Format("02.01"),
-			entryBreakoutHigh-currentPrice, ((entryBreakoutHigh-currentPrice)/currentPrice)*100,
-			currentPrice-entryBreakoutLow, ((currentPrice-entryBreakoutLow)/currentPrice)*100,
-			currentPrice-exitBreakoutLow, ((currentPrice-exitBreakoutLow)/currentPrice)*100,
-			exitBreakoutHigh-currentPrice, ((exitBreakoutHigh-currentPrice)/currentPrice)*100)
+			l.entryHigh, l.entryHighDate.Format("02.01"),
+			l.entryLow, l.entryLowDate.Format("02.01"),
+			l.exitLow, l.exitLowDate.Format("02.01"),
+			l.exitHigh, l.exitHighDate.Format("02.01"),
+			l.entryHigh-currentPrice, ((l.entryHigh-currentPrice)/currentPrice)*100,
+			currentPrice-l.entryLow, ((currentPrice-l.entryLow)/currentPrice)*100,
+			currentPrice-l.exitLow, ((currentPrice-l.exitLow)/currentPrice)*100,
+			l.exitHigh-currentPrice, ((l.exitHigh-currentPrice)/currentPrice)*100)
+
+		for _, other := range levels[1:] {
+			reason += fmt.Sprintf("🐢 SYSTEM %s: вход > %.2f / < %.2f, выход < %.2f / > %.2f\n\n",
+				other.system.name, other.entryHigh, other.entryLow, other.exitLow, other.exitHigh)
+		}
+		for _, note := range notes {
+			reason += note + "\n"
+		}
+		if len(notes) > 0 {
+			reason += "\n"
+		}
+		reason += "💡 РЕКОМЕНДАЦИЯ: Ожидание пробоя уровней"
 
 		signals = append(signals, Signal{
 			Instrument: instrument,
 			SignalType: "no_signal",
 			Price:      currentPrice,
 			Reason:     reason,
-			Timestamp:  time.Now(),
+			Timestamp:  currentDate,
 		})
 	}
 
 	return signals, nil
 }
 
+// entrySignal формирует сигнал на вход на свече barTime: стоп atrMultiplier N, размер юнита по риску
+// и уровни добавления юнитов. Фиксированного тейк-профита нет - выход по обратному прорыву.
+func (ts *TurtleStrategy) entrySignal(instrument, signalType string, system turtleSystem, info string, price, level, atr float64, barTime time.Time) Signal {
+	long := signalType == "entry_long"
+
+	stopLoss := price - atr*ts.atrMultiplier
+	distance := price - level
+	comparison := fmt.Sprintf("Текущая цена: %.2f > %.2f (превышение на %.2f₽ / %.2f%%)",
+		price, level, distance, distance/level*100)
+	if !long {
+		stopLoss = price + atr*ts.atrMultiplier
+		distance = level - price
+		comparison = fmt.Sprintf("Текущая цена: %.2f < %.2f (недобор на %.2f₽ / %.2f%%)",
+			price, level, distance, distance/level*100)
+	}
+
+	pyramid := ts.pyramidLevels(price, atr, long)
+
+	reason := fmt.Sprintf("%s\n%s\n"+
+		"N (ATR): %.2f | Стоп-лосс %.1fN: %.2f (%.2f%% от цены)\n"+
+		"Юнит: риск %.1f%% капитала\n"+
+		"Выход: обратный прорыв %d дней или стоп",
+		info, comparison,
+		atr, ts.atrMultiplier, stopLoss, math.Abs(price-stopLoss)/price*100,
+		ts.riskPerTrade*100,
+		system.exitDays)
+	if len(pyramid) > 0 {
+		reason += fmt.Sprintf("\nПирамидинг: +1 юнит через каждые %.1fN, до %d юнитов", ts.rules.PyramidStep, ts.rules.MaxUnits)
+		for _, add := range pyramid {
+			reason += fmt.Sprintf("\n  • Юнит %d: %.2f, стоп всей позиции %.2f", add.Unit, add.Price, add.StopLoss)
+		}
+	}
+
+	signal := Signal{
+		Instrument:   instrument,
+		SignalType:   signalType,
+		Price:        price,
+		StopLoss:     stopLoss,
+		Reason:       reason,
+		Timestamp:    barTime,
+		RiskPerTrade: ts.riskPerTrade,
+		System:       system.name,
+		Pyramid:      pyramid,
+	}
+	DefaultAccount().SizePosition(&signal, 1)
+	return signal
+}
+
+// pyramidLevels уровни добавления юнитов через PyramidStep N. После каждого
+// добавления стоп всей позиции переносится на atrMultiplier N от цены юнита.
+func (ts *TurtleStrategy) pyramidLevels(price, atr float64, long bool) []PyramidLevel {
+	if atr <= 0 {
+		return nil
+	}
+
+	step := ts.rules.PyramidStep * atr
+	stop := atr * ts.atrMultiplier
+	if !long {
+		step, stop = -step, -stop
+	}
+
+	var levels []PyramidLevel
+	for unit := 2; unit <= ts.rules.MaxUnits; unit++ {
+		addPrice := price + step*float64(unit-1)
+		levels = append(levels, PyramidLevel{
+			Unit:     unit,
+			Price:    addPrice,
+			StopLoss: addPrice - stop,
+		})
+	}
+	return levels
+}
+
+// lastSystem1Trade прогоняет условные сделки System 1 по истории до текущей свечи
+// и возвращает результат последней закрытой сделки и индекс ее входа. По правилам
+// "Черепах" учитывается каждый прорыв, даже если сделка по нему была пропущена.
+func (ts *TurtleStrategy) lastSystem1Trade(highs, lows, closes, atr []float64) (won bool, entryIdx int, found bool) {
+	entryDays, exitDays := ts.entryBreakoutDays, ts.exitBreakoutDays
+	current := len(closes) - 1
+
+	direction := 0 // 1 - лонг, -1 - шорт
+	var entry, stop float64
+	openedAt := 0
+
+	for i := entryDays; i < current; i++ {
+		if direction != 0 {
+			exitPrice, closed := 0.0, false
+			switch {
+			case direction > 0 && lows[i] <= stop:
+				exitPrice, closed = stop, true
+			case direction < 0 && highs[i] >= stop:
+				exitPrice, closed = stop, true
+			case i >= exitDays && direction > 0 && closes[i] < ts.mathUtils.MinFloat(lows[i-exitDays:i]):
+				exitPrice, closed = closes[i], true
+			case i >= exitDays && direction < 0 && closes[i] > ts.mathUtils.MaxFloat(highs[i-exitDays:i]):
+				exitPrice, closed = closes[i], true
+			}
+
+			if closed {
+				won, entryIdx, found = (exitPrice-entry)*float64(direction) > 0, openedAt, true
+				direction = 0
+			}
+			continue
+		}
+
+		if math.IsNaN(atr[i]) {
+			continue
+		}
+		switch {
+		case closes[i] > ts.mathUtils.MaxFloat(highs[i-entryDays:i]):
+			direction, entry, stop, openedAt = 1, closes[i], closes[i]-atr[i]*ts.atrMultiplier, i
+		case closes[i] < ts.mathUtils.MinFloat(lows[i-entryDays:i]):
+			direction, entry, stop, openedAt = -1, closes[i], closes[i]+atr[i]*ts.atrMultiplier, i
+		}
+	}
+
+	return won, entryIdx, found
+}
+
 // findBreakoutDate находит дату установки уровня прорыва
 func (ts *TurtleStrategy) findBreakoutDate(dates []time.Time, prices []float64, breakoutLevel float64, days int) time.Time {
 	if len(prices) < days {
@@ -295,51 +579,13 @@ func (ts *TurtleStrategy) calculateBreakout(prices []float64, days int, priceTyp
 	}
 }
 
-// calculateATR рассчитывает Average True Range
+// calculateATR рассчитывает текущий Average True Range (по Уайлдеру)
 func (ts *TurtleStrategy) calculateATR(highs, lows, closes []float64) float64 {
-	if len(highs) < ts.atrPeriod+1 {
-		return 0
-	}
-
-	trValues := make([]float64, 0)
-	for i := 1; i < len(highs); i++ {
-		hl := highs[i] - lows[i]
-		hcp := math.Abs(highs[i] - closes[i-1])
-		lcp := math.Abs(lows[i] - closes[i-1])
-
-		tr := math.Max(hl, math.Max(hcp, lcp))
-		trValues = append(trValues, tr)
-	}
-
-	// Простое скользящее среднее для TR
-	if len(trValues) < ts.atrPeriod {
+	atr, ok := indicators.Last(indicators.ATR(highs, lows, closes, ts.atrPeriod))
+	if !ok {
 		return 0
 	}
-
-	var sum float64
-	for i := len(trValues) - ts.atrPeriod; i < len(trValues); i++ {
-		sum += trValues[i]
-	}
-
-	return sum / float64(ts.atrPeriod)
-}
-
-// calculatePositionSize рассчитывает размер позиции
-func (ts *TurtleStrategy) calculatePositionSize(entryPrice, stopLoss float64) float64 {
-	if stopLoss == 0 {
-		return 0
-	}
-
-	riskPerUnit := math.Abs(entryPrice - stopLoss)
-	if riskPerUnit == 0 {
-		return 0
-	}
-
-	// Предполагаем счет 100000 рублей
-	accountSize := 100000.0
-	riskAmount := accountSize * ts.riskPerTrade
-
-	return riskAmount / riskPerUnit
+	return atr
 }
 
 func max(values ...float64) float64 {
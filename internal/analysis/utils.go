@@ -1,6 +1,12 @@
 package analysis
 
-import "math"
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
 
 // MathUtils содержит математические утилиты для стратегий
 type MathUtils struct{}
@@ -41,48 +47,62 @@ func (mu *MathUtils) MaxInt(a, b int) int {
 	return b
 }
 
-// CalculateATR рассчитывает Average True Range
-func (mu *MathUtils) CalculateATR(highs, lows, closes []float64, period int) []float64 {
-	if len(highs) < period+1 {
-		return make([]float64, len(highs))
-	}
+// candleSeries раскладывает свечи на отдельные ценовые ряды
+func candleSeries(candles []api.Candle) (highs, lows, closes, volumes []float64, dates []time.Time) {
+	highs = make([]float64, len(candles))
+	lows = make([]float64, len(candles))
+	closes = make([]float64, len(candles))
+	volumes = make([]float64, len(candles))
+	dates = make([]time.Time, len(candles))
 
-	tr := make([]float64, len(highs))
-	atr := make([]float64, len(highs))
+	for i, candle := range candles {
+		highs[i] = candle.High
+		lows[i] = candle.Low
+		closes[i] = candle.Close
+		volumes[i] = candle.Volume
+		dates[i] = candle.Begin
+	}
 
-	// Расчет True Range
-	for i := 1; i < len(highs); i++ {
-		hl := highs[i] - lows[i]
-		hcp := math.Abs(highs[i] - closes[i-1])
-		lcp := math.Abs(lows[i] - closes[i-1])
+	return highs, lows, closes, volumes, dates
+}
 
-		// Находим максимум из трех значений
-		maxVal := hl
-		if hcp > maxVal {
-			maxVal = hcp
-		}
-		if lcp > maxVal {
-			maxVal = lcp
-		}
-		tr[i] = maxVal
+// applyATRRisk рассчитывает стоп-лосс и тейк-профит сигнала на вход по ATR,
+// размер позиции по риску на сделку для счета по умолчанию и дописывает
+// уровни в причину сигнала
+func applyATRRisk(signal *Signal, atr, stopMultiplier, takeProfitRatio, riskPerTrade float64) {
+	if atr <= 0 || math.IsNaN(atr) {
+		return
 	}
 
-	// Первое значение ATR = среднее первых period TR
-	sum := 0.0
-	for i := 1; i <= period; i++ {
-		sum += tr[i]
+	var stopLoss, takeProfit, riskPerShare float64
+	switch signal.SignalType {
+	case "entry_long":
+		// Стоп-лосс ниже текущей цены на ATR * множитель
+		stopLoss = signal.Price - atr*stopMultiplier
+		takeProfit = signal.Price + atr*stopMultiplier*takeProfitRatio
+		riskPerShare = signal.Price - stopLoss
+	case "entry_short":
+		// Стоп-лосс выше текущей цены на ATR * множитель
+		stopLoss = signal.Price + atr*stopMultiplier
+		takeProfit = signal.Price - atr*stopMultiplier*takeProfitRatio
+		riskPerShare = stopLoss - signal.Price
+	default:
+		return
 	}
-	atr[period] = sum / float64(period)
 
-	// Расчет остальных ATR по формуле Уайлдера
-	for i := period + 1; i < len(highs); i++ {
-		atr[i] = (atr[i-1]*float64(period-1) + tr[i]) / float64(period)
-	}
+	signal.StopLoss = stopLoss
+	signal.TakeProfit = takeProfit
+	signal.RiskPerTrade = riskPerTrade
 
-	// Заполняем начало
-	for i := 0; i <= period; i++ {
-		atr[i] = tr[i]
-	}
+	// Размер позиции пересчитывается под счет пользователя при показе сигнала
+	DefaultAccount().SizePosition(signal, 1)
 
-	return atr
+	signal.Reason += fmt.Sprintf("\n\n🎯 УПРАВЛЕНИЕ РИСКАМИ:\n"+
+		"• Стоп-лосс: %.2f (%.1f%%)\n"+
+		"• Тейк-профит: %.2f (риск:прибыль = 1:%.1f)\n"+
+		"• Риск на сделку: %.1f%% счета\n"+
+		"• ATR: %.2f (текущая волатильность)",
+		stopLoss, riskPerShare/signal.Price*100,
+		takeProfit, takeProfitRatio,
+		riskPerTrade*100, atr)
 }
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen запрос не выполнен, так как circuit breaker разомкнут
+var ErrCircuitOpen = errors.New("API временно недоступен (circuit breaker открыт)")
+
+// BreakerState состояние circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Запросы проходят
+	BreakerOpen                         // Запросы отклоняются сразу
+	BreakerHalfOpen                     // Пропускается ограниченное число пробных запросов
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig параметры circuit breaker
+type BreakerConfig struct {
+	FailureThreshold int           // Подряд идущих ошибок до размыкания
+	OpenTimeout      time.Duration // Время в разомкнутом состоянии до пробных запросов
+	HalfOpenRequests int           // Одновременных пробных запросов в half-open
+
+	// OnStateChange вызывается при смене состояния (вне блокировки)
+	OnStateChange func(from, to BreakerState, lastErr error)
+}
+
+// BreakerSnapshot текущее состояние circuit breaker для отображения
+type BreakerSnapshot struct {
+	State       BreakerState
+	Failures    int
+	LastError   string
+	LastFailure time.Time
+	ChangedAt   time.Time
+	RetryAt     time.Time // Когда разомкнутый breaker начнет пропускать пробные запросы
+}
+
+// CircuitBreaker размыкает цепь после серии ошибок API и замыкает ее после успешного запроса
+type CircuitBreaker struct {
+	cfg BreakerConfig
+
+	mu          sync.Mutex
+	state       BreakerState
+	failures    int
+	inFlight    int
+	lastErr     error
+	lastFailure time.Time
+	changedAt   time.Time
+	openedAt    time.Time
+}
+
+// NewCircuitBreaker создает circuit breaker в замкнутом состоянии
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = time.Minute
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+
+	return &CircuitBreaker{
+		cfg:       cfg,
+		state:     BreakerClosed,
+		changedAt: time.Now(),
+	}
+}
+
+// Allow проверяет, можно ли выполнить запрос. При успехе вызывающий обязан
+// сообщить результат через Record.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+
+	if cb.state == BreakerOpen {
+		retryAt := cb.openedAt.Add(cb.cfg.OpenTimeout)
+		if time.Now().Before(retryAt) {
+			cb.mu.Unlock()
+			return fmt.Errorf("%w, повтор после %s", ErrCircuitOpen, retryAt.Format("15:04:05"))
+		}
+		cb.mu.Unlock()
+		cb.transition(BreakerOpen, BreakerHalfOpen)
+		cb.mu.Lock()
+	}
+
+	if cb.state == BreakerHalfOpen {
+		if cb.inFlight >= cb.cfg.HalfOpenRequests {
+			cb.mu.Unlock()
+			return fmt.Errorf("%w, выполняется пробный запрос", ErrCircuitOpen)
+		}
+		cb.inFlight++
+	}
+
+	cb.mu.Unlock()
+	return nil
+}
+
+// Record учитывает результат запроса, разрешенного Allow
+func (cb *CircuitBreaker) Record(ctx context.Context, err error) {
+	cb.mu.Lock()
+
+	if cb.state == BreakerHalfOpen && cb.inFlight > 0 {
+		cb.inFlight--
+	}
+
+	if !isBreakerFailure(ctx, err) {
+		// Отмена запроса вызывающим не говорит о состоянии API
+		if err != nil && ctx.Err() != nil {
+			cb.mu.Unlock()
+			return
+		}
+		cb.failures = 0
+		from := cb.state
+		cb.mu.Unlock()
+		if from != BreakerClosed {
+			cb.transition(from, BreakerClosed)
+		}
+		return
+	}
+
+	cb.failures++
+	cb.lastErr = err
+	cb.lastFailure = time.Now()
+	from := cb.state
+	trip := from == BreakerHalfOpen || (from == BreakerClosed && cb.failures >= cb.cfg.FailureThreshold)
+	cb.mu.Unlock()
+
+	if trip {
+		cb.transition(from, BreakerOpen)
+	}
+}
+
+// Reset принудительно замыкает цепь (например, после успешного HealthCheck)
+func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	cb.failures = 0
+	from := cb.state
+	cb.mu.Unlock()
+
+	if from != BreakerClosed {
+		cb.transition(from, BreakerClosed)
+	}
+}
+
+// State возвращает текущее состояние
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Snapshot возвращает копию состояния для отображения
+func (cb *CircuitBreaker) Snapshot() BreakerSnapshot {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	snapshot := BreakerSnapshot{
+		State:       cb.state,
+		Failures:    cb.failures,
+		LastFailure: cb.lastFailure,
+		ChangedAt:   cb.changedAt,
+	}
+	if cb.lastErr != nil {
+		snapshot.LastError = cb.lastErr.Error()
+	}
+	if cb.state == BreakerOpen {
+		snapshot.RetryAt = cb.openedAt.Add(cb.cfg.OpenTimeout)
+	}
+
+	return snapshot
+}
+
+// transition переводит breaker из состояния from в to, если он все еще в from
+func (cb *CircuitBreaker) transition(from, to BreakerState) {
+	cb.mu.Lock()
+	if cb.state != from {
+		cb.mu.Unlock()
+		return
+	}
+
+	cb.state = to
+	cb.changedAt = time.Now()
+	cb.inFlight = 0
+	if to == BreakerOpen {
+		cb.openedAt = cb.changedAt
+	}
+	lastErr := cb.lastErr
+	cb.mu.Unlock()
+
+	if cb.cfg.OnStateChange != nil {
+		cb.cfg.OnStateChange(from, to, lastErr)
+	}
+}
+
+// isBreakerFailure проверяет, говорит ли ошибка о недоступности API.
+// Ошибки клиента (4xx кроме 429) и некорректные данные не размыкают цепь.
+func isBreakerFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	return !errors.Is(err, ErrMalformedResponse) && !errors.Is(err, ErrMalformedCandle)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var (
+	errUnavailable = &APIError{StatusCode: 503, Status: "503 Service Unavailable"}
+	errNotFound    = &APIError{StatusCode: 404, Status: "404 Not Found"}
+)
+
+// recordTransitions собирает смены состояния breaker
+func recordTransitions(cfg *BreakerConfig) *[]string {
+	var transitions []string
+	cfg.OnStateChange = func(from, to BreakerState, lastErr error) {
+		transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+	}
+	return &transitions
+}
+
+func TestCircuitBreakerStateMachine(t *testing.T) {
+	cfg := BreakerConfig{FailureThreshold: 3, OpenTimeout: 20 * time.Millisecond, HalfOpenRequests: 1}
+	transitions := recordTransitions(&cfg)
+	cb := NewCircuitBreaker(cfg)
+	ctx := context.Background()
+
+	// Ошибки клиента и некорректные данные не размыкают цепь, успех сбрасывает счетчик
+	for _, err := range []error{errUnavailable, errUnavailable, errNotFound, ErrMalformedResponse, nil, errUnavailable, errUnavailable} {
+		if allowErr := cb.Allow(); allowErr != nil {
+			t.Fatalf("Allow() error = %v in closed state", allowErr)
+		}
+		cb.Record(ctx, err)
+	}
+	if cb.State() != BreakerClosed || cb.Snapshot().Failures != 2 {
+		t.Fatalf("state = %s, failures = %d, want closed with 2 failures", cb.State(), cb.Snapshot().Failures)
+	}
+
+	cb.Allow()
+	cb.Record(ctx, errUnavailable)
+	if cb.State() != BreakerOpen {
+		t.Fatalf("state = %s after %d failures, want open", cb.State(), cfg.FailureThreshold)
+	}
+	snapshot := cb.Snapshot()
+	if snapshot.RetryAt.IsZero() || snapshot.LastError == "" {
+		t.Errorf("snapshot = %+v, want retry time and last error", snapshot)
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() error = %v in open state, want ErrCircuitOpen", err)
+	}
+
+	// После паузы пропускается один пробный запрос
+	time.Sleep(cfg.OpenTimeout)
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Allow() error = %v after open timeout, want probe", err)
+	}
+	if cb.State() != BreakerHalfOpen {
+		t.Fatalf("state = %s, want half-open", cb.State())
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second Allow() error = %v in half-open, want ErrCircuitOpen", err)
+	}
+
+	// Неудачная проба снова размыкает цепь
+	cb.Record(ctx, errUnavailable)
+	if cb.State() != BreakerOpen {
+		t.Fatalf("state = %s after failed probe, want open", cb.State())
+	}
+
+	// Успешная проба замыкает цепь
+	time.Sleep(cfg.OpenTimeout)
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Allow() error = %v, want probe", err)
+	}
+	cb.Record(ctx, nil)
+	if cb.State() != BreakerClosed || cb.Snapshot().Failures != 0 {
+		t.Fatalf("state = %s after successful probe, want closed", cb.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if fmt.Sprint(*transitions) != fmt.Sprint(want) {
+		t.Errorf("transitions = %v, want %v", *transitions, want)
+	}
+}
+
+func TestCircuitBreakerIgnoresCanceledRequests(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cb.Allow()
+	cb.Record(ctx, fmt.Errorf("запрос прерван: %w", context.Canceled))
+	if cb.State() != BreakerClosed {
+		t.Errorf("state = %s after canceled request, want closed", cb.State())
+	}
+
+	cb.Allow()
+	cb.Record(context.Background(), errUnavailable)
+	if cb.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", cb.State())
+	}
+	cb.Reset()
+	if cb.State() != BreakerClosed || cb.Allow() != nil {
+		t.Errorf("state = %s after Reset, want closed", cb.State())
+	}
+}
+
+func TestAPIClientCircuitBreakerRejectsRequests(t *testing.T) {
+	server, client := newScriptedServer(t, 503, 503, 503, 503, 503, 503, 503, 503)
+	client.SetRetryPolicy(RetryPolicy{})
+	client.SetCircuitBreaker(NewCircuitBreaker(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetInstruments(context.Background()); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("request %d error = %v, want 503", i+1, err)
+		}
+	}
+
+	// Разомкнутая цепь отклоняет запросы без обращения к API
+	if _, err := client.GetInstruments(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GetInstruments() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := server.count("GET /api/instruments"); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if state, ok := client.CircuitState(); !ok || state.State != BreakerOpen {
+		t.Errorf("CircuitState() = %+v, %v, want open", state, ok)
+	}
+}
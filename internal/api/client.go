@@ -17,6 +17,8 @@ type APIClient struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+	retry      RetryPolicy
+	breaker    *CircuitBreaker
 }
 
 // NewAPIClient создает новый API клиент
@@ -36,9 +38,37 @@ func NewAPIClient(baseURL, token string, timeout time.Duration) *APIClient {
 	}
 }
 
-// HealthCheck проверяет доступность API
+// SetRetryPolicy задает политику повторных попыток
+func (c *APIClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker подключает circuit breaker ко всем запросам, кроме HealthCheck
+func (c *APIClient) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.breaker = breaker
+}
+
+// CircuitState возвращает состояние circuit breaker; false, если он не подключен
+func (c *APIClient) CircuitState() (BreakerSnapshot, bool) {
+	if c.breaker == nil {
+		return BreakerSnapshot{}, false
+	}
+	return c.breaker.Snapshot(), true
+}
+
+// HealthCheck проверяет доступность API. Запрос выполняется в обход circuit breaker
+// одной попыткой; успешный ответ замыкает цепь.
 func (c *APIClient) HealthCheck(ctx context.Context) (map[string]interface{}, error) {
-	return c.doRequest(ctx, "GET", "/health", nil)
+	respBody, status, err := c.doOnce(ctx, "GET", "/health", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.breaker != nil {
+		c.breaker.Reset()
+	}
+
+	return decodeResponse(respBody, status), nil
 }
 
 // GetStats получает статистику
@@ -67,7 +97,7 @@ func (c *APIClient) GetInstruments(ctx context.Context) ([]string, error) {
 }
 
 // GetCandles получает свечи
-func (c *APIClient) GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]map[string]interface{}, error) {
+func (c *APIClient) GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]Candle, error) {
 	url := fmt.Sprintf("/api/candles?instrument=%s&timeframe=%s", instrument, timeframe)
 	if from != "" {
 		url += fmt.Sprintf("&from=%s", from)
@@ -76,49 +106,52 @@ func (c *APIClient) GetCandles(ctx context.Context, instrument, timeframe, from,
 		url += fmt.Sprintf("&to=%s", to)
 	}
 
-	data, err := c.doRequest(ctx, "GET", url, nil)
-	if err != nil {
+	var resp struct {
+		Candles *[]json.RawMessage `json:"candles"`
+	}
+	if err := c.doJSON(ctx, "GET", url, nil, &resp); err != nil {
 		return nil, err
 	}
 
-	if candles, ok := data["candles"].([]interface{}); ok {
-		var result []map[string]interface{}
-		for _, candle := range candles {
-			if cmap, ok := candle.(map[string]interface{}); ok {
-				result = append(result, cmap)
-			}
-		}
-		return result, nil
+	if resp.Candles == nil {
+		return nil, fmt.Errorf("неверный формат свечей: %w", ErrMalformedResponse)
 	}
 
-	return nil, fmt.Errorf("неверный формат свечей")
+	return DecodeCandles(*resp.Candles)
 }
 
 // GetInstrumentInfo получает информацию об инструменте
-func (c *APIClient) GetInstrumentInfo(ctx context.Context, instrument string) (map[string]interface{}, error) {
+func (c *APIClient) GetInstrumentInfo(ctx context.Context, instrument string) (*Instrument, error) {
 	url := fmt.Sprintf("/api/instruments/%s", instrument)
-	return c.doRequest(ctx, "GET", url, nil)
+
+	var info Instrument
+	if err := c.doJSON(ctx, "GET", url, nil, &info); err != nil {
+		return nil, err
+	}
+
+	if info.Ticker == "" {
+		info.Ticker = instrument
+	}
+
+	return &info, nil
 }
 
 // GetInstrumentTimeframes получает таймфреймы для инструмента
-func (c *APIClient) GetInstrumentTimeframes(ctx context.Context, instrument string) ([]map[string]interface{}, error) {
+func (c *APIClient) GetInstrumentTimeframes(ctx context.Context, instrument string) ([]Timeframe, error) {
 	url := fmt.Sprintf("/api/instruments/%s/timeframes", instrument)
-	data, err := c.doRequest(ctx, "GET", url, nil)
-	if err != nil {
+
+	var resp struct {
+		Timeframes *[]Timeframe `json:"timeframes"`
+	}
+	if err := c.doJSON(ctx, "GET", url, nil, &resp); err != nil {
 		return nil, err
 	}
 
-	if timeframes, ok := data["timeframes"].([]interface{}); ok {
-		var result []map[string]interface{}
-		for _, tf := range timeframes {
-			if tmap, ok := tf.(map[string]interface{}); ok {
-				result = append(result, tmap)
-			}
-		}
-		return result, nil
+	if resp.Timeframes == nil {
+		return nil, fmt.Errorf("неверный формат таймфреймов: %w", ErrMalformedResponse)
 	}
 
-	return nil, fmt.Errorf("неверный формат таймфреймов")
+	return *resp.Timeframes, nil
 }
 
 // GetTables получает список таблиц
@@ -142,23 +175,19 @@ func (c *APIClient) GetTables(ctx context.Context) ([]map[string]interface{}, er
 }
 
 // GetTimeframes получает доступные таймфреймы
-func (c *APIClient) GetTimeframes(ctx context.Context) ([]map[string]interface{}, error) {
-	data, err := c.doRequest(ctx, "GET", "/api/timeframes", nil)
-	if err != nil {
+func (c *APIClient) GetTimeframes(ctx context.Context) ([]Timeframe, error) {
+	var resp struct {
+		Timeframes *[]Timeframe `json:"timeframes"`
+	}
+	if err := c.doJSON(ctx, "GET", "/api/timeframes", nil, &resp); err != nil {
 		return nil, err
 	}
 
-	if timeframes, ok := data["timeframes"].([]interface{}); ok {
-		var result []map[string]interface{}
-		for _, tf := range timeframes {
-			if tmap, ok := tf.(map[string]interface{}); ok {
-				result = append(result, tmap)
-			}
-		}
-		return result, nil
+	if resp.Timeframes == nil {
+		return nil, fmt.Errorf("неверный формат таймфреймов: %w", ErrMalformedResponse)
 	}
 
-	return nil, fmt.Errorf("неверный формат таймфреймов")
+	return *resp.Timeframes, nil
 }
 
 // TriggerFetch запускает загрузку данных
@@ -197,19 +226,109 @@ func (c *APIClient) CleanupTables(ctx context.Context, days int) (map[string]int
 
 // doRequest выполняет HTTP запрос
 func (c *APIClient) doRequest(ctx context.Context, method, path string, body interface{}) (map[string]interface{}, error) {
-	var reqBody io.Reader
+	respBody, status, err := c.doRaw(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(respBody, status), nil
+}
+
+// decodeResponse парсит JSON ответ; если ответ не JSON, возвращает его текстом
+func decodeResponse(respBody []byte, status int) map[string]interface{} {
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return map[string]interface{}{
+			"response": string(respBody),
+			"status":   status,
+		}
+	}
+
+	return result
+}
+
+// doJSON выполняет HTTP запрос и строго декодирует JSON ответ в out
+func (c *APIClient) doJSON(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	respBody, _, err := c.doRaw(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+	}
+
+	return nil
+}
+
+// doRaw выполняет HTTP запрос через circuit breaker и возвращает тело ответа
+func (c *APIClient) doRaw(ctx context.Context, method, path string, body interface{}) ([]byte, int, error) {
+	if c.breaker == nil {
+		return c.doWithRetry(ctx, method, path, body)
+	}
+
+	if err := c.breaker.Allow(); err != nil {
+		return nil, 0, err
+	}
+
+	respBody, status, err := c.doWithRetry(ctx, method, path, body)
+	c.breaker.Record(ctx, err)
+
+	return respBody, status, err
+}
+
+// doWithRetry выполняет HTTP запрос с повторными попытками
+func (c *APIClient) doWithRetry(ctx context.Context, method, path string, body interface{}) ([]byte, int, error) {
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("ошибка маршалинга JSON: %w", err)
+			return nil, 0, fmt.Errorf("ошибка маршалинга JSON: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	maxRetries := 0
+	if isRetrySafe(method, path) {
+		maxRetries = c.retry.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.retry.retryDelay(attempt, lastErr)); err != nil {
+				return nil, 0, fmt.Errorf("запрос прерван после %d попыток: %w", attempt, lastErr)
+			}
+		}
+
+		respBody, status, err := c.doOnce(ctx, method, path, jsonData)
+		if err == nil {
+			return respBody, status, nil
+		}
+
+		lastErr = err
+		if !isRetryableError(ctx, err) {
+			return nil, status, err
+		}
+	}
+
+	if maxRetries > 0 {
+		return nil, 0, fmt.Errorf("исчерпаны попытки (%d): %w", maxRetries+1, lastErr)
+	}
+	return nil, 0, lastErr
+}
+
+// doOnce выполняет одну попытку HTTP запроса
+func (c *APIClient) doOnce(ctx context.Context, method, path string, jsonData []byte) ([]byte, int, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
 	}
 
 	url := c.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+		return nil, 0, fmt.Errorf("ошибка создания запроса: %w", err)
 	}
 
 	// Добавляем заголовки
@@ -221,36 +340,31 @@ func (c *APIClient) doRequest(ctx context.Context, method, path string, body int
 	// Выполняем запрос
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+		return nil, 0, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Читаем ответ
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения ответа: %w", err)
+		return nil, resp.StatusCode, fmt.Errorf("ошибка чтения ответа: %w", err)
 	}
 
 	// Проверяем статус код
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("ошибка API: %s - %s", resp.Status, string(respBody))
-	}
-
-	// Парсим JSON ответ
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		// Если не JSON, возвращаем текстовый ответ
-		return map[string]interface{}{
-			"response": string(respBody),
-			"status":   resp.StatusCode,
-		}, nil
+		return nil, resp.StatusCode, &APIError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(respBody),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
-	return result, nil
+	return respBody, resp.StatusCode, nil
 }
 
 // GetTableData получает данные из таблицы
-func (c *APIClient) GetTableData(ctx context.Context, instrument, timeframe, from, to string, limit int) ([]map[string]interface{}, error) {
+func (c *APIClient) GetTableData(ctx context.Context, instrument, timeframe, from, to string, limit int) ([]Candle, error) {
 	url := fmt.Sprintf("/api/tables/%s/%s", instrument, timeframe)
 
 	// Добавляем параметры запроса
@@ -269,22 +383,18 @@ func (c *APIClient) GetTableData(ctx context.Context, instrument, timeframe, fro
 		url += "?" + stringJoin(params, "&")
 	}
 
-	data, err := c.doRequest(ctx, "GET", url, nil)
-	if err != nil {
+	var resp struct {
+		Candles *[]json.RawMessage `json:"candles"`
+	}
+	if err := c.doJSON(ctx, "GET", url, nil, &resp); err != nil {
 		return nil, err
 	}
 
-	if candles, ok := data["candles"].([]interface{}); ok {
-		var result []map[string]interface{}
-		for _, candle := range candles {
-			if cmap, ok := candle.(map[string]interface{}); ok {
-				result = append(result, cmap)
-			}
-		}
-		return result, nil
+	if resp.Candles == nil {
+		return nil, fmt.Errorf("неверный формат данных таблицы: %w", ErrMalformedResponse)
 	}
 
-	return nil, fmt.Errorf("неверный формат данных таблицы")
+	return DecodeCandles(*resp.Candles)
 }
 
 // stringJoin вспомогательная функция для объединения строк
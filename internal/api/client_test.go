@@ -0,0 +1,258 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+)
+
+// fastRetry политика повторов без заметных задержек
+var fastRetry = api.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestAPIClientEndpoints(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	ctx := context.Background()
+
+	health, err := client.HealthCheck(ctx)
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if health["status"] != "healthy" {
+		t.Errorf("health status = %v, want healthy", health["status"])
+	}
+
+	instruments, err := client.GetInstruments(ctx)
+	if err != nil {
+		t.Fatalf("GetInstruments() error = %v", err)
+	}
+	if len(instruments) != 3 || instruments[0] != "GAZP" {
+		t.Errorf("GetInstruments() = %v, want [GAZP LKOH SBER]", instruments)
+	}
+
+	from := time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+	candles, err := client.GetCandles(ctx, "SBER", "24", from, "")
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	if len(candles) < 15 || len(candles) > 23 {
+		t.Errorf("GetCandles() returned %d candles for 30 days, want trading days only", len(candles))
+	}
+	for _, candle := range candles {
+		if err := candle.Validate(); err != nil {
+			t.Fatalf("invalid candle %v: %v", candle.Begin, err)
+		}
+	}
+
+	info, err := client.GetInstrumentInfo(ctx, "SBER")
+	if err != nil {
+		t.Fatalf("GetInstrumentInfo() error = %v", err)
+	}
+	if info.Ticker != "SBER" || len(info.Timeframes) != 1 || info.Timeframes[0].Code != "24" {
+		t.Errorf("GetInstrumentInfo() = %+v", info)
+	}
+
+	tables, err := client.GetTables(ctx)
+	if err != nil {
+		t.Fatalf("GetTables() error = %v", err)
+	}
+	if len(tables) != 3 {
+		t.Errorf("GetTables() returned %d tables, want 3", len(tables))
+	}
+
+	data, err := client.GetTableData(ctx, "GAZP", "24", "", "", 10)
+	if err != nil {
+		t.Fatalf("GetTableData() error = %v", err)
+	}
+	if len(data) != 10 {
+		t.Errorf("GetTableData() returned %d candles, want 10", len(data))
+	}
+
+	if _, err := client.TriggerFetch(ctx); err != nil {
+		t.Fatalf("TriggerFetch() error = %v", err)
+	}
+	if fetcher.Fetches() != 1 {
+		t.Errorf("Fetches() = %d, want 1", fetcher.Fetches())
+	}
+}
+
+func TestAPIClientSyntheticDataIsDeterministic(t *testing.T) {
+	first := fakefetcher.Synthetic(42, 50, 100)
+	second := fakefetcher.Synthetic(42, 50, 100)
+
+	for i := range first {
+		if first[i].Close != second[i].Close || first[i].High != second[i].High {
+			t.Fatalf("candle %d differs: %v vs %v", i, first[i].Close, second[i].Close)
+		}
+	}
+}
+
+func TestAPIClientUnknownInstrument(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+
+	_, err := client.GetCandles(context.Background(), "UNKNOWN", "24", "", "")
+
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("GetCandles() error = %v, want APIError 404", err)
+	}
+}
+
+func TestAPIClientRetriesServerErrors(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	client.SetRetryPolicy(fastRetry)
+
+	fetcher.FailNext(2, http.StatusInternalServerError)
+
+	if _, err := client.GetInstruments(context.Background()); err != nil {
+		t.Fatalf("GetInstruments() error = %v, want success after retries", err)
+	}
+	if got := fetcher.RequestCount("/api/instruments"); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestAPIClientRetriesExhausted(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	client.SetRetryPolicy(fastRetry)
+
+	fetcher.FailNext(10, http.StatusBadGateway)
+
+	_, err := client.GetInstruments(context.Background())
+
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("GetInstruments() error = %v, want wrapped APIError 502", err)
+	}
+	if got := fetcher.RequestCount("/api/instruments"); got != fastRetry.MaxRetries+1 {
+		t.Errorf("requests = %d, want %d", got, fastRetry.MaxRetries+1)
+	}
+}
+
+func TestAPIClientDoesNotRetryFetch(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	client.SetRetryPolicy(fastRetry)
+
+	fetcher.FailNext(1, http.StatusServiceUnavailable)
+
+	if _, err := client.TriggerFetch(context.Background()); err == nil {
+		t.Fatal("TriggerFetch() should fail without retry")
+	}
+	if got := fetcher.RequestCount("/api/fetch"); got != 1 {
+		t.Errorf("requests = %d, want 1 (POST /api/fetch is not idempotent)", got)
+	}
+}
+
+func TestAPIClientDoesNotRetryClientErrors(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	client.SetRetryPolicy(fastRetry)
+
+	fetcher.FailNext(1, http.StatusBadRequest)
+
+	if _, err := client.GetInstruments(context.Background()); err == nil {
+		t.Fatal("GetInstruments() should fail on 400")
+	}
+	if got := fetcher.RequestCount("/api/instruments"); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestAPIClientMalformedResponse(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+
+	fetcher.MalformedNext(1)
+
+	_, err := client.GetCandles(context.Background(), "SBER", "24", "", "")
+	if !errors.Is(err, api.ErrMalformedResponse) {
+		t.Fatalf("GetCandles() error = %v, want ErrMalformedResponse", err)
+	}
+
+	if _, err := client.GetCandles(context.Background(), "SBER", "24", "", ""); err != nil {
+		t.Fatalf("GetCandles() after malformed response error = %v", err)
+	}
+}
+
+func TestAPIClientToken(t *testing.T) {
+	fetcher := fakefetcher.New(t, fakefetcher.WithToken("secret"), fakefetcher.WithSynthetic("SBER", 1, 10, 270))
+
+	unauthorized := api.NewAPIClient(fetcher.URL, "wrong", 5*time.Second)
+	var apiErr *api.APIError
+	if _, err := unauthorized.GetInstruments(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("GetInstruments() with wrong token error = %v, want 401", err)
+	}
+
+	client := api.NewAPIClient(fetcher.URL, "secret", 5*time.Second)
+	if _, err := client.GetInstruments(context.Background()); err != nil {
+		t.Fatalf("GetInstruments() with token error = %v", err)
+	}
+}
+
+func TestAPIClientLatencyRespectsContext(t *testing.T) {
+	fetcher := fakefetcher.New(t, fakefetcher.WithLatency(time.Second), fakefetcher.WithSynthetic("SBER", 1, 10, 270))
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	client.SetRetryPolicy(fastRetry)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetInstruments(ctx); err == nil {
+		t.Fatal("GetInstruments() should fail when context deadline is exceeded")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("GetInstruments() took %v, want to stop at context deadline", elapsed)
+	}
+}
+
+func TestAPIClientCircuitBreaker(t *testing.T) {
+	fetcher := fakefetcher.New(t)
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+	client.SetCircuitBreaker(api.NewCircuitBreaker(api.BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Hour,
+		HalfOpenRequests: 1,
+	}))
+	ctx := context.Background()
+
+	fetcher.FailNext(2, http.StatusInternalServerError)
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetInstruments(ctx); err == nil {
+			t.Fatalf("request %d should fail", i+1)
+		}
+	}
+
+	state, _ := client.CircuitState()
+	if state.State != api.BreakerOpen {
+		t.Fatalf("state = %s, want open", state.State)
+	}
+
+	before := fetcher.RequestCount("/api/instruments")
+	if _, err := client.GetInstruments(ctx); !errors.Is(err, api.ErrCircuitOpen) {
+		t.Fatalf("GetInstruments() error = %v, want ErrCircuitOpen", err)
+	}
+	if after := fetcher.RequestCount("/api/instruments"); after != before {
+		t.Errorf("open circuit sent %d requests, want none", after-before)
+	}
+
+	// Успешная проверка здоровья замыкает цепь
+	if _, err := client.HealthCheck(ctx); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if state, _ := client.CircuitState(); state.State != api.BreakerClosed {
+		t.Fatalf("state after health check = %s, want closed", state.State)
+	}
+	if _, err := client.GetInstruments(ctx); err != nil {
+		t.Fatalf("GetInstruments() after recovery error = %v", err)
+	}
+}
@@ -0,0 +1,438 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileColumns названия колонок CSV (без учета регистра и угловых скобок).
+// Time указывается, если дата и время хранятся в разных колонках.
+type FileColumns struct {
+	Date   string
+	Time   string
+	Open   string
+	High   string
+	Low    string
+	Close  string
+	Volume string
+}
+
+// FileOptions параметры разбора CSV файлов со свечами
+type FileOptions struct {
+	Pattern    string      // Шаблон имени файла с {ticker} и {timeframe}
+	Delimiter  rune        // Разделитель колонок
+	DateFormat string      // Формат даты (или даты и времени) в нотации Go
+	TimeFormat string      // Формат времени, если оно в отдельной колонке
+	Columns    FileColumns // Соответствие колонок
+}
+
+// FilePresets готовые настройки для распространенных форматов выгрузки
+var FilePresets = map[string]FileOptions{
+	// Экспорт котировок Finam: <TICKER>,<PER>,<DATE>,<TIME>,<OPEN>,<HIGH>,<LOW>,<CLOSE>,<VOL>
+	"finam": {
+		Delimiter:  ',',
+		DateFormat: "20060102",
+		TimeFormat: "150405",
+		Columns: FileColumns{
+			Date: "date", Time: "time",
+			Open: "open", High: "high", Low: "low", Close: "close",
+			Volume: "vol",
+		},
+	},
+	// candles.csv из MOEX ISS: open;close;high;low;value;volume;begin;end
+	"iss": {
+		Delimiter:  ';',
+		DateFormat: "2006-01-02 15:04:05",
+		Columns: FileColumns{
+			Date: "begin",
+			Open: "open", High: "high", Low: "low", Close: "close",
+			Volume: "volume",
+		},
+	},
+}
+
+// FileSource источник свечей из каталога CSV файлов (по файлу на тикер и таймфрейм).
+// Позволяет запускать стратегии полностью офлайн.
+type FileSource struct {
+	dir     string
+	options FileOptions
+	nameRe  *regexp.Regexp
+}
+
+// NewFileSource создает файловый источник. Незаданные поля options берутся из preset.
+func NewFileSource(dir, preset string, options FileOptions) (*FileSource, error) {
+	if preset != "" {
+		base, ok := FilePresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный формат файлов %q", preset)
+		}
+		options = mergeFileOptions(base, options)
+	}
+
+	if options.Pattern == "" {
+		options.Pattern = "{ticker}_{timeframe}.csv"
+	}
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.DateFormat == "" {
+		options.DateFormat = mskTimeLayout
+	}
+	if options.Columns.Date == "" || options.Columns.Open == "" || options.Columns.High == "" ||
+		options.Columns.Low == "" || options.Columns.Close == "" {
+		return nil, fmt.Errorf("не заданы обязательные колонки (date, open, high, low, close)")
+	}
+	if !strings.Contains(options.Pattern, "{ticker}") || !strings.Contains(options.Pattern, "{timeframe}") {
+		return nil, fmt.Errorf("шаблон имени файла должен содержать {ticker} и {timeframe}")
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("каталог с данными недоступен: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s не является каталогом", dir)
+	}
+
+	nameRe := regexp.QuoteMeta(options.Pattern)
+	nameRe = strings.Replace(nameRe, regexp.QuoteMeta("{ticker}"), `(?P<ticker>[A-Za-z0-9.\-]+?)`, 1)
+	nameRe = strings.Replace(nameRe, regexp.QuoteMeta("{timeframe}"), `(?P<timeframe>[A-Za-z0-9]+)`, 1)
+
+	return &FileSource{
+		dir:     dir,
+		options: options,
+		nameRe:  regexp.MustCompile("^" + nameRe + "$"),
+	}, nil
+}
+
+// GetCandles читает свечи из файла инструмента за период [from, to]
+func (s *FileSource) GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]Candle, error) {
+	candles, err := s.readFile(ctx, s.filePath(instrument, timeframe))
+	if err != nil {
+		return nil, err
+	}
+
+	var fromTime, toTime time.Time
+	if from != "" {
+		if fromTime, err = time.ParseInLocation("2006-01-02", from, MSK); err != nil {
+			return nil, fmt.Errorf("неверная дата начала %q: %w", from, err)
+		}
+	}
+	if to != "" {
+		if toTime, err = time.ParseInLocation("2006-01-02", to, MSK); err != nil {
+			return nil, fmt.Errorf("неверная дата окончания %q: %w", to, err)
+		}
+		toTime = toTime.AddDate(0, 0, 1)
+	}
+
+	result := candles[:0]
+	for _, candle := range candles {
+		if !fromTime.IsZero() && candle.Begin.Before(fromTime) {
+			continue
+		}
+		if !toTime.IsZero() && !candle.Begin.Before(toTime) {
+			continue
+		}
+		result = append(result, candle)
+	}
+
+	return result, nil
+}
+
+// GetInstruments возвращает тикеры, для которых в каталоге есть файлы
+func (s *FileSource) GetInstruments(ctx context.Context) ([]string, error) {
+	files, err := s.listFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var instruments []string
+	for _, file := range files {
+		if !seen[file.ticker] {
+			seen[file.ticker] = true
+			instruments = append(instruments, file.ticker)
+		}
+	}
+	sort.Strings(instruments)
+
+	return instruments, nil
+}
+
+// GetInstrumentInfo возвращает доступные таймфреймы инструмента и время последней свечи
+func (s *FileSource) GetInstrumentInfo(ctx context.Context, instrument string) (*Instrument, error) {
+	files, err := s.listFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Instrument{Ticker: strings.ToUpper(instrument), Name: strings.ToUpper(instrument)}
+	for _, file := range files {
+		if !strings.EqualFold(file.ticker, instrument) {
+			continue
+		}
+
+		candles, err := s.readFile(ctx, file.path)
+		if err != nil {
+			return nil, err
+		}
+
+		tf := Timeframe{
+			Code:        file.timeframe,
+			DisplayName: file.timeframe,
+			Description: filepath.Base(file.path),
+		}
+		if name, ok := issIntervalNames[file.timeframe]; ok {
+			tf.DisplayName = name
+		}
+		if len(candles) > 0 {
+			tf.LastCandle = candles[len(candles)-1].Begin
+		}
+		info.Timeframes = append(info.Timeframes, tf)
+	}
+
+	if len(info.Timeframes) == 0 {
+		return nil, fmt.Errorf("нет файлов с данными для инструмента %s", instrument)
+	}
+
+	return info, nil
+}
+
+// dataFile файл каталога с тикером и таймфреймом из имени
+type dataFile struct {
+	path      string
+	ticker    string
+	timeframe string
+}
+
+// listFiles возвращает файлы каталога, соответствующие шаблону имени
+func (s *FileSource) listFiles() ([]dataFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога с данными: %w", err)
+	}
+
+	tickerIdx := s.nameRe.SubexpIndex("ticker")
+	timeframeIdx := s.nameRe.SubexpIndex("timeframe")
+
+	var files []dataFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := s.nameRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		files = append(files, dataFile{
+			path:      filepath.Join(s.dir, entry.Name()),
+			ticker:    strings.ToUpper(match[tickerIdx]),
+			timeframe: match[timeframeIdx],
+		})
+	}
+
+	return files, nil
+}
+
+// filePath возвращает путь к файлу инструмента и таймфрейма
+func (s *FileSource) filePath(instrument, timeframe string) string {
+	name := strings.NewReplacer("{ticker}", strings.ToUpper(instrument), "{timeframe}", timeframe).
+		Replace(s.options.Pattern)
+	return filepath.Join(s.dir, name)
+}
+
+// readFile разбирает CSV файл со свечами. Строки до заголовка (например, имя блока
+// в выгрузке ISS) пропускаются. Результат отсортирован по времени.
+func (s *FileSource) readFile(ctx context.Context, path string) ([]Candle, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("нет файла с данными %s", filepath.Base(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(bufio.NewReader(f))
+	reader.Comma = s.options.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var index map[string]int
+	var candles []Candle
+	line := 0
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("%s, строка %d: %w", filepath.Base(path), line, err)
+		}
+		if line%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if index == nil {
+			index = s.headerIndex(record)
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		candle, err := s.parseRecord(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("%s, строка %d: %w", filepath.Base(path), line, err)
+		}
+		candles = append(candles, candle)
+	}
+
+	if index == nil {
+		return nil, fmt.Errorf("%s: не найден заголовок с колонкой %q", filepath.Base(path), s.options.Columns.Date)
+	}
+
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Begin.Before(candles[j].Begin)
+	})
+
+	return candles, nil
+}
+
+// headerIndex возвращает индексы колонок, если строка является заголовком, иначе nil
+func (s *FileSource) headerIndex(record []string) map[string]int {
+	index := make(map[string]int, len(record))
+	for i, name := range record {
+		index[normalizeColumn(name)] = i
+	}
+
+	if _, ok := index[normalizeColumn(s.options.Columns.Date)]; !ok {
+		return nil
+	}
+	return index
+}
+
+// parseRecord разбирает строку CSV в свечу
+func (s *FileSource) parseRecord(record []string, index map[string]int) (Candle, error) {
+	field := func(column string) (string, bool) {
+		if column == "" {
+			return "", false
+		}
+		i, ok := index[normalizeColumn(column)]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+
+	number := func(column string, required bool) (float64, error) {
+		value, ok := field(column)
+		if !ok || value == "" {
+			if required {
+				return 0, fmt.Errorf("%w: отсутствует колонка %s", ErrMalformedCandle, column)
+			}
+			return 0, nil
+		}
+		num, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: колонка %s не является числом: %q", ErrMalformedCandle, column, value)
+		}
+		return num, nil
+	}
+
+	dateValue, _ := field(s.options.Columns.Date)
+	layout := s.options.DateFormat
+	if timeValue, ok := field(s.options.Columns.Time); ok && s.options.TimeFormat != "" {
+		dateValue += " " + timeValue
+		layout += " " + s.options.TimeFormat
+	}
+
+	begin, err := time.ParseInLocation(layout, dateValue, MSK)
+	if err != nil {
+		return Candle{}, fmt.Errorf("%w: неверная дата %q (формат %s)", ErrMalformedCandle, dateValue, layout)
+	}
+
+	candle := Candle{Begin: begin}
+	if candle.Open, err = number(s.options.Columns.Open, true); err != nil {
+		return Candle{}, err
+	}
+	if candle.High, err = number(s.options.Columns.High, true); err != nil {
+		return Candle{}, err
+	}
+	if candle.Low, err = number(s.options.Columns.Low, true); err != nil {
+		return Candle{}, err
+	}
+	if candle.Close, err = number(s.options.Columns.Close, true); err != nil {
+		return Candle{}, err
+	}
+	if candle.Volume, err = number(s.options.Columns.Volume, false); err != nil {
+		return Candle{}, err
+	}
+
+	if err := candle.Validate(); err != nil {
+		return Candle{}, err
+	}
+
+	return candle, nil
+}
+
+// normalizeColumn приводит название колонки к виду для сравнения: <CLOSE> -> close
+func normalizeColumn(name string) string {
+	name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	name = strings.Trim(name, "<>")
+	return strings.ToLower(name)
+}
+
+// mergeFileOptions дополняет base заданными полями override
+func mergeFileOptions(base, override FileOptions) FileOptions {
+	if override.Pattern != "" {
+		base.Pattern = override.Pattern
+	}
+	if override.Delimiter != 0 {
+		base.Delimiter = override.Delimiter
+	}
+	if override.DateFormat != "" {
+		base.DateFormat = override.DateFormat
+	}
+	if override.TimeFormat != "" {
+		base.TimeFormat = override.TimeFormat
+	}
+
+	columns := override.Columns
+	if columns.Date != "" {
+		base.Columns.Date = columns.Date
+	}
+	if columns.Time != "" {
+		base.Columns.Time = columns.Time
+	}
+	if columns.Open != "" {
+		base.Columns.Open = columns.Open
+	}
+	if columns.High != "" {
+		base.Columns.High = columns.High
+	}
+	if columns.Low != "" {
+		base.Columns.Low = columns.Low
+	}
+	if columns.Close != "" {
+		base.Columns.Close = columns.Close
+	}
+	if columns.Volume != "" {
+		base.Columns.Volume = columns.Volume
+	}
+
+	return base
+}
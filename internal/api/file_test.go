@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSourceGetCandles(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    string
+		from, to  string
+		wantCount int
+		wantFirst time.Time
+	}{
+		{
+			name:      "Finam full file",
+			preset:    "finam",
+			wantCount: 4,
+			wantFirst: time.Date(2024, 1, 3, 0, 0, 0, 0, MSK),
+		},
+		{
+			name:      "Finam period",
+			preset:    "finam",
+			from:      "2024-01-04",
+			to:        "2024-01-05",
+			wantCount: 2,
+			wantFirst: time.Date(2024, 1, 4, 0, 0, 0, 0, MSK),
+		},
+		{
+			name:      "ISS export with block name line",
+			preset:    "iss",
+			wantCount: 2,
+			wantFirst: time.Date(2024, 1, 3, 0, 0, 0, 0, MSK),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewFileSource(filepath.Join("testdata", "csv", tt.preset), tt.preset, FileOptions{})
+			if err != nil {
+				t.Fatalf("NewFileSource() error = %v", err)
+			}
+
+			candles, err := source.GetCandles(context.Background(), "SBER", "24", tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetCandles() error = %v", err)
+			}
+			if len(candles) != tt.wantCount {
+				t.Fatalf("GetCandles() returned %d candles, want %d", len(candles), tt.wantCount)
+			}
+			if !candles[0].Begin.Equal(tt.wantFirst) {
+				t.Errorf("first.Begin = %v, want %v", candles[0].Begin, tt.wantFirst)
+			}
+			if tt.from == "" && candles[0].Close != 274.71 {
+				t.Errorf("first.Close = %.2f, want 274.71", candles[0].Close)
+			}
+		})
+	}
+}
+
+func TestFileSourceInstruments(t *testing.T) {
+	source, err := NewFileSource(filepath.Join("testdata", "csv", "finam"), "finam", FileOptions{})
+	if err != nil {
+		t.Fatalf("NewFileSource() error = %v", err)
+	}
+
+	instruments, err := source.GetInstruments(context.Background())
+	if err != nil {
+		t.Fatalf("GetInstruments() error = %v", err)
+	}
+	if len(instruments) != 2 || instruments[0] != "GAZP" || instruments[1] != "SBER" {
+		t.Errorf("GetInstruments() = %v, want [GAZP SBER]", instruments)
+	}
+
+	info, err := source.GetInstrumentInfo(context.Background(), "gazp")
+	if err != nil {
+		t.Fatalf("GetInstrumentInfo() error = %v", err)
+	}
+	if len(info.Timeframes) != 2 {
+		t.Fatalf("Timeframes = %d, want 2", len(info.Timeframes))
+	}
+
+	wantLast := time.Date(2024, 1, 3, 11, 0, 0, 0, MSK)
+	for _, tf := range info.Timeframes {
+		if tf.Code == "60" && !tf.LastCandle.Equal(wantLast) {
+			t.Errorf("LastCandle for 60 = %v, want %v", tf.LastCandle, wantLast)
+		}
+	}
+
+	if _, err := source.GetCandles(context.Background(), "LKOH", "24", "", ""); err == nil {
+		t.Error("GetCandles() for missing file should fail")
+	}
+}
+
+func TestFileSourceCustomColumns(t *testing.T) {
+	// Выгрузка ISS, описанная вручную вместо пресета
+	source, err := NewFileSource(filepath.Join("testdata", "csv", "iss"), "", FileOptions{
+		Delimiter:  ';',
+		DateFormat: "2006-01-02 15:04:05",
+		Columns: FileColumns{
+			Date: "begin", Open: "open", High: "high", Low: "low", Close: "close", Volume: "volume",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewFileSource() error = %v", err)
+	}
+
+	candles, err := source.GetCandles(context.Background(), "SBER", "24", "", "")
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	if len(candles) != 2 || candles[1].Volume != 21908670 {
+		t.Errorf("GetCandles() = %+v", candles)
+	}
+
+	if _, err := NewFileSource(filepath.Join("testdata", "csv", "iss"), "", FileOptions{}); err == nil {
+		t.Error("NewFileSource() without columns should fail")
+	}
+}
@@ -0,0 +1,401 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// issMaxPages ограничение числа страниц при постраничной загрузке свечей
+const issMaxPages = 1000
+
+// issIntervalNames названия интервалов свечей ISS
+var issIntervalNames = map[string]string{
+	"1":  "1 минута",
+	"10": "10 минут",
+	"60": "1 час",
+	"24": "1 день",
+	"7":  "1 неделя",
+	"31": "1 месяц",
+	"4":  "1 квартал",
+}
+
+// ISSOptions параметры рынка MOEX ISS
+type ISSOptions struct {
+	Engine      string   // Торговая система, например "stock"
+	Market      string   // Рынок, например "shares"
+	Board       string   // Режим торгов, например "TQBR"
+	Instruments []string // Список инструментов для анализа; пустой - все бумаги режима торгов
+}
+
+// ISSClient клиент публичного API MOEX ISS (iss.moex.com)
+type ISSClient struct {
+	baseURL    string
+	options    ISSOptions
+	httpClient *http.Client
+	retry      RetryPolicy
+}
+
+// issTable блок ответа ISS в формате columns/data
+type issTable struct {
+	Columns []string            `json:"columns"`
+	Data    [][]json.RawMessage `json:"data"`
+}
+
+// NewISSClient создает клиент MOEX ISS
+func NewISSClient(baseURL string, timeout time.Duration, options ISSOptions) *ISSClient {
+	if options.Engine == "" {
+		options.Engine = "stock"
+	}
+	if options.Market == "" {
+		options.Market = "shares"
+	}
+	if options.Board == "" {
+		options.Board = "TQBR"
+	}
+
+	return &ISSClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		options: options,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// SetRetryPolicy задает политику повторных попыток
+func (c *ISSClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// GetCandles получает свечи инструмента, последовательно загружая все страницы ответа.
+// Если страницы не закончились за issMaxPages запросов, возвращается ошибка: неполная
+// история не должна попасть в кэш или бэктест как полная.
+func (c *ISSClient) GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]Candle, error) {
+	path := fmt.Sprintf("/iss/engines/%s/markets/%s/boards/%s/securities/%s/candles.json",
+		c.options.Engine, c.options.Market, c.options.Board, url.PathEscape(instrument))
+
+	var rows []json.RawMessage
+	for page := 0; ; page++ {
+		if page == issMaxPages {
+			return nil, fmt.Errorf("свечи ISS %s: загружено %d страниц (%d свечей), история не получена полностью - сократите период",
+				instrument, issMaxPages, len(rows))
+		}
+
+		query := url.Values{}
+		query.Set("iss.meta", "off")
+		query.Set("interval", timeframe)
+		query.Set("start", strconv.Itoa(len(rows)))
+		if from != "" {
+			query.Set("from", from)
+		}
+		if to != "" {
+			query.Set("till", to)
+		}
+
+		var resp struct {
+			Candles *issTable `json:"candles"`
+		}
+		if err := c.get(ctx, path, query, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Candles == nil {
+			return nil, fmt.Errorf("неверный формат свечей ISS: %w", ErrMalformedResponse)
+		}
+
+		pageRows, err := resp.Candles.objects()
+		if err != nil {
+			return nil, err
+		}
+		if len(pageRows) == 0 {
+			break
+		}
+		rows = append(rows, pageRows...)
+	}
+
+	return DecodeCandles(rows)
+}
+
+// GetInstruments возвращает инструменты из настроек или все бумаги режима торгов
+func (c *ISSClient) GetInstruments(ctx context.Context) ([]string, error) {
+	if len(c.options.Instruments) > 0 {
+		return append([]string(nil), c.options.Instruments...), nil
+	}
+
+	path := fmt.Sprintf("/iss/engines/%s/markets/%s/boards/%s/securities.json",
+		c.options.Engine, c.options.Market, c.options.Board)
+	query := url.Values{}
+	query.Set("iss.meta", "off")
+	query.Set("iss.only", "securities")
+	query.Set("securities.columns", "SECID")
+
+	var resp struct {
+		Securities *issTable `json:"securities"`
+	}
+	if err := c.get(ctx, path, query, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Securities == nil {
+		return nil, fmt.Errorf("неверный формат списка бумаг ISS: %w", ErrMalformedResponse)
+	}
+
+	secidIdx := resp.Securities.column("secid")
+	if secidIdx < 0 {
+		return nil, fmt.Errorf("в ответе ISS нет колонки SECID: %w", ErrMalformedResponse)
+	}
+
+	instruments := make([]string, 0, len(resp.Securities.Data))
+	for _, row := range resp.Securities.Data {
+		if secidIdx < len(row) {
+			if secid := rawScalar(row[secidIdx]); secid != "" {
+				instruments = append(instruments, secid)
+			}
+		}
+	}
+
+	return instruments, nil
+}
+
+// GetInstrumentInfo получает описание бумаги и доступные интервалы свечей
+func (c *ISSClient) GetInstrumentInfo(ctx context.Context, instrument string) (*Instrument, error) {
+	path := fmt.Sprintf("/iss/engines/%s/markets/%s/boards/%s/securities/%s.json",
+		c.options.Engine, c.options.Market, c.options.Board, url.PathEscape(instrument))
+	query := url.Values{}
+	query.Set("iss.meta", "off")
+	query.Set("iss.only", "securities")
+	query.Set("securities.columns", "SECID,SHORTNAME,SECNAME,LOTSIZE")
+
+	var resp struct {
+		Securities *issTable `json:"securities"`
+	}
+	if err := c.get(ctx, path, query, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Securities == nil {
+		return nil, fmt.Errorf("неверный формат описания бумаги ISS: %w", ErrMalformedResponse)
+	}
+
+	objects, err := resp.Securities.objects()
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("инструмент %s не найден в режиме торгов %s", instrument, c.options.Board)
+	}
+
+	var raw struct {
+		SecID     string          `json:"secid"`
+		ShortName string          `json:"shortname"`
+		SecName   string          `json:"secname"`
+		LotSize   json.RawMessage `json:"lotsize"`
+	}
+	if err := json.Unmarshal(objects[0], &raw); err != nil {
+		return nil, fmt.Errorf("%w: описание бумаги: %v", ErrMalformedResponse, err)
+	}
+
+	lotSize, err := parseOptionalNumber(raw.LotSize, "lotsize")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Instrument{
+		Ticker:  raw.SecID,
+		Name:    raw.SecName,
+		LotSize: int(lotSize),
+	}
+	if info.Ticker == "" {
+		info.Ticker = instrument
+	}
+	if info.Name == "" {
+		info.Name = raw.ShortName
+	}
+
+	timeframes, err := c.getCandleBorders(ctx, instrument)
+	if err != nil {
+		return nil, err
+	}
+	info.Timeframes = timeframes
+
+	return info, nil
+}
+
+// getCandleBorders получает доступные интервалы свечей и дату последней свечи
+func (c *ISSClient) getCandleBorders(ctx context.Context, instrument string) ([]Timeframe, error) {
+	path := fmt.Sprintf("/iss/engines/%s/markets/%s/securities/%s/candleborders.json",
+		c.options.Engine, c.options.Market, url.PathEscape(instrument))
+	query := url.Values{}
+	query.Set("iss.meta", "off")
+
+	var resp struct {
+		Borders *issTable `json:"borders"`
+	}
+	if err := c.get(ctx, path, query, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Borders == nil {
+		return nil, nil
+	}
+
+	objects, err := resp.Borders.objects()
+	if err != nil {
+		return nil, err
+	}
+
+	var timeframes []Timeframe
+	index := make(map[string]int)
+	for _, object := range objects {
+		var raw struct {
+			End      json.RawMessage `json:"end"`
+			Interval json.RawMessage `json:"interval"`
+		}
+		if err := json.Unmarshal(object, &raw); err != nil {
+			return nil, fmt.Errorf("%w: границы свечей: %v", ErrMalformedResponse, err)
+		}
+
+		code := rawScalar(raw.Interval)
+		if code == "" {
+			continue
+		}
+		end, err := parseOptionalTime(raw.End, "end")
+		if err != nil {
+			return nil, err
+		}
+
+		// Интервал может повторяться для разных групп режимов торгов
+		if i, ok := index[code]; ok {
+			if end.After(timeframes[i].LastCandle) {
+				timeframes[i].LastCandle = end
+			}
+			continue
+		}
+
+		name := issIntervalNames[code]
+		if name == "" {
+			name = code
+		}
+		index[code] = len(timeframes)
+		timeframes = append(timeframes, Timeframe{
+			Code:        code,
+			DisplayName: name,
+			LastCandle:  end,
+		})
+	}
+
+	return timeframes, nil
+}
+
+// get выполняет GET запрос к ISS с повторными попытками и декодирует JSON ответ
+func (c *ISSClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.retry.retryDelay(attempt, lastErr)); err != nil {
+				return fmt.Errorf("запрос прерван после %d попыток: %w", attempt, lastErr)
+			}
+		}
+
+		body, err := c.getOnce(ctx, reqURL)
+		if err == nil {
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+			}
+			return nil
+		}
+
+		lastErr = err
+		if !isRetryableError(ctx, err) {
+			return err
+		}
+	}
+
+	return lastErr
+}
+
+// getOnce выполняет одну попытку GET запроса
+func (c *ISSClient) getOnce(ctx context.Context, reqURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения ответа: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return body, nil
+}
+
+// column возвращает индекс колонки без учета регистра или -1
+func (t *issTable) column(name string) int {
+	for i, column := range t.Columns {
+		if strings.EqualFold(column, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// objects преобразует строки таблицы в JSON объекты с ключами в нижнем регистре
+func (t *issTable) objects() ([]json.RawMessage, error) {
+	keys := make([][]byte, len(t.Columns))
+	for i, column := range t.Columns {
+		key, err := json.Marshal(strings.ToLower(column))
+		if err != nil {
+			return nil, fmt.Errorf("%w: колонка %q", ErrMalformedResponse, column)
+		}
+		keys[i] = key
+	}
+
+	objects := make([]json.RawMessage, 0, len(t.Data))
+	for i, row := range t.Data {
+		if len(row) != len(keys) {
+			return nil, fmt.Errorf("%w: строка %d содержит %d значений вместо %d",
+				ErrMalformedResponse, i, len(row), len(keys))
+		}
+
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for j, value := range row {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[j])
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+
+		objects = append(objects, buf.Bytes())
+	}
+
+	return objects, nil
+}
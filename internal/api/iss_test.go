@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newISSStandIn поднимает httptest сервер, отдающий записанные ответы ISS из testdata/iss
+func newISSStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	serveFile := func(w http.ResponseWriter, name string) {
+		data, err := os.ReadFile(filepath.Join("testdata", "iss", name))
+		if err != nil {
+			t.Errorf("testdata %s: %v", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/iss/engines/stock/markets/shares/boards/TQBR/securities/SBER/candles.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("interval") != "24" {
+			t.Errorf("interval = %q, want 24", r.URL.Query().Get("interval"))
+		}
+		switch r.URL.Query().Get("start") {
+		case "0":
+			serveFile(w, "candles_page1.json")
+		case "3":
+			serveFile(w, "candles_page2.json")
+		default:
+			serveFile(w, "candles_empty.json")
+		}
+	})
+	mux.HandleFunc("/iss/engines/stock/markets/shares/boards/TQBR/securities.json", func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, "securities.json")
+	})
+	mux.HandleFunc("/iss/engines/stock/markets/shares/boards/TQBR/securities/SBER.json", func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, "security_SBER.json")
+	})
+	mux.HandleFunc("/iss/engines/stock/markets/shares/securities/SBER/candleborders.json", func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, "candleborders_SBER.json")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestISSClientGetCandles(t *testing.T) {
+	server := newISSStandIn(t)
+	client := NewISSClient(server.URL, 5*time.Second, ISSOptions{})
+
+	candles, err := client.GetCandles(context.Background(), "SBER", "24", "2024-01-01", "2024-01-10")
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+
+	if len(candles) != 4 {
+		t.Fatalf("GetCandles() returned %d candles, want 4 (two pages)", len(candles))
+	}
+
+	first := candles[0]
+	wantBegin := time.Date(2024, 1, 3, 0, 0, 0, 0, MSK)
+	if !first.Begin.Equal(wantBegin) {
+		t.Errorf("first.Begin = %v, want %v", first.Begin, wantBegin)
+	}
+	if first.Open != 271.9 || first.High != 275.48 || first.Low != 270.11 || first.Close != 274.71 {
+		t.Errorf("first OHLC = %.2f/%.2f/%.2f/%.2f, want 271.90/275.48/270.11/274.71",
+			first.Open, first.High, first.Low, first.Close)
+	}
+	if first.Volume != 38651770 {
+		t.Errorf("first.Volume = %.0f, want 38651770", first.Volume)
+	}
+
+	last := candles[len(candles)-1]
+	if want := time.Date(2024, 1, 8, 0, 0, 0, 0, MSK); !last.Begin.Equal(want) {
+		t.Errorf("last.Begin = %v, want %v", last.Begin, want)
+	}
+}
+
+func TestISSClientGetCandlesPageLimit(t *testing.T) {
+	// Сервер без конца отдает непустые страницы
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		data, err := os.ReadFile(filepath.Join("testdata", "iss", "candles_page1.json"))
+		if err != nil {
+			t.Errorf("testdata: %v", err)
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	client := NewISSClient(server.URL, 5*time.Second, ISSOptions{})
+
+	candles, err := client.GetCandles(context.Background(), "SBER", "24", "2024-01-01", "")
+	if err == nil {
+		t.Fatalf("GetCandles() = %d candles, want error for truncated history", len(candles))
+	}
+	if requests != issMaxPages {
+		t.Errorf("requests = %d, want %d", requests, issMaxPages)
+	}
+}
+
+func TestISSClientGetInstruments(t *testing.T) {
+	server := newISSStandIn(t)
+
+	tests := []struct {
+		name    string
+		options ISSOptions
+		want    []string
+	}{
+		{
+			name:    "All board securities",
+			options: ISSOptions{},
+			want:    []string{"GAZP", "LKOH", "SBER"},
+		},
+		{
+			name:    "Configured list",
+			options: ISSOptions{Instruments: []string{"SBER", "VTBR"}},
+			want:    []string{"SBER", "VTBR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewISSClient(server.URL, 5*time.Second, tt.options)
+
+			got, err := client.GetInstruments(context.Background())
+			if err != nil {
+				t.Fatalf("GetInstruments() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetInstruments() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("GetInstruments()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestISSClientGetInstrumentInfo(t *testing.T) {
+	server := newISSStandIn(t)
+	client := NewISSClient(server.URL, 5*time.Second, ISSOptions{})
+
+	info, err := client.GetInstrumentInfo(context.Background(), "SBER")
+	if err != nil {
+		t.Fatalf("GetInstrumentInfo() error = %v", err)
+	}
+
+	if info.Ticker != "SBER" || info.LotSize != 10 {
+		t.Errorf("GetInstrumentInfo() = %s lot %d, want SBER lot 10", info.Ticker, info.LotSize)
+	}
+	if info.Name != "Сбербанк России ПАО ао" {
+		t.Errorf("Name = %q", info.Name)
+	}
+
+	// Интервал 24 встречается в двух группах режимов и должен быть объединен
+	if len(info.Timeframes) != 4 {
+		t.Fatalf("Timeframes = %d, want 4", len(info.Timeframes))
+	}
+	for _, tf := range info.Timeframes {
+		if tf.Code == "24" && tf.DisplayName != "1 день" {
+			t.Errorf("DisplayName for 24 = %q, want \"1 день\"", tf.DisplayName)
+		}
+	}
+}
+
+func TestISSClientNotFound(t *testing.T) {
+	server := newISSStandIn(t)
+	client := NewISSClient(server.URL, 5*time.Second, ISSOptions{})
+
+	if _, err := client.GetCandles(context.Background(), "UNKNOWN", "24", "2024-01-01", ""); err == nil {
+		t.Error("GetCandles() for unknown path should fail")
+	}
+}
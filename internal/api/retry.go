@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRetryAfter верхняя граница ожидания по заголовку Retry-After
+const maxRetryAfter = 2 * time.Minute
+
+// RetryPolicy параметры повторных попыток запросов к API
+type RetryPolicy struct {
+	MaxRetries int           // Количество повторов после первой попытки
+	BaseDelay  time.Duration // Базовая задержка, удваивается с каждой попыткой
+	MaxDelay   time.Duration // Максимальная задержка между попытками
+}
+
+// idempotentPosts POST эндпоинты, повтор которых безопасен
+var idempotentPosts = map[string]bool{
+	"/api/refresh-instruments": true,
+	"/api/tables/cleanup":      true,
+}
+
+// APIError ошибка, возвращенная сервером API
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ошибка API: %s - %s", e.Status, e.Body)
+}
+
+// Temporary сообщает, имеет ли смысл повторить запрос
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// isRetrySafe проверяет, можно ли повторять запрос без побочных эффектов
+func isRetrySafe(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		if idx := strings.IndexByte(path, '?'); idx >= 0 {
+			path = path[:idx]
+		}
+		return idempotentPosts[path]
+	}
+	return false
+}
+
+// isRetryableError проверяет, стоит ли повторять запрос после ошибки: повторяются
+// ответы 5xx и 429 и сбои сети. Остальные ошибки (например, неверный адрес API
+// в конфигурации) постоянные и возвращаются сразу.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// http.Client оборачивает в *url.Error любые ошибки, в том числе разбора
+	// адреса и неподдерживаемой схемы, поэтому смотрим на причину
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	// Сброс и отказ в соединении, обрыв ответа
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	// Таймауты и прочие ошибки сети (*net.OpError, *net.DNSError)
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// backoff возвращает задержку перед попыткой attempt (начиная с 1) с "equal jitter"
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// retryDelay вычисляет задержку с учетом Retry-After от сервера
+func (p RetryPolicy) retryDelay(attempt int, err error) time.Duration {
+	delay := p.backoff(attempt)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		delay = apiErr.RetryAfter
+		if delay > maxRetryAfter {
+			delay = maxRetryAfter
+		}
+	}
+
+	return delay
+}
+
+// parseRetryAfter разбирает заголовок Retry-After (секунды или HTTP-дата)
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// sleepContext ждет d или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
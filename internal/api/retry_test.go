@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testRetry политика повторов без заметных задержек
+var testRetry = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// scriptedServer отвечает на запросы по очереди заданными кодами, затем 200
+type scriptedServer struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	requests map[string]int
+}
+
+func newScriptedServer(t *testing.T, statuses ...int) (*scriptedServer, *APIClient) {
+	t.Helper()
+
+	s := &scriptedServer{statuses: statuses, header: http.Header{}, requests: make(map[string]int)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.Method+" "+r.URL.Path]++
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		for key, values := range s.header {
+			w.Header()[key] = values
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+		fmt.Fprint(w, `{"instruments": ["SBER"], "status": "ok"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewAPIClient(server.URL, "", 5*time.Second)
+	client.SetRetryPolicy(testRetry)
+	return s, client
+}
+
+func (s *scriptedServer) count(request string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[request]
+}
+
+func TestRetryStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		want     int
+	}{
+		{name: "5xx повторяется", statuses: []int{500, 502, 503}, want: 4},
+		{name: "429 повторяется", statuses: []int{429}, want: 2},
+		{name: "попытки исчерпаны", statuses: []int{503, 503, 503, 503, 503}, wantErr: true, want: 4},
+		{name: "404 не повторяется", statuses: []int{404}, wantErr: true, want: 1},
+		{name: "400 не повторяется", statuses: []int{400}, wantErr: true, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newScriptedServer(t, tt.statuses...)
+
+			_, err := client.GetInstruments(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetInstruments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := server.count("GET /api/instruments"); got != tt.want {
+				t.Errorf("requests = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPostWhitelist(t *testing.T) {
+	server, client := newScriptedServer(t, 503, 503)
+
+	// /api/fetch запускает загрузку данных - повтор небезопасен
+	if _, err := client.TriggerFetch(context.Background()); err == nil {
+		t.Fatal("TriggerFetch() error = nil, want 503")
+	}
+	if got := server.count("POST /api/fetch"); got != 1 {
+		t.Errorf("fetch requests = %d, want 1", got)
+	}
+
+	if _, err := client.RefreshInstruments(context.Background()); err != nil {
+		t.Fatalf("RefreshInstruments() error = %v, want success after retry", err)
+	}
+	if got := server.count("POST /api/refresh-instruments"); got != 2 {
+		t.Errorf("refresh requests = %d, want 2", got)
+	}
+
+	for path, want := range map[string]bool{
+		"/api/refresh-instruments":              true,
+		"/api/tables/cleanup?inactive_days=30":  true,
+		"/api/fetch":                            false,
+		"/api/instruments/add":                  false,
+		"/api/refresh-instruments/../api/fetch": false,
+	} {
+		if got := isRetrySafe(http.MethodPost, path); got != want {
+			t.Errorf("isRetrySafe(POST %s) = %v, want %v", path, got, want)
+		}
+	}
+	if !isRetrySafe(http.MethodGet, "/api/fetch") || isRetrySafe(http.MethodPatch, "/api/instruments") {
+		t.Error("isRetrySafe: GET должен повторяться, PATCH - нет")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	server, client := newScriptedServer(t, 429)
+	server.header.Set("Retry-After", "1")
+
+	start := time.Now()
+	if _, err := client.GetInstruments(context.Background()); err != nil {
+		t.Fatalf("GetInstruments() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retry after %v, want at least Retry-After 1s", elapsed)
+	}
+
+	for value, want := range map[string]time.Duration{
+		"":      0,
+		"5":     5 * time.Second,
+		"-1":    0,
+		"abc":   0,
+		" 120 ": 2 * time.Minute,
+	} {
+		if got := parseRetryAfter(value); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if got := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); got < 59*time.Minute {
+		t.Errorf("parseRetryAfter(HTTP-date) = %v, want about 1h", got)
+	}
+
+	// Retry-After больше задержки backoff, но не больше maxRetryAfter
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+	if got := policy.retryDelay(1, &APIError{StatusCode: 429, RetryAfter: 30 * time.Second}); got != 30*time.Second {
+		t.Errorf("retryDelay(Retry-After 30s) = %v, want 30s", got)
+	}
+	if got := policy.retryDelay(1, &APIError{StatusCode: 503, RetryAfter: time.Hour}); got != maxRetryAfter {
+		t.Errorf("retryDelay(Retry-After 1h) = %v, want %v", got, maxRetryAfter)
+	}
+	if got := policy.retryDelay(1, errors.New("сбой")); got > time.Millisecond {
+		t.Errorf("retryDelay() = %v, want backoff up to 1ms", got)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	opErr := func(errno syscall.Errno) error {
+		return &url.Error{Op: "Get", URL: "http://fetcher", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errno)}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "500", err: &APIError{StatusCode: 500}, want: true},
+		{name: "429", err: &APIError{StatusCode: 429}, want: true},
+		{name: "404", err: &APIError{StatusCode: 404}, want: false},
+		{name: "сброс соединения", err: fmt.Errorf("ошибка выполнения запроса: %w", opErr(syscall.ECONNRESET)), want: true},
+		{name: "отказ в соединении", err: opErr(syscall.ECONNREFUSED), want: true},
+		{name: "обрыв ответа", err: fmt.Errorf("ошибка чтения ответа: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "EOF", err: &url.Error{Op: "Get", URL: "http://fetcher", Err: io.EOF}, want: true},
+		{name: "таймаут", err: &net.DNSError{Err: "timeout", IsTimeout: true}, want: true},
+		{name: "неверная схема", err: &url.Error{Op: "Get", URL: "ftp://fetcher", Err: errors.New(`unsupported protocol scheme "ftp"`)}, want: false},
+		{name: "неверный адрес", err: fmt.Errorf("ошибка создания запроса: %w", &url.Error{Op: "parse", URL: "http://[::1", Err: errors.New("missing ']' in host")}), want: false},
+		{name: "отмена", err: context.Canceled, want: false},
+		{name: "прочее", err: errors.New("ошибка маршалинга JSON"), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryableError(context.Background(), tt.err); got != tt.want {
+			t.Errorf("%s: isRetryableError(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if isRetryableError(ctx, &APIError{StatusCode: 503}) {
+		t.Error("isRetryableError() after context cancel = true, want false")
+	}
+}
+
+func TestRetryBadBaseURL(t *testing.T) {
+	// Ошибка конфигурации возвращается сразу, без ожидания между попытками
+	client := NewAPIClient("http://[::1", "", 5*time.Second)
+	client.SetRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetInstruments(ctx); err == nil {
+		t.Fatal("GetInstruments() error = nil, want invalid URL")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetInstruments() took %v, want immediate error without retries", elapsed)
+	}
+}
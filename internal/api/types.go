@@ -0,0 +1,403 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MSK часовой пояс Московской биржи. Если в системе нет tzdata,
+// используется фиксированное смещение UTC+3 (переходов на летнее время в РФ нет).
+var MSK = loadMoscowLocation()
+
+func loadMoscowLocation() *time.Location {
+	if loc, err := time.LoadLocation("Europe/Moscow"); err == nil {
+		return loc
+	}
+	return time.FixedZone("MSK", 3*60*60)
+}
+
+// ErrMalformedResponse ответ API не соответствует ожидаемому формату
+var ErrMalformedResponse = errors.New("неверный формат ответа")
+
+// ErrMalformedCandle свеча содержит некорректные данные
+var ErrMalformedCandle = errors.New("некорректная свеча")
+
+// CandleError ошибка разбора конкретной свечи из ответа
+type CandleError struct {
+	Index int
+	Err   error
+}
+
+func (e *CandleError) Error() string {
+	return fmt.Sprintf("свеча #%d: %v", e.Index, e.Err)
+}
+
+func (e *CandleError) Unwrap() error {
+	return e.Err
+}
+
+// Candle свеча (OHLCV) инструмента. Время всегда в MSK.
+type Candle struct {
+	Begin  time.Time
+	End    time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+	Value  float64
+}
+
+// candleJSON сырое представление свечи в ответе MOEX Fetcher
+type candleJSON struct {
+	Begin  json.RawMessage `json:"begin"`
+	End    json.RawMessage `json:"end"`
+	Open   json.RawMessage `json:"open"`
+	High   json.RawMessage `json:"high"`
+	Low    json.RawMessage `json:"low"`
+	Close  json.RawMessage `json:"close"`
+	Volume json.RawMessage `json:"volume"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// UnmarshalJSON строго разбирает свечу: обязательные поля begin/open/high/low/close,
+// числа допускаются как JSON-числа или числовые строки
+func (c *Candle) UnmarshalJSON(data []byte) error {
+	var raw candleJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedCandle, err)
+	}
+
+	var candle Candle
+	var err error
+
+	if candle.Begin, err = parseRequiredTime(raw.Begin, "begin"); err != nil {
+		return err
+	}
+	if candle.End, err = parseOptionalTime(raw.End, "end"); err != nil {
+		return err
+	}
+	if candle.Open, err = parseRequiredNumber(raw.Open, "open"); err != nil {
+		return err
+	}
+	if candle.High, err = parseRequiredNumber(raw.High, "high"); err != nil {
+		return err
+	}
+	if candle.Low, err = parseRequiredNumber(raw.Low, "low"); err != nil {
+		return err
+	}
+	if candle.Close, err = parseRequiredNumber(raw.Close, "close"); err != nil {
+		return err
+	}
+	if candle.Volume, err = parseOptionalNumber(raw.Volume, "volume"); err != nil {
+		return err
+	}
+	if candle.Value, err = parseOptionalNumber(raw.Value, "value"); err != nil {
+		return err
+	}
+
+	if err := candle.Validate(); err != nil {
+		return err
+	}
+
+	*c = candle
+	return nil
+}
+
+// MarshalJSON сериализует свечу в формате MOEX Fetcher (время в MSK без смещения),
+// совместимом с UnmarshalJSON
+func (c Candle) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Begin  string  `json:"begin"`
+		End    string  `json:"end,omitempty"`
+		Open   float64 `json:"open"`
+		High   float64 `json:"high"`
+		Low    float64 `json:"low"`
+		Close  float64 `json:"close"`
+		Volume float64 `json:"volume"`
+		Value  float64 `json:"value,omitempty"`
+	}{
+		Begin:  c.Begin.In(MSK).Format(mskTimeLayout),
+		Open:   c.Open,
+		High:   c.High,
+		Low:    c.Low,
+		Close:  c.Close,
+		Volume: c.Volume,
+		Value:  c.Value,
+	}
+	if !c.End.IsZero() {
+		out.End = c.End.In(MSK).Format(mskTimeLayout)
+	}
+
+	return json.Marshal(out)
+}
+
+// Validate проверяет согласованность цен свечи
+func (c Candle) Validate() error {
+	if c.Begin.IsZero() {
+		return fmt.Errorf("%w: пустое время начала", ErrMalformedCandle)
+	}
+	if c.Open <= 0 || c.High <= 0 || c.Low <= 0 || c.Close <= 0 {
+		return fmt.Errorf("%w: цены должны быть положительными (O=%.4f H=%.4f L=%.4f C=%.4f)",
+			ErrMalformedCandle, c.Open, c.High, c.Low, c.Close)
+	}
+	if c.High < c.Low {
+		return fmt.Errorf("%w: high %.4f меньше low %.4f", ErrMalformedCandle, c.High, c.Low)
+	}
+	if c.High < math.Max(c.Open, c.Close) || c.Low > math.Min(c.Open, c.Close) {
+		return fmt.Errorf("%w: open/close вне диапазона high-low", ErrMalformedCandle)
+	}
+	if c.Volume < 0 {
+		return fmt.Errorf("%w: отрицательный объем %.0f", ErrMalformedCandle, c.Volume)
+	}
+	return nil
+}
+
+// DecodeCandles разбирает массив свечей, сообщая номер первой некорректной строки.
+// Результат отсортирован по времени начала.
+func DecodeCandles(rows []json.RawMessage) ([]Candle, error) {
+	candles := make([]Candle, 0, len(rows))
+	for i, row := range rows {
+		var candle Candle
+		if err := json.Unmarshal(row, &candle); err != nil {
+			return nil, &CandleError{Index: i, Err: err}
+		}
+		candles = append(candles, candle)
+	}
+
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Begin.Before(candles[j].Begin)
+	})
+
+	return candles, nil
+}
+
+// Timeframe таймфрейм (общий или доступный для инструмента)
+type Timeframe struct {
+	Code        string
+	DisplayName string
+	Description string
+	LastCandle  time.Time
+}
+
+// UnmarshalJSON разбирает таймфрейм. Код берется из поля "timeframe" или "code".
+func (t *Timeframe) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Timeframe   json.RawMessage `json:"timeframe"`
+		Code        json.RawMessage `json:"code"`
+		DisplayName string          `json:"display_name"`
+		Description string          `json:"description"`
+		LastCandle  *struct {
+			Date json.RawMessage `json:"date"`
+		} `json:"last_candle"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: таймфрейм: %v", ErrMalformedResponse, err)
+	}
+
+	code := rawScalar(raw.Timeframe)
+	if code == "" {
+		code = rawScalar(raw.Code)
+	}
+	if code == "" {
+		return fmt.Errorf("%w: таймфрейм без кода", ErrMalformedResponse)
+	}
+
+	tf := Timeframe{
+		Code:        code,
+		DisplayName: raw.DisplayName,
+		Description: raw.Description,
+	}
+	if tf.DisplayName == "" {
+		tf.DisplayName = code
+	}
+
+	if raw.LastCandle != nil {
+		lastCandle, err := parseOptionalTime(raw.LastCandle.Date, "last_candle.date")
+		if err != nil {
+			return err
+		}
+		tf.LastCandle = lastCandle
+	}
+
+	*t = tf
+	return nil
+}
+
+// timeframeDurations длительность свечи для кодов интервалов MOEX
+var timeframeDurations = map[string]time.Duration{
+	"1":  time.Minute,
+	"10": 10 * time.Minute,
+	"60": time.Hour,
+	"24": 24 * time.Hour,
+	"7":  7 * 24 * time.Hour,
+	"31": 31 * 24 * time.Hour,
+	"4":  92 * 24 * time.Hour,
+}
+
+// TimeframeDuration возвращает длительность свечи таймфрейма (для месяца и квартала
+// берется верхняя граница). Для неизвестного кода возвращается сутки.
+func TimeframeDuration(code string) time.Duration {
+	if d, ok := timeframeDurations[code]; ok {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// IsValidTimeframe проверяет, что код интервала поддерживается MOEX
+func IsValidTimeframe(code string) bool {
+	_, ok := timeframeDurations[code]
+	return ok
+}
+
+// Instrument информация об инструменте
+type Instrument struct {
+	Ticker     string
+	Name       string
+	LotSize    int
+	Timeframes []Timeframe
+}
+
+// UnmarshalJSON разбирает информацию об инструменте из ответа MOEX Fetcher
+func (i *Instrument) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Instrument json.RawMessage `json:"instrument"`
+		Ticker     json.RawMessage `json:"ticker"`
+		Name       string          `json:"name"`
+		LotSize    json.RawMessage `json:"lot_size"`
+		Timeframes []Timeframe     `json:"timeframes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: инструмент: %v", ErrMalformedResponse, err)
+	}
+
+	inst := Instrument{
+		Ticker:     rawScalar(raw.Instrument),
+		Name:       raw.Name,
+		Timeframes: raw.Timeframes,
+	}
+	if inst.Ticker == "" {
+		inst.Ticker = rawScalar(raw.Ticker)
+	}
+
+	lotSize, err := parseOptionalNumber(raw.LotSize, "lot_size")
+	if err != nil {
+		return err
+	}
+	inst.LotSize = int(lotSize)
+
+	*i = inst
+	return nil
+}
+
+// mskTimeLayout основной формат времени в ответах API
+const mskTimeLayout = "2006-01-02 15:04:05"
+
+// candleTimeLayouts форматы времени, встречающиеся в ответах API
+var candleTimeLayouts = []string{
+	mskTimeLayout,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseMSKTime разбирает время из ответа API. Время без смещения считается московским.
+func ParseMSKTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(MSK), nil
+	}
+
+	for _, layout := range candleTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, MSK); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("неизвестный формат времени: %q", value)
+}
+
+// isNull проверяет, что поле отсутствует или равно null
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// rawScalar возвращает строковое представление скалярного JSON значения
+func rawScalar(raw json.RawMessage) string {
+	if isNull(raw) {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+func parseRequiredNumber(raw json.RawMessage, field string) (float64, error) {
+	if isNull(raw) {
+		return 0, fmt.Errorf("%w: отсутствует поле %s", ErrMalformedCandle, field)
+	}
+	return parseNumber(raw, field)
+}
+
+func parseOptionalNumber(raw json.RawMessage, field string) (float64, error) {
+	if isNull(raw) {
+		return 0, nil
+	}
+	return parseNumber(raw, field)
+}
+
+func parseNumber(raw json.RawMessage, field string) (float64, error) {
+	var num json.Number
+	var s string
+
+	if err := json.Unmarshal(raw, &s); err == nil {
+		num = json.Number(strings.TrimSpace(s))
+	} else if err := json.Unmarshal(raw, &num); err != nil {
+		return 0, fmt.Errorf("%w: поле %s не является числом: %s", ErrMalformedCandle, field, string(raw))
+	}
+
+	value, err := strconv.ParseFloat(num.String(), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%w: поле %s не является числом: %s", ErrMalformedCandle, field, string(raw))
+	}
+
+	return value, nil
+}
+
+func parseRequiredTime(raw json.RawMessage, field string) (time.Time, error) {
+	if isNull(raw) {
+		return time.Time{}, fmt.Errorf("%w: отсутствует поле %s", ErrMalformedCandle, field)
+	}
+	return parseTime(raw, field)
+}
+
+func parseOptionalTime(raw json.RawMessage, field string) (time.Time, error) {
+	if isNull(raw) {
+		return time.Time{}, nil
+	}
+	return parseTime(raw, field)
+}
+
+func parseTime(raw json.RawMessage, field string) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return time.Time{}, fmt.Errorf("%w: поле %s должно быть строкой: %s", ErrMalformedCandle, field, string(raw))
+	}
+
+	t, err := ParseMSKTime(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: поле %s: %v", ErrMalformedCandle, field, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,153 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+func TestCandleUnmarshal(t *testing.T) {
+	var candle api.Candle
+	data := `{"begin": "2024-03-01 10:00:00", "end": "2024-03-01T10:59:59", "open": 280.5, "high": "282", "low": 279.9, "close": " 281.3 ", "volume": 15000}`
+	if err := json.Unmarshal([]byte(data), &candle); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	wantBegin := time.Date(2024, 3, 1, 10, 0, 0, 0, api.MSK)
+	if !candle.Begin.Equal(wantBegin) || candle.Begin.Location() != api.MSK {
+		t.Errorf("Begin = %v, want %v in MSK", candle.Begin, wantBegin)
+	}
+	if !candle.End.Equal(wantBegin.Add(59*time.Minute + 59*time.Second)) {
+		t.Errorf("End = %v", candle.End)
+	}
+	// Числа допускаются и строками
+	if candle.High != 282 || candle.Close != 281.3 || candle.Volume != 15000 || candle.Value != 0 {
+		t.Errorf("candle = %+v", candle)
+	}
+
+	// Время со смещением переводится в MSK
+	if err := json.Unmarshal([]byte(`{"begin": "2024-03-01T07:00:00Z", "open": 1, "high": 1, "low": 1, "close": 1}`), &candle); err != nil {
+		t.Fatalf("Unmarshal(RFC3339) error = %v", err)
+	}
+	if !candle.Begin.Equal(wantBegin) || candle.Begin.Hour() != 10 {
+		t.Errorf("Begin = %v, want 10:00 MSK", candle.Begin)
+	}
+}
+
+func TestCandleUnmarshalInvalid(t *testing.T) {
+	tests := map[string]string{
+		"нет begin":           `{"open": 1, "high": 1, "low": 1, "close": 1}`,
+		"нет close":           `{"begin": "2024-03-01", "open": 1, "high": 1, "low": 1}`,
+		"close null":          `{"begin": "2024-03-01", "open": 1, "high": 1, "low": 1, "close": null}`,
+		"не число":            `{"begin": "2024-03-01", "open": "abc", "high": 1, "low": 1, "close": 1}`,
+		"NaN":                 `{"begin": "2024-03-01", "open": "NaN", "high": 1, "low": 1, "close": 1}`,
+		"неверное время":      `{"begin": "01.03.2024", "open": 1, "high": 1, "low": 1, "close": 1}`,
+		"время числом":        `{"begin": 1709276400, "open": 1, "high": 1, "low": 1, "close": 1}`,
+		"нулевая цена":        `{"begin": "2024-03-01", "open": 0, "high": 1, "low": 1, "close": 1}`,
+		"high меньше low":     `{"begin": "2024-03-01", "open": 2, "high": 1, "low": 3, "close": 2}`,
+		"close выше high":     `{"begin": "2024-03-01", "open": 1, "high": 2, "low": 1, "close": 3}`,
+		"отрицательный объем": `{"begin": "2024-03-01", "open": 1, "high": 1, "low": 1, "close": 1, "volume": -5}`,
+		"не объект":           `[1, 2, 3]`,
+	}
+
+	for name, data := range tests {
+		var candle api.Candle
+		if err := json.Unmarshal([]byte(data), &candle); !errors.Is(err, api.ErrMalformedCandle) {
+			t.Errorf("%s: Unmarshal() error = %v, want ErrMalformedCandle", name, err)
+		}
+	}
+}
+
+func TestDecodeCandles(t *testing.T) {
+	rows := []json.RawMessage{
+		json.RawMessage(`{"begin": "2024-03-02", "open": 2, "high": 2, "low": 2, "close": 2}`),
+		json.RawMessage(`{"begin": "2024-03-01", "open": 1, "high": 1, "low": 1, "close": 1}`),
+	}
+	candles, err := api.DecodeCandles(rows)
+	if err != nil {
+		t.Fatalf("DecodeCandles() error = %v", err)
+	}
+	// Результат отсортирован по времени
+	if len(candles) != 2 || candles[0].Close != 1 || candles[1].Close != 2 {
+		t.Errorf("candles = %+v, want sorted by begin", candles)
+	}
+
+	rows = append(rows, json.RawMessage(`{"begin": "2024-03-03", "open": 1, "high": 1, "low": 2, "close": 1}`))
+	_, err = api.DecodeCandles(rows)
+
+	var candleErr *api.CandleError
+	if !errors.As(err, &candleErr) || candleErr.Index != 2 || !errors.Is(err, api.ErrMalformedCandle) {
+		t.Errorf("DecodeCandles() error = %v, want CandleError at index 2", err)
+	}
+}
+
+func TestTimeframeAndInstrumentUnmarshal(t *testing.T) {
+	var tf api.Timeframe
+	if err := json.Unmarshal([]byte(`{"code": 24, "last_candle": {"date": "2024-03-01 00:00:00"}}`), &tf); err != nil {
+		t.Fatalf("Unmarshal(timeframe) error = %v", err)
+	}
+	if tf.Code != "24" || tf.DisplayName != "24" || tf.LastCandle.Day() != 1 {
+		t.Errorf("timeframe = %+v", tf)
+	}
+	if err := json.Unmarshal([]byte(`{"display_name": "День"}`), &tf); !errors.Is(err, api.ErrMalformedResponse) {
+		t.Errorf("Unmarshal(timeframe without code) error = %v, want ErrMalformedResponse", err)
+	}
+
+	var inst api.Instrument
+	data := `{"ticker": "SBER", "name": "Сбербанк", "lot_size": "10", "timeframes": [{"timeframe": "60", "display_name": "Час"}]}`
+	if err := json.Unmarshal([]byte(data), &inst); err != nil {
+		t.Fatalf("Unmarshal(instrument) error = %v", err)
+	}
+	if inst.Ticker != "SBER" || inst.LotSize != 10 || len(inst.Timeframes) != 1 || inst.Timeframes[0].Code != "60" {
+		t.Errorf("instrument = %+v", inst)
+	}
+}
+
+func TestCandleMarshalRoundTrip(t *testing.T) {
+	begin := time.Date(2024, 3, 1, 7, 0, 0, 0, time.UTC)
+	candle := api.Candle{Begin: begin, End: begin.Add(time.Hour - time.Second), Open: 280.5, High: 282, Low: 279.9, Close: 281.3, Volume: 15000}
+
+	data, err := json.Marshal(candle)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var decoded api.Candle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", data, err)
+	}
+	// Время сохраняется в MSK и восстанавливается без потерь
+	if !decoded.Begin.Equal(candle.Begin) || !decoded.End.Equal(candle.End) || decoded.Begin.Location() != api.MSK {
+		t.Errorf("decoded = %+v, want %+v", decoded, candle)
+	}
+	if decoded.Close != candle.Close || decoded.Volume != candle.Volume {
+		t.Errorf("decoded = %+v, want %+v", decoded, candle)
+	}
+
+	// Без времени закрытия поле end не пишется
+	candle.End = time.Time{}
+	if data, _ = json.Marshal(candle); strings.Contains(string(data), `"end"`) {
+		t.Errorf("Marshal() = %s, want no end", data)
+	}
+}
+
+func TestTimeframeDuration(t *testing.T) {
+	for code, want := range map[string]time.Duration{
+		"1":  time.Minute,
+		"60": time.Hour,
+		"24": 24 * time.Hour,
+		"7":  7 * 24 * time.Hour,
+		"99": 24 * time.Hour,
+	} {
+		if got := api.TimeframeDuration(code); got != want {
+			t.Errorf("TimeframeDuration(%q) = %v, want %v", code, got, want)
+		}
+	}
+	if !api.IsValidTimeframe("10") || api.IsValidTimeframe("99") {
+		t.Error("IsValidTimeframe: 10 поддерживается, 99 - нет")
+	}
+}
@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/scheduler"
+	"telegram-bot-moex/internal/storage"
+)
+
+// alertsJob имя задачи проверки алертов в планировщике
+const alertsJob = "alerts"
+
+// alertsTimeframe таймфрейм свечей, по которым проверяются условия алертов
+const alertsTimeframe = "24"
+
+// moexTradingHours основная и вечерняя торговые сессии MOEX (MSK) с аукционом
+// открытия - время, когда обновляются свечи, если scheduler.trading_hours не задан
+const moexTradingHours = "09:50-23:50"
+
+// addAlertsJob добавляет в планировщик проверку алертов по расписанию alerts.check_schedule.
+// С alerts.trading_only проверка пропускается, пока биржа не торгует: свечи
+// в это время не меняются и не загружаются заново.
+func (b *Bot) addAlertsJob() {
+	if !b.config.Alerts.Enabled {
+		return
+	}
+
+	var calendar *scheduler.Calendar
+	if b.config.Alerts.TradingOnly {
+		hours := b.config.Scheduler.TradingHours
+		if hours == "" {
+			hours = moexTradingHours
+		}
+		var err error
+		calendar, err = scheduler.NewCalendar(api.MSK, true, hours, b.config.Scheduler.Holidays)
+		if err != nil {
+			b.logger.Error("Ошибка торгового календаря алертов", "error", err)
+			return
+		}
+	}
+
+	spec := b.config.Alerts.CheckSchedule
+	err := b.scheduler.Add(alertsJob, spec, func(ctx context.Context) {
+		if !calendar.IsOpen(time.Now()) {
+			b.logger.Debug("Биржа не торгует, проверка алертов пропущена", "calendar", calendar.String())
+			return
+		}
+		b.safeAnalysisRun(alertsJob, func() { b.checkAlerts(ctx) })
+	})
+	if err != nil {
+		b.logger.Error("Ошибка расписания проверки алертов",
+			"schedule", spec,
+			"error", err)
+		return
+	}
+	b.logger.Info("Проверка алертов по расписанию",
+		"schedule", spec,
+		"calendar", calendar.String())
+}
+
+// checkAlerts проверяет условия алертов по обновленным свечам и уведомляет
+// пользователей о пересечениях порога. Свечи загружаются один раз на инструмент.
+func (b *Bot) checkAlerts(ctx context.Context) {
+	now := time.Now()
+
+	byInstrument := make(map[string][]storage.Alert)
+	var instruments []string
+	for _, alert := range b.store.Alerts(0) {
+		if _, ok := byInstrument[alert.Instrument]; !ok {
+			instruments = append(instruments, alert.Instrument)
+		}
+		byInstrument[alert.Instrument] = append(byInstrument[alert.Instrument], alert)
+	}
+
+	for _, instrument := range instruments {
+		if ctx.Err() != nil {
+			return
+		}
+		b.checkInstrumentAlerts(ctx, instrument, byInstrument[instrument], now)
+	}
+}
+
+// checkInstrumentAlerts проверяет алерты одного инструмента
+func (b *Bot) checkInstrumentAlerts(ctx context.Context, instrument string, alerts []storage.Alert, now time.Time) {
+	conditions := make([]analysis.AlertCondition, len(alerts))
+	bars := 1
+	for i, alert := range alerts {
+		cond, err := analysis.ParseAlertCondition(alert.Condition)
+		if err != nil {
+			b.logger.Warn("Неверное условие алерта",
+				"alert_id", alert.ID,
+				"condition", alert.Condition,
+				"error", err)
+			continue
+		}
+		conditions[i] = cond
+		bars = max(bars, cond.Bars())
+	}
+
+	candles, err := b.alertCandles(ctx, instrument, bars)
+	if err != nil {
+		b.logger.Warn("Ошибка загрузки свечей для алертов",
+			"instrument", instrument,
+			"error", err)
+		return
+	}
+
+	for i, alert := range alerts {
+		if conditions[i].Metric == "" {
+			continue
+		}
+
+		value, met, err := conditions[i].Evaluate(candles)
+		if err != nil {
+			b.logger.Debug("Условие алерта не рассчитано",
+				"alert_id", alert.ID,
+				"instrument", instrument,
+				"error", err)
+			continue
+		}
+		if !alert.Crossed(met) || !alert.Ready(now) {
+			// Без пересечения порога (или во время паузы повторяющегося алерта)
+			// только запоминается состояние условия
+			if err := b.store.ObserveAlert(alert.ID, met); err != nil {
+				b.logger.Warn("Ошибка сохранения состояния алерта",
+					"alert_id", alert.ID,
+					"error", err)
+			}
+			continue
+		}
+
+		// Без отправленного уведомления алерт не отмечается сработавшим, и
+		// пересечение будет обработано при следующей проверке
+		msg := b.formatAlertTriggered(alert, conditions[i], value, candles[len(candles)-1].Close)
+		if err := b.sendFormattedMessage(alert.UserID, msg); err != nil {
+			b.logger.Warn("Ошибка отправки алерта",
+				"alert_id", alert.ID,
+				"user_id", alert.UserID,
+				"error", err)
+			continue
+		}
+		if err := b.store.TriggerAlert(alert.ID, value, now); err != nil {
+			b.logger.Warn("Ошибка сохранения срабатывания алерта",
+				"alert_id", alert.ID,
+				"error", err)
+		}
+	}
+}
+
+// alertCandles загружает дневные свечи инструмента с запасом под bars свечей.
+// Текущая свеча всегда запрашивается у источника заново.
+func (b *Bot) alertCandles(ctx context.Context, instrument string, bars int) ([]api.Candle, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -analysis.HistoryDaysFor(alertsTimeframe, bars))
+	candles, err := b.candles.GetCandles(ctx, instrument, alertsTimeframe, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("нет свечей")
+	}
+	return candles, nil
+}
+
+// formatAlertTriggered уведомление о сработавшем алерте
+func (b *Bot) formatAlertTriggered(alert storage.Alert, cond analysis.AlertCondition, value, price float64) string {
+	msg := fmt.Sprintf("🔔 АЛЕРТ #%d: %s\n\n", alert.ID, alert.Instrument)
+	msg += fmt.Sprintf("📐 Условие: %s\n", alertConditionText(cond))
+	if cond.Metric != analysis.AlertPrice {
+		msg += fmt.Sprintf("📊 Значение: %s\n", formatAlertValue(cond, value))
+	}
+	msg += fmt.Sprintf("💰 Цена: %.2f₽\n", price)
+	msg += fmt.Sprintf("📅 Время: %s\n\n", time.Now().Format("02.01.2006 15:04"))
+
+	if alert.Recurring {
+		msg += fmt.Sprintf("🔁 Повторяющийся алерт: сработает при следующем пересечении порога, не раньше чем через %s\n", shortDuration(alert.Cooldown))
+	} else {
+		msg += "✅ Разовый алерт выполнен и удален\n"
+	}
+	msg += "📋 Ваши алерты: /alerts"
+
+	return msg
+}
+
+// alertConditionText условие алерта для пользователя
+func alertConditionText(cond analysis.AlertCondition) string {
+	var metric string
+	switch cond.Metric {
+	case analysis.AlertPrice:
+		metric = "цена"
+	case analysis.AlertVolume:
+		metric = "объем"
+	case analysis.AlertChange:
+		metric = fmt.Sprintf("изменение за %d дн.", cond.Period)
+	default:
+		metric = fmt.Sprintf("%s(%d)", strings.ToUpper(cond.Metric), cond.Period)
+	}
+	return fmt.Sprintf("%s %s %s", metric, cond.Operator, formatAlertValue(cond, cond.Value))
+}
+
+// formatAlertValue значение показателя алерта для пользователя
+func formatAlertValue(cond analysis.AlertCondition, value float64) string {
+	switch cond.Metric {
+	case analysis.AlertChange:
+		return fmt.Sprintf("%+.2f%%", value)
+	case analysis.AlertVolume:
+		return fmt.Sprintf("%.0f", value)
+	case analysis.AlertPrice:
+		return fmt.Sprintf("%.2f₽", value)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
+
+// shortDuration длительность без нулевых минут и секунд: 1h, 1h30m, 45m
+func shortDuration(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
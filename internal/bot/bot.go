@@ -6,10 +6,14 @@ import (
 	"runtime/debug"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"telegram-bot-moex/internal/analysis"
 	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/cache"
 	"telegram-bot-moex/internal/config"
+	"telegram-bot-moex/internal/scheduler"
+	"telegram-bot-moex/internal/storage"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -24,17 +28,29 @@ type Logger interface {
 }
 
 type Bot struct {
-	config     *config.Config
-	botAPI     *tgbotapi.BotAPI
-	apiClient  *api.APIClient
-	logger     Logger
-	commands   map[string]CommandHandler
-	userStates map[int64]*UserState
-	stats      *BotStats
-	mu         sync.RWMutex
-	stopChan   chan struct{}
+	config      *config.Config
+	botAPI      *tgbotapi.BotAPI
+	apiClient   *api.APIClient
+	source      analysis.DataSource   // Источник рыночных данных (Fetcher или ISS)
+	candles     analysis.CandleSource // Источник свечей для стратегий (кэш поверх source)
+	cache       *cache.CandleCache    // nil, если кэш выключен
+	logger      Logger
+	commands    map[string]CommandHandler
+	userStates  map[int64]*UserState
+	stats       *BotStats
+	strategies  *analysis.Registry                  // Реестр стратегий
+	backtests   map[string]*analysis.BacktestResult // Последние бэктесты "Черепах" по инструментам
+	candleViews map[int64]*candlesView              // Последние выборки /candles по пользователям
+	scanExports map[int64]map[string]scanExport     // Последние результаты /scan по чатам и стратегиям
+	store       *storage.Store                      // Пользовательские настройки
+	lotSizes    map[string]int                      // Размеры лотов инструментов
+	lotMisses   map[string]time.Time                // Неудачные запросы размера лота: повтор после lotSizeRetry
+	lastScans   map[string]time.Time                // Время последнего сканирования по стратегиям
+	mu          sync.RWMutex
+	stopChan    chan struct{}
 
 	// Для фонового анализа
+	scheduler        *scheduler.Scheduler // Расписание фонового анализа стратегий
 	analysisTicker   *time.Ticker
 	analysisStopChan chan struct{}
 	analysisWg       sync.WaitGroup
@@ -43,7 +59,11 @@ type Bot struct {
 // NewBot создает новый экземпляр бота
 func NewBot(cfg *config.Config, logger Logger) (*Bot, error) {
 	// Инициализация Telegram API
-	botAPI, err := tgbotapi.NewBotAPI(cfg.Telegram.Token)
+	endpoint := cfg.Telegram.APIEndpoint
+	if endpoint == "" {
+		endpoint = tgbotapi.APIEndpoint
+	}
+	botAPI, err := tgbotapi.NewBotAPIWithAPIEndpoint(cfg.Telegram.Token, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
@@ -56,20 +76,101 @@ func NewBot(cfg *config.Config, logger Logger) (*Bot, error) {
 		cfg.API.Token,
 		cfg.API.Timeout,
 	)
+	apiClient.SetRetryPolicy(api.RetryPolicy{
+		MaxRetries: cfg.API.MaxRetries,
+		BaseDelay:  cfg.API.RetryDelay,
+		MaxDelay:   cfg.API.RetryMaxDelay,
+	})
+
+	if cbCfg := cfg.API.CircuitBreaker; cbCfg.Enabled {
+		apiClient.SetCircuitBreaker(api.NewCircuitBreaker(api.BreakerConfig{
+			FailureThreshold: cbCfg.FailureThreshold,
+			OpenTimeout:      cbCfg.OpenTimeout,
+			HalfOpenRequests: cbCfg.HalfOpenRequests,
+			OnStateChange: func(from, to api.BreakerState, lastErr error) {
+				if to == api.BreakerOpen {
+					logger.Warn("Circuit breaker API разомкнут",
+						"from", from.String(),
+						"error", lastErr)
+					return
+				}
+				logger.Info("Circuit breaker API сменил состояние",
+					"from", from.String(),
+					"to", to.String())
+			},
+		}))
+	}
+
+	// Источник рыночных данных
+	source, err := newDataSource(cfg, apiClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Кэш свечей поверх источника данных
+	var candles analysis.CandleSource = source
+	var candleCache *cache.CandleCache
+	if cfg.Cache.Enabled {
+		candleCache, err = cache.NewCandleCache(source, cfg.Cache.Dir)
+		if err != nil {
+			logger.Warn("Кэш свечей недоступен, данные будут запрашиваться из API",
+				"dir", cfg.Cache.Dir,
+				"error", err)
+		} else {
+			candles = candleCache
+		}
+	}
+
+	// Хранилище пользовательских настроек
+	store, err := storage.NewStore(cfg.Storage.Path)
+	if err != nil {
+		logger.Warn("Хранилище недоступно, настройки пользователей не будут сохраняться",
+			"path", cfg.Storage.Path,
+			"error", err)
+		store, _ = storage.NewStore("")
+	}
+
+	// Торговый календарь для расписания фонового анализа
+	calendar, err := scheduler.NewCalendar(api.MSK, cfg.Scheduler.TradingDaysOnly, cfg.Scheduler.TradingHours, cfg.Scheduler.Holidays)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настроек расписания: %w", err)
+	}
+	// Праздники MOEX задаются в конфиге на каждый год, устаревший список не ошибка, но календарь неточен
+	if year := time.Now().In(api.MSK).Year(); usesHolidays(cfg) && !calendar.HasHolidaysIn(year) {
+		logger.Warn("В scheduler.holidays нет праздников MOEX на текущий год, праздничные дни будут считаться торговыми",
+			"year", year)
+	}
 
 	bot := &Bot{
 		config:           cfg,
 		botAPI:           botAPI,
 		apiClient:        apiClient,
+		source:           source,
+		candles:          candles,
+		cache:            candleCache,
 		logger:           logger,
 		commands:         make(map[string]CommandHandler),
 		userStates:       make(map[int64]*UserState),
 		stats:            NewBotStats(),
+		strategies:       analysis.NewRegistry(),
+		backtests:        make(map[string]*analysis.BacktestResult),
+		candleViews:      make(map[int64]*candlesView),
+		scanExports:      make(map[int64]map[string]scanExport),
+		store:            store,
+		lotSizes:         make(map[string]int),
+		lotMisses:        make(map[string]time.Time),
+		lastScans:        make(map[string]time.Time),
 		stopChan:         make(chan struct{}),
 		analysisStopChan: make(chan struct{}),
 	}
-
-	// Регистрация команд
+	bot.scheduler = scheduler.New(scheduler.Options{
+		Calendar: calendar,
+		State:    store,
+		CatchUp:  cfg.Scheduler.CatchUp,
+	}, logger)
+
+	// Регистрация стратегий и команд
+	bot.registerStrategies()
 	bot.registerCommands()
 
 	// Установка команд меню
@@ -78,6 +179,55 @@ func NewBot(cfg *config.Config, logger Logger) (*Bot, error) {
 	return bot, nil
 }
 
+// newDataSource создает источник рыночных данных согласно настройкам
+func newDataSource(cfg *config.Config, apiClient *api.APIClient) (analysis.DataSource, error) {
+	switch cfg.DataSource.Provider {
+	case "", "fetcher":
+		return apiClient, nil
+	case "iss":
+		issCfg := cfg.DataSource.ISS
+		issClient := api.NewISSClient(issCfg.URL, issCfg.Timeout, api.ISSOptions{
+			Engine:      issCfg.Engine,
+			Market:      issCfg.Market,
+			Board:       issCfg.Board,
+			Instruments: issCfg.Instruments,
+		})
+		issClient.SetRetryPolicy(api.RetryPolicy{
+			MaxRetries: cfg.API.MaxRetries,
+			BaseDelay:  cfg.API.RetryDelay,
+			MaxDelay:   cfg.API.RetryMaxDelay,
+		})
+		return issClient, nil
+	case "file":
+		fileCfg := cfg.DataSource.File
+		delimiter, _ := utf8.DecodeRuneInString(fileCfg.Delimiter)
+		if fileCfg.Delimiter == "\\t" {
+			delimiter = '\t'
+		}
+		source, err := api.NewFileSource(fileCfg.Dir, fileCfg.Preset, api.FileOptions{
+			Pattern:    fileCfg.Pattern,
+			Delimiter:  delimiter,
+			DateFormat: fileCfg.DateFormat,
+			TimeFormat: fileCfg.TimeFormat,
+			Columns: api.FileColumns{
+				Date:   fileCfg.Columns.Date,
+				Time:   fileCfg.Columns.Time,
+				Open:   fileCfg.Columns.Open,
+				High:   fileCfg.Columns.High,
+				Low:    fileCfg.Columns.Low,
+				Close:  fileCfg.Columns.Close,
+				Volume: fileCfg.Columns.Volume,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка инициализации файлового источника: %w", err)
+		}
+		return source, nil
+	default:
+		return nil, fmt.Errorf("неизвестный источник данных: %s", cfg.DataSource.Provider)
+	}
+}
+
 // Start запускает бота
 func (b *Bot) Start(ctx context.Context) error {
 	b.logger.Info("Starting bot",
@@ -88,9 +238,16 @@ func (b *Bot) Start(ctx context.Context) error {
 	// Запускаем горутину для очистки неактивных состояний
 	go b.startCleanupRoutine(ctx)
 
-	// Запускаем фоновый анализ стратегий
+	// Запускаем фоновый анализ стратегий и проверку алертов
+	b.addAnalysisJobs()
+	b.addAlertsJob()
 	go b.startBackgroundAnalysis(ctx)
 
+	// Запускаем проверку доступности API при разомкнутом circuit breaker
+	if b.config.API.CircuitBreaker.Enabled {
+		go b.startHealthProbe(ctx)
+	}
+
 	// Используем только polling режим
 	return b.startPolling(ctx)
 }
@@ -105,6 +262,7 @@ func (b *Bot) startPolling(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
+			b.botAPI.StopReceivingUpdates()
 			return ctx.Err()
 		case update := <-updates:
 			go b.handleUpdate(update)
@@ -168,92 +326,99 @@ func (b *Bot) GetConfig() *config.Config {
 	return b.config
 }
 
-// startTurtleAnalysisRoutine запускает горутину для анализа по стратегии "Черепах"
-func (b *Bot) startTurtleAnalysisRoutine(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Hour) // Анализируем каждый час
+// startHealthProbe периодически вызывает HealthCheck, пока circuit breaker разомкнут,
+// чтобы сканирование возобновилось сразу после восстановления API
+func (b *Bot) startHealthProbe(ctx context.Context) {
+	ticker := time.NewTicker(b.config.API.CircuitBreaker.ProbeInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-b.stopChan:
+			return
 		case <-ticker.C:
-			b.analyzeTurtleStrategy()
+			state, ok := b.apiClient.CircuitState()
+			if !ok || state.State == api.BreakerClosed {
+				continue
+			}
+
+			probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			_, err := b.apiClient.HealthCheck(probeCtx)
+			cancel()
+
+			if err != nil {
+				b.logger.Debug("API по-прежнему недоступен", "error", err)
+			}
 		}
 	}
 }
 
-func (b *Bot) startMAAnalysisRoutine(ctx context.Context) {
-	ticker := time.NewTicker(2 * time.Hour) // Анализируем каждые 2 часа
-	defer ticker.Stop()
+// addAnalysisJobs добавляет в планировщик фоновый анализ включенных стратегий
+// по расписанию strategy.notifications.analysis_schedule
+func (b *Bot) addAnalysisJobs() {
+	if !b.config.Strategy.Notifications.Enabled {
+		return
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			b.analyzeMAStrategy()
+	for _, reg := range b.strategies.Enabled() {
+		name := reg.Name
+		spec := b.analysisSchedule(reg)
+		err := b.scheduler.Add(name, spec, func(ctx context.Context) {
+			b.safeAnalysisRun(name, func() { b.analyzeStrategy(ctx, name) })
+		})
+		if err != nil {
+			b.logger.Error("Ошибка расписания фонового анализа",
+				"strategy", name,
+				"schedule", spec,
+				"error", err)
+			continue
+		}
+		b.logger.Info("Фоновый анализ по расписанию",
+			"strategy", name,
+			"schedule", spec)
+	}
+
+	for name := range b.config.Strategy.Notifications.AnalysisSchedule {
+		if _, ok := b.strategies.Get(scheduleStrategyName(name)); !ok {
+			b.logger.Warn("Расписание для неизвестной стратегии", "strategy", name)
 		}
 	}
 }
 
-// startBackgroundAnalysis запускает фоновый анализ всех стратегий
+// startBackgroundAnalysis запускает планировщик фонового анализа и ждет его остановки
 func (b *Bot) startBackgroundAnalysis(ctx context.Context) {
 	b.logger.Info("Запуск фонового анализа стратегий")
 
-	// Создаем тикеры для каждой стратегии
-	var tickers []*time.Ticker
-
-	// Анализ по стратегии "Черепах"
-	if b.config.Strategy.Turtles.Enabled && b.config.Strategy.Notifications.Enabled {
-		turtleTicker := time.NewTicker(1 * time.Hour)
-		tickers = append(tickers, turtleTicker)
-		b.startStrategyAnalysis(ctx, "turtle", turtleTicker, b.analyzeTurtleStrategy)
-	}
+	b.analysisWg.Add(1)
+	defer b.analysisWg.Done()
 
-	// Анализ по стратегии MA Crossover
-	if b.config.Strategy.MACrossover.Enabled && b.config.Strategy.Notifications.Enabled {
-		maTicker := time.NewTicker(2 * time.Hour)
-		tickers = append(tickers, maTicker)
-		b.startStrategyAnalysis(ctx, "ma_crossover", maTicker, b.analyzeMAStrategy)
-	}
+	b.scheduler.Start(ctx)
 
-	// Ожидаем остановки
+	// Ожидаем остановки и завершения выполняющихся запусков
 	<-ctx.Done()
-
-	// Останавливаем все тикеры
-	for _, ticker := range tickers {
-		ticker.Stop()
-	}
-
-	// Ожидаем завершения всех горутин анализа
-	b.analysisWg.Wait()
+	b.scheduler.Wait()
 	b.logger.Info("Фоновый анализ остановлен")
 }
 
-// startStrategyAnalysis запускает анализ для конкретной стратегии
-func (b *Bot) startStrategyAnalysis(ctx context.Context, strategyName string, ticker *time.Ticker, analysisFunc func()) {
-	b.analysisWg.Add(1)
-
-	go func() {
-		defer b.analysisWg.Done()
-		defer ticker.Stop()
-
-		b.logger.Info("Запуск фонового анализа", "strategy", strategyName)
-
-		// Первый запуск сразу
-		b.safeAnalysisRun(strategyName, analysisFunc)
-
-		for {
-			select {
-			case <-ctx.Done():
-				b.logger.Info("Остановка анализа", "strategy", strategyName)
-				return
-			case <-ticker.C:
-				b.safeAnalysisRun(strategyName, analysisFunc)
-			}
+// analysisSchedule расписание фонового анализа стратегии: из analysis_schedule
+// или по умолчанию из реестра
+func (b *Bot) analysisSchedule(reg analysis.Registration) string {
+	for name, spec := range b.config.Strategy.Notifications.AnalysisSchedule {
+		if scheduleStrategyName(name) == reg.Name && spec != "" {
+			return spec
 		}
-	}()
+	}
+	return reg.Schedule
+}
+
+// scheduleStrategyName имя стратегии в analysis_schedule; turtles - как в секции strategy.turtles
+func scheduleStrategyName(name string) string {
+	if name == "turtles" {
+		return "turtle"
+	}
+	return name
 }
 
 // safeAnalysisRun безопасно запускает функцию анализа
@@ -278,177 +443,27 @@ func (b *Bot) safeAnalysisRun(strategyName string, analysisFunc func()) {
 		"duration", duration)
 }
 
-// analyzeTurtleStrategy анализирует инструменты по стратегии "Черепах"
-func (b *Bot) analyzeTurtleStrategy() {
-	b.logger.Debug("Запуск анализа по стратегии 'Черепах'")
-
-	// Проверяем, нужно ли отправлять уведомления
-	if !b.config.Strategy.Notifications.Enabled ||
-		b.config.Strategy.Notifications.SignalChatID == 0 {
-		b.logger.Debug("Уведомления отключены для стратегии 'Черепах'")
-		return
+// sendStrategyNotification отправляет уведомление о сигналах. Сигналы должны быть
+// отсортированы по уверенности: в сообщение попадают первые max_signals_per_notification.
+func (b *Bot) sendStrategyNotification(strategyName string, signals []analysis.Signal, chatID int64) error {
+	if len(signals) == 0 || chatID == 0 {
+		return nil
 	}
 
-	// Запускаем сканирование в фоне
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
-
-		// Создаем стратегию
-		strategy := analysis.NewTurtleStrategy(
-			b.apiClient,
-			b.config.Strategy.Turtles.LookbackPeriod,
-			b.config.Strategy.Turtles.EntryBreakoutDays,
-			b.config.Strategy.Turtles.ExitBreakoutDays,
-			b.config.Strategy.Turtles.AtrPeriod,
-			b.config.Strategy.Turtles.AtrMultiplier,
-			b.config.Strategy.Turtles.RiskPerTrade,
-		)
-
-		// Получаем инструменты
-		instruments, err := b.apiClient.GetInstruments(ctx)
-		if err != nil {
-			b.logger.Error("Ошибка получения инструментов для анализа",
-				"strategy", "turtle",
-				"error", err)
-			return
-		}
-
-		if len(instruments) == 0 {
-			b.logger.Debug("Нет инструментов для анализа", "strategy", "turtle")
-			return
-		}
-
-		// Анализируем каждый инструмент
-		var signals []analysis.Signal
-		analyzedCount := 0
-
-		for _, instrument := range instruments {
-			// Пропускаем некорректные инструменты
-			if !b.isValidInstrument(instrument) {
-				continue
-			}
-
-			instrumentSignals, err := strategy.AnalyzeInstrument(ctx, instrument)
-			if err != nil {
-				b.logger.Debug("Ошибка анализа инструмента",
-					"strategy", "turtle",
-					"instrument", instrument,
-					"error", err)
-				continue
-			}
-
-			analyzedCount++
-
-			// Добавляем только сигналы на вход
-			for _, signal := range instrumentSignals {
-				if signal.SignalType == "entry_long" || signal.SignalType == "entry_short" {
-					signals = append(signals, signal)
-				}
-			}
-
-			// Делаем паузу между запросами
-			time.Sleep(100 * time.Millisecond)
-		}
-
-		b.logger.Info("Анализ стратегии 'Черепах' завершен",
-			"instruments", analyzedCount,
-			"signals", len(signals))
-
-		// Если есть сигналы, отправляем уведомление
-		if len(signals) > 0 {
-			b.sendStrategyNotification("turtle", signals, b.config.Strategy.Notifications.SignalChatID)
-		}
-	}()
-}
-
-// analyzeMAStrategy анализирует инструменты по стратегии MA Crossover
-func (b *Bot) analyzeMAStrategy() {
-	b.logger.Debug("Запуск анализа по стратегии MA Crossover")
-
-	// Проверяем, нужно ли отправлять уведомления
-	if !b.config.Strategy.Notifications.Enabled ||
-		b.config.Strategy.Notifications.SignalChatID == 0 {
-		b.logger.Debug("Уведомления отключены для стратегии MA Crossover")
-		return
+	limit := b.config.Strategy.Notifications.MaxSignalsPerNotification
+	if limit <= 0 {
+		limit = defaultMaxNotificationSignals
 	}
-
-	// Запускаем сканирование в фоне
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
-
-		// Создаем стратегию
-		strategy := b.createMACrossoverStrategy()
-
-		// Получаем инструменты
-		instruments, err := b.apiClient.GetInstruments(ctx)
-		if err != nil {
-			b.logger.Error("Ошибка получения инструментов для анализа",
-				"strategy", "ma_crossover",
-				"error", err)
-			return
-		}
-
-		if len(instruments) == 0 {
-			b.logger.Debug("Нет инструментов для анализа", "strategy", "ma_crossover")
-			return
-		}
-
-		// Анализируем каждый инструмент
-		var signals []analysis.Signal
-		analyzedCount := 0
-
-		for _, instrument := range instruments {
-			// Пропускаем некорректные инструменты
-			if !b.isValidInstrument(instrument) {
-				continue
-			}
-
-			instrumentSignals, err := strategy.Analyze(ctx, instrument)
-			if err != nil {
-				b.logger.Debug("Ошибка анализа инструмента",
-					"strategy", "ma_crossover",
-					"instrument", instrument,
-					"error", err)
-				continue
-			}
-
-			analyzedCount++
-
-			// Добавляем сигналы на вход
-			for _, signal := range instrumentSignals {
-				if signal.SignalType == "entry_long" || signal.SignalType == "entry_short" {
-					signals = append(signals, signal)
-				}
-			}
-
-			// Делаем паузу между запросами
-			time.Sleep(100 * time.Millisecond)
-		}
-
-		b.logger.Info("Анализ стратегии MA Crossover завершен",
-			"instruments", analyzedCount,
-			"signals", len(signals))
-
-		// Если есть сигналы, отправляем уведомление
-		if len(signals) > 0 {
-			b.sendStrategyNotification("ma_crossover", signals, b.config.Strategy.Notifications.SignalChatID)
-		}
-	}()
-}
-
-// sendStrategyNotification отправляет уведомление о сигналах
-func (b *Bot) sendStrategyNotification(strategyName string, signals []analysis.Signal, chatID int64) {
-	if len(signals) == 0 || chatID == 0 {
-		return
+	shown := signals
+	if len(shown) > limit {
+		shown = shown[:limit]
 	}
 
 	// Группируем сигналы по типу
 	entryLong := []analysis.Signal{}
 	entryShort := []analysis.Signal{}
 
-	for _, signal := range signals {
+	for _, signal := range shown {
 		switch signal.SignalType {
 		case "entry_long":
 			entryLong = append(entryLong, signal)
@@ -458,35 +473,33 @@ func (b *Bot) sendStrategyNotification(strategyName string, signals []analysis.S
 	}
 
 	// Формируем сообщение
-	var msg string
-
-	switch strategyName {
-	case "turtle":
-		msg = "🐢 СИГНАЛЫ СТРАТЕГИИ 'ЧЕРЕПАХ'\n\n"
-	case "ma_crossover":
-		msg = "📊 СИГНАЛЫ MA CROSSOVER\n\n"
-	default:
-		msg = "📈 ТОРГОВЫЕ СИГНАЛЫ\n\n"
+	title := strategyName
+	if reg, ok := b.strategies.Get(strategyName); ok {
+		title = reg.Title
 	}
+	msg := fmt.Sprintf("📈 СИГНАЛЫ СТРАТЕГИИ '%s'\n\n", title)
 
 	msg += fmt.Sprintf("📅 Время анализа: %s\n", time.Now().Format("02.01.2006 15:04"))
-	msg += fmt.Sprintf("📊 Всего сигналов: %d\n\n", len(signals))
+	msg += fmt.Sprintf("📊 Всего сигналов: %d\n", len(signals))
+	if minConfidence := b.config.Strategy.Notifications.MinConfidencePercent; minConfidence > 0 {
+		msg += fmt.Sprintf("🎯 Уверенность не ниже %.0f%%\n", minConfidence)
+	}
+	msg += "\n"
 
 	// Добавляем сигналы на покупку
 	if len(entryLong) > 0 {
 		msg += "🟢 СИГНАЛЫ НА ПОКУПКУ:\n"
-		for i, signal := range entryLong {
-			if i >= 10 { // Ограничиваем количество сигналов
-				msg += fmt.Sprintf("... и ещё %d сигналов\n", len(entryLong)-10)
-				break
-			}
+		for _, signal := range entryLong {
 			msg += fmt.Sprintf("• %s - %.2f₽", signal.Instrument, signal.Price)
 			if signal.StopLoss > 0 && signal.TakeProfit > 0 {
 				stopPercent := ((signal.Price - signal.StopLoss) / signal.Price) * 100
 				profitPercent := ((signal.TakeProfit - signal.Price) / signal.Price) * 100
 				msg += fmt.Sprintf(" (SL: -%.1f%%, TP: +%.1f%%)", stopPercent, profitPercent)
 			}
-			msg += "\n"
+			msg += formatConfidence(signal) + "\n"
+			if signal.Sized() {
+				msg += fmt.Sprintf("  Размер: %s\n", formatPosition(signal))
+			}
 		}
 		msg += "\n"
 	}
@@ -494,36 +507,40 @@ func (b *Bot) sendStrategyNotification(strategyName string, signals []analysis.S
 	// Добавляем сигналы на продажу
 	if len(entryShort) > 0 {
 		msg += "🔴 СИГНАЛЫ НА ПРОДАЖУ:\n"
-		for i, signal := range entryShort {
-			if i >= 10 {
-				msg += fmt.Sprintf("... и ещё %d сигналов\n", len(entryShort)-10)
-				break
-			}
+		for _, signal := range entryShort {
 			msg += fmt.Sprintf("• %s - %.2f₽", signal.Instrument, signal.Price)
 			if signal.StopLoss > 0 && signal.TakeProfit > 0 {
 				stopPercent := ((signal.StopLoss - signal.Price) / signal.Price) * 100
 				profitPercent := ((signal.Price - signal.TakeProfit) / signal.Price) * 100
 				msg += fmt.Sprintf(" (SL: +%.1f%%, TP: -%.1f%%)", stopPercent, profitPercent)
 			}
-			msg += "\n"
+			msg += formatConfidence(signal) + "\n"
+			if signal.Sized() {
+				msg += fmt.Sprintf("  Размер: %s\n", formatPosition(signal))
+			}
 		}
 		msg += "\n"
 	}
 
-	msg += "💡 Для получения детальной информации используйте команды:\n"
-	if strategyName == "turtle" {
-		msg += "• /turtle_signals - детальные сигналы 'Черепах'\n"
-		msg += "• /scan_turtles - полное сканирование\n"
-	} else if strategyName == "ma_crossover" {
-		msg += "• /ma_signals - детальные сигналы MA Crossover\n"
-		msg += "• /scan_ma - полное сканирование\n"
+	if hidden := len(signals) - len(shown); hidden > 0 {
+		msg += fmt.Sprintf("... и ещё %d сигналов с меньшей уверенностью\n\n", hidden)
 	}
 
+	msg += fmt.Sprintf("💡 Полное сканирование: /scan %s\n", strategyName)
+
 	// Отправляем сообщение
 	if err := b.sendFormattedMessage(chatID, msg); err != nil {
 		b.logger.Error("Ошибка отправки уведомления",
 			"strategy", strategyName,
 			"chat_id", chatID,
 			"error", err)
+		return err
 	}
+	return nil
+}
+
+// usesHolidays учитывают ли задачи бота праздники MOEX: фоновый анализ
+// в торговые дни или проверка алертов во время торгов
+func usesHolidays(cfg *config.Config) bool {
+	return cfg.Scheduler.TradingDaysOnly || (cfg.Alerts.Enabled && cfg.Alerts.TradingOnly)
 }
@@ -0,0 +1,724 @@
+package bot_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/bot"
+	"telegram-bot-moex/internal/config"
+	"telegram-bot-moex/internal/storage"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+	"telegram-bot-moex/internal/testutil/faketelegram"
+)
+
+const (
+	testUser  int64 = 1001
+	testAdmin int64 = 2002
+	testGroup int64 = -100500
+)
+
+// nopLogger логгер, отбрасывающий записи
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields ...interface{})  {}
+func (nopLogger) Error(msg string, fields ...interface{}) {}
+func (nopLogger) Warn(msg string, fields ...interface{})  {}
+func (nopLogger) Debug(msg string, fields ...interface{}) {}
+func (nopLogger) Fatal(msg string, fields ...interface{}) {}
+
+// testEnv запущенный бот с заглушками Telegram и MOEX Fetcher
+type testEnv struct {
+	telegram *faketelegram.Server
+	fetcher  *fakefetcher.Server
+	config   *config.Config
+}
+
+// startBot запускает бота поверх заглушек; modify может изменить конфигурацию до старта
+func startBot(t *testing.T, fetcher *fakefetcher.Server, modify func(cfg *config.Config)) *testEnv {
+	t.Helper()
+
+	telegram := faketelegram.New(t)
+
+	cfg := config.DefaultConfig()
+	cfg.Telegram.Token = "123456789:TEST"
+	cfg.Telegram.APIEndpoint = telegram.Endpoint()
+	cfg.Telegram.UpdatesTimeout = 1
+	cfg.API.URL = fetcher.URL
+	cfg.API.RetryDelay = time.Millisecond
+	cfg.API.RetryMaxDelay = 10 * time.Millisecond
+	cfg.Cache.Dir = t.TempDir()
+	cfg.Storage.Path = filepath.Join(t.TempDir(), "storage.json")
+	cfg.Security.AdminUsers = []int64{testAdmin}
+	cfg.Strategy.Notifications.Enabled = false
+	if modify != nil {
+		modify(cfg)
+	}
+
+	b, err := bot.NewBot(cfg, nopLogger{})
+	if err != nil {
+		t.Fatalf("NewBot() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return &testEnv{telegram: telegram, fetcher: fetcher, config: cfg}
+}
+
+// enableMACrossover включает MA Crossover с короткими периодами под синтетические свечи
+func enableMACrossover(cfg *config.Config) {
+	cfg.Strategy.MACrossover.Enabled = true
+	cfg.Strategy.MACrossover.Timeframe = "24"
+	cfg.Strategy.MACrossover.FastPeriod = 5
+	cfg.Strategy.MACrossover.SlowPeriod = 20
+	cfg.Strategy.MACrossover.StopLossATRMultiplier = 2
+	cfg.Strategy.MACrossover.TakeProfitRatio = 2
+	cfg.Strategy.MACrossover.CrossoverTypes.GoldenCross = true
+	cfg.Strategy.MACrossover.CrossoverTypes.DeathCross = true
+	cfg.Strategy.MACrossover.Filters.TrendFilter = "none"
+}
+
+func TestBotRegistersCommands(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+
+	commands := env.telegram.Commands()
+	if len(commands) == 0 {
+		t.Fatal("setMyCommands was not called")
+	}
+
+	found := false
+	for _, command := range commands {
+		if command.Command == "candles" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("commands = %v, want /candles", commands)
+	}
+}
+
+func TestBotRegistersStrategyCommands(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+		enableMACrossover(cfg)
+	})
+
+	names := make(map[string]bool)
+	for _, command := range env.telegram.Commands() {
+		names[command.Command] = true
+	}
+
+	for _, want := range []string{"start", "scan", "strategies", "turtle", "ma", "admin"} {
+		if !names[want] {
+			t.Errorf("commands missing /%s", want)
+		}
+	}
+}
+
+func TestBotStart(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+
+	env.telegram.SendText(testUser, "/start")
+	msg := env.telegram.WaitMessage(t, "Привет, Test!")
+
+	if msg.ChatID != testUser {
+		t.Errorf("ChatID = %d, want %d", msg.ChatID, testUser)
+	}
+	if !msg.HasButton("quick_instruments") {
+		t.Errorf("buttons = %v, want quick_instruments", msg.Buttons())
+	}
+	if strings.Contains(msg.Text, "Админ команды") {
+		t.Error("regular user should not see admin commands")
+	}
+
+	env.telegram.SendText(testAdmin, "/start")
+	if msg := env.telegram.WaitMessage(t, "Привет"); !strings.Contains(msg.Text, "Админ команды") {
+		t.Error("admin should see admin commands")
+	}
+}
+
+func TestBotUnknownCommand(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+
+	env.telegram.SendText(testUser, "/nope")
+	env.telegram.WaitMessage(t, "Неизвестная команда: /nope")
+}
+
+func TestBotHealth(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+
+	env.telegram.SendText(testUser, "/health")
+	env.telegram.WaitMessage(t, "healthy")
+
+	if env.fetcher.RequestCount("/health") == 0 {
+		t.Error("/health did not reach the fetcher")
+	}
+}
+
+func TestBotCandlesConversation(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+	tg := env.telegram
+
+	tg.SendText(testUser, "/candles")
+	tg.WaitMessage(t, "введите тикер")
+
+	tg.SendText(testUser, "SBER")
+	choice := tg.WaitMessage(t, "Выберите таймфрейм")
+	if !choice.HasButton("timeframe_24") {
+		t.Fatalf("buttons = %v, want timeframe_24", choice.Buttons())
+	}
+
+	tg.Press(testUser, choice.MessageID, "timeframe_24")
+	tg.WaitMessage(t, "Теперь укажите период")
+
+	tg.SendText(testUser, "вчера-позавчера")
+	tg.WaitMessage(t, "Попробуйте снова")
+
+	tg.SendText(testUser, "1y")
+	table := tg.WaitMessage(t, "СВЕЧИ: SBER (24)")
+	for _, want := range []string{"Свечей: ", "Максимум: ", "<pre>Дата", "Объем", "Страница "} {
+		if !strings.Contains(table.Text, want) {
+			t.Errorf("candles = %q, want %q", table.Text, want)
+		}
+	}
+	if !table.HasButton("candles_page_0") || !table.HasButton("candles_export_xlsx") || !table.HasButton("candles_chart") {
+		t.Fatalf("buttons = %v, want pagination, export and chart", table.Buttons())
+	}
+
+	tg.Press(testUser, table.MessageID, "candles_page_0")
+	first := tg.WaitMessage(t, "Страница 1 из ")
+	if first.Method != "editMessageText" || first.MessageID != table.MessageID {
+		t.Errorf("page = %s #%d, want editMessageText #%d", first.Method, first.MessageID, table.MessageID)
+	}
+	if first.HasButton("candles_page_0") {
+		t.Errorf("buttons = %v, want no backward buttons on the first page", first.Buttons())
+	}
+
+	tg.Press(testUser, table.MessageID, "candles_export_csv")
+	if doc := tg.WaitMessage(t, "Свечи SBER (24)"); doc.Method != "sendDocument" || !strings.HasPrefix(doc.FileName, "SBER_24_") || !strings.HasSuffix(doc.FileName, ".csv") {
+		t.Errorf("csv = %s %q, want sendDocument SBER_24_*.csv", doc.Method, doc.FileName)
+	}
+
+	tg.Press(testUser, table.MessageID, "candles_chart")
+	tg.WaitMessage(t, "График SBER (24)")
+}
+
+func TestBotCandlesUnknownInstrument(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+	tg := env.telegram
+
+	tg.SendText(testUser, "/candles")
+	tg.WaitMessage(t, "введите тикер")
+
+	tg.SendText(testUser, "NOPE")
+	tg.WaitMessage(t, "Инструмент не найден")
+}
+
+func TestBotScanTurtles(t *testing.T) {
+	breakout := make([]float64, 0, 61)
+	for i := 0; i < 60; i++ {
+		breakout = append(breakout, 100+float64(i%2))
+	}
+	breakout = append(breakout, 110)
+
+	fetcher := fakefetcher.New(t,
+		fakefetcher.WithCandles("SBER", "24", fakefetcher.Series(breakout)),
+		fakefetcher.WithSynthetic("GAZP", 2, 60, 160),
+	)
+	env := startBot(t, fetcher, func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+	})
+
+	env.telegram.SendText(testUser, "/scan_turtles")
+	env.telegram.WaitMessage(t, "Сканирование всех инструментов")
+	result := env.telegram.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ")
+
+	if !strings.Contains(result.Text, "Проанализировано инструментов: 2") {
+		t.Errorf("scan result = %q, want 2 instruments", result.Text)
+	}
+	if !strings.Contains(result.Text, "SBER") {
+		t.Errorf("scan result = %q, want SBER breakout", result.Text)
+	}
+}
+
+func TestBotExport(t *testing.T) {
+	breakout := make([]float64, 0, 61)
+	for i := 0; i < 60; i++ {
+		breakout = append(breakout, 100+float64(i%2))
+	}
+	breakout = append(breakout, 110)
+
+	env := startBot(t, fakefetcher.New(t, fakefetcher.WithCandles("SBER", "24", fakefetcher.Series(breakout))), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/export")
+	tg.WaitMessage(t, "/export ТИКЕР [ТАЙМФРЕЙМ] [ПЕРИОД]")
+
+	tg.SendText(testUser, "/export SBER 24 3m xlsx")
+	if doc := tg.WaitMessage(t, "Свечи SBER (24)"); doc.Method != "sendDocument" || !strings.HasPrefix(doc.FileName, "SBER_24_") || !strings.HasSuffix(doc.FileName, ".xlsx") {
+		t.Errorf("xlsx = %s %q, want sendDocument SBER_24_*.xlsx", doc.Method, doc.FileName)
+	}
+
+	tg.SendText(testUser, "/export SBER 24 вчера-завтра")
+	tg.WaitMessage(t, "Период: ")
+
+	tg.SendText(testUser, "/export signals")
+	tg.WaitMessage(t, "Сигналов в журнале нет")
+
+	tg.SendText(testUser, "/export scan")
+	tg.WaitMessage(t, "Сначала выполните /scan")
+
+	tg.SendText(testUser, "/scan_turtles")
+	result := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ")
+	if !result.HasButton("export_scan_turtle_json") {
+		t.Fatalf("buttons = %v, want export", result.Buttons())
+	}
+
+	tg.Press(testUser, result.MessageID, "export_scan_turtle_csv")
+	if doc := tg.WaitMessage(t, "Результаты сканирования"); doc.Method != "sendDocument" || !strings.HasPrefix(doc.FileName, "scan_turtle_") || !strings.HasSuffix(doc.FileName, ".csv") {
+		t.Errorf("scan export = %s %q, want sendDocument scan_turtle_*.csv", doc.Method, doc.FileName)
+	}
+}
+
+func TestBotSchedule(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+		enableMACrossover(cfg)
+		cfg.Strategy.Notifications.Enabled = true
+		cfg.Strategy.Notifications.AnalysisSchedule = map[string]string{"turtles": "30 10 * * 1-5"}
+		cfg.Scheduler.CatchUp = false
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/schedule")
+	tg.WaitMessage(t, "Расписание доступно только администраторам")
+
+	tg.SendText(testAdmin, "/schedule")
+	schedule := tg.WaitMessage(t, "РАСПИСАНИЕ ФОНОВОГО АНАЛИЗА")
+	for _, want := range []string{"Черепахи (turtle)", "Расписание: 30 10 * * 1-5", "MA Crossover (ma_crossover)", "Расписание: 0 */2 * * *", "Следующий запуск: "} {
+		if !strings.Contains(schedule.Text, want) {
+			t.Errorf("schedule = %q, want %q", schedule.Text, want)
+		}
+	}
+}
+
+func TestBotEquity(t *testing.T) {
+	breakout := make([]float64, 0, 61)
+	for i := 0; i < 60; i++ {
+		breakout = append(breakout, 100+float64(i%2))
+	}
+	breakout = append(breakout, 110)
+
+	fetcher := fakefetcher.New(t,
+		fakefetcher.WithCandles("SBER", "24", fakefetcher.Series(breakout)),
+		fakefetcher.WithLotSize("SBER", 10),
+	)
+	env := startBot(t, fetcher, func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/equity")
+	if account := tg.WaitMessage(t, "РАЗМЕР СЧЕТА"); !strings.Contains(account.Text, "100000₽ (из конфигурации)") {
+		t.Errorf("equity = %q, want default account", account.Text)
+	}
+
+	tg.SendText(testUser, "/equity abc")
+	tg.WaitMessage(t, "Неверный размер счета")
+
+	// Нечисловые и слишком большие значения не попадают в хранилище
+	for _, amount := range []string{"NaN", "Inf", "1e309", "1e13"} {
+		tg.SendText(testUser, "/equity "+amount)
+		tg.WaitMessage(t, "Неверный размер счета: "+amount)
+	}
+	tg.SendText(testUser, "/equity 1000000 NaN")
+	tg.WaitMessage(t, "Неверная доля позиции: NaN")
+
+	tg.SendText(testUser, "/equity 1000000 20")
+	if account := tg.WaitMessage(t, "Размер счета сохранен"); !strings.Contains(account.Text, "20% счета (200000₽)") {
+		t.Errorf("equity = %q, want max position 20%%", account.Text)
+	}
+
+	// Позиция ограничена 20% счета и округлена до целых лотов по 10 бумаг
+	tg.SendText(testUser, "/scan_turtles")
+	result := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ")
+	if !strings.Contains(result.Text, "1810 шт. (181 лот. по 10) на 199100₽") {
+		t.Errorf("scan result = %q, want lot-aware position size", result.Text)
+	}
+
+	// В группе используется счет отправителя команды, а не ID чата
+	tg.SendGroupText(testGroup, testUser, "/scan_turtles")
+	result = tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ")
+	if result.ChatID != testGroup || !strings.Contains(result.Text, "1810 шт. (181 лот. по 10) на 199100₽") {
+		t.Errorf("group scan result = %d %q, want user's account", result.ChatID, result.Text)
+	}
+
+	tg.SendText(testUser, "/equity reset")
+	if account := tg.WaitMessage(t, "сброшен"); !strings.Contains(account.Text, "100000₽ (из конфигурации)") {
+		t.Errorf("equity = %q, want default account after reset", account.Text)
+	}
+}
+
+func TestBotUnknownLotSize(t *testing.T) {
+	breakout := make([]float64, 0, 61)
+	for i := 0; i < 60; i++ {
+		breakout = append(breakout, 100+float64(i%2))
+	}
+	breakout = append(breakout, 110)
+
+	fetcher := fakefetcher.New(t,
+		fakefetcher.WithCandles("SBER", "24", fakefetcher.Series(breakout)),
+		fakefetcher.WithLotSize("SBER", 0),
+	)
+	env := startBot(t, fetcher, func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+	})
+	tg := env.telegram
+
+	// Без размера лота позиция не округляется до лота в 1 бумагу
+	for i := 0; i < 2; i++ {
+		tg.SendText(testUser, "/scan_turtles")
+		result := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ")
+		if !strings.Contains(result.Text, "размер лота неизвестен") || strings.Contains(result.Text, "шт. на") {
+			t.Errorf("scan result = %q, want unknown lot size warning", result.Text)
+		}
+	}
+
+	// Неудачный запрос размера лота не повторяется для каждого сигнала
+	if got := fetcher.RequestCount("/api/instruments/SBER"); got != 1 {
+		t.Errorf("instrument info requests = %d, want 1", got)
+	}
+}
+
+func TestBotSignalsHistory(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+	tg := env.telegram
+
+	tg.SendText(testUser, "/signals_history")
+	tg.WaitMessage(t, "Сигналов в журнале нет")
+
+	tg.SendText(testUser, "/signals_history turtle sber 5")
+	if history := tg.WaitMessage(t, "ИСТОРИЯ СИГНАЛОВ"); !strings.Contains(history.Text, "turtle SBER") {
+		t.Errorf("history = %q, want strategy and ticker filter", history.Text)
+	}
+
+	tg.SendText(testUser, "/signals_history 500")
+	tg.WaitMessage(t, "Количество сигналов должно быть от 1 до 50")
+}
+
+func TestBotWatchlist(t *testing.T) {
+	fetcher := fakefetcher.New(t,
+		fakefetcher.WithSynthetic("SBER", 1, 60, 100),
+		fakefetcher.WithSynthetic("GAZP", 2, 60, 160),
+	)
+	env := startBot(t, fetcher, func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/watchlist")
+	tg.WaitMessage(t, "Список пуст")
+
+	tg.SendText(testUser, "/watch sber, GAZP")
+	tg.WaitMessage(t, "Добавлено в список наблюдения: SBER, GAZP")
+
+	tg.SendText(testUser, "/unwatch GAZP")
+	tg.WaitMessage(t, "Удалено из списка наблюдения: GAZP")
+
+	tg.SendText(testUser, "/watchlist")
+	watchlist := tg.WaitMessage(t, "СПИСОК НАБЛЮДЕНИЯ")
+	if !watchlist.HasButton("watch_del_SBER") || !watchlist.HasButton("watch_scans") {
+		t.Fatalf("buttons = %v, want watch_del_SBER and watch_scans", watchlist.Buttons())
+	}
+
+	// Сканирование только по списку наблюдения
+	tg.Press(testUser, watchlist.MessageID, "watch_scans")
+	tg.WaitMessage(t, "Сканирование: только список")
+
+	tg.SendText(testUser, "/scan_turtles")
+	result := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ")
+	if !strings.Contains(result.Text, "Проанализировано инструментов: 1") || !strings.Contains(result.Text, "По списку наблюдения") {
+		t.Errorf("scan result = %q, want watchlist scan of 1 instrument", result.Text)
+	}
+}
+
+func TestBotAlerts(t *testing.T) {
+	closes := make([]float64, 0, 30)
+	for i := 0; i < 30; i++ {
+		closes = append(closes, 280+float64(i))
+	}
+
+	fetcher := fakefetcher.New(t, fakefetcher.WithCandles("SBER", "24", fakefetcher.Series(closes)))
+	env := startBot(t, fetcher, func(cfg *config.Config) {
+		// Первая проверка алертов выполняется сразу после старта
+		cfg.Scheduler.CatchUp = true
+		cfg.Scheduler.TradingDaysOnly = false
+		cfg.Scheduler.TradingHours = ""
+		cfg.Alerts.TradingOnly = false
+
+		store, err := storage.NewStore(cfg.Storage.Path)
+		if err != nil {
+			t.Fatalf("NewStore() error = %v", err)
+		}
+		met, notMet := true, false
+		for _, alert := range []storage.Alert{
+			// Условие выполнялось и раньше - пересечения нет
+			{UserID: testUser, Instrument: "SBER", Condition: "price > 290", Recurring: true, Cooldown: time.Hour, Met: &met},
+			{UserID: testUser, Instrument: "SBER", Condition: "price > 300", Met: &notMet},
+			{UserID: testUser, Instrument: "SBER", Condition: "price < 100", Recurring: true, Cooldown: time.Hour, Met: &notMet},
+		} {
+			if _, err := store.AddAlert(alert); err != nil {
+				t.Fatalf("AddAlert() error = %v", err)
+			}
+		}
+	})
+	tg := env.telegram
+
+	triggered := tg.WaitMessage(t, "АЛЕРТ #2: SBER")
+	if !strings.Contains(triggered.Text, "Разовый алерт выполнен") {
+		t.Errorf("alert = %q, want one-shot alert", triggered.Text)
+	}
+	// Алерты проверяются по порядку: #1 уже был бы отправлен
+	for _, sent := range tg.Sent() {
+		if strings.Contains(sent.Text, "АЛЕРТ #1") {
+			t.Errorf("alert #1 sent without crossing: %q", sent.Text)
+		}
+	}
+
+	// Разовый алерт удален после срабатывания
+	tg.SendText(testUser, "/alerts")
+	alerts := tg.WaitMessage(t, "ВАШИ АЛЕРТЫ (2)")
+	if !alerts.HasButton("alert_del_3") || alerts.HasButton("alert_del_2") {
+		t.Fatalf("buttons = %v, want alert_del_3 without alert_del_2", alerts.Buttons())
+	}
+
+	tg.SendText(testUser, "/alert SBER price = 300")
+	tg.WaitMessage(t, "неверное условие")
+
+	tg.SendText(testUser, "/alert sber change1d > 5% repeat 4h")
+	created := tg.WaitMessage(t, "Алерт #4 создан")
+	if !strings.Contains(created.Text, "изменение за 1 дн.") || !strings.Contains(created.Text, "пауза 4h") {
+		t.Errorf("created = %q, want change alert repeating with 4h cooldown", created.Text)
+	}
+
+	tg.SendText(testUser, "/alert SBER > 250")
+	if created := tg.WaitMessage(t, "Алерт #5 создан"); !strings.Contains(created.Text, "уведомление придет при следующем пересечении") {
+		t.Errorf("created = %q, want notice that condition already holds", created.Text)
+	}
+
+	tg.Press(testUser, alerts.MessageID, "alert_del_3")
+	tg.WaitMessage(t, "Удалено алертов: 1")
+	tg.WaitMessage(t, "ВАШИ АЛЕРТЫ (3)")
+}
+
+func TestBotStrategyStats(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+		enableMACrossover(cfg)
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/turtle_stats")
+	stats := tg.WaitMessage(t, "СТАТИСТИКА СТРАТЕГИИ 'ЧЕРЕПАХ'")
+	for _, want := range []string{"Последнее сканирование: не выполнялось", "Автосканирование: 0 * * * * (MSK)", "Сигналов в журнале пока нет"} {
+		if !strings.Contains(stats.Text, want) {
+			t.Errorf("turtle stats = %q, want %q", stats.Text, want)
+		}
+	}
+
+	tg.SendText(testUser, "/ma_stats")
+	stats = tg.WaitMessage(t, "СТАТИСТИКА СТРАТЕГИИ MA CROSSOVER")
+	if !strings.Contains(stats.Text, "Автосканирование: 0 */2 * * * (MSK)") {
+		t.Errorf("ma stats = %q, want scan interval", stats.Text)
+	}
+}
+
+func TestBotTurtleBacktest(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/turtle_backtest")
+	tg.WaitMessage(t, "Использование: /turtle_backtest")
+
+	tg.SendText(testUser, "/turtle_backtest SBER 6m")
+	tg.WaitMessage(t, "Бэктест SBER")
+	report := tg.WaitMessage(t, "БЭКТЕСТ 'ЧЕРЕПАХ': SBER")
+	if !strings.Contains(report.Text, "Профит-фактор") || !strings.Contains(report.Text, "Макс. просадка") {
+		t.Errorf("report = %q, want metrics", report.Text)
+	}
+
+	tg.SendText(testUser, "/turtle_stats")
+	if stats := tg.WaitMessage(t, "СТАТИСТИКА СТРАТЕГИИ"); !strings.Contains(stats.Text, "SBER (") {
+		t.Errorf("stats = %q, want SBER backtest summary", stats.Text)
+	}
+
+	tg.SendText(testUser, "/turtle_backtest SBER 5x")
+	tg.WaitMessage(t, "Неверный период")
+}
+
+func TestBotScanAllStrategies(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+		enableMACrossover(cfg)
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/strategies")
+	list := tg.WaitMessage(t, "СТРАТЕГИИ")
+	if !strings.Contains(list.Text, "/scan turtle") || !strings.Contains(list.Text, "/scan ma_crossover") {
+		t.Errorf("strategies = %q, want both strategies", list.Text)
+	}
+
+	tg.SendText(testUser, "/scan")
+	tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ: Черепахи")
+	if ma := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ: MA Crossover"); !strings.Contains(ma.Text, "Проанализировано инструментов: 3 из 3") {
+		t.Errorf("MA scan = %q, want 3 analyzed instruments", ma.Text)
+	}
+
+	tg.SendText(testUser, "/scan nope")
+	tg.WaitMessage(t, "Неизвестная стратегия: nope")
+}
+
+func TestBotScanCircuitOpen(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Turtles.Enabled = true
+		enableMACrossover(cfg)
+		cfg.API.MaxRetries = 0
+		cfg.API.CircuitBreaker = config.CircuitBreakerConfig{
+			Enabled:          true,
+			FailureThreshold: 1,
+			OpenTimeout:      time.Hour,
+			HalfOpenRequests: 1,
+			ProbeInterval:    time.Hour,
+		}
+	})
+	tg := env.telegram
+
+	// Сбой размыкает цепь, и сканирование не получает даже списка инструментов
+	env.fetcher.FailNext(1, 503)
+	tg.SendText(testUser, "/instruments")
+	tg.WaitMessage(t, "Ошибка получения инструментов")
+
+	tg.SendText(testUser, "/scan turtle")
+	tg.WaitMessage(t, "Сканирование 'Черепахи' прервано: API недоступен")
+
+	tg.SendText(testUser, "/scan_ma")
+	msg := tg.WaitMessage(t, "Сканирование прервано: API недоступен")
+	if !strings.Contains(msg.Text, "Проанализировано инструментов: 0") {
+		t.Errorf("scan result = %q, want 0 instruments", msg.Text)
+	}
+
+	// Бот продолжает отвечать после прерванных сканирований
+	tg.SendText(testUser, "/scan_turtles")
+	tg.WaitMessage(t, "Сканирование прервано: API недоступен")
+}
+
+func TestBotMATestConversation(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		enableMACrossover(cfg)
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/ma_test")
+	tg.WaitMessage(t, "Введите тикер")
+
+	tg.SendText(testUser, "SBER")
+	tg.WaitMessage(t, "ОТЧЕТ ПО ТЕСТИРОВАНИЮ 'MA Crossover': SBER")
+	if photo := tg.WaitMessage(t, "📊 MA Crossover: SBER"); photo.Method != "sendPhoto" {
+		t.Errorf("chart method = %q, want sendPhoto", photo.Method)
+	}
+}
+
+func TestBotIndicators(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+	tg := env.telegram
+
+	tg.SendText(testUser, "/indicators")
+	tg.WaitMessage(t, "Использование: /indicators")
+
+	tg.SendText(testUser, "/indicators sber")
+	report := tg.WaitMessage(t, "ИНДИКАТОРЫ: SBER (24)")
+	for _, want := range []string{"SMA 20:", "SMA 200:", "EMA 26:", "RSI 14:", "MACD 12/26/9:", "Bollinger 20/2:"} {
+		if !strings.Contains(report.Text, want) {
+			t.Errorf("report = %q, want %q", report.Text, want)
+		}
+	}
+	if strings.Contains(report.Text, "недостаточно данных") {
+		t.Errorf("report = %q, want all indicators calculated", report.Text)
+	}
+}
+
+func TestBotChart(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), nil)
+	tg := env.telegram
+
+	tg.SendText(testUser, "/chart")
+	tg.WaitMessage(t, "Использование: /chart")
+
+	tg.SendText(testUser, "/chart SBER 24 90d bb dc")
+	photo := tg.WaitMessage(t, "График SBER (24)")
+	if photo.Method != "sendPhoto" || photo.FileName != "chart.png" {
+		t.Errorf("chart = %s %q, want sendPhoto chart.png", photo.Method, photo.FileName)
+	}
+	if !strings.Contains(photo.Text, "свечей: ") {
+		t.Errorf("caption = %q, want candle count", photo.Text)
+	}
+
+	tg.SendText(testUser, "/chart SBER 24 nope")
+	tg.WaitMessage(t, "неверный период")
+}
+
+func TestBotMACD(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.MACD.Enabled = true
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/macd")
+	if info := tg.WaitMessage(t, "СТРАТЕГИИ MACD"); !strings.Contains(info.Text, "12 / 26 / 9") {
+		t.Errorf("macd = %q, want periods from technical section", info.Text)
+	}
+
+	tg.SendText(testUser, "/scan_macd")
+	if scan := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ: MACD"); !strings.Contains(scan.Text, "Проанализировано инструментов: 3 из 3") {
+		t.Errorf("MACD scan = %q, want 3 analyzed instruments", scan.Text)
+	}
+
+	tg.SendText(testUser, "/macd_test")
+	tg.WaitMessage(t, "Введите тикер")
+
+	tg.SendText(testUser, "SBER")
+	tg.WaitMessage(t, "ОТЧЕТ ПО ТЕСТИРОВАНИЮ 'MACD': SBER")
+}
+
+func TestBotScanBollinger(t *testing.T) {
+	env := startBot(t, fakefetcher.New(t), func(cfg *config.Config) {
+		cfg.Strategy.Bollinger.Enabled = true
+	})
+	tg := env.telegram
+
+	tg.SendText(testUser, "/strategies")
+	if list := tg.WaitMessage(t, "СТРАТЕГИИ"); !strings.Contains(list.Text, "SMA20 ± 2.0σ") {
+		t.Errorf("strategies = %q, want bands from technical section", list.Text)
+	}
+
+	tg.SendText(testUser, "/scan bollinger")
+	if scan := tg.WaitMessage(t, "РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ: Bollinger"); !strings.Contains(scan.Text, "Проанализировано инструментов: 3 из 3") {
+		t.Errorf("Bollinger scan = %q, want 3 analyzed instruments", scan.Text)
+	}
+}
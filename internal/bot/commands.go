@@ -44,6 +44,23 @@ func (b *Bot) registerCommands() {
 	b.commands["turtle_enable"] = b.handleTurtleEnable
 	b.commands["turtle_disable"] = b.handleTurtleDisable
 	b.commands["turtle_test"] = b.handleTurtleTest
+	b.commands["turtle_backtest"] = b.handleTurtleBacktest
+
+	// Технический анализ
+	b.commands["indicators"] = b.handleIndicators
+	b.commands["chart"] = b.handleChart
+
+	// Общие команды стратегий
+	b.commands["scan"] = b.handleScan
+	b.commands["strategies"] = b.handleStrategies
+	b.commands["equity"] = b.handleEquity
+	b.commands["signals_history"] = b.handleSignalsHistory
+	b.commands["watch"] = b.handleWatch
+	b.commands["unwatch"] = b.handleUnwatch
+	b.commands["watchlist"] = b.handleWatchlist
+	b.commands["alert"] = b.handleAlert
+	b.commands["alerts"] = b.handleAlerts
+	b.commands["export"] = b.handleExport
 
 	// Команды стратегии "MA"
 	b.commands["ma"] = b.handleMA
@@ -51,6 +68,12 @@ func (b *Bot) registerCommands() {
 	b.commands["scan_ma"] = b.handleScanMA
 	b.commands["ma_config"] = b.handleMAConfig
 	b.commands["ma_test"] = b.handleMATest
+	b.commands["ma_stats"] = b.handleMAStats
+
+	// Команды стратегии "MACD"
+	b.commands["macd"] = b.handleMACD
+	b.commands["scan_macd"] = b.handleScanMACD
+	b.commands["macd_test"] = b.handleMACDTest
 
 	// Команды управления ботом
 	b.commands["cancel"] = b.handleCancel
@@ -65,6 +88,8 @@ func (b *Bot) registerCommands() {
 	b.commands["broadcast"] = b.handleBroadcast
 	b.commands["debug"] = b.handleDebug
 	b.commands["system"] = b.handleSystem
+	b.commands["cache"] = b.handleCache
+	b.commands["schedule"] = b.handleSchedule
 }
 
 // setBotCommands устанавливает команды в меню Telegram
@@ -82,6 +107,20 @@ func (b *Bot) setBotCommands() {
 		{Command: "tables", Description: "Список таблиц"},
 		{Command: "timeframes", Description: "Доступные таймфреймы"},
 		{Command: "health", Description: "Проверка здоровья API"},
+		{Command: "indicators", Description: "Технические индикаторы инструмента"},
+		{Command: "chart", Description: "Свечной график с индикаторами"},
+
+		// Команды стратегий
+		{Command: "strategies", Description: "Список стратегий и параметров"},
+		{Command: "scan", Description: "Сканировать по включенным стратегиям"},
+		{Command: "equity", Description: "Размер счета для расчета позиций"},
+		{Command: "signals_history", Description: "Журнал сигналов фонового анализа"},
+		{Command: "watchlist", Description: "Список наблюдения"},
+		{Command: "watch", Description: "Добавить инструменты в список наблюдения"},
+		{Command: "unwatch", Description: "Удалить инструменты из списка наблюдения"},
+		{Command: "alert", Description: "Создать алерт по цене или индикатору"},
+		{Command: "alerts", Description: "Ваши алерты"},
+		{Command: "export", Description: "Выгрузка свечей и сигналов в CSV, XLSX, JSON"},
 
 		// Команды управления данными
 		{Command: "fetch", Description: "Запустить загрузку данных"},
@@ -95,6 +134,7 @@ func (b *Bot) setBotCommands() {
 			{Command: "turtle_signals", Description: "Текущие сигналы стратегии"},
 			{Command: "scan_turtles", Description: "Сканировать все инструменты"},
 			{Command: "turtle_stats", Description: "Статистика стратегии"},
+			{Command: "turtle_backtest", Description: "Бэктест стратегии на истории"},
 			{Command: "turtle_config", Description: "Настройки стратегии"},
 		}
 		commands = append(commands, strategiesCommands...)
@@ -107,15 +147,18 @@ func (b *Bot) setBotCommands() {
 			{Command: "scan_ma", Description: "Сканировать по MA Crossover"},
 			{Command: "ma_config", Description: "Настройки MA Crossover"},
 			{Command: "ma_test", Description: "Тестирование MA Crossover"},
+			{Command: "ma_stats", Description: "Статистика MA Crossover"},
 		}
-		commands = append(maCommands, maCommands...)
-		// Комментарий: Нужно добавить эти команды в глобальный список
-		// В родительском методе setBotCommands() должно быть что-то вроде:
-		// allCommands = append(allCommands, maCommands...)
-		//_ = maCommands // Используем переменную, чтобы избежать предупреждения
+		commands = append(commands, maCommands...)
+	}
 
-		// Если в структуре Bot есть поле для хранения команд, добавьте их:
-		// b.allCommands = append(b.allCommands, maCommands...)
+	if b.config.Strategy.MACD.Enabled {
+		macdCommands := []tgbotapi.BotCommand{
+			{Command: "macd", Description: "Анализ по стратегии MACD"},
+			{Command: "scan_macd", Description: "Сканировать по MACD"},
+			{Command: "macd_test", Description: "Тестирование MACD"},
+		}
+		commands = append(commands, macdCommands...)
 	}
 
 	// Админ команды - проверяем есть ли админы в чате
@@ -129,6 +172,8 @@ func (b *Bot) setBotCommands() {
 		{Command: "config", Description: "Показать конфигурацию"},
 		{Command: "restart", Description: "Перезапустить бота"},
 		{Command: "users", Description: "Управление пользователями"},
+		{Command: "cache", Description: "Кэш свечей"},
+		{Command: "schedule", Description: "Расписание фонового анализа"},
 	}
 	commands = append(commands, adminCommands...)
 
@@ -231,7 +276,22 @@ func (b *Bot) sendUnknownCommand(chatID int64, command string, userID int64) {
 	msg += "• /stats - Статистика данных\n"
 	msg += "• /tables - Список таблиц\n"
 	msg += "• /timeframes - Таймфреймы\n"
-	msg += "• /health - Проверка здоровья\n\n"
+	msg += "• /health - Проверка здоровья\n"
+	msg += "• /indicators ТИКЕР - Индикаторы\n"
+	msg += "• /chart ТИКЕР [ТФ] [период] - График\n\n"
+
+	// Общие команды стратегий
+	msg += "📋 Стратегии:\n"
+	msg += "• /strategies - Список стратегий\n"
+	msg += "• /scan [стратегия] - Сканировать\n"
+	msg += "• /equity [сумма] - Размер счета\n"
+	msg += "• /signals_history - Журнал сигналов\n"
+	msg += "• /watchlist - Список наблюдения\n"
+	msg += "• /watch ТИКЕР, /unwatch ТИКЕР - Изменить список наблюдения\n"
+	msg += "• /alert SBER > 300 - Создать алерт\n"
+	msg += "• /alerts - Ваши алерты\n"
+	msg += "• /export ТИКЕР|scan|signals [формат] - Выгрузка в файл\n"
+	msg += "• /macd - Стратегия MACD\n\n"
 
 	// Команды стратегии если включена
 	if b.config.Strategy.Turtles.Enabled {
@@ -240,6 +300,7 @@ func (b *Bot) sendUnknownCommand(chatID int64, command string, userID int64) {
 		msg += "• /turtle_signals - Текущие сигналы\n"
 		msg += "• /scan_turtles - Сканировать\n"
 		msg += "• /turtle_stats - Статистика\n"
+		msg += "• /turtle_backtest - Бэктест на истории\n"
 		msg += "• /turtle_config - Настройки\n\n"
 	}
 
@@ -253,6 +314,8 @@ func (b *Bot) sendUnknownCommand(chatID int64, command string, userID int64) {
 		msg += "• /config - Конфигурация\n"
 		msg += "• /log - Логи\n"
 		msg += "• /users - Пользователи\n"
+		msg += "• /cache - Кэш свечей\n"
+		msg += "• /schedule - Расписание анализа\n"
 		msg += "• /broadcast - Рассылка\n"
 	}
 
@@ -324,6 +387,8 @@ func (b *Bot) handleCallbackQuery(update tgbotapi.Update) {
 	case strings.HasPrefix(data, "timeframe_"):
 		tf := strings.TrimPrefix(data, "timeframe_")
 		b.handleTimeframeSelection(chatID, callback.From.ID, tf)
+	case strings.HasPrefix(data, "candles_"):
+		b.handleCandlesCallback(chatID, callback.From.ID, callback.Message.MessageID, data)
 	case strings.HasPrefix(data, "cleanup_"):
 		daysStr := strings.TrimPrefix(data, "cleanup_")
 		days, _ := strconv.Atoi(daysStr)
@@ -334,12 +399,22 @@ func (b *Bot) handleCallbackQuery(update tgbotapi.Update) {
 		b.handleInstrumentCallback(chatID, data)
 	case strings.HasPrefix(data, "admin_"):
 		b.handleAdminCallback(chatID, data)
+	case strings.HasPrefix(data, "cache_"):
+		b.handleCacheCallback(chatID, callback.From.ID, data)
 	case strings.HasPrefix(data, "turtle_"):
 		b.handleTurtleCallback(chatID, callback.From.ID, data)
+	case strings.HasPrefix(data, "macd_"):
+		b.handleMACDCallback(chatID, callback.From.ID, data)
 	case strings.HasPrefix(data, "ma_"):
 		b.handleMACallback(chatID, callback.From.ID, data)
+	case strings.HasPrefix(data, "alert_"):
+		b.handleAlertCallback(chatID, callback.From.ID, data)
+	case strings.HasPrefix(data, "watch_"):
+		b.handleWatchCallback(chatID, callback.From.ID, data)
 	case strings.HasPrefix(data, "strategy_"):
 		b.handleStrategyCallback(chatID, callback.From.ID, data)
+	case strings.HasPrefix(data, "export_"):
+		b.handleExportCallback(chatID, data)
 	case strings.HasPrefix(data, "help_"):
 		b.handleHelpCallback(chatID, data)
 	}
@@ -414,6 +489,10 @@ func (b *Bot) handleCommandStep(update tgbotapi.Update, state *UserState) {
 		b.handleRemoveInstrumentStep(chatID, userID, state, text)
 	case "turtle_test":
 		b.handleTurtleTestStep(chatID, userID, state, text)
+	case "ma_test":
+		b.handleStrategyTestStep(chatID, userID, "ma_crossover", text)
+	case "macd_test":
+		b.handleStrategyTestStep(chatID, userID, "macd", text)
 	case "broadcast":
 		b.handleBroadcastStep(chatID, state, text)
 	default:
@@ -609,10 +688,11 @@ func (b *Bot) sendHelpMenu(chatID int64) {
 			tgbotapi.NewInlineKeyboardButtonData("📈 Стратегия", "help_strategy"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 Все стратегии", "help_strategies"),
 			tgbotapi.NewInlineKeyboardButtonData("⚙️ Управление", "help_management"),
-			tgbotapi.NewInlineKeyboardButtonData("👑 Админ", "help_admin"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👑 Админ", "help_admin"),
 			tgbotapi.NewInlineKeyboardButtonData("❓ Подробная справка", "help_full"),
 		),
 	)
@@ -674,6 +754,16 @@ func (b *Bot) handleMACallback(chatID, userID int64, data string) {
 			},
 		}
 		b.handleMATest(update)
+	case "stats":
+		update := tgbotapi.Update{
+			CallbackQuery: &tgbotapi.CallbackQuery{
+				From: &tgbotapi.User{ID: userID},
+				Message: &tgbotapi.Message{
+					Chat: &tgbotapi.Chat{ID: chatID},
+				},
+			},
+		}
+		b.handleMAStats(update)
 	case "set_fast_9":
 		b.setMAFastPeriod(chatID, 9)
 	case "set_fast_12":
@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxEquity верхняя граница размера счета, задаваемого командой /equity, ₽
+const maxEquity = 1e12
+
+// handleEquity обработчик команды /equity [СУММА [МАКС%] | reset] - размер счета
+// пользователя для расчета позиций
+func (b *Bot) handleEquity(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendFormattedMessage(chatID, b.formatAccount(userID))
+	}
+
+	if strings.EqualFold(args[0], "reset") {
+		if err := b.store.UpdateUser(userID, func(settings *storage.UserSettings) {
+			settings.Equity = 0
+			settings.MaxPositionPercent = 0
+		}); err != nil {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка сохранения настроек: %v", err))
+		}
+		return b.sendFormattedMessage(chatID, "✅ Размер счета сброшен к значению по умолчанию.\n\n"+b.formatAccount(userID))
+	}
+
+	equity, err := parseAmount(args[0])
+	if err != nil || equity <= 0 || equity > maxEquity {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный размер счета: %s\n\nУкажите сумму до %.0f₽, например: /equity 500000", args[0], maxEquity))
+	}
+
+	var maxPercent float64
+	if len(args) > 1 {
+		maxPercent, err = parseAmount(strings.TrimSuffix(args[1], "%"))
+		if err != nil || maxPercent <= 0 || maxPercent > 100 {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверная доля позиции: %s\n\nУкажите процент от 0 до 100, например: /equity 500000 20", args[1]))
+		}
+	}
+
+	if err := b.store.UpdateUser(userID, func(settings *storage.UserSettings) {
+		settings.Equity = equity
+		if maxPercent > 0 {
+			settings.MaxPositionPercent = maxPercent
+		}
+	}); err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка сохранения настроек: %v", err))
+	}
+
+	return b.sendFormattedMessage(chatID, "✅ Размер счета сохранен.\n\n"+b.formatAccount(userID))
+}
+
+// formatAccount формирует описание счета пользователя
+func (b *Bot) formatAccount(userID int64) string {
+	account := b.accountFor(userID)
+	settings := b.store.User(userID)
+
+	source := "из конфигурации"
+	if settings.Equity > 0 {
+		source = "личный"
+	}
+
+	msg := "💰 РАЗМЕР СЧЕТА\n\n"
+	msg += fmt.Sprintf("• Счет: %.0f₽ (%s)\n", account.Equity, source)
+	if account.MaxPositionPercent > 0 {
+		msg += fmt.Sprintf("• Макс. позиция: %.0f%% счета (%.0f₽)\n", account.MaxPositionPercent,
+			account.Equity*account.MaxPositionPercent/100)
+	} else {
+		msg += "• Макс. позиция: без ограничения\n"
+	}
+	msg += "\nРазмер позиции в сигналах рассчитывается от риска на сделку и округляется вниз до целых лотов.\n\n"
+	msg += "Использование:\n"
+	msg += "• /equity 500000 - задать размер счета\n"
+	msg += "• /equity 500000 20 - счет и макс. доля позиции в %\n"
+	msg += "• /equity reset - вернуть значение из конфигурации"
+
+	return msg
+}
+
+// accountFor возвращает счет для расчета позиций: настройки из конфигурации,
+// переопределенные личными настройками пользователя
+func (b *Bot) accountFor(userID int64) analysis.Account {
+	account := b.defaultAccount()
+
+	settings := b.store.User(userID)
+	if settings.Equity > 0 {
+		account.Equity = settings.Equity
+	}
+	if settings.MaxPositionPercent > 0 {
+		account.MaxPositionPercent = settings.MaxPositionPercent
+	}
+
+	return account
+}
+
+// defaultAccount счет из секции account конфигурации
+func (b *Bot) defaultAccount() analysis.Account {
+	account := analysis.Account{
+		Equity:             b.config.Account.Equity,
+		MaxPositionPercent: b.config.Account.MaxPositionPercent,
+	}
+	if account.Equity <= 0 {
+		account.Equity = analysis.DefaultEquity
+	}
+	return account
+}
+
+// lotSizeRetry пауза перед повторным запросом размера лота после неудачи: не
+// меньше длительности сканирования, чтобы при недоступном источнике не
+// запрашивать лот заново для каждого сигнала
+const lotSizeRetry = 10 * time.Minute
+
+// lotSize возвращает размер лота инструмента или 0, если он неизвестен.
+// Размер кэшируется, неудачный запрос - на lotSizeRetry.
+func (b *Bot) lotSize(ctx context.Context, instrument string) int {
+	b.mu.RLock()
+	lotSize, ok := b.lotSizes[instrument]
+	missedAt, missed := b.lotMisses[instrument]
+	b.mu.RUnlock()
+	if ok {
+		return lotSize
+	}
+	if missed && time.Since(missedAt) < lotSizeRetry {
+		return 0
+	}
+
+	info, err := b.source.GetInstrumentInfo(ctx, instrument)
+	if err == nil && info.LotSize <= 0 {
+		err = fmt.Errorf("источник не сообщает размер лота")
+	}
+	if err != nil {
+		b.logger.Warn("Размер лота недоступен, позиция рассчитана без лотов",
+			"instrument", instrument,
+			"error", err)
+		b.mu.Lock()
+		b.lotMisses[instrument] = time.Now()
+		b.mu.Unlock()
+		return 0
+	}
+
+	b.mu.Lock()
+	b.lotSizes[instrument] = info.LotSize
+	delete(b.lotMisses, instrument)
+	b.mu.Unlock()
+
+	return info.LotSize
+}
+
+// sizeSignals пересчитывает размер позиций сигналов под счет и размер лота инструмента
+func (b *Bot) sizeSignals(ctx context.Context, signals []analysis.Signal, account analysis.Account) {
+	for i := range signals {
+		if signals[i].RiskPerTrade <= 0 {
+			continue
+		}
+		account.SizePosition(&signals[i], b.lotSize(ctx, signals[i].Instrument))
+	}
+}
+
+// formatPosition размер позиции сигнала: штуки, лоты и стоимость
+func formatPosition(signal analysis.Signal) string {
+	if signal.LotSizeUnknown {
+		return fmt.Sprintf("до %.0f шт., ⚠️ размер лота неизвестен - проверьте лот перед сделкой", signal.PositionSize)
+	}
+	if signal.Lots == 0 {
+		return "меньше 1 лота при текущем размере счета"
+	}
+	if signal.LotSize > 1 {
+		return fmt.Sprintf("%.0f шт. (%d лот. по %d) на %.0f₽", signal.PositionSize, signal.Lots, signal.LotSize, signal.Cost)
+	}
+	return fmt.Sprintf("%.0f шт. на %.0f₽", signal.PositionSize, signal.Cost)
+}
+
+// parseAmount разбирает сумму, допуская разделитель разрядов "_" и десятичную запятую.
+// NaN и бесконечность не принимаются: такие значения нельзя сохранить в JSON.
+func parseAmount(text string) (float64, error) {
+	text = strings.ReplaceAll(text, "_", "")
+	text = strings.ReplaceAll(text, ",", ".")
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("неверное число %q", text)
+	}
+	return value, nil
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -382,3 +383,128 @@ func (b *Bot) getUserActivityInfo(userID int64) string {
 	}
 	return "(неактивен)"
 }
+
+// handleCache обработчик команды /cache: статистика кэша свечей и очистка.
+// Использование: /cache, /cache purge [ТИКЕР] [ТАЙМФРЕЙМ]
+func (b *Bot) handleCache(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	if !b.isAdmin(userID) {
+		return b.sendFormattedMessage(chatID, "❌ Управление кэшем доступно только администраторам")
+	}
+
+	if b.cache == nil {
+		return b.sendFormattedMessage(chatID, "💾 Кэш свечей выключен\n\nВключите его в секции cache файла config.yaml")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) > 0 && strings.EqualFold(args[0], "purge") {
+		var instrument, timeframe string
+		if len(args) > 1 {
+			instrument = b.normalizeInstrument(args[1])
+			if !b.isValidInstrument(instrument) {
+				return b.sendFormattedMessage(chatID, "❌ Неверный формат тикера")
+			}
+		}
+		if len(args) > 2 {
+			timeframe = args[2]
+		}
+		return b.purgeCache(chatID, instrument, timeframe)
+	}
+
+	return b.sendCacheStats(chatID)
+}
+
+// sendCacheStats отправляет статистику кэша свечей
+func (b *Bot) sendCacheStats(chatID int64) error {
+	stats, err := b.cache.Stats()
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка чтения кэша: %v", err))
+	}
+
+	var totalCandles int
+	var totalSize int64
+	for _, entry := range stats {
+		totalCandles += entry.Candles
+		totalSize += entry.SizeBytes
+	}
+
+	msg := "💾 КЭШ СВЕЧЕЙ\n\n"
+	msg += fmt.Sprintf("• Директория: %s\n", b.cache.Dir())
+	msg += fmt.Sprintf("• Записей: %d\n", len(stats))
+	msg += fmt.Sprintf("• Свечей: %d\n", totalCandles)
+	msg += fmt.Sprintf("• Размер: %.1f КБ\n\n", float64(totalSize)/1024)
+
+	const maxEntries = 30
+	for i, entry := range stats {
+		if i >= maxEntries {
+			msg += fmt.Sprintf("... и ещё %d записей\n", len(stats)-maxEntries)
+			break
+		}
+		msg += fmt.Sprintf("• %s [%s]: %d свечей с %s", entry.Instrument, entry.Timeframe, entry.Candles, entry.CoveredFrom)
+		if !entry.LastCandle.IsZero() {
+			msg += fmt.Sprintf(", последняя %s", entry.LastCandle.Format("02.01.2006 15:04"))
+		}
+		msg += "\n"
+	}
+
+	msg += "\n💡 /cache purge [ТИКЕР] [ТАЙМФРЕЙМ] - очистить кэш"
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "cache_stats"),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Очистить всё", "cache_purge"),
+		),
+	)
+
+	return b.sendMessageWithKeyboard(chatID, msg, keyboard)
+}
+
+// purgeCache удаляет записи кэша и сообщает результат
+func (b *Bot) purgeCache(chatID int64, instrument, timeframe string) error {
+	removed, err := b.cache.Purge(instrument, timeframe)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка очистки кэша: %v", err))
+	}
+
+	b.logger.Info("Кэш свечей очищен",
+		"instrument", instrument,
+		"timeframe", timeframe,
+		"removed", removed)
+
+	target := "весь кэш"
+	if instrument != "" {
+		target = instrument
+		if timeframe != "" {
+			target += " [" + timeframe + "]"
+		}
+	}
+
+	return b.sendFormattedMessage(chatID, fmt.Sprintf("🗑 Очищено: %s\nУдалено записей: %d", target, removed))
+}
+
+// handleCacheCallback обработка cache_ callback
+func (b *Bot) handleCacheCallback(chatID, userID int64, data string) {
+	if !b.isAdmin(userID) {
+		b.sendFormattedMessage(chatID, "❌ Управление кэшем доступно только администраторам")
+		return
+	}
+	if b.cache == nil {
+		return
+	}
+
+	switch data {
+	case "cache_stats":
+		b.sendCacheStats(chatID)
+	case "cache_purge":
+		b.purgeCache(chatID, "", "")
+	}
+}
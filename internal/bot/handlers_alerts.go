@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// minAlertCooldown минимальная пауза повторяющегося алерта
+const minAlertCooldown = time.Minute
+
+// alertUsage справка по команде /alert
+const alertUsage = "🔔 Использование: /alert ТИКЕР [показатель] >|<|>=|<= значение [repeat [пауза]]\n\n" +
+	"Показатели (по дневным свечам):\n" +
+	"• price - цена (по умолчанию): /alert SBER > 300\n" +
+	"• rsi14 - RSI: /alert GAZP rsi14 < 30\n" +
+	"• sma50, ema20 - скользящие средние: /alert SBER sma50 > 250\n" +
+	"• change1d - изменение цены за N дней, %: /alert LKOH change1d > 5%\n" +
+	"• volume - объем текущей свечи\n\n" +
+	"Алерт срабатывает, когда показатель пересекает порог. По умолчанию алерт разовый и удаляется после срабатывания. " +
+	"С repeat срабатывает на каждом новом пересечении, но не чаще чем раз в паузу: /alert SBER < 250 repeat 4h\n\n" +
+	"Ваши алерты: /alerts"
+
+// handleAlert обработчик команды /alert ТИКЕР условие [repeat [пауза]] - создать алерт
+func (b *Bot) handleAlert(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	if !b.config.Alerts.Enabled {
+		return b.sendFormattedMessage(chatID, "❌ Алерты отключены в конфигурации (alerts.enabled)")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) < 2 {
+		return b.sendFormattedMessage(chatID, alertUsage)
+	}
+
+	instrument := b.normalizeInstrument(args[0])
+	if !b.isValidInstrument(instrument) {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный формат тикера: %s", args[0]))
+	}
+
+	alert := storage.Alert{
+		UserID:     userID,
+		Instrument: instrument,
+		CreatedAt:  time.Now(),
+	}
+
+	conditionArgs := args[1:]
+	for i, arg := range conditionArgs {
+		if !strings.EqualFold(arg, "repeat") {
+			continue
+		}
+		alert.Recurring = true
+		alert.Cooldown = b.config.Alerts.Cooldown
+		switch rest := conditionArgs[i+1:]; len(rest) {
+		case 0:
+		case 1:
+			cooldown, err := time.ParseDuration(rest[0])
+			if err != nil || cooldown < minAlertCooldown {
+				return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверная пауза %q: укажите не меньше 1m, например 30m, 4h, 24h", rest[0]))
+			}
+			alert.Cooldown = cooldown
+		default:
+			return b.sendFormattedMessage(chatID, alertUsage)
+		}
+		conditionArgs = conditionArgs[:i]
+		break
+	}
+
+	cond, err := analysis.ParseAlertCondition(strings.Join(conditionArgs, " "))
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v\n\n%s", err, alertUsage))
+	}
+	alert.Condition = cond.String()
+
+	if limit := b.config.Alerts.MaxPerUser; limit > 0 && len(b.store.Alerts(userID)) >= limit {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Не больше %d алертов. Удалите ненужные: /alerts", limit))
+	}
+
+	// Текущее значение показателя: от него отсчитывается пересечение порога, и
+	// пользователь видит, насколько далеко до условия
+	current := ""
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if candles, err := b.alertCandles(ctx, instrument, cond.Bars()); err == nil {
+		if value, met, err := cond.Evaluate(candles); err == nil {
+			alert.Met = &met
+			current = fmt.Sprintf("📍 Сейчас: %s", formatAlertValue(cond, value))
+			if met {
+				current += " - условие уже выполнено, уведомление придет при следующем пересечении порога"
+			}
+			current += "\n"
+		}
+	}
+
+	alert, err = b.store.AddAlert(alert)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка сохранения алерта: %v", err))
+	}
+
+	msg := fmt.Sprintf("✅ Алерт #%d создан\n\n", alert.ID)
+	msg += fmt.Sprintf("📊 %s: %s\n", alert.Instrument, alertConditionText(cond))
+	msg += fmt.Sprintf("🔁 %s\n", alertRepeatText(alert))
+	msg += fmt.Sprintf("⏱ Проверка: %s\n", b.alertsScheduleText())
+	msg += current
+
+	msg += "\n📋 Ваши алерты: /alerts"
+
+	return b.sendFormattedMessage(chatID, msg)
+}
+
+// alertRepeatText разовый или повторяющийся алерт
+func alertRepeatText(alert storage.Alert) string {
+	if !alert.Recurring {
+		return "разовый"
+	}
+	return "повторяющийся, пауза " + shortDuration(alert.Cooldown)
+}
+
+// alertsScheduleText расписание проверки алертов для пользователя
+func (b *Bot) alertsScheduleText() string {
+	text := b.config.Alerts.CheckSchedule + " (MSK)"
+	if b.config.Alerts.TradingOnly {
+		text += ", только во время торгов"
+	}
+	return text
+}
+
+// handleAlerts обработчик команды /alerts - алерты пользователя с кнопками удаления
+func (b *Bot) handleAlerts(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	return b.sendAlerts(chatID, userID)
+}
+
+// sendAlerts отправляет список алертов пользователя
+func (b *Bot) sendAlerts(chatID, userID int64) error {
+	alerts := b.store.Alerts(userID)
+	if len(alerts) == 0 {
+		return b.sendFormattedMessage(chatID, "📭 Алертов нет\n\n"+alertUsage)
+	}
+
+	msg := fmt.Sprintf("🔔 ВАШИ АЛЕРТЫ (%d)\n\n", len(alerts))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, alert := range alerts {
+		condition := alert.Condition
+		if cond, err := analysis.ParseAlertCondition(alert.Condition); err == nil {
+			condition = alertConditionText(cond)
+		}
+
+		msg += fmt.Sprintf("#%d %s: %s (%s)\n", alert.ID, alert.Instrument, condition, alertRepeatText(alert))
+		if alert.Triggers > 0 {
+			msg += fmt.Sprintf("   Сработал: %d раз, последний %s\n", alert.Triggers, alert.LastTriggered.Format("02.01 15:04"))
+		}
+
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🗑 #%d %s", alert.ID, alert.Instrument), fmt.Sprintf("alert_del_%d", alert.ID)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить все", "alert_clear"),
+	))
+
+	msg += fmt.Sprintf("\n⏱ Проверка: %s\n", b.alertsScheduleText())
+	msg += "💡 Новый алерт: /alert SBER > 300"
+
+	return b.sendMessageWithKeyboard(chatID, msg, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// handleAlertCallback обработка кнопок удаления алертов
+func (b *Bot) handleAlertCallback(chatID, userID int64, data string) {
+	var ids []int64
+	switch {
+	case data == "alert_clear":
+	case strings.HasPrefix(data, "alert_del_"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(data, "alert_del_"), 10, 64)
+		if err != nil {
+			return
+		}
+		ids = append(ids, id)
+	default:
+		return
+	}
+
+	deleted, err := b.store.DeleteAlerts(userID, ids...)
+	if err != nil {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка удаления алерта: %v", err))
+		return
+	}
+	if deleted == 0 {
+		b.sendFormattedMessage(chatID, "ℹ️ Алерт уже удален")
+	} else {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("🗑 Удалено алертов: %d", deleted))
+	}
+
+	if len(b.store.Alerts(userID)) > 0 {
+		b.sendAlerts(chatID, userID)
+	}
+}
@@ -89,6 +89,8 @@ func (b *Bot) handleHelp(update tgbotapi.Update) error {
 		msg += "• /log - Просмотр логов\n"
 		msg += "• /restart - Перезапустить бота\n"
 		msg += "• /users - Управление пользователями\n"
+		msg += "• /cache - Кэш свечей (статистика, очистка)\n"
+		msg += "• /schedule - Расписание фонового анализа и ближайшие запуски\n"
 		msg += "• /broadcast - Рассылка сообщений\n"
 		msg += "• /debug - Режим отладки\n"
 		msg += "• /system - Системная информация\n"
@@ -97,7 +99,7 @@ func (b *Bot) handleHelp(update tgbotapi.Update) error {
 	msg += "\n💡 КАК ИСПОЛЬЗОВАТЬ:\n"
 	msg += "1. Получить список инструментов: /instruments\n"
 	msg += "2. Получить свечи: /candles → выберите инструмент → таймфрейм → период\n"
-	msg += "3. Проверить сигналы: /turtle_signals\n"
+	msg += "3. Проверить сигналы: /scan или /turtle_signals (список стратегий: /strategies)\n"
 	msg += "4. Загрузить данные: /fetch\n\n"
 
 	msg += "📱 ТЕКСТОВЫЕ КОМАНДЫ:\n"
@@ -153,6 +155,7 @@ func (b *Bot) handleStatus(update tgbotapi.Update) error {
 	if err != nil {
 		msg += "🔌 API СЕРВЕР: 🔴 НЕДОСТУПЕН\n"
 		msg += fmt.Sprintf("• Ошибка: %v\n", err)
+		msg += b.formatCircuitState()
 	} else {
 		msg += "🔌 API СЕРВЕР: 🟢 ДОСТУПЕН\n"
 		if status, ok := health["status"].(string); ok {
@@ -161,6 +164,7 @@ func (b *Bot) handleStatus(update tgbotapi.Update) error {
 		if uptime, ok := health["uptime"].(string); ok {
 			msg += fmt.Sprintf("• Uptime: %s\n", uptime)
 		}
+		msg += b.formatCircuitState()
 
 		// Получаем статистику если API доступен
 		stats, err := b.apiClient.GetStats(ctx)
@@ -213,7 +217,7 @@ func (b *Bot) handleInstruments(update tgbotapi.Update) error {
 		return err
 	}
 
-	instruments, err := b.apiClient.GetInstruments(context.Background())
+	instruments, err := b.source.GetInstruments(context.Background())
 	if err != nil {
 		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка получения инструментов: %v", err))
 	}
@@ -343,7 +347,11 @@ func (b *Bot) handleHealth(update tgbotapi.Update) error {
 
 	health, err := b.apiClient.HealthCheck(context.Background())
 	if err != nil {
-		return b.sendFormattedMessage(chatID, "❌ API сервер недоступен")
+		msg := "❌ API сервер недоступен\n"
+		if state := b.formatCircuitState(); state != "" {
+			msg += "\n🔌 Состояние клиента:\n" + state
+		}
+		return b.sendFormattedMessage(chatID, msg)
 	}
 
 	msg := "🏥 Проверка здоровья:\n\n"
@@ -351,6 +359,9 @@ func (b *Bot) handleHealth(update tgbotapi.Update) error {
 	msg += fmt.Sprintf("⏱ Uptime: %s\n", health["uptime"])
 	msg += fmt.Sprintf("🔋 Версия: %s\n", health["version"])
 	msg += fmt.Sprintf("🕒 Время сервера: %s\n", health["timestamp"])
+	if state := b.formatCircuitState(); state != "" {
+		msg += "\n🔌 Состояние клиента:\n" + state
+	}
 
 	if stats, ok := health["stats"].(map[string]interface{}); ok {
 		msg += "\n📊 Статистика:\n"
@@ -378,8 +389,8 @@ func (b *Bot) handleTimeframes(update tgbotapi.Update) error {
 
 	msg := "⏱ Доступные таймфреймы:\n\n"
 	for _, tf := range timeframes {
-		msg += fmt.Sprintf("• %s (%s)\n", tf["code"], tf["display_name"])
-		msg += fmt.Sprintf("  %s\n\n", tf["description"])
+		msg += fmt.Sprintf("• %s (%s)\n", tf.Code, tf.DisplayName)
+		msg += fmt.Sprintf("  %s\n\n", tf.Description)
 	}
 
 	return b.sendFormattedMessage(chatID, msg)
@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/export"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Параметры вывода /candles
+const (
+	candlesPageSize = 20   // Строк таблицы на странице
+	candlesMaxRows  = 5000 // Больше свечей не храним для листания и выгрузки
+)
+
+// candlesView выборка свечей пользователя для листания, выгрузки и графика
+type candlesView struct {
+	Instrument string
+	Timeframe  string
+	From, To   time.Time
+	Candles    []api.Candle
+	Skipped    int // Сколько ранних свечей отброшено сверх candlesMaxRows
+}
+
+// pages количество страниц таблицы
+func (v *candlesView) pages() int {
+	return (len(v.Candles) + candlesPageSize - 1) / candlesPageSize
+}
+
+// showCandles загружает свечи за период и отправляет последнюю страницу таблицы
+func (b *Bot) showCandles(chatID, userID int64, instrument, timeframe string, from, to time.Time) {
+	b.sendTypingAction(chatID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	candles, err := b.candles.GetCandles(ctx, instrument, timeframe, from.Format(periodDateLayout), to.Format(periodDateLayout))
+	if err != nil {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка получения свечей %s: %v", instrument, err))
+		return
+	}
+	if len(candles) == 0 {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("📭 Нет свечей %s (%s) за %s - %s",
+			instrument, timeframe, from.Format("02.01.2006"), to.Format("02.01.2006")))
+		return
+	}
+
+	view := &candlesView{
+		Instrument: instrument,
+		Timeframe:  timeframe,
+		From:       from,
+		To:         to,
+		Candles:    candles,
+	}
+	if len(candles) > candlesMaxRows {
+		view.Skipped = len(candles) - candlesMaxRows
+		view.Candles = candles[view.Skipped:]
+	}
+
+	b.mu.Lock()
+	b.candleViews[userID] = view
+	b.mu.Unlock()
+
+	page := view.pages() - 1
+	b.sendHTMLWithKeyboard(chatID, formatCandlesPage(view, page), candlesKeyboard(view, page))
+}
+
+// formatCandlesPage сводка по выборке и страница таблицы OHLCV
+func formatCandlesPage(view *candlesView, page int) string {
+	candles := view.Candles
+	first, last := candles[0], candles[len(candles)-1]
+
+	high, low := first, first
+	volume := 0.0
+	for _, candle := range candles {
+		if candle.High > high.High {
+			high = candle
+		}
+		if candle.Low < low.Low {
+			low = candle
+		}
+		volume += candle.Volume
+	}
+
+	decimals := priceDecimals(candles)
+	price := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', decimals, 64)
+	}
+
+	msg := fmt.Sprintf("📈 СВЕЧИ: %s (%s)\n", view.Instrument, view.Timeframe)
+	msg += fmt.Sprintf("📅 %s - %s\n\n", view.From.Format("02.01.2006"), view.To.Format("02.01.2006"))
+
+	msg += fmt.Sprintf("📊 Свечей: %d", len(candles))
+	if view.Skipped > 0 {
+		msg += fmt.Sprintf(" (ранние %d не показаны)", view.Skipped)
+	}
+	msg += "\n"
+	msg += fmt.Sprintf("💰 %s₽ → %s₽ (%+.2f%%)\n", price(first.Open), price(last.Close), (last.Close/first.Open-1)*100)
+	msg += fmt.Sprintf("⬆️ Максимум: %s₽ (%s)\n", price(high.High), high.Begin.Format("02.01.2006"))
+	msg += fmt.Sprintf("⬇️ Минимум: %s₽ (%s)\n", price(low.Low), low.Begin.Format("02.01.2006"))
+	msg += fmt.Sprintf("📦 Объем: %s, в среднем %s за свечу\n\n", formatVolume(volume), formatVolume(volume/float64(len(candles))))
+
+	start := page * candlesPageSize
+	end := min(start+candlesPageSize, len(candles))
+	msg += "<pre>" + formatCandlesTable(candles[start:end], candleDateLayout(view.Timeframe), decimals) + "</pre>\n"
+	msg += fmt.Sprintf("Страница %d из %d, свечи %d-%d", page+1, view.pages(), start+1, end)
+
+	return msg
+}
+
+// formatCandlesTable моноширинная таблица OHLCV с выравниванием по столбцам
+func formatCandlesTable(candles []api.Candle, dateLayout string, decimals int) string {
+	header := []string{"Дата", "Откр", "Макс", "Мин", "Закр", "Объем"}
+	rows := [][]string{header}
+	for _, candle := range candles {
+		rows = append(rows, []string{
+			candle.Begin.Format(dateLayout),
+			strconv.FormatFloat(candle.Open, 'f', decimals, 64),
+			strconv.FormatFloat(candle.High, 'f', decimals, 64),
+			strconv.FormatFloat(candle.Low, 'f', decimals, 64),
+			strconv.FormatFloat(candle.Close, 'f', decimals, 64),
+			formatVolume(candle.Volume),
+		})
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == 0 {
+				// Дата выравнивается влево, числа - вправо
+				fmt.Fprintf(&sb, "%-*s", widths[i], cell)
+			} else {
+				fmt.Fprintf(&sb, " %*s", widths[i], cell)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// candlesKeyboard кнопки листания, выгрузки и графика
+func candlesKeyboard(view *candlesView, page int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav,
+			tgbotapi.NewInlineKeyboardButtonData("⏮", "candles_page_0"),
+			tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("candles_page_%d", page-1)),
+		)
+	}
+	if last := view.pages() - 1; page < last {
+		nav = append(nav,
+			tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("candles_page_%d", page+1)),
+			tgbotapi.NewInlineKeyboardButtonData("⏭", fmt.Sprintf("candles_page_%d", last)),
+		)
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows,
+		exportButtons("candles_export_"),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("📊 График", "candles_chart")),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleCandlesCallback листание таблицы свечей, выгрузка и график
+func (b *Bot) handleCandlesCallback(chatID, userID int64, messageID int, data string) {
+	b.mu.RLock()
+	view := b.candleViews[userID]
+	b.mu.RUnlock()
+
+	if view == nil {
+		b.sendFormattedMessage(chatID, "❌ Выборка свечей устарела. Повторите запрос: /candles")
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(data, "candles_page_"):
+		page, err := strconv.Atoi(strings.TrimPrefix(data, "candles_page_"))
+		if err != nil || page < 0 || page >= view.pages() {
+			return
+		}
+		b.editHTMLMessage(chatID, messageID, formatCandlesPage(view, page), candlesKeyboard(view, page))
+
+	case strings.HasPrefix(data, "candles_export_"):
+		format, ok := export.ParseFormat(strings.TrimPrefix(data, "candles_export_"))
+		if !ok {
+			return
+		}
+		b.sendExport(chatID, candlesTable(view.Instrument, view.Timeframe, view.Candles), format,
+			candlesFileName(view.Instrument, view.Timeframe, view.From, view.To),
+			fmt.Sprintf("📤 Свечи %s (%s): %d шт.", view.Instrument, view.Timeframe, len(view.Candles)))
+
+	case data == "candles_chart":
+		b.sendTypingAction(chatID)
+		b.sendIndicatorChart(chatID, view.Instrument, view.Timeframe, view.From, view.To, []string{"sma"})
+	}
+}
+
+// candleDateLayout формат времени свечи в таблице: для дневных и старших таймфреймов только дата
+func candleDateLayout(timeframe string) string {
+	if api.TimeframeDuration(timeframe) >= 24*time.Hour {
+		return "02.01.06"
+	}
+	return "02.01 15:04"
+}
+
+// priceDecimals знаков после запятой для цен: у дешевых бумаг больше
+func priceDecimals(candles []api.Candle) int {
+	low := candles[0].Low
+	for _, candle := range candles {
+		low = min(low, candle.Low)
+	}
+
+	switch {
+	case low < 1:
+		return 4
+	case low < 10:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// formatVolume объем в сокращенной записи: 950, 12.3K, 4.56M, 1.2B
+func formatVolume(volume float64) string {
+	switch {
+	case volume >= 1e9:
+		return strconv.FormatFloat(volume/1e9, 'f', 1, 64) + "B"
+	case volume >= 1e6:
+		return strconv.FormatFloat(volume/1e6, 'f', 2, 64) + "M"
+	case volume >= 1e3:
+		return strconv.FormatFloat(volume/1e3, 'f', 1, 64) + "K"
+	default:
+		return strconv.FormatFloat(volume, 'f', 0, 64)
+	}
+}
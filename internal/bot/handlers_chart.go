@@ -0,0 +1,329 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/chart"
+	"telegram-bot-moex/internal/indicators"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Параметры графиков
+const (
+	chartDefaultTimeframe = "24"
+	chartDefaultPeriod    = "90d"
+	chartMaxCandles       = 300 // Больше свечей на графике не различить
+	chartDonchianPeriod   = 20
+)
+
+// chartPalette цвета скользящих средних по порядку
+var chartPalette = []color.RGBA{chart.ColorBlue, chart.ColorOrange, chart.ColorPurple}
+
+// chartOverlays индикаторы, которые можно наложить на график командой /chart
+var chartOverlays = []string{"sma", "ema", "bb", "dc"}
+
+// chartUsage справка по команде /chart
+const chartUsage = "📊 Использование: /chart ТИКЕР [ТАЙМФРЕЙМ] [ПЕРИОД] [sma|ema|bb|dc]\n\n" +
+	"Примеры:\n" +
+	"• /chart SBER - дневные свечи за 90 дней со SMA\n" +
+	"• /chart SBER 24 90d\n" +
+	"• /chart GAZP 60 2w ema bb\n" +
+	"• /chart LKOH 7 1y dc\n\n" +
+	"Индикаторы: sma, ema - скользящие средние из секции technical, bb - полосы Боллинджера, dc - канал Дончиана\n" +
+	"Период: " + periodHelp
+
+// handleChart обработчик команды /chart ТИКЕР [ТАЙМФРЕЙМ] [ПЕРИОД] [индикаторы] - свечной график
+func (b *Bot) handleChart(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendFormattedMessage(chatID, chartUsage)
+	}
+
+	instrument := b.normalizeInstrument(args[0])
+	if !b.isValidInstrument(instrument) {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный тикер: %s", args[0]))
+	}
+
+	// Таймфрейм, период и индикаторы указываются в любом порядке
+	timeframe := chartDefaultTimeframe
+	from, to, _ := parsePeriod(chartDefaultPeriod, time.Now())
+	var overlays []string
+	for _, arg := range args[1:] {
+		name := strings.ToLower(arg)
+		if api.IsValidTimeframe(arg) {
+			timeframe = arg
+			continue
+		}
+		if containsString(chartOverlays, name) {
+			if !containsString(overlays, name) {
+				overlays = append(overlays, name)
+			}
+			continue
+		}
+		from, to, err = parsePeriod(arg, time.Now())
+		if err != nil {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v\n\n%s", err, chartUsage))
+		}
+	}
+	if len(overlays) == 0 {
+		overlays = []string{"sma"}
+	}
+
+	b.sendTypingAction(chatID)
+
+	return b.sendIndicatorChart(chatID, instrument, timeframe, from, to, overlays)
+}
+
+// sendIndicatorChart строит график свечей за период с индикаторами и отправляет его
+func (b *Bot) sendIndicatorChart(chatID int64, instrument, timeframe string, from, to time.Time, overlays []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, err := b.indicatorChart(ctx, instrument, timeframe, from, to, overlays)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка построения графика %s: %v", instrument, err))
+	}
+
+	data, err := c.PNG()
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка построения графика %s: %v", instrument, err))
+	}
+
+	first, last := c.Candles[0], c.Candles[len(c.Candles)-1]
+	caption := fmt.Sprintf("📊 График %s (%s)\n", instrument, timeframe)
+	caption += fmt.Sprintf("📅 %s - %s, свечей: %d\n", first.Begin.Format("02.01.2006"), last.Begin.Format("02.01.2006"), len(c.Candles))
+	caption += fmt.Sprintf("💰 Цена: %.2f₽ (%+.1f%% за период)", last.Close, (last.Close/first.Open-1)*100)
+
+	return b.sendPhoto(chatID, data, caption)
+}
+
+// indicatorChart строит график свечей за период с наложенными индикаторами. Индикаторы
+// считаются по истории с запасом до начала периода, чтобы линии начинались с первой свечи.
+func (b *Bot) indicatorChart(ctx context.Context, instrument, timeframe string, from, to time.Time, overlays []string) (*chart.Chart, error) {
+	cfg := b.config.Technical
+
+	// Запас истории для самого длинного индикатора
+	warmup := 0
+	for _, overlay := range overlays {
+		switch overlay {
+		case "sma":
+			for _, period := range cfg.SMA {
+				warmup = max(warmup, period)
+			}
+		case "ema":
+			for _, period := range cfg.EMA {
+				warmup = max(warmup, period)
+			}
+		case "bb":
+			warmup = max(warmup, cfg.BollingerPeriod)
+		case "dc":
+			warmup = max(warmup, chartDonchianPeriod)
+		}
+	}
+
+	start := from
+	if warmup > 0 {
+		start = from.AddDate(0, 0, -analysis.HistoryDaysFor(timeframe, warmup))
+	}
+	candles, err := b.candles.GetCandles(ctx, instrument, timeframe, start.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	closes := make([]float64, len(candles))
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i], highs[i], lows[i] = candle.Close, candle.High, candle.Low
+	}
+
+	c := &chart.Chart{
+		Title:   fmt.Sprintf("%s %s", instrument, timeframe),
+		Candles: candles,
+	}
+	for _, overlay := range overlays {
+		switch overlay {
+		case "sma", "ema":
+			periods, calc := cfg.SMA, indicators.SMA
+			if overlay == "ema" {
+				periods, calc = cfg.EMA, indicators.EMA
+			}
+			for _, period := range periods {
+				c.Lines = append(c.Lines, chart.Line{
+					Name:   fmt.Sprintf("%s %d", strings.ToUpper(overlay), period),
+					Values: calc(closes, period),
+					Color:  chartPalette[len(c.Lines)%len(chartPalette)],
+				})
+			}
+		case "bb":
+			if cfg.BollingerPeriod <= 0 {
+				continue
+			}
+			upper, middle, lower := indicators.Bollinger(closes, cfg.BollingerPeriod, float64(cfg.BollingerStd))
+			c.Lines = append(c.Lines,
+				chart.Line{Name: fmt.Sprintf("BB %d/%d", cfg.BollingerPeriod, cfg.BollingerStd), Values: upper, Color: chart.ColorGray},
+				chart.Line{Values: middle, Color: chart.ColorGray},
+				chart.Line{Values: lower, Color: chart.ColorGray},
+			)
+		case "dc":
+			upper, _, lower := indicators.Donchian(highs, lows, chartDonchianPeriod)
+			c.Lines = append(c.Lines,
+				chart.Line{Name: fmt.Sprintf("DC %d", chartDonchianPeriod), Values: upper, Color: chart.ColorPurple},
+				chart.Line{Values: lower, Color: chart.ColorPurple},
+			)
+		}
+	}
+
+	// Оставляем только запрошенный период
+	first := 0
+	for first < len(candles) && candles[first].Begin.Before(from) {
+		first++
+	}
+	cropChart(c, first)
+
+	if len(c.Candles) == 0 {
+		return nil, fmt.Errorf("нет свечей за период")
+	}
+	return c, nil
+}
+
+// cropChart убирает из графика свечи до first и оставляет не больше chartMaxCandles последних
+func cropChart(c *chart.Chart, first int) {
+	first = max(first, len(c.Candles)-chartMaxCandles)
+	if first <= 0 {
+		return
+	}
+	first = min(first, len(c.Candles))
+
+	c.Candles = c.Candles[first:]
+	for i := range c.Lines {
+		c.Lines[i].Values = c.Lines[i].Values[first:]
+	}
+}
+
+// sendStrategyChart отправляет график к отчету теста стратегии: свечи с линиями стратегии,
+// отметки сигналов и уровни стоп-лосса и тейк-профита последнего входа. Ошибки только
+// логируются - текстовый отчет уже отправлен.
+func (b *Bot) sendStrategyChart(chatID int64, strategy analysis.Strategy, instrument string, signals []analysis.Signal) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -strategy.HistoryDays())
+	candles, err := b.candles.GetCandles(ctx, instrument, strategy.Timeframe(), from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil || len(candles) == 0 {
+		b.logger.Warn("Нет свечей для графика стратегии",
+			"strategy", strategy.Name(),
+			"instrument", instrument,
+			"error", err)
+		return
+	}
+
+	c := &chart.Chart{
+		Title:   fmt.Sprintf("%s %s %s", instrument, strategy.Timeframe(), strategy.Name()),
+		Candles: candles,
+		Lines:   b.strategyChartLines(strategy.Name(), candles),
+	}
+	cropChart(c, 0)
+
+	var entry *analysis.Signal
+	for i, signal := range signals {
+		marker := chart.Marker{Time: signal.Timestamp, Price: signal.Price, Kind: chart.MarkerExit}
+		switch signal.SignalType {
+		case "entry_long":
+			marker.Kind = chart.MarkerLong
+		case "entry_short":
+			marker.Kind = chart.MarkerShort
+		case "no_signal":
+			continue
+		}
+		c.Markers = append(c.Markers, marker)
+
+		if strings.HasPrefix(signal.SignalType, "entry_") {
+			entry = &signals[i]
+		}
+	}
+	if entry != nil {
+		if entry.StopLoss > 0 {
+			c.Levels = append(c.Levels, chart.Level{Name: "SL", Price: entry.StopLoss, Color: chart.ColorDown})
+		}
+		if entry.TakeProfit > 0 {
+			c.Levels = append(c.Levels, chart.Level{Name: "TP", Price: entry.TakeProfit, Color: chart.ColorUp})
+		}
+	}
+
+	data, err := c.PNG()
+	if err != nil {
+		b.logger.Warn("Ошибка построения графика стратегии",
+			"strategy", strategy.Name(),
+			"instrument", instrument,
+			"error", err)
+		return
+	}
+
+	caption := fmt.Sprintf("📊 %s: %s (%s)", strategy.Title(), instrument, strategy.Timeframe())
+	if entry != nil {
+		caption += fmt.Sprintf("\n🎯 %s по %.2f₽", b.getSignalTypeText(entry.SignalType), entry.Price)
+	}
+	b.sendPhoto(chatID, data, caption)
+}
+
+// strategyChartLines линии, по которым стратегия принимает решения
+func (b *Bot) strategyChartLines(name string, candles []api.Candle) []chart.Line {
+	closes := make([]float64, len(candles))
+	highs := make([]float64, len(candles))
+	lows := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i], highs[i], lows[i] = candle.Close, candle.High, candle.Low
+	}
+
+	switch name {
+	case "turtle":
+		// Прорыв считается по предыдущим свечам, поэтому канал сдвинут на одну свечу
+		cfg := b.config.Strategy.Turtles
+		entryUpper, _, entryLower := indicators.Donchian(highs, lows, cfg.EntryBreakoutDays)
+		exitUpper, _, exitLower := indicators.Donchian(highs, lows, cfg.ExitBreakoutDays)
+		return []chart.Line{
+			{Name: fmt.Sprintf("DC %d", cfg.EntryBreakoutDays), Values: shiftSeries(entryUpper), Color: chart.ColorBlue},
+			{Values: shiftSeries(entryLower), Color: chart.ColorBlue},
+			{Name: fmt.Sprintf("DC %d", cfg.ExitBreakoutDays), Values: shiftSeries(exitUpper), Color: chart.ColorOrange},
+			{Values: shiftSeries(exitLower), Color: chart.ColorOrange},
+		}
+	case "ma_crossover":
+		cfg := b.config.Strategy.MACrossover
+		label, calc := "SMA", indicators.SMA
+		if cfg.UseEMA {
+			label, calc = "EMA", indicators.EMA
+		}
+		return []chart.Line{
+			{Name: fmt.Sprintf("%s %d", label, cfg.FastPeriod), Values: calc(closes, cfg.FastPeriod), Color: chart.ColorBlue},
+			{Name: fmt.Sprintf("%s %d", label, cfg.SlowPeriod), Values: calc(closes, cfg.SlowPeriod), Color: chart.ColorOrange},
+		}
+	}
+	return nil
+}
+
+// shiftSeries сдвигает ряд на одну свечу вперед: значение свечи i - значение свечи i-1
+func shiftSeries(series []float64) []float64 {
+	shifted := make([]float64, len(series))
+	for i := range shifted {
+		shifted[i] = math.NaN()
+		if i > 0 {
+			shifted[i] = series[i-1]
+		}
+	}
+	return shifted
+}
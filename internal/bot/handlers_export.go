@@ -0,0 +1,322 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/export"
+	"telegram-bot-moex/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Ограничения выгрузки
+const (
+	telegramMaxDocumentSize = 50 << 20 // Лимит Bot API на отправку файла
+	exportMaxRows           = 500000   // Больше строк не формируем даже в CSV
+	exportDefaultPeriod     = "1y"
+)
+
+// exportLabels подписи кнопок выгрузки
+var exportLabels = map[export.Format]string{
+	export.CSV:  "📄 CSV",
+	export.XLSX: "📗 XLSX",
+	export.JSON: "🧾 JSON",
+}
+
+// exportUsage справка по команде /export
+const exportUsage = "📤 Использование:\n" +
+	"• /export ТИКЕР [ТАЙМФРЕЙМ] [ПЕРИОД] [csv|xlsx|json] - свечи\n" +
+	"• /export scan [стратегия] [формат] - результаты последнего /scan в этом чате\n" +
+	"• /export signals [ТИКЕР|СТРАТЕГИЯ] [формат] - журнал сигналов\n\n" +
+	"Примеры:\n" +
+	"• /export SBER 24 2024-01-01:2024-06-30 csv\n" +
+	"• /export GAZP 60 2w xlsx\n" +
+	"• /export signals turtle json\n\n" +
+	"По умолчанию: дневные свечи за год, формат CSV\n" +
+	"Период: " + periodHelp
+
+// scanExport результаты последнего сканирования стратегии в чате
+type scanExport struct {
+	Strategy string
+	Time     time.Time
+	Signals  []analysis.Signal
+}
+
+// handleExport обработчик команды /export - выгрузка свечей, результатов сканирования и журнала сигналов
+func (b *Bot) handleExport(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	// Формат можно указать в любом месте
+	format := export.CSV
+	var args []string
+	for _, arg := range strings.Fields(update.Message.CommandArguments()) {
+		if f, ok := export.ParseFormat(arg); ok {
+			format = f
+			continue
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return b.sendFormattedMessage(chatID, exportUsage)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "scan":
+		return b.exportScan(chatID, args[1:], format)
+	case "signals":
+		return b.exportSignals(chatID, args[1:], format)
+	}
+	return b.exportCandles(chatID, args, format)
+}
+
+// exportCandles выгрузка свечей: ТИКЕР [ТАЙМФРЕЙМ] [ПЕРИОД]
+func (b *Bot) exportCandles(chatID int64, args []string, format export.Format) error {
+	instrument := b.normalizeInstrument(args[0])
+	if !b.isValidInstrument(instrument) {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный тикер: %s\n\n%s", args[0], exportUsage))
+	}
+
+	timeframe := "24"
+	from, to, _ := parsePeriod(exportDefaultPeriod, time.Now())
+	for _, arg := range args[1:] {
+		if api.IsValidTimeframe(arg) {
+			timeframe = arg
+			continue
+		}
+		var err error
+		from, to, err = parsePeriod(arg, time.Now())
+		if err != nil {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v\n\n%s", err, exportUsage))
+		}
+	}
+
+	b.sendTypingAction(chatID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	candles, err := b.candles.GetCandles(ctx, instrument, timeframe, from.Format(periodDateLayout), to.Format(periodDateLayout))
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка получения свечей %s: %v", instrument, err))
+	}
+	if len(candles) == 0 {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("📭 Нет свечей %s (%s) за %s - %s",
+			instrument, timeframe, from.Format("02.01.2006"), to.Format("02.01.2006")))
+	}
+
+	return b.sendExport(chatID, candlesTable(instrument, timeframe, candles), format,
+		candlesFileName(instrument, timeframe, from, to),
+		fmt.Sprintf("📤 Свечи %s (%s): %d шт., %s - %s", instrument, timeframe, len(candles),
+			from.Format("02.01.2006"), to.Format("02.01.2006")))
+}
+
+// exportScan выгрузка результатов последнего сканирования в чате: одной стратегии или всех
+func (b *Bot) exportScan(chatID int64, args []string, format export.Format) error {
+	strategy := ""
+	if len(args) > 0 {
+		strategy = strings.ToLower(args[0])
+		if _, ok := b.strategies.Get(strategy); !ok {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неизвестная стратегия: %s\n\nДоступные стратегии: %s",
+				args[0], strings.Join(b.strategies.Names(), ", ")))
+		}
+	}
+
+	b.mu.RLock()
+	var scans []scanExport
+	for name, scan := range b.scanExports[chatID] {
+		if strategy == "" || name == strategy {
+			scans = append(scans, scan)
+		}
+	}
+	b.mu.RUnlock()
+
+	if len(scans) == 0 {
+		return b.sendFormattedMessage(chatID, "📭 Нет результатов сканирования для выгрузки\n\nСначала выполните /scan")
+	}
+	sort.Slice(scans, func(i, j int) bool { return scans[i].Strategy < scans[j].Strategy })
+
+	count := 0
+	latest := scans[0].Time
+	for _, scan := range scans {
+		count += len(scan.Signals)
+		if scan.Time.After(latest) {
+			latest = scan.Time
+		}
+	}
+	if count == 0 {
+		return b.sendFormattedMessage(chatID, "📭 Последнее сканирование не нашло сигналов - выгружать нечего")
+	}
+
+	name := "scan"
+	if strategy != "" {
+		name += "_" + strategy
+	}
+	name += "_" + latest.Format("20060102-1504")
+
+	return b.sendExport(chatID, scanTable(scans), format, name,
+		fmt.Sprintf("📤 Результаты сканирования: %d сигналов, %s", count, latest.Format("02.01.2006 15:04")))
+}
+
+// exportSignals выгрузка журнала сигналов целиком с фильтром по стратегии и тикеру
+func (b *Bot) exportSignals(chatID int64, args []string, format export.Format) error {
+	var strategy, instrument string
+	for _, arg := range args {
+		if _, ok := b.strategies.Get(strings.ToLower(arg)); ok {
+			strategy = strings.ToLower(arg)
+			continue
+		}
+		instrument = b.normalizeInstrument(arg)
+		if !b.isValidInstrument(instrument) {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный тикер или стратегия: %s\n\nСтратегии: %s",
+				arg, strings.Join(b.strategies.Names(), ", ")))
+		}
+	}
+
+	history := b.store.SignalHistory(strategy, instrument, 0)
+	if len(history) == 0 {
+		return b.sendFormattedMessage(chatID, "📭 Сигналов в журнале нет\n\nВ журнал попадают сигналы на вход фонового анализа стратегий")
+	}
+
+	name := "signals"
+	for _, part := range []string{strategy, instrument} {
+		if part != "" {
+			name += "_" + part
+		}
+	}
+	name += "_" + time.Now().Format("20060102")
+
+	return b.sendExport(chatID, journalTable(history), format, name,
+		fmt.Sprintf("📤 Журнал сигналов: %d записей", len(history)))
+}
+
+// sendExport кодирует таблицу и отправляет документом name с расширением формата.
+// Слишком большие выборки и файлы сверх лимита Telegram не отправляются.
+func (b *Bot) sendExport(chatID int64, table *export.Table, format export.Format, name, caption string) error {
+	if len(table.Rows) > exportMaxRows {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Слишком много строк для выгрузки: %d (не больше %d). Сократите период или выберите старший таймфрейм",
+			len(table.Rows), exportMaxRows))
+	}
+
+	data, err := table.Encode(format)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка выгрузки: %v", err))
+	}
+	if len(data) > telegramMaxDocumentSize {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Файл слишком большой: %.1f МБ при лимите Telegram %d МБ. Сократите период или выберите другой формат",
+			float64(len(data))/(1<<20), telegramMaxDocumentSize>>20))
+	}
+
+	return b.sendDocumentData(chatID, name+format.Extension(), data, caption)
+}
+
+// exportButtons кнопки выгрузки во всех форматах, callback - prefix и формат
+func exportButtons(prefix string) []tgbotapi.InlineKeyboardButton {
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(export.Formats))
+	for _, format := range export.Formats {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(exportLabels[format], prefix+string(format)))
+	}
+	return buttons
+}
+
+// handleExportCallback кнопки выгрузки результатов сканирования: export_scan_СТРАТЕГИЯ_ФОРМАТ
+func (b *Bot) handleExportCallback(chatID int64, data string) {
+	rest, ok := strings.CutPrefix(data, "export_scan_")
+	if !ok {
+		return
+	}
+	i := strings.LastIndex(rest, "_")
+	if i < 0 {
+		return
+	}
+	format, ok := export.ParseFormat(rest[i+1:])
+	if !ok {
+		return
+	}
+
+	var args []string
+	if strategy := rest[:i]; strategy != "" {
+		args = append(args, strategy)
+	}
+	b.exportScan(chatID, args, format)
+}
+
+// recordScanExport запоминает результаты сканирования стратегии в чате для выгрузки
+func (b *Bot) recordScanExport(chatID int64, strategy string, signals []analysis.Signal) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.scanExports[chatID] == nil {
+		b.scanExports[chatID] = make(map[string]scanExport)
+	}
+	b.scanExports[chatID][strategy] = scanExport{
+		Strategy: strategy,
+		Time:     time.Now(),
+		Signals:  signals,
+	}
+}
+
+// candlesTable таблица свечей для выгрузки
+func candlesTable(instrument, timeframe string, candles []api.Candle) *export.Table {
+	table := &export.Table{
+		Name:    instrument + " " + timeframe,
+		Columns: []string{"begin", "open", "high", "low", "close", "volume"},
+		Rows:    make([][]any, 0, len(candles)),
+	}
+	for _, candle := range candles {
+		table.Rows = append(table.Rows, []any{candle.Begin, candle.Open, candle.High, candle.Low, candle.Close, candle.Volume})
+	}
+	return table
+}
+
+// candlesFileName имя файла выгрузки свечей без расширения: SBER_24_20240101-20240630
+func candlesFileName(instrument, timeframe string, from, to time.Time) string {
+	return fmt.Sprintf("%s_%s_%s-%s", instrument, timeframe, from.Format("20060102"), to.Format("20060102"))
+}
+
+// scanTable таблица сигналов сканирования для выгрузки
+func scanTable(scans []scanExport) *export.Table {
+	table := &export.Table{
+		Name: "scan",
+		Columns: []string{"strategy", "instrument", "signal_type", "time", "price", "stop_loss", "take_profit",
+			"confidence", "lot_size", "lots", "position_size", "cost", "system", "reason"},
+	}
+	for _, scan := range scans {
+		for _, signal := range scan.Signals {
+			table.Rows = append(table.Rows, []any{
+				scan.Strategy, signal.Instrument, signal.SignalType, signal.Timestamp,
+				signal.Price, signal.StopLoss, signal.TakeProfit, signal.Confidence,
+				signal.LotSize, signal.Lots, signal.PositionSize, signal.Cost,
+				signal.System, signal.Reason,
+			})
+		}
+	}
+	return table
+}
+
+// journalTable таблица записей журнала сигналов для выгрузки
+func journalTable(records []storage.SignalRecord) *export.Table {
+	table := &export.Table{
+		Name: "signals",
+		Columns: []string{"id", "strategy", "instrument", "signal_type", "bar_time", "price", "stop_loss", "take_profit",
+			"confidence", "status", "created_at", "last_seen", "closed_at", "exit_price", "exit_time", "outcome", "r_multiple"},
+		Rows: make([][]any, 0, len(records)),
+	}
+	for _, record := range records {
+		table.Rows = append(table.Rows, []any{
+			record.ID, record.Strategy, record.Instrument, record.SignalType, record.BarTime,
+			record.Price, record.StopLoss, record.TakeProfit, record.Confidence, string(record.Status),
+			record.CreatedAt, record.LastSeen, record.ClosedAt, record.ExitPrice, record.ExitTime,
+			record.Outcome, record.RMultiple,
+		})
+	}
+	return table
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/indicators"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleIndicators обработчик команды /indicators ТИКЕР [ТАЙМФРЕЙМ]
+func (b *Bot) handleIndicators(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		msg := "📐 ТЕХНИЧЕСКИЕ ИНДИКАТОРЫ\n\n"
+		msg += "Использование: /indicators ТИКЕР [ТАЙМФРЕЙМ]\n\n"
+		msg += "Примеры:\n"
+		msg += "• /indicators SBER - по дневным свечам\n"
+		msg += "• /indicators GAZP 60 - по часовым свечам\n\n"
+		msg += "Набор индикаторов задается в секции technical конфигурации"
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	instrument := b.normalizeInstrument(args[0])
+	if !b.isValidInstrument(instrument) {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный тикер: %s", args[0]))
+	}
+
+	timeframe := "24"
+	if len(args) > 1 {
+		timeframe = args[1]
+	}
+
+	b.sendTypingAction(chatID)
+
+	msg, err := b.formatIndicators(instrument, timeframe)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка расчета индикаторов %s: %v", instrument, err))
+	}
+
+	return b.sendFormattedMessage(chatID, msg)
+}
+
+// formatIndicators рассчитывает индикаторы из секции technical и формирует отчет
+func (b *Bot) formatIndicators(instrument, timeframe string) (string, error) {
+	cfg := b.config.Technical
+
+	// Сколько свечей нужно для самого длинного индикатора
+	bars := cfg.RSIPeriod + 1
+	for _, period := range append(append([]int{}, cfg.SMA...), cfg.EMA...) {
+		bars = max(bars, period)
+	}
+	bars = max(bars, cfg.MACDSlow+cfg.MACDSignal, cfg.BollingerPeriod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -analysis.HistoryDaysFor(timeframe, bars))
+	candles, err := b.candles.GetCandles(ctx, instrument, timeframe, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+	if len(candles) == 0 {
+		return "", fmt.Errorf("нет свечей")
+	}
+
+	closes := make([]float64, len(candles))
+	for i, candle := range candles {
+		closes[i] = candle.Close
+	}
+	last := candles[len(candles)-1]
+	price := last.Close
+
+	msg := fmt.Sprintf("📐 ИНДИКАТОРЫ: %s (%s)\n\n", instrument, timeframe)
+	msg += fmt.Sprintf("📅 Последняя свеча: %s\n", last.Begin.Format("02.01.2006 15:04"))
+	msg += fmt.Sprintf("💰 Цена: %.2f₽\n", price)
+	msg += fmt.Sprintf("📊 Свечей в расчете: %d\n\n", len(candles))
+
+	if len(cfg.SMA) > 0 || len(cfg.EMA) > 0 {
+		msg += "📈 СКОЛЬЗЯЩИЕ СРЕДНИЕ:\n"
+		for _, period := range cfg.SMA {
+			msg += formatMovingAverage("SMA", period, indicators.SMA(closes, period), price)
+		}
+		for _, period := range cfg.EMA {
+			msg += formatMovingAverage("EMA", period, indicators.EMA(closes, period), price)
+		}
+		msg += "\n"
+	}
+
+	msg += "🔄 ОСЦИЛЛЯТОРЫ:\n"
+	if cfg.RSIPeriod > 0 {
+		if rsi, ok := indicators.Last(indicators.RSI(closes, cfg.RSIPeriod)); ok {
+			zone := ""
+			switch {
+			case rsi >= 70:
+				zone = " - перекупленность"
+			case rsi <= 30:
+				zone = " - перепроданность"
+			}
+			msg += fmt.Sprintf("• RSI %d: %.1f%s\n", cfg.RSIPeriod, rsi, zone)
+		} else {
+			msg += fmt.Sprintf("• RSI %d: недостаточно данных\n", cfg.RSIPeriod)
+		}
+	}
+	if cfg.MACDFast > 0 && cfg.MACDSlow > 0 && cfg.MACDSignal > 0 {
+		macd, signal, hist := indicators.MACD(closes, cfg.MACDFast, cfg.MACDSlow, cfg.MACDSignal)
+		m, okMACD := indicators.Last(macd)
+		s, okSignal := indicators.Last(signal)
+		h, _ := indicators.Last(hist)
+		if okMACD && okSignal {
+			direction := "🟢 выше сигнальной"
+			if h < 0 {
+				direction = "🔴 ниже сигнальной"
+			}
+			msg += fmt.Sprintf("• MACD %d/%d/%d: %.2f, сигнальная %.2f, гистограмма %.2f (%s)\n",
+				cfg.MACDFast, cfg.MACDSlow, cfg.MACDSignal, m, s, h, direction)
+		} else {
+			msg += fmt.Sprintf("• MACD %d/%d/%d: недостаточно данных\n", cfg.MACDFast, cfg.MACDSlow, cfg.MACDSignal)
+		}
+	}
+
+	if cfg.BollingerPeriod > 0 {
+		msg += "\n📏 ВОЛАТИЛЬНОСТЬ:\n"
+		upper, middle, lower := indicators.Bollinger(closes, cfg.BollingerPeriod, float64(cfg.BollingerStd))
+		u, ok := indicators.Last(upper)
+		if ok {
+			mid, _ := indicators.Last(middle)
+			l, _ := indicators.Last(lower)
+			msg += fmt.Sprintf("• Bollinger %d/%d: %.2f / %.2f / %.2f\n", cfg.BollingerPeriod, cfg.BollingerStd, u, mid, l)
+			if u > l {
+				msg += fmt.Sprintf("  Положение цены в канале: %.0f%%\n", (price-l)/(u-l)*100)
+			}
+		} else {
+			msg += fmt.Sprintf("• Bollinger %d/%d: недостаточно данных\n", cfg.BollingerPeriod, cfg.BollingerStd)
+		}
+	}
+
+	return msg, nil
+}
+
+// formatMovingAverage строка отчета для скользящей средней и положения цены относительно нее
+func formatMovingAverage(name string, period int, series []float64, price float64) string {
+	value, ok := indicators.Last(series)
+	if !ok {
+		return fmt.Sprintf("• %s %d: недостаточно данных\n", name, period)
+	}
+
+	position := "🟢 цена выше"
+	if price < value {
+		position = "🔴 цена ниже"
+	}
+	return fmt.Sprintf("• %s %d: %.2f (%s, %+.1f%%)\n", name, period, value, position, (price/value-1)*100)
+}
@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Размер выборки /signals_history
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 50
+)
+
+// defaultSignalTTL время жизни сигнала в журнале, если storage.signal_ttl не задан
+const defaultSignalTTL = 72 * time.Hour
+
+// journalSignals записывает сигналы фонового сканирования стратегии в журнал и
+// возвращает сигналы на вход, о которых еще не отправлялось уведомление, вместе
+// с номерами их записей в журнале (ключ - journalKey)
+func (b *Bot) journalSignals(strategy string, signals []analysis.Signal) ([]analysis.Signal, map[string]int64, error) {
+	observed := make([]storage.SignalRecord, 0, len(signals))
+	for _, signal := range signals {
+		if signal.SignalType == "no_signal" {
+			continue
+		}
+		observed = append(observed, storage.SignalRecord{
+			Instrument: signal.Instrument,
+			SignalType: signal.SignalType,
+			BarTime:    signal.Timestamp,
+			Price:      signal.Price,
+			StopLoss:   signal.StopLoss,
+			TakeProfit: signal.TakeProfit,
+			Confidence: signal.Confidence,
+		})
+	}
+
+	ttl := b.config.Storage.SignalTTL
+	if ttl <= 0 {
+		ttl = defaultSignalTTL
+	}
+
+	records, err := b.store.ObserveSignals(strategy, observed, time.Now(), ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make(map[string]int64, len(records))
+	for _, record := range records {
+		ids[record.Instrument+"|"+record.SignalType] = record.ID
+	}
+
+	var pending []analysis.Signal
+	for _, signal := range signals {
+		if _, ok := ids[journalKey(signal)]; ok {
+			pending = append(pending, signal)
+		}
+	}
+
+	return pending, ids, nil
+}
+
+// markNotified отмечает в журнале сигналы, о которых отправлено уведомление
+func (b *Bot) markNotified(signals []analysis.Signal, ids map[string]int64) error {
+	notified := make([]int64, 0, len(signals))
+	for _, signal := range signals {
+		if id, ok := ids[journalKey(signal)]; ok {
+			notified = append(notified, id)
+		}
+	}
+	return b.store.MarkSignalsActive(notified)
+}
+
+// journalKey ключ сигнала одного сканирования: инструмент и тип
+func journalKey(signal analysis.Signal) string {
+	return signal.Instrument + "|" + signal.SignalType
+}
+
+// handleSignalsHistory обработчик команды /signals_history [ТИКЕР|СТРАТЕГИЯ] [N]
+func (b *Bot) handleSignalsHistory(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	var strategy, instrument string
+	limit := defaultHistoryLimit
+
+	for _, arg := range strings.Fields(update.Message.CommandArguments()) {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n <= 0 || n > maxHistoryLimit {
+				return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Количество сигналов должно быть от 1 до %d", maxHistoryLimit))
+			}
+			limit = n
+			continue
+		}
+		if _, ok := b.strategies.Get(strings.ToLower(arg)); ok {
+			strategy = strings.ToLower(arg)
+			continue
+		}
+		instrument = b.normalizeInstrument(arg)
+		if !b.isValidInstrument(instrument) {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный тикер или стратегия: %s\n\nСтратегии: %s",
+				arg, strings.Join(b.strategies.Names(), ", ")))
+		}
+	}
+
+	history := b.store.SignalHistory(strategy, instrument, limit)
+
+	msg := "📜 ИСТОРИЯ СИГНАЛОВ"
+	if filter := strings.TrimSpace(strategy + " " + instrument); filter != "" {
+		msg += ": " + filter
+	}
+	msg += "\n\n"
+
+	if len(history) == 0 {
+		msg += "📭 Сигналов в журнале нет\n\n"
+		msg += "В журнал попадают сигналы на вход фонового анализа стратегий (уведомления в strategy.notifications)\n\n"
+		msg += "Использование: /signals_history [ТИКЕР|СТРАТЕГИЯ] [N]"
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	for _, record := range history {
+		title := record.Strategy
+		if reg, ok := b.strategies.Get(record.Strategy); ok {
+			title = reg.Title
+		}
+
+		msg += fmt.Sprintf("%s %s - %s\n", b.getSignalTypeText(record.SignalType), record.Instrument, title)
+		msg += fmt.Sprintf("  Вход: %.2f₽ | %s | 🎯 %.0f%%\n", record.Price, record.BarTime.Format("02.01.2006"), record.Confidence)
+		if record.StopLoss > 0 {
+			msg += fmt.Sprintf("  Стоп: %.2f", record.StopLoss)
+			if record.TakeProfit > 0 {
+				msg += fmt.Sprintf(" | Тейк: %.2f", record.TakeProfit)
+			}
+			msg += "\n"
+		}
+		msg += fmt.Sprintf("  Статус: %s\n", formatSignalStatus(record))
+		if outcome := formatOutcome(record); outcome != "" {
+			msg += fmt.Sprintf("  Исход: %s\n", outcome)
+		}
+		msg += "\n"
+	}
+
+	msg += fmt.Sprintf("Показано: %d (не более %d)", len(history), limit)
+
+	return b.sendFormattedMessage(chatID, msg)
+}
+
+// formatSignalStatus состояние записи журнала для пользователя
+func formatSignalStatus(record storage.SignalRecord) string {
+	switch record.Status {
+	case storage.SignalNew:
+		return "🆕 новый, уведомление не отправлено"
+	case storage.SignalActive:
+		return fmt.Sprintf("🔔 активен, повторялся %s", record.LastSeen.Format("02.01 15:04"))
+	case storage.SignalExited:
+		if record.Price <= 0 || record.ExitPrice <= 0 {
+			return fmt.Sprintf("📤 закрыт %s", record.ClosedAt.Format("02.01 15:04"))
+		}
+		change := (record.ExitPrice - record.Price) / record.Price * 100
+		if record.SignalType == "entry_short" {
+			change = -change
+		}
+		return fmt.Sprintf("📤 закрыт %s по %.2f₽ (%+.1f%%)", record.ClosedAt.Format("02.01 15:04"), record.ExitPrice, change)
+	case storage.SignalExpired:
+		return fmt.Sprintf("⌛ истек %s", record.ClosedAt.Format("02.01 15:04"))
+	default:
+		return string(record.Status)
+	}
+}
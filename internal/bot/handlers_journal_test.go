@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/config"
+	"telegram-bot-moex/internal/storage"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+)
+
+func TestJournalTurtleSignalsByBar(t *testing.T) {
+	store, err := storage.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	b := &Bot{config: config.DefaultConfig(), store: store}
+
+	breakout := make([]float64, 0, 61)
+	for i := 0; i < 60; i++ {
+		breakout = append(breakout, 100+float64(i%2))
+	}
+	breakout = append(breakout, 110)
+	candles := fakefetcher.Series(breakout)
+	strategy := b.createTurtleStrategy()
+
+	signals, err := strategy.AnalyzeCandles("SBER", candles)
+	if err != nil {
+		t.Fatalf("AnalyzeCandles() error = %v", err)
+	}
+	pending, ids, err := b.journalSignals("turtle", signals)
+	if err != nil {
+		t.Fatalf("journalSignals() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].SignalType != "entry_long" {
+		t.Fatalf("pending = %+v, want entry_long", pending)
+	}
+	if !pending[0].Timestamp.Equal(candles[len(candles)-1].Begin) {
+		t.Errorf("Timestamp = %v, want last candle %v", pending[0].Timestamp, candles[len(candles)-1].Begin)
+	}
+
+	// Сигнал закрыт (например, по стопу), а последняя свеча не изменилась
+	if err := store.SetOutcome(ids[journalKey(pending[0])], "stop", 100, time.Now(), -1, time.Now()); err != nil {
+		t.Fatalf("SetOutcome() error = %v", err)
+	}
+
+	// Повторное сканирование той же свечи не создает запись и не повторяет уведомление
+	signals, err = strategy.AnalyzeCandles("SBER", candles)
+	if err != nil {
+		t.Fatalf("AnalyzeCandles() error = %v", err)
+	}
+	pending, _, err = b.journalSignals("turtle", signals)
+	if err != nil {
+		t.Fatalf("journalSignals() error = %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending = %+v after repeated scan, want none", pending)
+	}
+	if history := store.SignalHistory("turtle", "SBER", 0); len(history) != 1 {
+		t.Errorf("journal records = %d, want 1", len(history))
+	}
+}
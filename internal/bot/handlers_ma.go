@@ -2,10 +2,12 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -57,7 +59,8 @@ func (b *Bot) handleMA(update tgbotapi.Update) error {
 	msg += fmt.Sprintf("• Стоп-лосс: %.1fxATR\n", cfg.StopLossATRMultiplier)
 	msg += fmt.Sprintf("• Тейк-профит: 1:%.1f\n", cfg.TakeProfitRatio)
 	msg += fmt.Sprintf("• Подтверждение объема: %v\n", cfg.UseVolumeConfirmation)
-	msg += fmt.Sprintf("• RSI фильтр: %v\n\n", cfg.Filters.RSIFilter)
+	msg += fmt.Sprintf("• RSI фильтр: %v\n", cfg.Filters.RSIFilter)
+	msg += fmt.Sprintf("• Фильтр тренда: %s\n\n", cfg.Filters.TrendFilter)
 
 	msg += "🎯 ТИПЫ СИГНАЛОВ:\n"
 	if cfg.CrossoverTypes.GoldenCross {
@@ -73,6 +76,7 @@ func (b *Bot) handleMA(update tgbotapi.Update) error {
 	msg += "• /scan_ma - Сканировать все инструменты\n"
 	msg += "• /ma_config - Настройки стратегии\n"
 	msg += "• /ma_test - Тестирование на инструменте\n"
+	msg += "• /ma_stats - Статистика сигналов\n"
 
 	// Добавляем кнопки управления
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -84,6 +88,50 @@ func (b *Bot) handleMA(update tgbotapi.Update) error {
 			tgbotapi.NewInlineKeyboardButtonData("⚙️ Настройки", "ma_config"),
 			tgbotapi.NewInlineKeyboardButtonData("🧪 Тестировать", "ma_test"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Статистика", "ma_stats"),
+		),
+	)
+
+	return b.sendMessageWithKeyboard(chatID, msg, keyboard)
+}
+
+// handleMAStats обработчик команды /ma_stats
+func (b *Bot) handleMAStats(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	msg := "📊 СТАТИСТИКА СТРАТЕГИИ MA CROSSOVER\n\n"
+
+	if !b.config.Strategy.MACrossover.Enabled {
+		msg += "❌ Стратегия отключена\n\n"
+		msg += "Используйте /ma_config для включения и настройки"
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	cfg := b.config.Strategy.MACrossover
+
+	msg += "📈 ОБЩАЯ ИНФОРМАЦИЯ:\n"
+	msg += fmt.Sprintf("• Включена: %s\n", b.getMAStatus())
+	msg += fmt.Sprintf("• Таймфрейм анализа: %s\n", cfg.Timeframe)
+	msg += fmt.Sprintf("• Последнее сканирование: %s\n", b.formatLastScan("ma_crossover"))
+	msg += fmt.Sprintf("• Автосканирование: %s\n\n", b.scheduleText("ma_crossover"))
+
+	msg += "⚙️ ПАРАМЕТРЫ РИСК-МЕНЕДЖМЕНТА:\n"
+	msg += fmt.Sprintf("• Риск на сделку: %.1f%%\n", cfg.RiskPerTrade*100)
+	msg += fmt.Sprintf("• Стоп-лосс: %.1fxATR\n", cfg.StopLossATRMultiplier)
+	msg += fmt.Sprintf("• Тейк-профит: 1:%.1f\n\n", cfg.TakeProfitRatio)
+
+	msg += "🎯 СИГНАЛЫ ФОНОВОГО АНАЛИЗА:\n"
+	msg += b.formatSignalStats("ma_crossover")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Обновить", "ma_stats"),
+			tgbotapi.NewInlineKeyboardButtonData("📈 Сигналы", "ma_signals"),
+		),
 	)
 
 	return b.sendMessageWithKeyboard(chatID, msg, keyboard)
@@ -96,6 +144,11 @@ func (b *Bot) handleMASignals(update tgbotapi.Update) error {
 		return err
 	}
 
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
 	if !b.config.Strategy.MACrossover.Enabled {
 		return b.sendFormattedMessage(chatID, "❌ Стратегия MA Crossover отключена.\nИспользуйте /ma_config для включения.")
 	}
@@ -103,7 +156,7 @@ func (b *Bot) handleMASignals(update tgbotapi.Update) error {
 	b.sendFormattedMessage(chatID, "🔍 Поиск сигналов по стратегии MA Crossover...")
 
 	// Запускаем анализ в фоне
-	go b.scanAndShowMASignals(chatID)
+	go b.scanAndShowMASignals(chatID, userID)
 
 	return nil
 }
@@ -115,6 +168,11 @@ func (b *Bot) handleScanMA(update tgbotapi.Update) error {
 		return err
 	}
 
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
 	if !b.config.Strategy.MACrossover.Enabled {
 		return b.sendFormattedMessage(chatID, "❌ Стратегия MA Crossover отключена.\nИспользуйте /ma_config для включения.")
 	}
@@ -122,7 +180,7 @@ func (b *Bot) handleScanMA(update tgbotapi.Update) error {
 	b.sendFormattedMessage(chatID, "🔍 Сканирование всех инструментов по стратегии MA Crossover...\n\n⏳ Это может занять несколько минут.")
 
 	// Запускаем сканирование в фоне
-	go b.scanAndShowMASignals(chatID)
+	go b.scanAndShowMASignals(chatID, userID)
 
 	return nil
 }
@@ -249,62 +307,49 @@ func (b *Bot) handleMATest(update tgbotapi.Update) error {
 }
 
 // scanAndShowMASignals сканирует и показывает сигналы MA Crossover
-func (b *Bot) scanAndShowMASignals(chatID int64) {
-	// Создаем стратегию
-	strategy := b.createMACrossoverStrategy()
-
-	// Получаем инструменты
-	instruments, err := b.apiClient.GetInstruments(context.Background())
+func (b *Bot) scanAndShowMASignals(chatID, userID int64) {
+	result, err := b.scanInstruments(context.Background(), b.createMACrossoverStrategy(), b.accountFor(userID), userID)
+	if errors.Is(err, api.ErrCircuitOpen) {
+		analyzed := 0
+		if result != nil {
+			analyzed = result.Analyzed
+		}
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Сканирование прервано: API недоступен\n\nПроанализировано инструментов: %d\nСостояние можно проверить командой /health", analyzed))
+		return
+	}
 	if err != nil {
-		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка получения инструментов: %v", err))
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v", err))
 		return
 	}
 
-	if len(instruments) == 0 {
+	if result.Total == 0 {
 		b.sendFormattedMessage(chatID, "📭 Нет доступных инструментов для анализа")
 		return
 	}
 
-	// Анализируем каждый инструмент
+	// Пересечения, отфильтрованные по тренду или RSI, в сканирование не попадают
 	var allSignals []analysis.Signal
-	totalInstruments := 0
-	analyzedInstruments := 0
-
-	for _, instrument := range instruments {
-		totalInstruments++
-
-		// Пропускаем некорректные инструменты
-		if !b.isValidInstrument(instrument) {
-			continue
+	for _, signal := range result.Signals {
+		if signal.SignalType != "no_signal" {
+			allSignals = append(allSignals, signal)
 		}
-
-		signals, err := strategy.Analyze(context.Background(), instrument)
-		if err != nil {
-			b.logger.Debug("Ошибка анализа инструмента", "instrument", instrument, "error", err)
-			continue
-		}
-
-		analyzedInstruments++
-
-		if len(signals) > 0 {
-			allSignals = append(allSignals, signals...)
-		}
-
-		// Делаем небольшую паузу между запросами
-		time.Sleep(100 * time.Millisecond)
 	}
+	b.recordScanExport(chatID, "ma_crossover", allSignals)
 
 	// Формируем сообщение с результатами
 	msg := "📈 РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ MA CROSSOVER\n\n"
-	msg += fmt.Sprintf("📊 Всего инструментов: %d\n", totalInstruments)
-	msg += fmt.Sprintf("📊 Проанализировано: %d\n", analyzedInstruments)
+	msg += fmt.Sprintf("📊 Всего инструментов: %d\n", result.Total)
+	msg += fmt.Sprintf("📊 Проанализировано: %d\n", result.Analyzed)
+	if result.Watchlist {
+		msg += "👁 По списку наблюдения (/watchlist)\n"
+	}
 	msg += fmt.Sprintf("🚨 Найдено сигналов: %d\n\n", len(allSignals))
 
 	if len(allSignals) == 0 {
 		msg += "📭 Торговых сигналов не найдено\n"
 		msg += "💡 Возможные причины:\n"
 		msg += "• Нет пересечений скользящих средних\n"
-		msg += "• Сигналы не прошли фильтры (объем, RSI)\n"
+		msg += "• Сигналы не прошли фильтры (объем, тренд, RSI)\n"
 		msg += "• Недостаточно данных для анализа\n\n"
 		msg += "Попробуйте изменить параметры стратегии через /ma_config"
 		b.sendFormattedMessage(chatID, msg)
@@ -328,12 +373,12 @@ func (b *Bot) scanAndShowMASignals(chatID int64) {
 	if len(goldenCross) > 0 {
 		msg += "🟢 ЗОЛОТЫЕ ПЕРЕСЕЧЕНИЯ (ПОКУПКА):\n"
 		for _, signal := range goldenCross {
-			msg += fmt.Sprintf("• %s - %.2f₽\n", signal.Instrument, signal.Price)
+			msg += fmt.Sprintf("• %s - %.2f₽%s\n", signal.Instrument, signal.Price, formatConfidence(signal))
 			if signal.StopLoss > 0 {
 				msg += fmt.Sprintf("  Стоп: %.2f | Тейк: %.2f\n", signal.StopLoss, signal.TakeProfit)
 			}
-			if signal.PositionSize > 0 {
-				msg += fmt.Sprintf("  Размер: %.0f шт.\n", signal.PositionSize)
+			if signal.Sized() {
+				msg += fmt.Sprintf("  Размер: %s\n", formatPosition(signal))
 			}
 			msg += fmt.Sprintf("  📅 %s\n\n", signal.Timestamp.Format("02.01 15:04"))
 		}
@@ -342,12 +387,12 @@ func (b *Bot) scanAndShowMASignals(chatID int64) {
 	if len(deathCross) > 0 {
 		msg += "🔴 МЕРТВЫЕ ПЕРЕСЕЧЕНИЯ (ПРОДАЖА):\n"
 		for _, signal := range deathCross {
-			msg += fmt.Sprintf("• %s - %.2f₽\n", signal.Instrument, signal.Price)
+			msg += fmt.Sprintf("• %s - %.2f₽%s\n", signal.Instrument, signal.Price, formatConfidence(signal))
 			if signal.StopLoss > 0 {
 				msg += fmt.Sprintf("  Стоп: %.2f | Тейк: %.2f\n", signal.StopLoss, signal.TakeProfit)
 			}
-			if signal.PositionSize > 0 {
-				msg += fmt.Sprintf("  Размер: %.0f шт.\n", signal.PositionSize)
+			if signal.Sized() {
+				msg += fmt.Sprintf("  Размер: %s\n", formatPosition(signal))
 			}
 			msg += fmt.Sprintf("  📅 %s\n\n", signal.Timestamp.Format("02.01 15:04"))
 		}
@@ -368,6 +413,7 @@ func (b *Bot) scanAndShowMASignals(chatID int64) {
 			tgbotapi.NewInlineKeyboardButtonData("📊 Подробнее о стратегии", "help_strategy"),
 			tgbotapi.NewInlineKeyboardButtonData("🧪 Тестировать", "ma_test"),
 		),
+		exportButtons("export_scan_ma_crossover_"),
 	)
 
 	b.sendSafeMessageWithKeyboard(chatID, msg, keyboard)
@@ -398,8 +444,12 @@ func (b *Bot) createMACrossoverStrategy() *analysis.MACrossoverStrategy {
 	maConfig.Filters.RSIFilter = cfg.Filters.RSIFilter
 	maConfig.Filters.RSIOverbought = cfg.Filters.RSIOverbought
 	maConfig.Filters.RSIOversold = cfg.Filters.RSIOversold
+	maConfig.Filters.EMASlopePeriod = cfg.Filters.EMASlopePeriod
+	maConfig.Filters.EMASlopeBars = cfg.Filters.EMASlopeBars
+	maConfig.Filters.ADXPeriod = cfg.Filters.ADXPeriod
+	maConfig.Filters.ADXThreshold = cfg.Filters.ADXThreshold
 
-	return analysis.NewMACrossoverStrategy(b.apiClient, maConfig)
+	return analysis.NewMACrossoverStrategy(b.candles, maConfig)
 }
 
 // getMAStatus возвращает статус стратегии MA Crossover
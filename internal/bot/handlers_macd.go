@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleMACD обработчик команды /macd - описание и настройки стратегии MACD
+func (b *Bot) handleMACD(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	msg := "📊 АНАЛИЗ ПО СТРАТЕГИИ MACD\n\n"
+
+	msg += "📖 ОПИСАНИЕ СТРАТЕГИИ:\n"
+	msg += "MACD - разница быстрой и медленной EMA. Сигналы на покупку и продажу формируются при пересечении сигнальной линии, нулевой линии и при дивергенции гистограммы с ценой.\n\n"
+
+	cfg := b.config.Strategy.MACD
+	strategy := b.createMACDStrategy()
+
+	msg += "⚙️ ТЕКУЩИЕ НАСТРОЙКИ:\n"
+	msg += fmt.Sprintf("• Статус: %s\n", b.getMACDStatus())
+	for _, param := range strategy.Params() {
+		msg += fmt.Sprintf("• %s: %s\n", param.Name, param.Value)
+	}
+	msg += "\n"
+
+	msg += "🎯 ТИПЫ СИГНАЛОВ:\n"
+	if cfg.Signals.SignalCross {
+		msg += "• Пересечение сигнальной линии снизу вверх - 🟢 покупка, сверху вниз - 🔴 продажа\n"
+	}
+	if cfg.Signals.ZeroCross {
+		msg += "• Пересечение нулевой линии - подтверждение смены тренда\n"
+	}
+	if cfg.Signals.Divergence {
+		msg += fmt.Sprintf("• Дивергенция гистограммы и цены за %d свечей - разворот\n", cfg.Signals.DivergenceLookback)
+	}
+	msg += "• Сигнал на вход одновременно является сигналом выхода из противоположной позиции\n\n"
+
+	msg += "📈 КОМАНДЫ:\n"
+	msg += "• /scan_macd - Сканировать все инструменты\n"
+	msg += "• /macd_test - Тестирование на инструменте\n"
+	msg += "• /scan macd - То же, через общее сканирование\n"
+
+	if !cfg.Enabled {
+		msg += "\n❌ Стратегия отключена"
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if cfg.Enabled {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔍 Сканировать", "macd_scan"),
+			tgbotapi.NewInlineKeyboardButtonData("🧪 Тестировать", "macd_test"),
+		))
+	}
+
+	if userID, err := b.getUserID(update); err == nil && b.isAdmin(userID) {
+		if cfg.Enabled {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🔴 Выключить", "macd_disable"),
+			))
+		} else {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🟢 Включить", "macd_enable"),
+			))
+		}
+	}
+
+	if len(rows) == 0 {
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	return b.sendMessageWithKeyboard(chatID, msg, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// handleScanMACD обработчик команды /scan_macd
+func (b *Bot) handleScanMACD(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	b.scanMACD(chatID, userID)
+	return nil
+}
+
+// scanMACD запускает сканирование всех инструментов стратегией MACD
+func (b *Bot) scanMACD(chatID, userID int64) {
+	if !b.config.Strategy.MACD.Enabled {
+		b.sendFormattedMessage(chatID, "❌ Стратегия MACD отключена.\nИспользуйте /macd для включения.")
+		return
+	}
+
+	reg, ok := b.strategies.Get("macd")
+	if !ok {
+		b.sendFormattedMessage(chatID, "❌ Стратегия MACD не зарегистрирована")
+		return
+	}
+
+	b.sendFormattedMessage(chatID, "🔍 Сканирование всех инструментов по стратегии MACD...\n\n⏳ Это может занять несколько минут.")
+
+	// Запускаем сканирование в фоне
+	go b.scanAndShowSignals(chatID, userID, reg)
+}
+
+// handleMACDTest обработчик команды /macd_test
+func (b *Bot) handleMACDTest(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	return b.startMACDTest(chatID, userID)
+}
+
+// startMACDTest начинает диалог тестирования стратегии MACD
+func (b *Bot) startMACDTest(chatID, userID int64) error {
+	if !b.config.Strategy.MACD.Enabled {
+		return b.sendFormattedMessage(chatID, "❌ Стратегия MACD отключена.\nИспользуйте /macd для включения.")
+	}
+
+	state := &UserState{
+		CurrentCommand: "macd_test",
+		Step:           1,
+		Data:           make(map[string]interface{}),
+		LastActivity:   time.Now(),
+	}
+	b.setUserState(userID, state)
+
+	msg := "🧪 ТЕСТИРОВАНИЕ СТРАТЕГИИ MACD\n\n"
+	msg += "Этот тест покажет, как работает стратегия на конкретном инструменте.\n\n"
+	msg += "Введите тикер инструмента для тестирования (например: SBER):"
+
+	return b.sendFormattedMessage(chatID, msg)
+}
+
+// handleMACDCallback обработка callback кнопок стратегии MACD
+func (b *Bot) handleMACDCallback(chatID, userID int64, data string) {
+	switch data {
+	case "macd_scan":
+		b.scanMACD(chatID, userID)
+	case "macd_test":
+		b.startMACDTest(chatID, userID)
+	case "macd_enable", "macd_disable":
+		if !b.isAdmin(userID) {
+			b.sendFormattedMessage(chatID, "❌ Управление стратегией доступно только администраторам")
+			return
+		}
+
+		enabled := data == "macd_enable"
+		b.config.Strategy.MACD.Enabled = enabled
+		if enabled {
+			b.sendFormattedMessage(chatID, "✅ Стратегия MACD включена!\n\nИспользуйте /scan_macd для поиска сигналов.")
+		} else {
+			b.sendFormattedMessage(chatID, "✅ Стратегия MACD отключена.")
+		}
+
+		// Обновляем команды меню
+		b.setBotCommands()
+	}
+}
+
+// createMACDStrategy создает стратегию MACD из конфига. Нулевые периоды
+// берутся из секции technical.
+func (b *Bot) createMACDStrategy() *analysis.MACDStrategy {
+	cfg := b.config.Strategy.MACD
+	technical := b.config.Technical
+
+	macdConfig := analysis.MACDConfig{
+		Timeframe:             cfg.Timeframe,
+		FastPeriod:            cfg.FastPeriod,
+		SlowPeriod:            cfg.SlowPeriod,
+		SignalPeriod:          cfg.SignalPeriod,
+		AtrPeriod:             cfg.AtrPeriod,
+		RiskPerTrade:          cfg.RiskPerTrade,
+		StopLossATRMultiplier: cfg.StopLossATRMultiplier,
+		TakeProfitRatio:       cfg.TakeProfitRatio,
+		SignalCross:           cfg.Signals.SignalCross,
+		ZeroCross:             cfg.Signals.ZeroCross,
+		Divergence:            cfg.Signals.Divergence,
+		DivergenceLookback:    cfg.Signals.DivergenceLookback,
+	}
+
+	if macdConfig.FastPeriod == 0 {
+		macdConfig.FastPeriod = technical.MACDFast
+	}
+	if macdConfig.SlowPeriod == 0 {
+		macdConfig.SlowPeriod = technical.MACDSlow
+	}
+	if macdConfig.SignalPeriod == 0 {
+		macdConfig.SignalPeriod = technical.MACDSignal
+	}
+
+	return analysis.NewMACDStrategy(b.candles, macdConfig)
+}
+
+// getMACDStatus возвращает статус стратегии MACD
+func (b *Bot) getMACDStatus() string {
+	if b.config.Strategy.MACD.Enabled {
+		return "🟢 ВКЛЮЧЕНА"
+	}
+	return "🔴 ВЫКЛЮЧЕНА"
+}
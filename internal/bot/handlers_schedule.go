@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/scheduler"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// scheduleText расписание фонового анализа стратегии для пользователя
+func (b *Bot) scheduleText(strategy string) string {
+	reg, ok := b.strategies.Get(strategy)
+	if !ok {
+		return "нет"
+	}
+
+	text := b.analysisSchedule(reg) + " (MSK)"
+	if entry, ok := b.scheduleEntry(strategy); ok && !entry.Next.IsZero() {
+		text += ", следующий запуск " + entry.Next.In(api.MSK).Format("02.01 15:04")
+	}
+	return text
+}
+
+// scheduleEntry состояние задачи фонового анализа стратегии в планировщике
+func (b *Bot) scheduleEntry(strategy string) (scheduler.Entry, bool) {
+	for _, entry := range b.scheduler.Entries() {
+		if entry.Name == strategy {
+			return entry, true
+		}
+	}
+	return scheduler.Entry{}, false
+}
+
+// handleSchedule обработчик команды /schedule: задачи фонового анализа и время
+// их ближайших запусков
+func (b *Bot) handleSchedule(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	if !b.isAdmin(userID) {
+		return b.sendFormattedMessage(chatID, "❌ Расписание доступно только администраторам")
+	}
+
+	tradingDay := "нет"
+	if b.scheduler.Calendar().IsTradingDay(time.Now()) {
+		tradingDay = "да"
+	}
+	catchUp := "не выполняются"
+	if b.scheduler.CatchUp() {
+		catchUp = "выполняются сразу"
+	}
+
+	msg := "🗓 РАСПИСАНИЕ ФОНОВОГО АНАЛИЗА\n\n"
+	msg += fmt.Sprintf("• Время: MSK, сейчас %s\n", time.Now().In(api.MSK).Format("02.01.2006 15:04"))
+	msg += fmt.Sprintf("• Календарь: %s\n", b.scheduler.Calendar())
+	if year := time.Now().In(api.MSK).Year(); usesHolidays(b.config) && !b.scheduler.Calendar().HasHolidaysIn(year) {
+		msg += fmt.Sprintf("⚠️ В scheduler.holidays нет праздников на %d год - обновите список по календарю MOEX\n", year)
+	}
+	msg += fmt.Sprintf("• Сегодня торговый день: %s\n", tradingDay)
+	msg += fmt.Sprintf("• Пропущенные запуски после рестарта: %s\n\n", catchUp)
+
+	entries := b.scheduler.Entries()
+	if len(entries) == 0 {
+		msg += "📭 Задач нет: фоновый анализ работает при включенных уведомлениях (strategy.notifications.enabled) для включенных стратегий, проверка алертов - при alerts.enabled\n"
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	for _, entry := range entries {
+		title := entry.Name
+		if reg, ok := b.strategies.Get(entry.Name); ok {
+			title = fmt.Sprintf("%s (%s)", reg.Title, reg.Name)
+		} else if entry.Name == alertsJob {
+			title = fmt.Sprintf("Алерты (%s, активных: %d)", alertsJob, len(b.store.Alerts(0)))
+		}
+
+		msg += fmt.Sprintf("📈 %s\n", title)
+		msg += fmt.Sprintf("• Расписание: %s\n", entry.Spec)
+		if entry.Next.IsZero() {
+			msg += "• Следующий запуск: нет\n"
+		} else {
+			msg += fmt.Sprintf("• Следующий запуск: %s (через %s)\n",
+				entry.Next.In(api.MSK).Format("02.01.2006 15:04"), time.Until(entry.Next).Truncate(time.Minute))
+		}
+		if len(entry.Upcoming) > 1 {
+			msg += "• Далее:"
+			for _, t := range entry.Upcoming[1:] {
+				msg += " " + t.In(api.MSK).Format("02.01 15:04")
+			}
+			msg += "\n"
+		}
+		if entry.LastRun.IsZero() {
+			msg += "• Последний запуск: не выполнялся\n"
+		} else {
+			msg += fmt.Sprintf("• Последний запуск: %s\n", entry.LastRun.In(api.MSK).Format("02.01.2006 15:04"))
+		}
+		if entry.Running {
+			msg += "• ⏳ Выполняется\n"
+		}
+		if entry.Skipped > 0 {
+			msg += fmt.Sprintf("• ⚠️ Пропущено из-за незавершенного запуска: %d\n", entry.Skipped)
+		}
+		msg += "\n"
+	}
+
+	msg += "💡 Расписание задается в strategy.notifications.analysis_schedule и alerts.check_schedule, календарь - в секции scheduler"
+
+	return b.sendFormattedMessage(chatID, msg)
+}
@@ -3,6 +3,7 @@ package bot
 import (
 	"context"
 	"fmt"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -33,8 +34,8 @@ func (b *Bot) handleCandlesStep(chatID, userID int64, state *UserState, text str
 		for _, tf := range timeframes {
 			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("%s (%s)", tf["display_name"], tf["timeframe"]),
-					fmt.Sprintf("timeframe_%s", tf["timeframe"]),
+					fmt.Sprintf("%s (%s)", tf.DisplayName, tf.Code),
+					fmt.Sprintf("timeframe_%s", tf.Code),
 				),
 			))
 		}
@@ -50,6 +51,24 @@ func (b *Bot) handleCandlesStep(chatID, userID int64, state *UserState, text str
 		if _, err := b.botAPI.Send(msg); err != nil {
 			b.logger.Error("Ошибка отправки сообщения", "error", err)
 		}
+
+	case 2: // Таймфрейм выбирается кнопкой
+		b.sendFormattedMessage(chatID, "👆 Выберите таймфрейм кнопкой выше или /cancel для отмены")
+
+	case 3: // Ввод периода
+		from, to, err := parsePeriod(text, time.Now())
+		if err != nil {
+			b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v. Попробуйте снова (%s):", err, periodHelp))
+			return
+		}
+
+		instrument, _ := state.Data["instrument"].(string)
+		timeframe, _ := state.Data["timeframe"].(string)
+
+		// Завершаем состояние
+		b.resetUserState(userID)
+
+		go b.showCandles(chatID, userID, instrument, timeframe, from, to)
 	}
 }
 
@@ -64,7 +83,7 @@ func (b *Bot) handleTurtleTestStep(chatID, userID int64, state *UserState, text
 	state.Data["instrument"] = instrument
 
 	// Запускаем тест в фоне
-	go b.runTurtleTest(chatID, instrument)
+	go b.runTurtleTest(chatID, userID, instrument)
 
 	// Завершаем состояние
 	b.resetUserState(userID)
@@ -2,15 +2,26 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"runtime/debug"
+	"sort"
+	"strings"
 	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+const (
+	// defaultBacktestPeriod период бэктеста по умолчанию
+	defaultBacktestPeriod = "3y"
+	// maxBacktestTrades сколько последних сделок показывать в отчете
+	maxBacktestTrades = 10
+)
+
 func (b *Bot) handleTurtleAnalysis(update tgbotapi.Update) error {
 	chatID, err := b.getChatID(update)
 	if err != nil {
@@ -30,13 +41,15 @@ func (b *Bot) handleTurtleAnalysis(update tgbotapi.Update) error {
 	msg += fmt.Sprintf("• Прорыв для выхода: %d дней\n", b.config.Strategy.Turtles.ExitBreakoutDays)
 	msg += fmt.Sprintf("• Риск на сделку: %.1f%%\n", b.config.Strategy.Turtles.RiskPerTrade*100)
 	msg += fmt.Sprintf("• ATR период: %d\n", b.config.Strategy.Turtles.AtrPeriod)
-	msg += fmt.Sprintf("• ATR множитель: %.1f\n\n", b.config.Strategy.Turtles.AtrMultiplier)
+	msg += fmt.Sprintf("• ATR множитель: %.1f\n", b.config.Strategy.Turtles.AtrMultiplier)
+	msg += fmt.Sprintf("• Система: %s\n\n", b.getTurtleSystemText())
 
 	msg += "🎯 КАК РАБОТАЕТ:\n"
-	msg += "1. Ищет прорыв максимума/минимума за N дней\n"
-	msg += "2. Рассчитывает стоп-лосс на основе волатильности (ATR)\n"
-	msg += "3. Определяет размер позиции\n"
-	msg += "4. Выходит при обратном прорыве\n\n"
+	msg += "1. Ищет прорыв максимума/минимума: System 1 - 20 дней, System 2 - 55 дней\n"
+	msg += "2. Пропускает прорыв System 1, если предыдущий прорыв был прибыльным\n"
+	msg += "3. Размер юнита - риск на сделку, деленный на стоп 2N (N - ATR)\n"
+	msg += "4. Добавляет юниты через каждые 0.5N, стоп подтягивается на 2N за последним юнитом\n"
+	msg += "5. Выходит при обратном прорыве (10 или 20 дней) или по стопу\n\n"
 
 	msg += "📈 КОМАНДЫ УПРАВЛЕНИЯ:\n"
 	msg += "• /turtle_signals - Текущие сигналы\n"
@@ -44,6 +57,7 @@ func (b *Bot) handleTurtleAnalysis(update tgbotapi.Update) error {
 	msg += "• /turtle_stats - Статистика\n"
 	msg += "• /turtle_config - Настройки\n"
 	msg += "• /turtle_test - Тестирование\n"
+	msg += "• /turtle_backtest - Бэктест на истории\n"
 
 	// Добавляем кнопки управления
 	var rows [][]tgbotapi.InlineKeyboardButton
@@ -76,6 +90,11 @@ func (b *Bot) handleTurtleSignals(update tgbotapi.Update) error {
 		return err
 	}
 
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
 	if !b.config.Strategy.Turtles.Enabled {
 		return b.sendFormattedMessage(chatID, "❌ Стратегия 'Черепах' отключена.\nИспользуйте /turtle_config для включения.")
 	}
@@ -83,7 +102,7 @@ func (b *Bot) handleTurtleSignals(update tgbotapi.Update) error {
 	b.sendFormattedMessage(chatID, "🔍 Поиск сигналов по стратегии 'Черепах'...")
 
 	// Запускаем анализ в фоне
-	go b.scanAndShowTurtleSignals(chatID)
+	go b.scanAndShowTurtleSignals(chatID, userID)
 
 	return nil
 }
@@ -94,6 +113,11 @@ func (b *Bot) handleScanTurtles(update tgbotapi.Update) error {
 		return err
 	}
 
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
 	if !b.config.Strategy.Turtles.Enabled {
 		return b.sendFormattedMessage(chatID, "❌ Стратегия 'Черепах' отключена.\nИспользуйте /turtle_config для включения.")
 	}
@@ -102,7 +126,7 @@ func (b *Bot) handleScanTurtles(update tgbotapi.Update) error {
 	b.sendFormattedMessage(chatID, "🔍 Сканирование всех инструментов по стратегии 'Черепах'...\n\n⏳ Это может занять несколько минут.")
 
 	// Запускаем сканирование в фоне
-	go b.scanAndShowTurtleSignals(chatID)
+	go b.scanAndShowTurtleSignals(chatID, userID)
 
 	return nil
 }
@@ -124,23 +148,25 @@ func (b *Bot) handleTurtleStats(update tgbotapi.Update) error {
 	msg += "📈 ОБЩАЯ ИНФОРМАЦИЯ:\n"
 	msg += fmt.Sprintf("• Включена: %s\n", b.getTurtleStatus())
 	msg += fmt.Sprintf("• Таймфрейм анализа: %s\n", b.config.Strategy.Turtles.Timeframe)
-	msg += "• Последнее сканирование: сегодня\n"
-	msg += "• Автосканирование: каждый час\n\n"
+	msg += fmt.Sprintf("• Последнее сканирование: %s\n", b.formatLastScan("turtle"))
+	msg += fmt.Sprintf("• Автосканирование: %s\n\n", b.scheduleText("turtle"))
 
 	msg += "⚙️ ПАРАМЕТРЫ РИСК-МЕНЕДЖМЕНТА:\n"
 	msg += fmt.Sprintf("• Риск на сделку: %.1f%%\n", b.config.Strategy.Turtles.RiskPerTrade*100)
 	msg += fmt.Sprintf("• Размер позиции: %s\n", b.getPositionSizingStatus())
 	msg += fmt.Sprintf("• Стоп-лосс: %.1fxATR\n", b.config.Strategy.Turtles.AtrMultiplier)
-	msg += "• Тейк-профит: 2xриск\n\n"
+	msg += "• Тейк-профит: нет (выход по обратному прорыву)\n\n"
+
+	msg += "🎯 СИГНАЛЫ ФОНОВОГО АНАЛИЗА:\n"
+	msg += b.formatSignalStats("turtle")
+	msg += "\n"
 
 	msg += "📊 ИСТОРИЧЕСКАЯ ЭФФЕКТИВНОСТЬ:\n"
-	msg += "• В разработке...\n\n"
+	msg += b.formatBacktestSummary()
+	msg += "\n"
 
 	msg += "📈 ПЛАНИРУЕМЫЕ УЛУЧШЕНИЯ:\n"
-	msg += "• Сбор статистики по сделкам\n"
-	msg += "• Анализ эффективности\n"
 	msg += "• Оптимизация параметров\n"
-	msg += "• Бэктестинг\n"
 
 	// Добавляем кнопки
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -183,6 +209,7 @@ func (b *Bot) handleTurtleConfig(update tgbotapi.Update) error {
 	msg += fmt.Sprintf("• Риск на сделку: %.1f%%\n", b.config.Strategy.Turtles.RiskPerTrade*100)
 	msg += fmt.Sprintf("• ATR период: %d\n", b.config.Strategy.Turtles.AtrPeriod)
 	msg += fmt.Sprintf("• ATR множитель: %.1f\n", b.config.Strategy.Turtles.AtrMultiplier)
+	msg += fmt.Sprintf("• Система: %s\n", b.getTurtleSystemText())
 	msg += fmt.Sprintf("• Расчет позиции: %s\n", b.getPositionSizingStatus())
 	msg += fmt.Sprintf("• Уведомления: %s\n\n", b.getNotificationsStatus())
 
@@ -277,6 +304,53 @@ func (b *Bot) handleTurtleTest(update tgbotapi.Update) error {
 	return b.sendFormattedMessage(chatID, msg)
 }
 
+func (b *Bot) handleTurtleBacktest(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		msg := "🧪 БЭКТЕСТ СТРАТЕГИИ 'ЧЕРЕПАХ'\n\n"
+		msg += "Использование: /turtle_backtest ТИКЕР [ПЕРИОД]\n\n"
+		msg += "Примеры:\n"
+		msg += "• /turtle_backtest SBER - за последние 3 года\n"
+		msg += "• /turtle_backtest SBER 1y\n"
+		msg += "• /turtle_backtest SBER 2020-01-01:2024-12-31\n\n"
+		msg += fmt.Sprintf("Форматы периода: %s", periodHelp)
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	instrument := b.normalizeInstrument(args[0])
+	if !b.isValidInstrument(instrument) {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный тикер: %s", args[0]))
+	}
+
+	period := defaultBacktestPeriod
+	if len(args) > 1 {
+		period = args[1]
+	}
+
+	from, to, err := parsePeriod(period, time.Now())
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный период: %v\n\nФорматы: %s", err, periodHelp))
+	}
+
+	b.sendFormattedMessage(chatID, fmt.Sprintf("⏳ Бэктест %s за %s - %s...",
+		instrument, from.Format("02.01.2006"), to.Format("02.01.2006")))
+
+	// Запускаем бэктест в фоне
+	go b.runTurtleBacktest(chatID, userID, instrument, from, to)
+
+	return nil
+}
+
 func (b *Bot) handleTurtleEnable(update tgbotapi.Update) error {
 	chatID, err := b.getChatID(update)
 	if err != nil {
@@ -325,51 +399,32 @@ func (b *Bot) handleTurtleDisable(update tgbotapi.Update) error {
 	return nil
 }
 
-func (b *Bot) scanAndShowTurtleSignals(chatID int64) {
-	// Создаем стратегию
-	strategy := analysis.NewTurtleStrategy(
-		b.apiClient,
-		b.config.Strategy.Turtles.LookbackPeriod,
-		b.config.Strategy.Turtles.EntryBreakoutDays,
-		b.config.Strategy.Turtles.ExitBreakoutDays,
-		b.config.Strategy.Turtles.AtrPeriod,
-		b.config.Strategy.Turtles.AtrMultiplier,
-		b.config.Strategy.Turtles.RiskPerTrade,
-	)
-
-	// Получаем инструменты
-	instruments, err := b.apiClient.GetInstruments(context.Background())
+func (b *Bot) scanAndShowTurtleSignals(chatID, userID int64) {
+	result, err := b.scanInstruments(context.Background(), b.createTurtleStrategy(), b.accountFor(userID), userID)
+	if errors.Is(err, api.ErrCircuitOpen) {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Сканирование прервано: API недоступен\n\n%v\n\nСостояние можно проверить командой /health", err))
+		return
+	}
 	if err != nil {
-		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка получения инструментов: %v", err))
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v", err))
 		return
 	}
 
-	if len(instruments) == 0 {
+	if result.Total == 0 {
 		b.sendFormattedMessage(chatID, "📭 Нет доступных инструментов для анализа")
 		return
 	}
 
-	// Анализируем каждый инструмент
-	var allSignals []analysis.Signal
-	totalInstruments := 0
-
-	for _, instrument := range instruments {
-		totalInstruments++
-		signals, err := strategy.AnalyzeInstrument(context.Background(), instrument)
-		if err != nil {
-			b.logger.Debug("Ошибка анализа инструмента", "instrument", instrument, "error", err)
-			continue
-		}
-
-		if len(signals) > 0 {
-			allSignals = append(allSignals, signals...)
-		}
-	}
+	allSignals := result.Signals
+	b.recordScanExport(chatID, "turtle", allSignals)
 
 	// Формируем сообщение с результатами
 	//nolint:gocritic
 	msg := "📈 РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ\n\n"
-	msg += fmt.Sprintf("📊 Проанализировано инструментов: %d\n", totalInstruments)
+	msg += fmt.Sprintf("📊 Проанализировано инструментов: %d\n", result.Total)
+	if result.Watchlist {
+		msg += "👁 По списку наблюдения (/watchlist)\n"
+	}
 	msg += fmt.Sprintf("🚨 Найдено сигналов: %d\n\n", len(allSignals))
 
 	if len(allSignals) == 0 {
@@ -402,9 +457,10 @@ func (b *Bot) scanAndShowTurtleSignals(chatID int64) {
 	if len(entryLong) > 0 {
 		msg += "🟢 СИГНАЛЫ НА ПОКУПКУ:\n"
 		for _, signal := range entryLong {
-			msg += fmt.Sprintf("• %s - %.2f₽\n", signal.Instrument, signal.Price)
-			msg += fmt.Sprintf("  Стоп: %.2f | Тейк: %.2f\n", signal.StopLoss, signal.TakeProfit)
-			msg += fmt.Sprintf("  Размер: %.0f шт. | Риск: %.1f%%\n", signal.PositionSize, b.config.Strategy.Turtles.RiskPerTrade*100)
+			msg += fmt.Sprintf("• %s - %.2f₽%s\n", signal.Instrument, signal.Price, formatConfidence(signal))
+			msg += fmt.Sprintf("  System %s | Стоп: %.2f\n", signal.System, signal.StopLoss)
+			msg += fmt.Sprintf("  Юнит: %s | Риск: %.1f%%\n", formatPosition(signal), signal.RiskPerTrade*100)
+			msg += formatPyramid(signal.Pyramid)
 			msg += fmt.Sprintf("  📅 %s\n\n", signal.Timestamp.Format("02.01 15:04"))
 		}
 	}
@@ -412,9 +468,10 @@ func (b *Bot) scanAndShowTurtleSignals(chatID int64) {
 	if len(entryShort) > 0 {
 		msg += "🔴 СИГНАЛЫ НА ПРОДАЖУ:\n"
 		for _, signal := range entryShort {
-			msg += fmt.Sprintf("• %s - %.2f₽\n", signal.Instrument, signal.Price)
-			msg += fmt.Sprintf("  Стоп: %.2f | Тейк: %.2f\n", signal.StopLoss, signal.TakeProfit)
-			msg += fmt.Sprintf("  Размер: %.0f шт. | Риск: %.1f%%\n", signal.PositionSize, b.config.Strategy.Turtles.RiskPerTrade*100)
+			msg += fmt.Sprintf("• %s - %.2f₽%s\n", signal.Instrument, signal.Price, formatConfidence(signal))
+			msg += fmt.Sprintf("  System %s | Стоп: %.2f\n", signal.System, signal.StopLoss)
+			msg += fmt.Sprintf("  Юнит: %s | Риск: %.1f%%\n", formatPosition(signal), signal.RiskPerTrade*100)
+			msg += formatPyramid(signal.Pyramid)
 			msg += fmt.Sprintf("  📅 %s\n\n", signal.Timestamp.Format("02.01 15:04"))
 		}
 	}
@@ -451,24 +508,17 @@ func (b *Bot) scanAndShowTurtleSignals(chatID int64) {
 			tgbotapi.NewInlineKeyboardButtonData("📊 Подробнее о стратегии", "help_strategy"),
 			tgbotapi.NewInlineKeyboardButtonData("📈 Тестировать", "turtle_test"),
 		),
+		exportButtons("export_scan_turtle_"),
 	)
 
 	b.sendSafeMessageWithKeyboard(chatID, msg, keyboard)
 }
 
-func (b *Bot) runTurtleTest(chatID int64, instrument string) {
+func (b *Bot) runTurtleTest(chatID, userID int64, instrument string) {
 	b.sendFormattedMessage(chatID, fmt.Sprintf("🧪 Тестирование стратегии для %s...", instrument))
 
 	// Создаем стратегию
-	strategy := analysis.NewTurtleStrategy(
-		b.apiClient,
-		b.config.Strategy.Turtles.LookbackPeriod,
-		b.config.Strategy.Turtles.EntryBreakoutDays,
-		b.config.Strategy.Turtles.ExitBreakoutDays,
-		b.config.Strategy.Turtles.AtrPeriod,
-		b.config.Strategy.Turtles.AtrMultiplier,
-		b.config.Strategy.Turtles.RiskPerTrade,
-	)
+	strategy := b.createTurtleStrategy()
 
 	// Анализируем инструмент
 	signals, err := strategy.AnalyzeInstrument(context.Background(), instrument)
@@ -476,6 +526,7 @@ func (b *Bot) runTurtleTest(chatID int64, instrument string) {
 		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка тестирования: %v", err))
 		return
 	}
+	b.sizeSignals(context.Background(), signals, b.accountFor(userID))
 
 	// Формируем отчет
 	msg := fmt.Sprintf("📊 ОТЧЕТ ПО ТЕСТИРОВАНИЮ: %s\n\n", instrument)
@@ -514,8 +565,11 @@ func (b *Bot) runTurtleTest(chatID int64, instrument string) {
 					(math.Abs(signal.TakeProfit-signal.Price)/signal.Price)*100)
 			}
 
-			if signal.PositionSize > 0 {
-				msg += fmt.Sprintf("• Размер позиции: %.0f шт.\n", signal.PositionSize)
+			if signal.Sized() {
+				msg += fmt.Sprintf("• Размер позиции: %s\n", formatPosition(signal))
+			}
+			if signal.System != "" {
+				msg += fmt.Sprintf("• Система: %s\n", signal.System)
 			}
 
 			msg += fmt.Sprintf("• Причина: %s\n", signal.Reason)
@@ -536,6 +590,176 @@ func (b *Bot) runTurtleTest(chatID int64, instrument string) {
 	)
 
 	b.sendSafeMessageWithKeyboard(chatID, msg, keyboard)
+	b.sendStrategyChart(chatID, strategy, instrument, signals)
+}
+
+func (b *Bot) runTurtleBacktest(chatID, userID int64, instrument string, from, to time.Time) {
+	strategy := b.createTurtleStrategy()
+
+	// Начальный капитал - размер счета пользователя
+	backtestConfig := analysis.DefaultBacktestConfig()
+	backtestConfig.InitialCapital = b.accountFor(userID).Equity
+	backtester := analysis.NewBacktester(strategy, backtestConfig)
+
+	result, err := backtester.Backtest(context.Background(), instrument, from, to)
+	if err != nil {
+		b.logger.Error("Ошибка бэктеста", "instrument", instrument, "error", err)
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка бэктеста %s: %v", instrument, err))
+		return
+	}
+
+	b.mu.Lock()
+	b.backtests[instrument] = result
+	b.mu.Unlock()
+
+	msg := fmt.Sprintf("🧪 БЭКТЕСТ 'ЧЕРЕПАХ': %s\n", instrument)
+	msg += fmt.Sprintf("📅 %s - %s\n\n", result.From.Format("02.01.2006"), result.To.Format("02.01.2006"))
+
+	msg += "💰 РЕЗУЛЬТАТ:\n"
+	msg += fmt.Sprintf("• Начальный капитал: %.0f₽\n", result.InitialCapital)
+	msg += fmt.Sprintf("• Итоговый капитал: %.0f₽\n", result.FinalEquity)
+	msg += fmt.Sprintf("• Доходность: %+.2f%%\n", result.TotalReturn)
+	msg += fmt.Sprintf("• Годовая доходность (CAGR): %+.2f%%\n", result.CAGR)
+	msg += fmt.Sprintf("• Макс. просадка: %.2f%%\n\n", result.MaxDrawdown)
+
+	msg += "📊 СДЕЛКИ:\n"
+	msg += fmt.Sprintf("• Всего: %d (✅ %d / ❌ %d)\n", len(result.Trades), result.Wins, result.Losses)
+	msg += fmt.Sprintf("• Доля прибыльных: %.1f%%\n", result.WinRate)
+	msg += fmt.Sprintf("• Профит-фактор: %s\n", formatProfitFactor(result.ProfitFactor))
+	msg += fmt.Sprintf("• Средняя прибыль: %.0f₽ | Средний убыток: %.0f₽\n\n", result.AvgWin, result.AvgLoss)
+
+	if len(result.Trades) > 0 {
+		trades := result.Trades
+		if len(trades) > maxBacktestTrades {
+			trades = trades[len(trades)-maxBacktestTrades:]
+			msg += fmt.Sprintf("📋 ПОСЛЕДНИЕ %d СДЕЛОК:\n", maxBacktestTrades)
+		} else {
+			msg += "📋 СПИСОК СДЕЛОК:\n"
+		}
+
+		for _, trade := range trades {
+			icon := "🟢"
+			if trade.Direction == "short" {
+				icon = "🔴"
+			}
+			units := ""
+			if trade.Units > 1 {
+				units = fmt.Sprintf(" ×%d юн.", trade.Units)
+			}
+			msg += fmt.Sprintf("%s S%s %s %.2f%s → %s %.2f | %+.0f₽ (%+.1f%%) %s\n",
+				icon, trade.System,
+				trade.EntryDate.Format("02.01.06"), trade.EntryPrice, units,
+				trade.ExitDate.Format("02.01.06"), trade.ExitPrice,
+				trade.PnL, trade.PnLPercent,
+				getExitReasonText(trade.ExitReason))
+		}
+	} else {
+		msg += "📭 За период не было сделок\n"
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Статистика", "turtle_stats"),
+			tgbotapi.NewInlineKeyboardButtonData("⚙️ Настройки", "turtle_config"),
+		),
+	)
+
+	b.sendSafeMessageWithKeyboard(chatID, msg, keyboard)
+}
+
+// formatBacktestSummary краткая сводка по сохраненным бэктестам для /turtle_stats
+func (b *Bot) formatBacktestSummary() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.backtests) == 0 {
+		return "• Нет данных. Запустите /turtle_backtest SBER\n"
+	}
+
+	instruments := make([]string, 0, len(b.backtests))
+	for instrument := range b.backtests {
+		instruments = append(instruments, instrument)
+	}
+	sort.Strings(instruments)
+
+	msg := ""
+	for _, instrument := range instruments {
+		result := b.backtests[instrument]
+		msg += fmt.Sprintf("• %s (%s - %s): %+.1f%%, сделок %d, прибыльных %.0f%%, PF %s, просадка %.1f%%\n",
+			instrument,
+			result.From.Format("01.2006"), result.To.Format("01.2006"),
+			result.TotalReturn, len(result.Trades), result.WinRate,
+			formatProfitFactor(result.ProfitFactor), result.MaxDrawdown)
+	}
+	return msg
+}
+
+// formatProfitFactor форматирует профит-фактор (бесконечность - когда нет убыточных сделок)
+func formatProfitFactor(pf float64) string {
+	if math.IsInf(pf, 1) {
+		return "∞"
+	}
+	return fmt.Sprintf("%.2f", pf)
+}
+
+// formatPyramid уровни добавления юнитов для списка сигналов
+func formatPyramid(levels []analysis.PyramidLevel) string {
+	if len(levels) == 0 {
+		return ""
+	}
+
+	var adds []string
+	for _, level := range levels {
+		adds = append(adds, fmt.Sprintf("%.2f", level.Price))
+	}
+	last := levels[len(levels)-1]
+	return fmt.Sprintf("  Добавление юнитов: %s (стоп до %.2f)\n", strings.Join(adds, " → "), last.StopLoss)
+}
+
+// getTurtleSystemText описание выбранной системы "Черепах" и правил пирамидинга
+func (b *Bot) getTurtleSystemText() string {
+	cfg := b.config.Strategy.Turtles
+	rules := analysis.DefaultTurtleRules()
+	if cfg.System2EntryDays > 0 && cfg.System2ExitDays > 0 {
+		rules.System2EntryDays, rules.System2ExitDays = cfg.System2EntryDays, cfg.System2ExitDays
+	}
+	if cfg.MaxUnits > 0 {
+		rules.MaxUnits = cfg.MaxUnits
+	}
+	if cfg.PyramidStep > 0 {
+		rules.PyramidStep = cfg.PyramidStep
+	}
+
+	system1 := fmt.Sprintf("System 1 (%d/%d)", cfg.EntryBreakoutDays, cfg.ExitBreakoutDays)
+	system2 := fmt.Sprintf("System 2 (%d/%d)", rules.System2EntryDays, rules.System2ExitDays)
+
+	var text string
+	switch cfg.System {
+	case analysis.TurtleSystem2:
+		text = system2
+	case analysis.TurtleSystemBoth:
+		text = system1 + " + " + system2
+	default:
+		text = system1
+	}
+	if cfg.SkipAfterWin && cfg.System != analysis.TurtleSystem2 {
+		text += ", пропуск после прибыльного прорыва"
+	}
+	return text + fmt.Sprintf(", до %d юнитов через %.1fN", rules.MaxUnits, rules.PyramidStep)
+}
+
+// getExitReasonText описание причины выхода из сделки
+func getExitReasonText(reason string) string {
+	switch reason {
+	case "stop":
+		return "стоп"
+	case "exit_breakout":
+		return "выход по прорыву"
+	case "end":
+		return "конец периода"
+	default:
+		return reason
+	}
 }
 
 func (b *Bot) getTurtleStatus() string {
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// scanTargets инструменты для сканирования: список наблюдения пользователя, если
+// он включил сканирование по списку, иначе все инструменты источника данных.
+// Второе значение true - используется список наблюдения.
+func (b *Bot) scanTargets(ctx context.Context, userID int64) ([]string, bool, error) {
+	if userID != 0 && b.store.User(userID).WatchlistScans {
+		if watchlist := b.store.Watchlist(userID); len(watchlist) > 0 {
+			return watchlist, true, nil
+		}
+	}
+
+	instruments, err := b.source.GetInstruments(ctx)
+	return instruments, false, err
+}
+
+// notifyWatchers отправляет пользователям, включившим уведомления по списку
+// наблюдения, сигналы по их инструментам с размером позиции под их счет
+func (b *Bot) notifyWatchers(ctx context.Context, strategy string, signals []analysis.Signal) {
+	for userID, watchlist := range b.store.WatchlistSubscribers() {
+		var watched []analysis.Signal
+		for _, signal := range signals {
+			if containsString(watchlist, signal.Instrument) {
+				watched = append(watched, signal)
+			}
+		}
+		if len(watched) == 0 {
+			continue
+		}
+
+		b.sizeSignals(ctx, watched, b.accountFor(userID))
+		if err := b.sendStrategyNotification(strategy, watched, userID); err != nil {
+			b.logger.Warn("Ошибка отправки уведомления по списку наблюдения",
+				"strategy", strategy,
+				"user_id", userID,
+				"error", err)
+		}
+	}
+}
+
+// containsString есть ли значение в списке
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWatchArgs разбирает тикеры аргументов /watch и /unwatch. Возвращает
+// нормализованные тикеры и неверные аргументы.
+func (b *Bot) parseWatchArgs(args []string) ([]string, []string) {
+	var instruments, invalid []string
+	for _, arg := range args {
+		for _, ticker := range strings.Split(arg, ",") {
+			if ticker = strings.TrimSpace(ticker); ticker == "" {
+				continue
+			}
+			instrument := b.normalizeInstrument(ticker)
+			if !b.isValidInstrument(instrument) {
+				invalid = append(invalid, ticker)
+				continue
+			}
+			if !containsString(instruments, instrument) {
+				instruments = append(instruments, instrument)
+			}
+		}
+	}
+	return instruments, invalid
+}
+
+// handleWatch обработчик команды /watch ТИКЕР [ТИКЕР...] - добавить инструменты в список наблюдения
+func (b *Bot) handleWatch(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	instruments, invalid := b.parseWatchArgs(strings.Fields(update.Message.CommandArguments()))
+	if len(invalid) > 0 {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный формат тикера: %s", strings.Join(invalid, ", ")))
+	}
+	if len(instruments) == 0 {
+		return b.sendFormattedMessage(chatID, "👁 Использование: /watch ТИКЕР [ТИКЕР...]\n\nПример: /watch SBER GAZP LKOH\n\nСписок наблюдения: /watchlist")
+	}
+
+	added, err := b.store.Watch(userID, instruments)
+	if err != nil && len(added) == 0 {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v", err))
+	}
+
+	msg := ""
+	if len(added) > 0 {
+		msg += fmt.Sprintf("✅ Добавлено в список наблюдения: %s\n", strings.Join(added, ", "))
+	} else {
+		msg += "ℹ️ Инструменты уже в списке наблюдения\n"
+	}
+	if err != nil {
+		msg += fmt.Sprintf("⚠️ %v\n", err)
+	}
+	msg += fmt.Sprintf("\n👁 В списке: %d (/watchlist)", len(b.store.Watchlist(userID)))
+
+	return b.sendFormattedMessage(chatID, msg)
+}
+
+// handleUnwatch обработчик команды /unwatch ТИКЕР [ТИКЕР...] | all - удалить инструменты из списка наблюдения
+func (b *Bot) handleUnwatch(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendFormattedMessage(chatID, "👁 Использование: /unwatch ТИКЕР [ТИКЕР...] или /unwatch all\n\nСписок наблюдения: /watchlist")
+	}
+
+	var instruments []string
+	if !(len(args) == 1 && strings.EqualFold(args[0], "all")) {
+		var invalid []string
+		instruments, invalid = b.parseWatchArgs(args)
+		if len(invalid) > 0 {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неверный формат тикера: %s", strings.Join(invalid, ", ")))
+		}
+	}
+
+	removed, err := b.store.Unwatch(userID, instruments)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка сохранения списка наблюдения: %v", err))
+	}
+	if len(removed) == 0 {
+		return b.sendFormattedMessage(chatID, "ℹ️ Этих инструментов нет в списке наблюдения\n\nСписок наблюдения: /watchlist")
+	}
+
+	return b.sendFormattedMessage(chatID, fmt.Sprintf("✅ Удалено из списка наблюдения: %s\n\n👁 В списке: %d (/watchlist)",
+		strings.Join(removed, ", "), len(b.store.Watchlist(userID))))
+}
+
+// handleWatchlist обработчик команды /watchlist - список наблюдения с кнопками
+func (b *Bot) handleWatchlist(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	return b.sendWatchlist(chatID, userID)
+}
+
+// sendWatchlist отправляет список наблюдения пользователя с кнопками удаления и настроек
+func (b *Bot) sendWatchlist(chatID, userID int64) error {
+	watchlist := b.store.Watchlist(userID)
+	settings := b.store.User(userID)
+
+	msg := "👁 СПИСОК НАБЛЮДЕНИЯ\n\n"
+	if len(watchlist) == 0 {
+		msg += "📭 Список пуст\n\n"
+		msg += "Добавьте инструменты: /watch SBER GAZP\n\n"
+		msg += "Сканирования (/scan, /turtle_signals, /ma_signals) можно ограничить списком, а уведомления фонового анализа - получать лично по своим инструментам."
+		return b.sendFormattedMessage(chatID, msg)
+	}
+
+	msg += fmt.Sprintf("📊 Инструменты (%d из %d):\n%s\n\n", len(watchlist), storage.MaxWatchlistSize, strings.Join(watchlist, ", "))
+	msg += "⚙️ НАСТРОЙКИ:\n"
+	msg += fmt.Sprintf("• Сканирование: %s\n", watchlistOption(settings.WatchlistScans, "только список", "все инструменты"))
+	msg += fmt.Sprintf("• Уведомления: %s\n\n", watchlistOption(settings.WatchlistAlerts, "сигналы по списку лично вам", "выключены"))
+	msg += "💡 /watch ТИКЕР - добавить, /unwatch ТИКЕР - удалить, ❌ - удалить кнопкой"
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, instrument := range watchlist {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("❌ "+instrument, "watch_del_"+instrument))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(watchlistOption(settings.WatchlistScans, "🔍 Сканы: список ✅", "🔍 Сканы: все"), "watch_scans"),
+			tgbotapi.NewInlineKeyboardButtonData(watchlistOption(settings.WatchlistAlerts, "🔔 Уведомления ✅", "🔕 Уведомления"), "watch_alerts"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Очистить", "watch_clear"),
+		),
+	)
+
+	return b.sendMessageWithKeyboard(chatID, msg, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// watchlistOption текст включенной или выключенной настройки
+func watchlistOption(enabled bool, on, off string) string {
+	if enabled {
+		return on
+	}
+	return off
+}
+
+// handleWatchCallback обработка кнопок списка наблюдения
+func (b *Bot) handleWatchCallback(chatID, userID int64, data string) {
+	var err error
+
+	switch {
+	case strings.HasPrefix(data, "watch_del_"):
+		_, err = b.store.Unwatch(userID, []string{strings.TrimPrefix(data, "watch_del_")})
+	case data == "watch_clear":
+		_, err = b.store.Unwatch(userID, nil)
+	case data == "watch_scans":
+		err = b.store.UpdateUser(userID, func(settings *storage.UserSettings) {
+			settings.WatchlistScans = !settings.WatchlistScans
+		})
+	case data == "watch_alerts":
+		err = b.store.UpdateUser(userID, func(settings *storage.UserSettings) {
+			settings.WatchlistAlerts = !settings.WatchlistAlerts
+		})
+	default:
+		return
+	}
+
+	if err != nil {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка сохранения списка наблюдения: %v", err))
+		return
+	}
+
+	b.sendWatchlist(chatID, userID)
+}
@@ -10,25 +10,51 @@ func (b *Bot) handleHelpCallback(chatID int64, data string) {
 	case "help_data":
 		msg := "📊 КОМАНДЫ ДАННЫХ:\n\n"
 		msg += "• /instruments - Список всех инструментов\n"
-		msg += "• /candles - Получить свечи для инструмента\n"
+		msg += "• /candles - Таблица свечей за период (7d, 1y, ytd, сегодня, даты) с выгрузкой в CSV и графиком\n"
 		msg += "• /stats - Статистика по данным\n"
 		msg += "• /tables - Список таблиц с данными\n"
 		msg += "• /timeframes - Доступные таймфреймы\n"
-		msg += "• /health - Проверка здоровья API\n\n"
+		msg += "• /health - Проверка здоровья API\n"
+		msg += "• /indicators SBER - Технические индикаторы (SMA, EMA, RSI, MACD, Bollinger)\n"
+		msg += "• /chart SBER 24 90d [sma|ema|bb|dc] - Свечной график с объемом и индикаторами\n\n"
 		msg += "💡 Просто отправьте тикер инструмента (например: SBER) для получения информации о нем."
 		b.sendFormattedMessage(chatID, msg)
 
+	case "help_strategies":
+		msg := "📋 КОМАНДЫ СТРАТЕГИЙ:\n\n"
+		msg += "• /strategies - Список стратегий, статус и параметры\n"
+		msg += "• /scan - Сканировать по всем включенным стратегиям\n"
+		msg += "• /scan turtle - Сканировать по одной стратегии\n"
+		msg += "• /equity 500000 - Размер счета для расчета позиций в сигналах\n"
+		msg += "• /signals_history [SBER|turtle] [N] - Журнал сигналов фонового анализа и их исходы\n"
+		msg += "• /watch SBER GAZP, /unwatch SBER - Список наблюдения\n"
+		msg += "• /watchlist - Список наблюдения и его настройки: сканирование только по списку, личные уведомления\n"
+		msg += "• /alert SBER > 300, /alert GAZP rsi14 < 30, /alert LKOH change1d > 5% repeat 4h - Алерты по цене и индикаторам\n"
+		msg += "• /alerts - Ваши алерты с кнопками удаления\n"
+		msg += "• /export SBER 24 2024-01-01:2024-06-30 csv|xlsx|json, /export scan, /export signals turtle - Выгрузка свечей, результатов сканирования и журнала сигналов в файл\n\n"
+		msg += "📈 MA CROSSOVER:\n"
+		msg += "• /ma - Описание и настройки\n"
+		msg += "• /ma_stats - Исходы сигналов фонового анализа\n\n"
+		msg += "📊 MACD:\n"
+		msg += "• /macd - Описание, настройки, включение\n"
+		msg += "• /scan_macd - Сканировать все инструменты\n"
+		msg += "• /macd_test - Тестирование на инструменте\n\n"
+		msg += "📏 BOLLINGER:\n"
+		msg += "• /scan bollinger - Возврат к средней от внешней полосы (с RSI) и пробой после сжатия полос\n"
+		b.sendFormattedMessage(chatID, msg)
+
 	case "help_strategy":
 		msg := "📈 КОМАНДЫ СТРАТЕГИИ 'ЧЕРЕПАХ':\n\n"
 		if b.config.Strategy.Turtles.Enabled {
 			msg += "• /turtle или /turtle_analysis - Анализ по стратегии\n"
 			msg += "• /turtle_signals - Текущие торговые сигналы\n"
 			msg += "• /scan_turtles - Сканировать все инструменты\n"
-			msg += "• /turtle_stats - Статистика работы стратегии\n"
+			msg += "• /turtle_stats - Статистика работы стратегии и исходы сигналов\n"
 			msg += "• /turtle_config - Настройки стратегии\n"
 			msg += "• /turtle_enable - Включить стратегию\n"
 			msg += "• /turtle_disable - Выключить стратегию\n"
-			msg += "• /turtle_test - Тестирование стратегии\n\n"
+			msg += "• /turtle_test - Тестирование стратегии\n"
+			msg += "• /turtle_backtest SBER 3y - Бэктест на истории\n\n"
 			msg += "📱 ТЕКСТОВЫЕ КОМАНДЫ:\n"
 			msg += "• 'сигналы' - показать сигналы\n"
 			msg += "• 'сканировать' - запустить сканирование\n"
@@ -56,6 +82,8 @@ func (b *Bot) handleHelpCallback(chatID int64, data string) {
 		msg := "👑 АДМИН КОМАНДЫ:\n\n"
 		msg += "• /admin - Админ панель\n"
 		msg += "• /users - Управление пользователями\n"
+		msg += "• /cache - Кэш свечей (статистика, очистка)\n"
+		msg += "• /schedule - Расписание фонового анализа и ближайшие запуски\n"
 		msg += "• /broadcast - Рассылка сообщений\n"
 		msg += "• /debug - Режим отладки\n"
 		msg += "• /system - Системная информация\n"
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// periodDateLayout формат дат в периодах
+const periodDateLayout = "2006-01-02"
+
+// periodHelp подсказка по форматам периода
+const periodHelp = "7d, 2w, 6m, 1y, ytd, сегодня или даты: 2024-01-01:2024-12-31"
+
+// parsePeriod разбирает период относительно now и возвращает даты начала и окончания (MSK).
+// Поддерживаются относительные периоды (7d, 2w, 6m, 1y, а также 7д, 2н, 6м, 1г),
+// ytd - с начала года, сегодня (today), диапазон дат "2024-01-01:2024-12-31"
+// и одна дата "2024-01-01" - с нее по сегодня.
+func parsePeriod(text string, now time.Time) (time.Time, time.Time, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	now = now.In(api.MSK)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, api.MSK)
+
+	switch text {
+	case "":
+		return time.Time{}, time.Time{}, fmt.Errorf("пустой период")
+	case "ytd":
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, api.MSK), today, nil
+	case "сегодня", "today":
+		return today, today, nil
+	}
+
+	// Диапазон или одна дата
+	if text[0] >= '0' && text[0] <= '9' && strings.Contains(text, "-") {
+		parts := strings.SplitN(text, ":", 2)
+
+		from, err := time.ParseInLocation(periodDateLayout, strings.TrimSpace(parts[0]), api.MSK)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("неверная дата начала %q", parts[0])
+		}
+
+		to := today
+		if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+			to, err = time.ParseInLocation(periodDateLayout, strings.TrimSpace(parts[1]), api.MSK)
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("неверная дата окончания %q", parts[1])
+			}
+		}
+
+		if to.Before(from) {
+			return time.Time{}, time.Time{}, fmt.Errorf("дата окончания раньше даты начала")
+		}
+		return from, to, nil
+	}
+
+	// Относительный период: число и единица измерения
+	runes := []rune(text)
+	unit := string(runes[len(runes)-1])
+	count, err := strconv.Atoi(string(runes[:len(runes)-1]))
+	if err != nil || count <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("неверный период %q", text)
+	}
+
+	var from time.Time
+	switch unit {
+	case "d", "д":
+		from = today.AddDate(0, 0, -count)
+	case "w", "н":
+		from = today.AddDate(0, 0, -7*count)
+	case "m", "м":
+		from = today.AddDate(0, -count, 0)
+	case "y", "г":
+		from = today.AddDate(-count, 0, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("неизвестная единица периода %q", unit)
+	}
+
+	return from, today, nil
+}
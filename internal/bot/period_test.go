@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Date(2024, 3, 15, 14, 30, 0, 0, api.MSK)
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, api.MSK)
+	}
+
+	tests := []struct {
+		input    string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantErr  bool
+	}{
+		{input: "30d", wantFrom: date(2024, 2, 14), wantTo: date(2024, 3, 15)},
+		{input: "2w", wantFrom: date(2024, 3, 1), wantTo: date(2024, 3, 15)},
+		{input: "6m", wantFrom: date(2023, 9, 15), wantTo: date(2024, 3, 15)},
+		{input: "1y", wantFrom: date(2023, 3, 15), wantTo: date(2024, 3, 15)},
+		{input: "1Г", wantFrom: date(2023, 3, 15), wantTo: date(2024, 3, 15)},
+		{input: "7д", wantFrom: date(2024, 3, 8), wantTo: date(2024, 3, 15)},
+		{input: "2020-01-01:2023-12-31", wantFrom: date(2020, 1, 1), wantTo: date(2023, 12, 31)},
+		{input: "2024-01-01", wantFrom: date(2024, 1, 1), wantTo: date(2024, 3, 15)},
+		{input: "ytd", wantFrom: date(2024, 1, 1), wantTo: date(2024, 3, 15)},
+		{input: "YTD", wantFrom: date(2024, 1, 1), wantTo: date(2024, 3, 15)},
+		{input: "сегодня", wantFrom: date(2024, 3, 15), wantTo: date(2024, 3, 15)},
+		{input: "today", wantFrom: date(2024, 3, 15), wantTo: date(2024, 3, 15)},
+		{input: "", wantErr: true},
+		{input: "0d", wantErr: true},
+		{input: "10x", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "2024-13-01:2024-12-31", wantErr: true},
+		{input: "2024-12-31:2024-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			from, to, err := parsePeriod(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePeriod(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("parsePeriod(%q) = %s..%s, want %s..%s", tt.input,
+					from.Format("2006-01-02"), to.Format("2006-01-02"),
+					tt.wantFrom.Format("2006-01-02"), tt.wantTo.Format("2006-01-02"))
+			}
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// scanPause пауза между запросами свечей при сканировании инструментов
+const scanPause = 100 * time.Millisecond
+
+// defaultMaxNotificationSignals сигналов в уведомлении, если max_signals_per_notification не задан
+const defaultMaxNotificationSignals = 10
+
+// scanResult результат сканирования инструментов одной стратегией
+type scanResult struct {
+	Signals   []analysis.Signal
+	Total     int  // Всего инструментов
+	Analyzed  int  // Успешно проанализировано
+	Watchlist bool // Сканировался список наблюдения пользователя
+}
+
+// registerStrategies регистрирует стратегии бота. Новая стратегия добавляется
+// здесь и автоматически попадает в фоновый анализ, уведомления, /scan и /strategies.
+func (b *Bot) registerStrategies() {
+	registrations := []analysis.Registration{
+		{
+			Name:     "turtle",
+			Title:    "Черепахи",
+			Schedule: "0 * * * *",
+			Enabled:  func() bool { return b.config.Strategy.Turtles.Enabled },
+			New:      func() analysis.Strategy { return b.createTurtleStrategy() },
+		},
+		{
+			Name:     "ma_crossover",
+			Title:    "MA Crossover",
+			Schedule: "0 */2 * * *",
+			Enabled:  func() bool { return b.config.Strategy.MACrossover.Enabled },
+			New:      func() analysis.Strategy { return b.createMACrossoverStrategy() },
+		},
+		{
+			Name:     "macd",
+			Title:    "MACD",
+			Schedule: "0 */2 * * *",
+			Enabled:  func() bool { return b.config.Strategy.MACD.Enabled },
+			New:      func() analysis.Strategy { return b.createMACDStrategy() },
+		},
+		{
+			Name:     "bollinger",
+			Title:    "Bollinger",
+			Schedule: "0 */2 * * *",
+			Enabled:  func() bool { return b.config.Strategy.Bollinger.Enabled },
+			New:      func() analysis.Strategy { return b.createBollingerStrategy() },
+		},
+	}
+
+	for _, reg := range registrations {
+		if err := b.strategies.Register(reg); err != nil {
+			b.logger.Error("Ошибка регистрации стратегии", "strategy", reg.Name, "error", err)
+		}
+	}
+}
+
+// createTurtleStrategy создает стратегию "Черепах" из конфига. Незаполненные
+// правила системы берутся из analysis.DefaultTurtleRules.
+func (b *Bot) createTurtleStrategy() *analysis.TurtleStrategy {
+	cfg := b.config.Strategy.Turtles
+
+	return analysis.NewTurtleStrategy(
+		b.candles,
+		cfg.LookbackPeriod,
+		cfg.EntryBreakoutDays,
+		cfg.ExitBreakoutDays,
+		cfg.AtrPeriod,
+		cfg.AtrMultiplier,
+		cfg.RiskPerTrade,
+	).WithRules(analysis.TurtleRules{
+		System:           cfg.System,
+		System2EntryDays: cfg.System2EntryDays,
+		System2ExitDays:  cfg.System2ExitDays,
+		SkipAfterWin:     cfg.SkipAfterWin,
+		MaxUnits:         cfg.MaxUnits,
+		PyramidStep:      cfg.PyramidStep,
+	})
+}
+
+// createBollingerStrategy создает стратегию Боллинджера из конфига. Нулевые
+// период, ширина полос и период RSI берутся из секции technical.
+func (b *Bot) createBollingerStrategy() *analysis.BollingerStrategy {
+	cfg := b.config.Strategy.Bollinger
+	technical := b.config.Technical
+
+	bollingerConfig := analysis.BollingerConfig{
+		Timeframe:             cfg.Timeframe,
+		Mode:                  cfg.Mode,
+		Period:                cfg.Period,
+		StdDev:                cfg.StdDev,
+		RSIPeriod:             cfg.RSIPeriod,
+		RSIOverbought:         float64(cfg.RSIOverbought),
+		RSIOversold:           float64(cfg.RSIOversold),
+		SqueezeLookback:       cfg.SqueezeLookback,
+		AtrPeriod:             cfg.AtrPeriod,
+		RiskPerTrade:          cfg.RiskPerTrade,
+		StopLossATRMultiplier: cfg.StopLossATRMultiplier,
+		TakeProfitRatio:       cfg.TakeProfitRatio,
+	}
+
+	if bollingerConfig.Period == 0 {
+		bollingerConfig.Period = technical.BollingerPeriod
+	}
+	if bollingerConfig.StdDev == 0 {
+		bollingerConfig.StdDev = float64(technical.BollingerStd)
+	}
+	if bollingerConfig.RSIPeriod == 0 {
+		bollingerConfig.RSIPeriod = technical.RSIPeriod
+	}
+
+	return analysis.NewBollingerStrategy(b.candles, bollingerConfig)
+}
+
+// scanInstruments анализирует все инструменты источника данных стратегией и
+// рассчитывает размер позиций под счет account. Если userID включил сканирование
+// по списку наблюдения, анализируется только его список (0 - все инструменты).
+// При разомкнутом circuit breaker сканирование прерывается и возвращается
+// частичный результат вместе с ошибкой.
+func (b *Bot) scanInstruments(ctx context.Context, strategy analysis.Strategy, account analysis.Account, userID int64) (*scanResult, error) {
+	instruments, watchlist, err := b.scanTargets(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения инструментов: %w", err)
+	}
+
+	result := &scanResult{Total: len(instruments), Watchlist: watchlist}
+
+	for i, instrument := range instruments {
+		// Пропускаем некорректные инструменты
+		if !b.isValidInstrument(instrument) {
+			continue
+		}
+
+		signals, err := analysis.AnalyzeWith(ctx, b.candles, strategy, instrument)
+		if errors.Is(err, api.ErrCircuitOpen) {
+			return result, err
+		}
+		if err != nil {
+			b.logger.Debug("Ошибка анализа инструмента",
+				"strategy", strategy.Name(),
+				"instrument", instrument,
+				"error", err)
+			continue
+		}
+
+		b.sizeSignals(ctx, signals, account)
+
+		result.Analyzed++
+		result.Signals = append(result.Signals, signals...)
+
+		// Делаем паузу между запросами
+		if i < len(instruments)-1 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(scanPause):
+			}
+		}
+	}
+
+	b.recordScan(strategy.Name())
+	return result, nil
+}
+
+// analyzeStrategy фоновый анализ стратегии с отправкой уведомлений о сигналах на вход
+func (b *Bot) analyzeStrategy(ctx context.Context, name string) {
+	reg, ok := b.strategies.Get(name)
+	if !ok {
+		return
+	}
+
+	b.logger.Debug("Запуск анализа стратегии", "strategy", name)
+
+	// Проверяем, нужно ли отправлять уведомления: в общий чат или по спискам наблюдения
+	if !b.config.Strategy.Notifications.Enabled ||
+		(b.config.Strategy.Notifications.SignalChatID == 0 && len(b.store.WatchlistSubscribers()) == 0) {
+		b.logger.Debug("Уведомления отключены", "strategy", name)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	strategy := reg.New()
+	result, err := b.scanInstruments(ctx, strategy, b.defaultAccount(), 0)
+	if err != nil {
+		if result == nil {
+			b.logger.Error("Ошибка получения инструментов для анализа",
+				"strategy", name,
+				"error", err)
+			return
+		}
+		b.logger.Warn("Анализ прерван",
+			"strategy", name,
+			"analyzed", result.Analyzed,
+			"error", err)
+	}
+
+	// Журнал отсеивает сигналы, о которых уже уведомляли
+	pending, ids, err := b.journalSignals(name, result.Signals)
+	if err != nil {
+		b.logger.Warn("Ошибка записи журнала сигналов",
+			"strategy", name,
+			"error", err)
+	}
+
+	// Свечи обновлены - проверяем исходы прошлых сигналов
+	b.trackOutcomes(ctx, strategy)
+
+	// Уведомляем только о новых сигналах на вход с достаточной уверенностью,
+	// самые уверенные - первыми
+	minConfidence := b.config.Strategy.Notifications.MinConfidencePercent
+	var signals []analysis.Signal
+	lowConfidence := 0
+	for _, signal := range pending {
+		if signal.Confidence < minConfidence {
+			lowConfidence++
+			continue
+		}
+		signals = append(signals, signal)
+	}
+	rankSignals(signals)
+
+	b.logger.Info("Анализ стратегии завершен",
+		"strategy", name,
+		"instruments", result.Analyzed,
+		"signals", len(signals),
+		"low_confidence", lowConfidence)
+
+	// Если есть сигналы, отправляем уведомление
+	if len(signals) == 0 {
+		return
+	}
+	if err := b.sendStrategyNotification(name, signals, b.config.Strategy.Notifications.SignalChatID); err != nil {
+		return
+	}
+	b.notifyWatchers(ctx, name, signals)
+	if err := b.markNotified(signals, ids); err != nil {
+		b.logger.Warn("Ошибка записи журнала сигналов",
+			"strategy", name,
+			"error", err)
+	}
+}
+
+// formatConfidence уверенность сигнала на вход для строки списка сигналов
+func formatConfidence(signal analysis.Signal) string {
+	if signal.SignalType != "entry_long" && signal.SignalType != "entry_short" {
+		return ""
+	}
+	return fmt.Sprintf(" 🎯 %.0f%%", signal.Confidence)
+}
+
+// rankSignals сортирует сигналы по убыванию уверенности
+func rankSignals(signals []analysis.Signal) {
+	sort.SliceStable(signals, func(i, j int) bool {
+		return signals[i].Confidence > signals[j].Confidence
+	})
+}
+
+// handleScan обработчик команды /scan [стратегия]
+func (b *Bot) handleScan(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	userID, err := b.getUserID(update)
+	if err != nil {
+		return b.sendFormattedMessage(chatID, "❌ Не удалось определить пользователя")
+	}
+
+	var registrations []analysis.Registration
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		registrations = b.strategies.Enabled()
+		if len(registrations) == 0 {
+			return b.sendFormattedMessage(chatID, "❌ Нет включенных стратегий.\n\nСписок стратегий: /strategies")
+		}
+	} else {
+		name := strings.ToLower(args[0])
+		reg, ok := b.strategies.Get(name)
+		if !ok {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неизвестная стратегия: %s\n\nДоступные стратегии: %s",
+				args[0], strings.Join(b.strategies.Names(), ", ")))
+		}
+		if !reg.Enabled() {
+			return b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Стратегия '%s' отключена.\n\nСписок стратегий: /strategies", reg.Title))
+		}
+		registrations = append(registrations, reg)
+	}
+
+	titles := make([]string, 0, len(registrations))
+	for _, reg := range registrations {
+		titles = append(titles, reg.Title)
+	}
+
+	b.sendFormattedMessage(chatID, fmt.Sprintf("🔍 Сканирование всех инструментов: %s\n\n⏳ Это может занять несколько минут.",
+		strings.Join(titles, ", ")))
+
+	// Запускаем сканирование в фоне
+	go func() {
+		for _, reg := range registrations {
+			b.scanAndShowSignals(chatID, userID, reg)
+		}
+	}()
+
+	return nil
+}
+
+// scanAndShowSignals сканирует инструменты стратегией и отправляет отчет
+func (b *Bot) scanAndShowSignals(chatID, userID int64, reg analysis.Registration) {
+	result, err := b.scanInstruments(context.Background(), reg.New(), b.accountFor(userID), userID)
+	if errors.Is(err, api.ErrCircuitOpen) {
+		// Без списка инструментов результата нет: цепь могла разомкнуться до начала сканирования
+		analyzed := 0
+		if result != nil {
+			analyzed = result.Analyzed
+		}
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Сканирование '%s' прервано: API недоступен\n\nПроанализировано инструментов: %d\nСостояние можно проверить командой /health",
+			reg.Title, analyzed))
+		return
+	}
+	if err != nil {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ %v", err))
+		return
+	}
+
+	// Информационные сигналы (например, отфильтрованные пересечения) видны в тесте стратегии
+	var signals []analysis.Signal
+	for _, signal := range result.Signals {
+		if signal.SignalType != "no_signal" {
+			signals = append(signals, signal)
+		}
+	}
+
+	b.recordScanExport(chatID, reg.Name, signals)
+
+	msg := fmt.Sprintf("📈 РЕЗУЛЬТАТЫ СКАНИРОВАНИЯ: %s\n\n", reg.Title)
+	msg += fmt.Sprintf("📊 Проанализировано инструментов: %d из %d\n", result.Analyzed, result.Total)
+	if result.Watchlist {
+		msg += "👁 По списку наблюдения (/watchlist)\n"
+	}
+	msg += fmt.Sprintf("🚨 Найдено сигналов: %d\n\n", len(signals))
+
+	if len(signals) == 0 {
+		msg += "📭 Торговых сигналов не найдено\n"
+		b.sendFormattedMessage(chatID, msg)
+		return
+	}
+
+	for _, signal := range signals {
+		msg += fmt.Sprintf("%s %s - %.2f₽%s\n", b.getSignalTypeText(signal.SignalType), signal.Instrument, signal.Price, formatConfidence(signal))
+		if signal.StopLoss > 0 && signal.TakeProfit > 0 {
+			msg += fmt.Sprintf("  Стоп: %.2f | Тейк: %.2f\n", signal.StopLoss, signal.TakeProfit)
+		}
+		if signal.Sized() {
+			msg += fmt.Sprintf("  Размер: %s\n", formatPosition(signal))
+		}
+	}
+
+	msg += "\nПараметры стратегии: /strategies\n"
+	msg += fmt.Sprintf("📤 Выгрузка: /export scan %s", reg.Name)
+
+	b.sendHTMLWithKeyboard(chatID, msg, tgbotapi.NewInlineKeyboardMarkup(
+		exportButtons(fmt.Sprintf("export_scan_%s_", reg.Name)),
+	))
+}
+
+// handleStrategies обработчик команды /strategies - список стратегий и их параметров
+func (b *Bot) handleStrategies(update tgbotapi.Update) error {
+	chatID, err := b.getChatID(update)
+	if err != nil {
+		return err
+	}
+
+	msg := "📋 СТРАТЕГИИ\n\n"
+
+	for _, reg := range b.strategies.List() {
+		status := "🔴 выключена"
+		if reg.Enabled() {
+			status = "🟢 включена"
+		}
+
+		strategy := reg.New()
+		msg += fmt.Sprintf("📈 %s (%s) - %s\n", reg.Title, reg.Name, status)
+		for _, param := range strategy.Params() {
+			msg += fmt.Sprintf("• %s: %s\n", param.Name, param.Value)
+		}
+		msg += fmt.Sprintf("• Фоновый анализ: %s\n", b.scheduleText(reg.Name))
+		msg += fmt.Sprintf("• Сканирование: /scan %s\n\n", reg.Name)
+	}
+
+	msg += "💡 /scan без параметров сканирует все включенные стратегии"
+
+	return b.sendFormattedMessage(chatID, msg)
+}
+
+// handleStrategyTestStep шаг диалога тестирования стратегии: ввод тикера
+func (b *Bot) handleStrategyTestStep(chatID, userID int64, name, text string) {
+	instrument := b.normalizeInstrument(text)
+
+	if !b.isValidInstrument(instrument) {
+		b.sendFormattedMessage(chatID, "❌ Неверный тикер инструмента. Попробуйте снова (например: SBER):")
+		return
+	}
+
+	// Завершаем состояние
+	b.resetUserState(userID)
+
+	reg, ok := b.strategies.Get(name)
+	if !ok {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Неизвестная стратегия: %s", name))
+		return
+	}
+
+	// Запускаем тест в фоне
+	go b.runStrategyTest(chatID, userID, reg, instrument)
+}
+
+// runStrategyTest анализирует один инструмент стратегией и отправляет отчет
+func (b *Bot) runStrategyTest(chatID, userID int64, reg analysis.Registration, instrument string) {
+	b.sendFormattedMessage(chatID, fmt.Sprintf("🧪 Тестирование стратегии '%s' для %s...", reg.Title, instrument))
+
+	strategy := reg.New()
+
+	signals, err := analysis.AnalyzeWith(context.Background(), b.candles, strategy, instrument)
+	if err != nil {
+		b.sendFormattedMessage(chatID, fmt.Sprintf("❌ Ошибка тестирования: %v", err))
+		return
+	}
+	b.sizeSignals(context.Background(), signals, b.accountFor(userID))
+
+	msg := fmt.Sprintf("📊 ОТЧЕТ ПО ТЕСТИРОВАНИЮ '%s': %s\n\n", reg.Title, instrument)
+
+	if len(signals) == 0 {
+		msg += "📭 Сигналов не найдено\n\n"
+		msg += "ПАРАМЕТРЫ ТЕСТА:\n"
+		for _, param := range strategy.Params() {
+			msg += fmt.Sprintf("• %s: %s\n", param.Name, param.Value)
+		}
+	} else {
+		msg += fmt.Sprintf("🚨 НАЙДЕНО СИГНАЛОВ: %d\n\n", len(signals))
+
+		for i, signal := range signals {
+			msg += fmt.Sprintf("📈 СИГНАЛ #%d:\n", i+1)
+			msg += fmt.Sprintf("• Тип: %s\n", b.getSignalTypeText(signal.SignalType))
+			msg += fmt.Sprintf("• Цена: %.2f₽\n", signal.Price)
+
+			if signal.StopLoss > 0 {
+				msg += fmt.Sprintf("• Стоп-лосс: %.2f₽ (%.1f%%)\n", signal.StopLoss,
+					math.Abs(signal.Price-signal.StopLoss)/signal.Price*100)
+			}
+			if signal.TakeProfit > 0 {
+				msg += fmt.Sprintf("• Тейк-профит: %.2f₽\n", signal.TakeProfit)
+			}
+			if signal.Sized() {
+				msg += fmt.Sprintf("• Размер позиции: %s\n", formatPosition(signal))
+			}
+
+			msg += fmt.Sprintf("• Причина: %s\n", signal.Reason)
+			msg += fmt.Sprintf("• Время: %s\n\n", signal.Timestamp.Format("02.01.2006 15:04"))
+		}
+	}
+
+	b.sendFormattedMessage(chatID, msg)
+	b.sendStrategyChart(chatID, strategy, instrument, signals)
+}
@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"telegram-bot-moex/internal/analysis"
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/storage"
+)
+
+// defaultOutcomeTimeout сколько отслеживается исход сигнала, если storage.outcome_timeout не задан
+const defaultOutcomeTimeout = 30 * 24 * time.Hour
+
+// trackOutcomes проверяет сигналы журнала стратегии по свечам, появившимся после
+// них, и записывает исходы: тейк-профит, стоп-лосс, сигнал выхода или таймаут.
+// Вызывается после каждого фонового сканирования, когда свечи обновлены.
+func (b *Bot) trackOutcomes(ctx context.Context, strategy analysis.Strategy) {
+	records := b.store.TrackedSignals(strategy.Name())
+	if len(records) == 0 {
+		return
+	}
+
+	timeout := b.config.Storage.OutcomeTimeout
+	if timeout <= 0 {
+		timeout = defaultOutcomeTimeout
+	}
+
+	// Свечи загружаются один раз на инструмент, от самого раннего сигнала
+	from := make(map[string]time.Time)
+	for _, record := range records {
+		if start, ok := from[record.Instrument]; !ok || record.BarTime.Before(start) {
+			from[record.Instrument] = record.BarTime
+		}
+	}
+
+	now := time.Now()
+	candles := make(map[string][]api.Candle, len(from))
+	for instrument, start := range from {
+		series, err := b.candles.GetCandles(ctx, instrument, strategy.Timeframe(),
+			start.Format("2006-01-02"), now.Format("2006-01-02"))
+		if err != nil {
+			b.logger.Debug("Ошибка загрузки свечей для отслеживания сигналов",
+				"strategy", strategy.Name(),
+				"instrument", instrument,
+				"error", err)
+			continue
+		}
+		candles[instrument] = series
+	}
+
+	closed := 0
+	for _, record := range records {
+		series, ok := candles[record.Instrument]
+		if !ok {
+			continue
+		}
+
+		outcome, ok := evaluateRecord(record, series, timeout)
+		if !ok {
+			continue
+		}
+
+		if err := b.store.SetOutcome(record.ID, outcome.Result, outcome.ExitPrice, outcome.ExitTime, outcome.RMultiple, now); err != nil {
+			b.logger.Warn("Ошибка записи исхода сигнала",
+				"strategy", strategy.Name(),
+				"instrument", record.Instrument,
+				"error", err)
+			continue
+		}
+		closed++
+	}
+
+	b.logger.Debug("Отслеживание сигналов завершено",
+		"strategy", strategy.Name(),
+		"tracked", len(records),
+		"closed", closed)
+}
+
+// evaluateRecord определяет исход записи журнала. Для сигнала, закрытого сигналом
+// выхода, проверяются свечи до выхода: если стоп или тейк сработали раньше,
+// исход - они, иначе выход по цене сигнала выхода.
+func evaluateRecord(record storage.SignalRecord, candles []api.Candle, timeout time.Duration) (analysis.Outcome, bool) {
+	signal := analysis.Signal{
+		Instrument: record.Instrument,
+		SignalType: record.SignalType,
+		Price:      record.Price,
+		StopLoss:   record.StopLoss,
+		TakeProfit: record.TakeProfit,
+		Timestamp:  record.BarTime,
+	}
+
+	exitSignal := record.Status == storage.SignalExited && record.ExitPrice > 0
+	if exitSignal && !record.ExitTime.IsZero() {
+		var before []api.Candle
+		for _, candle := range candles {
+			if candle.Begin.Before(record.ExitTime) {
+				before = append(before, candle)
+			}
+		}
+		candles = before
+	}
+
+	if outcome, ok := analysis.EvaluateOutcome(signal, candles, record.BarTime.Add(timeout)); ok {
+		return outcome, true
+	}
+	if !exitSignal {
+		return analysis.Outcome{}, false
+	}
+
+	return analysis.Outcome{
+		Result:    analysis.OutcomeExitSignal,
+		ExitPrice: record.ExitPrice,
+		ExitTime:  record.ExitTime,
+		RMultiple: analysis.RMultiple(record.SignalType, record.Price, record.StopLoss, record.ExitPrice),
+	}, true
+}
+
+// recordScan запоминает время завершения сканирования стратегии
+func (b *Bot) recordScan(strategy string) {
+	b.mu.Lock()
+	b.lastScans[strategy] = time.Now()
+	b.mu.Unlock()
+}
+
+// formatLastScan время последнего сканирования стратегии для пользователя
+func (b *Bot) formatLastScan(strategy string) string {
+	b.mu.RLock()
+	last, ok := b.lastScans[strategy]
+	b.mu.RUnlock()
+
+	if !ok {
+		return "не выполнялось"
+	}
+	return fmt.Sprintf("%s (%s назад)", last.Format("02.01.2006 15:04"), time.Since(last).Truncate(time.Second))
+}
+
+// formatSignalStats статистика исходов сигналов фонового анализа стратегии
+func (b *Bot) formatSignalStats(strategy string) string {
+	stats := b.store.SignalOutcomeStats(strategy)
+	if stats.Signals == 0 {
+		return "• Сигналов в журнале пока нет (сигналы фонового анализа: /signals_history)\n"
+	}
+
+	msg := fmt.Sprintf("• Сигналов в журнале: %d (отслеживается: %d)\n", stats.Signals, stats.Tracked)
+	if stats.Closed == 0 {
+		return msg + "• Закрытых сигналов пока нет\n"
+	}
+
+	msg += fmt.Sprintf("• Закрыто: %d - ✅ тейк-профит %d / ❌ стоп-лосс %d / 📤 выход %d / ⌛ таймаут %d\n",
+		stats.Closed,
+		stats.Outcomes[analysis.OutcomeTakeProfit],
+		stats.Outcomes[analysis.OutcomeStopLoss],
+		stats.Outcomes[analysis.OutcomeExitSignal],
+		stats.Outcomes[analysis.OutcomeTimeout])
+	msg += fmt.Sprintf("• Доля прибыльных: %.1f%%\n", stats.WinRate())
+	msg += fmt.Sprintf("• Средний результат: %+.2fR (сумма %+.2fR)\n", stats.AvgR(), stats.TotalR)
+	msg += fmt.Sprintf("• Лучший / худший: %+.2fR / %+.2fR\n", stats.BestR, stats.WorstR)
+	return msg
+}
+
+// formatOutcome исход сигнала журнала для пользователя
+func formatOutcome(record storage.SignalRecord) string {
+	var result string
+	switch record.Outcome {
+	case analysis.OutcomeTakeProfit:
+		result = "✅ тейк-профит"
+	case analysis.OutcomeStopLoss:
+		result = "❌ стоп-лосс"
+	case analysis.OutcomeExitSignal:
+		result = "📤 сигнал выхода"
+	case analysis.OutcomeTimeout:
+		result = "⌛ таймаут"
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s %s по %.2f₽, %+.2fR", result, record.ExitTime.Format("02.01.2006"), record.ExitPrice, record.RMultiple)
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"telegram-bot-moex/internal/api"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -53,7 +55,7 @@ func (b *Bot) sendInstrumentInfo(chatID int64, instrument string) {
 	}
 
 	// Получаем информацию об инструменте
-	info, err := b.apiClient.GetInstrumentInfo(context.Background(), instrument)
+	info, err := b.source.GetInstrumentInfo(context.Background(), instrument)
 	if err != nil {
 		b.sendMessage(chatID, fmt.Sprintf("❌ Инструмент %s не найден", instrument))
 		return
@@ -61,16 +63,14 @@ func (b *Bot) sendInstrumentInfo(chatID int64, instrument string) {
 
 	msg := fmt.Sprintf("📊 Инструмент: %s\n\n", instrument)
 
-	if timeframes, ok := info["timeframes"].([]interface{}); ok {
+	if len(info.Timeframes) > 0 {
 		msg += "📈 Доступные данные:\n"
-		for _, tf := range timeframes {
-			if tfMap, ok := tf.(map[string]interface{}); ok {
-				msg += fmt.Sprintf("• %s: ", tfMap["display_name"])
-				if lastCandle, ok := tfMap["last_candle"].(map[string]interface{}); ok {
-					msg += fmt.Sprintf("последняя свеча %s\n", lastCandle["date"])
-				} else {
-					msg += "нет данных\n"
-				}
+		for _, tf := range info.Timeframes {
+			msg += fmt.Sprintf("• %s: ", tf.DisplayName)
+			if !tf.LastCandle.IsZero() {
+				msg += fmt.Sprintf("последняя свеча %s\n", tf.LastCandle.Format("2006-01-02 15:04"))
+			} else {
+				msg += "нет данных\n"
 			}
 		}
 	}
@@ -103,7 +103,7 @@ func (b *Bot) handleTimeframeSelection(chatID, userID int64, timeframe string) {
 	state.Step = 3
 
 	b.sendFormattedMessage(chatID,
-		fmt.Sprintf("📈 Инструмент: %s\nТаймфрейм: %s\n\nТеперь укажите период (например: 7d, 30d, 1y или конкретные даты: 2024-01-01:2024-01-31):",
+		fmt.Sprintf("📈 Инструмент: %s\nТаймфрейм: %s\n\nТеперь укажите период (например: 7d, 30d, 1y, ytd, сегодня или конкретные даты: 2024-01-01:2024-01-31):",
 			state.Data["instrument"], timeframe))
 }
 
@@ -166,8 +166,8 @@ func (b *Bot) handleInstrumentCallback(chatID int64, data string) {
 		for _, tf := range timeframes {
 			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("%s (%s)", tf["display_name"], tf["timeframe"]),
-					fmt.Sprintf("timeframe_%s", tf["timeframe"]),
+					fmt.Sprintf("%s (%s)", tf.DisplayName, tf.Code),
+					fmt.Sprintf("timeframe_%s", tf.Code),
 				),
 			))
 		}
@@ -236,6 +236,34 @@ func (b *Bot) isUserAllowed(update tgbotapi.Update) bool {
 	return false
 }
 
+// formatCircuitState возвращает описание состояния circuit breaker API
+func (b *Bot) formatCircuitState() string {
+	state, ok := b.apiClient.CircuitState()
+	if !ok {
+		return ""
+	}
+
+	var msg string
+	switch state.State {
+	case api.BreakerClosed:
+		msg = "• Circuit breaker: 🟢 замкнут\n"
+	case api.BreakerHalfOpen:
+		msg = "• Circuit breaker: 🟡 пробные запросы\n"
+	case api.BreakerOpen:
+		msg = "• Circuit breaker: 🔴 разомкнут, запросы отклоняются\n"
+		msg += fmt.Sprintf("• Повторная проверка: %s\n", state.RetryAt.Format("15:04:05"))
+	}
+
+	if state.Failures > 0 {
+		msg += fmt.Sprintf("• Ошибок подряд: %d\n", state.Failures)
+	}
+	if state.LastError != "" && state.State != api.BreakerClosed {
+		msg += fmt.Sprintf("• Последняя ошибка (%s): %s\n", state.LastFailure.Format("15:04:05"), state.LastError)
+	}
+
+	return msg
+}
+
 // startCleanupRoutine запускает горутину для очистки неактивных состояний
 func (b *Bot) startCleanupRoutine(ctx context.Context) {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -330,6 +358,40 @@ func (b *Bot) editMessage(chatID int64, messageID int, text string, keyboard int
 	return nil
 }
 
+// sendHTMLWithKeyboard отправляет сообщение с готовой HTML разметкой (например, таблицей в <pre>).
+// Текст не экранируется: данные экранирует вызывающий.
+func (b *Bot) sendHTMLWithKeyboard(chatID int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+
+	_, err := b.botAPI.Send(msg)
+	if err != nil {
+		b.logger.Error("Ошибка отправки сообщения",
+			"chat_id", chatID,
+			"error", err)
+		return err
+	}
+
+	b.stats.UpdateStats("message_sent")
+	return nil
+}
+
+// editHTMLMessage заменяет текст и клавиатуру сообщения готовой HTML разметкой
+func (b *Bot) editHTMLMessage(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	editMsg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	editMsg.ParseMode = "HTML"
+
+	if _, err := b.botAPI.Send(editMsg); err != nil {
+		b.logger.Error("Ошибка редактирования сообщения",
+			"chat_id", chatID,
+			"message_id", messageID,
+			"error", err)
+		return err
+	}
+	return nil
+}
+
 // deleteMessage удаляет сообщение
 func (b *Bot) deleteMessage(chatID int64, messageID int) error {
 	deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
@@ -397,3 +459,43 @@ func (b *Bot) sendDocument(chatID int64, filePath, caption string) error {
 	b.stats.UpdateStats("message_sent")
 	return nil
 }
+
+// sendDocumentData отправляет документ из памяти под именем fileName
+func (b *Bot) sendDocumentData(chatID int64, fileName string, data []byte, caption string) error {
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	if caption != "" {
+		doc.Caption = caption
+	}
+
+	_, err := b.botAPI.Send(doc)
+	if err != nil {
+		b.logger.Error("Ошибка отправки документа",
+			"chat_id", chatID,
+			"file_name", fileName,
+			"error", err)
+		return err
+	}
+
+	b.stats.UpdateStats("message_sent")
+	return nil
+}
+
+// sendPhoto отправляет изображение PNG из памяти
+func (b *Bot) sendPhoto(chatID int64, data []byte, caption string) error {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: data})
+	if caption != "" {
+		photo.Caption = caption
+	}
+
+	_, err := b.botAPI.Send(photo)
+	if err != nil {
+		b.logger.Error("Ошибка отправки изображения",
+			"chat_id", chatID,
+			"size", len(data),
+			"error", err)
+		return err
+	}
+
+	b.stats.UpdateStats("message_sent")
+	return nil
+}
@@ -0,0 +1,376 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// dateLayout формат дат в запросах свечей
+const dateLayout = "2006-01-02"
+
+// Source источник свечей, поверх которого работает кэш
+type Source interface {
+	GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]api.Candle, error)
+}
+
+// CandleCache кэш свечей на диске с инкрементальным обновлением.
+// Для каждой пары инструмент+таймфрейм хранится отдельный JSON файл.
+// В файл попадают только завершенные свечи: текущая (незакрытая) свеча
+// всегда запрашивается у источника заново.
+type CandleCache struct {
+	source Source
+	dir    string
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// entryFile содержимое файла кэша
+type entryFile struct {
+	Instrument    string       `json:"instrument"`
+	Timeframe     string       `json:"timeframe"`
+	CoveredFrom   string       `json:"covered_from"`   // Дата, начиная с которой данные загружены полностью
+	CompleteUntil time.Time    `json:"complete_until"` // Момент, до которого данные загружены полностью
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Candles       []api.Candle `json:"candles"`
+}
+
+// EntryStats информация о записи кэша
+type EntryStats struct {
+	Instrument  string
+	Timeframe   string
+	Candles     int
+	CoveredFrom string
+	LastCandle  time.Time
+	UpdatedAt   time.Time
+	SizeBytes   int64
+}
+
+// NewCandleCache создает кэш в директории dir поверх источника source
+func NewCandleCache(source Source, dir string) (*CandleCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("ошибка создания директории кэша: %w", err)
+	}
+
+	return &CandleCache{
+		source: source,
+		dir:    dir,
+		locks:  make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// GetCandles возвращает свечи за период [from, to], загружая у источника только
+// недостающие данные. Без даты начала запрос передается источнику напрямую.
+func (c *CandleCache) GetCandles(ctx context.Context, instrument, timeframe, from, to string) ([]api.Candle, error) {
+	if from == "" {
+		return c.source.GetCandles(ctx, instrument, timeframe, from, to)
+	}
+
+	fromDate, err := time.ParseInLocation(dateLayout, from, api.MSK)
+	if err != nil {
+		return nil, fmt.Errorf("неверная дата начала %q: %w", from, err)
+	}
+
+	now := time.Now().In(api.MSK)
+	toDate := startOfDay(now)
+	if to != "" {
+		if toDate, err = time.ParseInLocation(dateLayout, to, api.MSK); err != nil {
+			return nil, fmt.Errorf("неверная дата окончания %q: %w", to, err)
+		}
+	}
+	toEnd := toDate.AddDate(0, 0, 1)
+
+	lock := c.lockFor(instrument, timeframe)
+	lock.Lock()
+	defer lock.Unlock()
+
+	entry, err := c.load(instrument, timeframe)
+	if err != nil {
+		return nil, err
+	}
+
+	if fetchFrom, fetchTo, ok := plan(entry, fromDate, toEnd, to); ok {
+		fresh, err := c.source.GetCandles(ctx, instrument, timeframe, fetchFrom.Format(dateLayout), fetchTo)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Candles = mergeCandles(entry.Candles, fresh)
+		if covered := coveredFrom(entry); covered.IsZero() || fetchFrom.Before(covered) {
+			entry.CoveredFrom = fetchFrom.Format(dateLayout)
+		}
+
+		complete := now
+		if fetchTo != "" && toEnd.Before(now) {
+			complete = toEnd
+		}
+		if complete.After(entry.CompleteUntil) {
+			entry.CompleteUntil = complete
+		}
+		entry.UpdatedAt = now
+
+		if err := c.save(entry, timeframe, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// В ответ включаем и незавершенную свечу, полученную от источника
+	result := make([]api.Candle, 0, len(entry.Candles))
+	for _, candle := range entry.Candles {
+		if !candle.Begin.Before(fromDate) && candle.Begin.Before(toEnd) {
+			result = append(result, candle)
+		}
+	}
+
+	return result, nil
+}
+
+// plan определяет, какой период нужно запросить у источника
+func plan(entry *entryFile, fromDate, toEnd time.Time, to string) (time.Time, string, bool) {
+	covered := coveredFrom(entry)
+
+	// Нет данных: загружаем запрошенный период
+	if covered.IsZero() {
+		return fromDate, to, true
+	}
+
+	// Запрошен более ранний период: загружаем от новой даты начала до текущего момента,
+	// чтобы не образовалось разрыва с уже сохраненными свечами
+	if fromDate.Before(covered) {
+		return fromDate, "", true
+	}
+
+	// Период уже покрыт данными, загруженными после его окончания
+	if !entry.CompleteUntil.Before(toEnd) {
+		return time.Time{}, "", false
+	}
+
+	// Догружаем с дня последней сохраненной свечи
+	since := entry.CompleteUntil
+	if len(entry.Candles) > 0 {
+		since = entry.Candles[len(entry.Candles)-1].Begin
+	}
+	if since.IsZero() {
+		since = covered
+	}
+
+	return startOfDay(since), to, true
+}
+
+// Stats возвращает информацию о всех записях кэша
+func (c *CandleCache) Stats() ([]EntryStats, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения директории кэша: %w", err)
+	}
+
+	stats := make([]EntryStats, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+
+		entry, err := readEntry(file)
+		if err != nil {
+			continue
+		}
+
+		stat := EntryStats{
+			Instrument:  entry.Instrument,
+			Timeframe:   entry.Timeframe,
+			Candles:     len(entry.Candles),
+			CoveredFrom: entry.CoveredFrom,
+			UpdatedAt:   entry.UpdatedAt,
+			SizeBytes:   info.Size(),
+		}
+		if len(entry.Candles) > 0 {
+			stat.LastCandle = entry.Candles[len(entry.Candles)-1].Begin
+		}
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Instrument != stats[j].Instrument {
+			return stats[i].Instrument < stats[j].Instrument
+		}
+		return stats[i].Timeframe < stats[j].Timeframe
+	})
+
+	return stats, nil
+}
+
+// Purge удаляет записи кэша. Пустой instrument удаляет все записи,
+// пустой timeframe - все таймфреймы инструмента. Возвращает число удаленных файлов.
+func (c *CandleCache) Purge(instrument, timeframe string) (int, error) {
+	pattern := "*.json"
+	switch {
+	case instrument != "" && timeframe != "":
+		pattern = entryFileName(instrument, timeframe)
+	case instrument != "":
+		pattern = strings.ToUpper(instrument) + "_*.json"
+	}
+
+	files, err := filepath.Glob(filepath.Join(c.dir, pattern))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения директории кэша: %w", err)
+	}
+
+	removed := 0
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("ошибка удаления %s: %w", filepath.Base(file), err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// Dir возвращает директорию кэша
+func (c *CandleCache) Dir() string {
+	return c.dir
+}
+
+// lockFor возвращает мьютекс для пары инструмент+таймфрейм
+func (c *CandleCache) lockFor(instrument, timeframe string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := entryFileName(instrument, timeframe)
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[key] = lock
+	}
+	return lock
+}
+
+// load читает запись кэша; отсутствующий или поврежденный файл дает пустую запись
+func (c *CandleCache) load(instrument, timeframe string) (*entryFile, error) {
+	path := filepath.Join(c.dir, entryFileName(instrument, timeframe))
+
+	entry, err := readEntry(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &entryFile{Instrument: instrument, Timeframe: timeframe}, nil
+	}
+	if err != nil {
+		// Поврежденный файл не должен блокировать анализ - загрузим данные заново
+		_ = os.Remove(path)
+		return &entryFile{Instrument: instrument, Timeframe: timeframe}, nil
+	}
+
+	return entry, nil
+}
+
+// save атомарно записывает завершенные свечи записи на диск
+func (c *CandleCache) save(entry *entryFile, timeframe string, now time.Time) error {
+	stored := *entry
+	stored.Candles = make([]api.Candle, 0, len(entry.Candles))
+	for _, candle := range entry.Candles {
+		if candleEnd(candle, timeframe).Before(now) {
+			stored.Candles = append(stored.Candles, candle)
+		}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации кэша: %w", err)
+	}
+
+	path := filepath.Join(c.dir, entryFileName(entry.Instrument, entry.Timeframe))
+	tmp, err := os.CreateTemp(c.dir, ".candles-*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка записи кэша: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи кэша: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка записи кэша: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ошибка записи кэша: %w", err)
+	}
+
+	return nil
+}
+
+// readEntry читает файл кэша
+func readEntry(path string) (*entryFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry entryFile
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("поврежденный файл кэша %s: %w", filepath.Base(path), err)
+	}
+
+	return &entry, nil
+}
+
+// mergeCandles объединяет свечи по времени начала; новые данные заменяют старые
+func mergeCandles(cached, fresh []api.Candle) []api.Candle {
+	byBegin := make(map[int64]api.Candle, len(cached)+len(fresh))
+	for _, candle := range cached {
+		byBegin[candle.Begin.Unix()] = candle
+	}
+	for _, candle := range fresh {
+		byBegin[candle.Begin.Unix()] = candle
+	}
+
+	merged := make([]api.Candle, 0, len(byBegin))
+	for _, candle := range byBegin {
+		merged = append(merged, candle)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Begin.Before(merged[j].Begin)
+	})
+
+	return merged
+}
+
+// candleEnd возвращает время закрытия свечи
+func candleEnd(candle api.Candle, timeframe string) time.Time {
+	if !candle.End.IsZero() {
+		return candle.End
+	}
+	return candle.Begin.Add(api.TimeframeDuration(timeframe))
+}
+
+// coveredFrom возвращает дату, с которой запись покрывает данные
+func coveredFrom(entry *entryFile) time.Time {
+	t, err := time.ParseInLocation(dateLayout, entry.CoveredFrom, api.MSK)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// entryFileName имя файла для пары инструмент+таймфрейм
+func entryFileName(instrument, timeframe string) string {
+	return fmt.Sprintf("%s_%s.json", strings.ToUpper(instrument), timeframe)
+}
+
+// startOfDay возвращает начало дня в MSK
+func startOfDay(t time.Time) time.Time {
+	t = t.In(api.MSK)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, api.MSK)
+}
@@ -0,0 +1,90 @@
+package cache_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/cache"
+	"telegram-bot-moex/internal/testutil/fakefetcher"
+)
+
+func TestCandleCacheServesCompletedPeriodFromDisk(t *testing.T) {
+	fetcher := fakefetcher.New(t, fakefetcher.WithSynthetic("SBER", 7, 60, 270))
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+
+	dir := t.TempDir()
+	candleCache, err := cache.NewCandleCache(client, dir)
+	if err != nil {
+		t.Fatalf("NewCandleCache() error = %v", err)
+	}
+
+	from := time.Now().AddDate(0, 0, -40).Format("2006-01-02")
+	to := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+
+	first, err := candleCache.GetCandles(context.Background(), "SBER", "24", from, to)
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	if len(first) == 0 {
+		t.Fatal("GetCandles() returned no candles")
+	}
+
+	// Повторный запрос того же периода не обращается к источнику, в том числе после перезапуска
+	reopened, err := cache.NewCandleCache(client, dir)
+	if err != nil {
+		t.Fatalf("NewCandleCache() error = %v", err)
+	}
+	second, err := reopened.GetCandles(context.Background(), "SBER", "24", from, to)
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+
+	if got := fetcher.RequestCount("/api/candles"); got != 1 {
+		t.Errorf("source requests = %d, want 1", got)
+	}
+	if len(second) != len(first) {
+		t.Errorf("cached candles = %d, want %d", len(second), len(first))
+	}
+}
+
+func TestCandleCacheFetchesOnlyNewCandles(t *testing.T) {
+	fetcher := fakefetcher.New(t, fakefetcher.WithSynthetic("SBER", 7, 60, 270))
+	client := api.NewAPIClient(fetcher.URL, "", 5*time.Second)
+
+	candleCache, err := cache.NewCandleCache(client, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCandleCache() error = %v", err)
+	}
+
+	from := time.Now().AddDate(0, 0, -40).Format("2006-01-02")
+	first, err := candleCache.GetCandles(context.Background(), "SBER", "24", from, "")
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	second, err := candleCache.GetCandles(context.Background(), "SBER", "24", from, "")
+	if err != nil {
+		t.Fatalf("GetCandles() error = %v", err)
+	}
+	if len(second) != len(first) {
+		t.Errorf("candles = %d, want %d", len(second), len(first))
+	}
+
+	requests := fetcher.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("source requests = %d, want 2", len(requests))
+	}
+
+	// Догрузка начинается с дня последней завершенной (сохраненной) свечи, а не с начала периода
+	var lastStored string
+	for _, candle := range first {
+		if candle.End.Before(time.Now()) {
+			lastStored = candle.Begin.Format("2006-01-02")
+		}
+	}
+	if want := "from=" + lastStored; !strings.Contains(requests[1].Query, want) {
+		t.Errorf("incremental query = %q, want %s", requests[1].Query, want)
+	}
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// day возвращает начало дня в MSK
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, api.MSK)
+}
+
+// candleAt свеча с заданным временем начала и ценой закрытия
+func candleAt(begin time.Time, price float64) api.Candle {
+	return api.Candle{Begin: begin, Open: price, High: price, Low: price, Close: price}
+}
+
+func TestPlan(t *testing.T) {
+	cached := &entryFile{
+		CoveredFrom:   "2024-03-01",
+		CompleteUntil: day(2024, 3, 20).Add(15 * time.Hour),
+		Candles: []api.Candle{
+			candleAt(day(2024, 3, 1).Add(10*time.Hour), 1),
+			candleAt(day(2024, 3, 19).Add(10*time.Hour), 2),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		entry    *entryFile
+		from     time.Time
+		toEnd    time.Time
+		to       string
+		wantOK   bool
+		wantFrom time.Time
+		wantTo   string
+	}{
+		{
+			name:     "пустой кэш",
+			entry:    &entryFile{},
+			from:     day(2024, 3, 1),
+			toEnd:    day(2024, 3, 11),
+			to:       "2024-03-10",
+			wantOK:   true,
+			wantFrom: day(2024, 3, 1),
+			wantTo:   "2024-03-10",
+		},
+		{
+			name:   "период покрыт",
+			entry:  cached,
+			from:   day(2024, 3, 5),
+			toEnd:  day(2024, 3, 11),
+			to:     "2024-03-10",
+			wantOK: false,
+		},
+		{
+			name:     "более ранний период загружается до текущего момента",
+			entry:    cached,
+			from:     day(2024, 2, 1),
+			toEnd:    day(2024, 3, 11),
+			to:       "2024-03-10",
+			wantOK:   true,
+			wantFrom: day(2024, 2, 1),
+			wantTo:   "",
+		},
+		{
+			name:     "догрузка с дня последней свечи",
+			entry:    cached,
+			from:     day(2024, 3, 5),
+			toEnd:    day(2024, 3, 26),
+			to:       "",
+			wantOK:   true,
+			wantFrom: day(2024, 3, 19),
+			wantTo:   "",
+		},
+		{
+			name:     "догрузка без свечей с момента полной загрузки",
+			entry:    &entryFile{CoveredFrom: "2024-03-01", CompleteUntil: day(2024, 3, 20).Add(15 * time.Hour)},
+			from:     day(2024, 3, 5),
+			toEnd:    day(2024, 3, 26),
+			to:       "2024-03-25",
+			wantOK:   true,
+			wantFrom: day(2024, 3, 20),
+			wantTo:   "2024-03-25",
+		},
+	}
+
+	for _, tt := range tests {
+		fetchFrom, fetchTo, ok := plan(tt.entry, tt.from, tt.toEnd, tt.to)
+		if ok != tt.wantOK {
+			t.Errorf("%s: plan() ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if ok && (!fetchFrom.Equal(tt.wantFrom) || fetchTo != tt.wantTo) {
+			t.Errorf("%s: plan() = %s..%q, want %s..%q", tt.name,
+				fetchFrom.Format(dateLayout), fetchTo, tt.wantFrom.Format(dateLayout), tt.wantTo)
+		}
+	}
+}
+
+func TestMergeCandles(t *testing.T) {
+	first := day(2024, 3, 1).Add(10 * time.Hour)
+	second := first.Add(time.Hour)
+	third := second.Add(time.Hour)
+
+	cached := []api.Candle{candleAt(first, 1), candleAt(second, 2)}
+	fresh := []api.Candle{candleAt(third, 30), candleAt(second.UTC(), 20)}
+
+	merged := mergeCandles(cached, fresh)
+	if len(merged) != 3 {
+		t.Fatalf("mergeCandles() = %d candles, want 3", len(merged))
+	}
+	// Свечи упорядочены по времени, новые данные заменяют сохраненные
+	for i, want := range []float64{1, 20, 30} {
+		if merged[i].Close != want {
+			t.Errorf("merged[%d].Close = %v, want %v", i, merged[i].Close, want)
+		}
+	}
+}
+
+func TestSaveSkipsUnfinishedCandle(t *testing.T) {
+	c, err := NewCandleCache(nil, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCandleCache() error = %v", err)
+	}
+
+	now := day(2024, 3, 1).Add(12*time.Hour + 30*time.Minute)
+	entry := &entryFile{
+		Instrument: "SBER",
+		Timeframe:  "60",
+		Candles: []api.Candle{
+			candleAt(now.Add(-2*time.Hour-30*time.Minute), 1),
+			candleAt(now.Add(-90*time.Minute), 2),
+			candleAt(now.Add(-30*time.Minute), 3), // Текущая часовая свеча
+		},
+	}
+	if err := c.save(entry, "60", now); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	stored, err := c.load("SBER", "60")
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if len(stored.Candles) != 2 || stored.Candles[1].Close != 2 {
+		t.Errorf("stored candles = %+v, want 2 completed candles", stored.Candles)
+	}
+	if len(entry.Candles) != 3 {
+		t.Errorf("save() modified entry: %d candles, want 3", len(entry.Candles))
+	}
+
+	// Свеча с явным временем закрытия учитывается по нему
+	if end := candleEnd(api.Candle{Begin: now, End: now.Add(time.Minute)}, "24"); !end.Equal(now.Add(time.Minute)) {
+		t.Errorf("candleEnd() = %v, want explicit end", end)
+	}
+	if end := candleEnd(candleAt(now, 1), "24"); !end.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("candleEnd() = %v, want begin + 24h", end)
+	}
+}
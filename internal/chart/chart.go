@@ -0,0 +1,447 @@
+// Package chart рисует свечные графики в PNG средствами стандартной библиотеки:
+// свечи, объем, линии индикаторов, отметки сигналов и уровни стопа и цели.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// Размер графика по умолчанию
+const (
+	DefaultWidth  = 1200
+	DefaultHeight = 700
+)
+
+// Цвета графика
+var (
+	ColorUp     = color.RGBA{38, 166, 154, 255} // Растущая свеча, сигнал на покупку
+	ColorDown   = color.RGBA{239, 83, 80, 255}  // Падающая свеча, сигнал на продажу
+	ColorExit   = color.RGBA{255, 152, 0, 255}  // Сигнал выхода
+	ColorBlue   = color.RGBA{33, 150, 243, 255}
+	ColorPurple = color.RGBA{156, 39, 176, 255}
+	ColorOrange = color.RGBA{255, 111, 0, 255}
+	ColorGray   = color.RGBA{120, 120, 120, 255}
+
+	colorBackground = color.RGBA{255, 255, 255, 255}
+	colorGrid       = color.RGBA{236, 236, 236, 255}
+	colorText       = color.RGBA{50, 50, 50, 255}
+	colorAxis       = color.RGBA{180, 180, 180, 255}
+)
+
+// MarkerKind вид отметки сигнала
+type MarkerKind int
+
+const (
+	MarkerLong  MarkerKind = iota // Вход в длинную позицию: треугольник под свечой
+	MarkerShort                   // Вход в короткую позицию: треугольник над свечой
+	MarkerExit                    // Выход: ромб на уровне цены
+)
+
+// Line линия индикатора поверх свечей
+type Line struct {
+	Name   string     // Подпись в легенде (пусто - линия без подписи)
+	Values []float64  // По значению на свечу, NaN - значения нет
+	Color  color.RGBA // Цвет линии
+}
+
+// Marker отметка сигнала на свече
+type Marker struct {
+	Time  time.Time // Время сигнала: отметка ставится на последнюю свечу, начавшуюся не позже
+	Price float64   // Цена сигнала (для выхода)
+	Kind  MarkerKind
+}
+
+// Level горизонтальный уровень (стоп-лосс, тейк-профит)
+type Level struct {
+	Name  string // Подпись на шкале цены, например SL или TP
+	Price float64
+	Color color.RGBA
+}
+
+// Chart свечной график
+type Chart struct {
+	Title   string
+	Candles []api.Candle // От старых к новым
+	Lines   []Line
+	Markers []Marker
+	Levels  []Level
+
+	Width, Height int // 0 - DefaultWidth x DefaultHeight
+}
+
+// Масштаб шрифта и отступы
+const (
+	textScale = 2
+	padding   = 10
+)
+
+// PNG рисует график и возвращает PNG
+func (c *Chart) PNG() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Render(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render рисует график и записывает PNG в w
+func (c *Chart) Render(w io.Writer) error {
+	img, err := c.draw()
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("ошибка кодирования PNG: %w", err)
+	}
+	return nil
+}
+
+// layout области графика в пикселях
+type layout struct {
+	left, right    int // Границы области свечей по горизонтали
+	priceTop       int
+	priceBottom    int
+	volumeTop      int // volumeTop == volumeBottom - объема нет
+	volumeBottom   int
+	step           float64 // Ширина свечи с промежутком
+	low, high      float64 // Диапазон шкалы цены
+	maxVolume      float64
+	labelsRight    int // Левая граница подписей шкалы цены
+	dateLabelsY    int
+	hasVolumePanel bool
+}
+
+// x центр свечи i
+func (l *layout) x(i int) int {
+	return l.left + int(l.step*(float64(i)+0.5))
+}
+
+// y координата цены
+func (l *layout) y(price float64) int {
+	return l.priceBottom - int((price-l.low)/(l.high-l.low)*float64(l.priceBottom-l.priceTop))
+}
+
+// draw рисует график в изображение
+func (c *Chart) draw() (*image.RGBA, error) {
+	if len(c.Candles) == 0 {
+		return nil, fmt.Errorf("нет свечей для графика")
+	}
+	for _, line := range c.Lines {
+		if len(line.Values) != len(c.Candles) {
+			return nil, fmt.Errorf("линия %s: %d значений на %d свечей", line.Name, len(line.Values), len(c.Candles))
+		}
+	}
+
+	width, height := c.Width, c.Height
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, colorBackground)
+
+	l := c.layout(width, height)
+	if l.right-l.left < len(c.Candles) || l.priceBottom-l.priceTop < 50 {
+		return nil, fmt.Errorf("размер %dx%d слишком мал для %d свечей", width, height, len(c.Candles))
+	}
+
+	c.drawGrid(img, l)
+	c.drawVolume(img, l)
+	c.drawCandles(img, l)
+	c.drawLines(img, l)
+	c.drawLevels(img, l)
+	c.drawMarkers(img, l)
+	c.drawHeader(img, l)
+
+	return img, nil
+}
+
+// layout рассчитывает области и шкалу цены
+func (c *Chart) layout(width, height int) *layout {
+	l := &layout{}
+
+	// Диапазон цены: свечи, линии и уровни, чтобы стоп и цель были видны
+	l.low, l.high = math.Inf(1), math.Inf(-1)
+	extend := func(v float64) {
+		if math.IsNaN(v) || math.IsInf(v, 0) || v <= 0 {
+			return
+		}
+		l.low = math.Min(l.low, v)
+		l.high = math.Max(l.high, v)
+	}
+	for _, candle := range c.Candles {
+		extend(candle.Low)
+		extend(candle.High)
+		l.maxVolume = math.Max(l.maxVolume, candle.Volume)
+	}
+	for _, line := range c.Lines {
+		for _, v := range line.Values {
+			extend(v)
+		}
+	}
+	for _, level := range c.Levels {
+		extend(level.Price)
+	}
+	if math.IsInf(l.low, 0) {
+		l.low, l.high = 0, 1
+	}
+	if l.high-l.low < 1e-9 {
+		l.low, l.high = l.low-1, l.high+1
+	}
+	pad := (l.high - l.low) * 0.05
+	l.low, l.high = l.low-pad, l.high+pad
+
+	// Справа - подписи цены и уровней
+	labelWidth := textWidth(formatPrice(l.high, l.high-l.low), textScale)
+	for _, level := range c.Levels {
+		labelWidth = max(labelWidth, textWidth(levelLabel(level, l.high-l.low), textScale))
+	}
+
+	l.left = padding
+	l.labelsRight = width - padding - labelWidth - 8
+	l.right = l.labelsRight - padding
+	l.priceTop = padding + textHeight(textScale) + padding
+	l.dateLabelsY = height - padding - textHeight(textScale)
+	bottom := l.dateLabelsY - padding
+
+	l.hasVolumePanel = l.maxVolume > 0
+	if l.hasVolumePanel {
+		volumeHeight := (bottom - l.priceTop) / 5
+		l.volumeBottom = bottom
+		l.volumeTop = bottom - volumeHeight
+		l.priceBottom = l.volumeTop - padding
+	} else {
+		l.priceBottom = bottom
+	}
+
+	l.step = float64(l.right-l.left) / float64(len(c.Candles))
+	return l
+}
+
+// drawGrid рисует сетку, шкалу цены и даты
+func (c *Chart) drawGrid(img *image.RGBA, l *layout) {
+	bottom := l.priceBottom
+	if l.hasVolumePanel {
+		bottom = l.volumeBottom
+	}
+
+	for _, tick := range niceTicks(l.low, l.high, 6) {
+		y := l.y(tick)
+		fillRect(img, l.left, y, l.right, y+1, colorGrid)
+		label := formatPrice(tick, l.high-l.low)
+		drawText(img, l.labelsRight+8, y-textHeight(textScale)/2, label, colorText, textScale)
+	}
+
+	// Даты: не больше 6 подписей
+	dateLayout := "02.01.06"
+	if len(c.Candles) > 1 && c.Candles[1].Begin.Sub(c.Candles[0].Begin) < 24*time.Hour {
+		dateLayout = "02.01 15:04"
+	}
+	labelWidth := textWidth(dateLayout, textScale)
+	every := max(1, int(math.Ceil(float64(len(c.Candles))/6)))
+	for i := 0; i < len(c.Candles); i += every {
+		x := l.x(i)
+		fillRect(img, x, l.priceTop, x+1, bottom, colorGrid)
+		labelX := min(max(x-labelWidth/2, l.left), l.right-labelWidth)
+		drawText(img, labelX, l.dateLabelsY, c.Candles[i].Begin.In(api.MSK).Format(dateLayout), colorText, textScale)
+	}
+
+	// Рамки областей
+	fillRect(img, l.right, l.priceTop, l.right+1, bottom, colorAxis)
+	fillRect(img, l.left, l.priceBottom, l.right, l.priceBottom+1, colorAxis)
+	if l.hasVolumePanel {
+		fillRect(img, l.left, l.volumeBottom, l.right, l.volumeBottom+1, colorAxis)
+	}
+}
+
+// bodyWidth ширина тела свечи
+func (l *layout) bodyWidth() int {
+	return max(1, int(l.step*0.7))
+}
+
+// drawVolume рисует объем под свечами
+func (c *Chart) drawVolume(img *image.RGBA, l *layout) {
+	if !l.hasVolumePanel {
+		return
+	}
+
+	body := l.bodyWidth()
+	height := float64(l.volumeBottom - l.volumeTop)
+	for i, candle := range c.Candles {
+		if candle.Volume <= 0 {
+			continue
+		}
+		fill := lighten(ColorUp, 0.5)
+		if candle.Close < candle.Open {
+			fill = lighten(ColorDown, 0.5)
+		}
+		x := l.x(i) - body/2
+		top := l.volumeBottom - int(candle.Volume/l.maxVolume*height)
+		fillRect(img, x, top, x+body, l.volumeBottom, fill)
+	}
+}
+
+// drawCandles рисует свечи: тень от минимума до максимума и тело от открытия до закрытия
+func (c *Chart) drawCandles(img *image.RGBA, l *layout) {
+	body := l.bodyWidth()
+	for i, candle := range c.Candles {
+		fill := ColorUp
+		if candle.Close < candle.Open {
+			fill = ColorDown
+		}
+
+		x := l.x(i)
+		fillRect(img, x, l.y(candle.High), x+1, l.y(candle.Low)+1, fill)
+
+		top, bottom := l.y(math.Max(candle.Open, candle.Close)), l.y(math.Min(candle.Open, candle.Close))
+		fillRect(img, x-body/2, top, x-body/2+body, bottom+1, fill)
+	}
+}
+
+// drawLines рисует линии индикаторов, пропуская NaN
+func (c *Chart) drawLines(img *image.RGBA, l *layout) {
+	for _, line := range c.Lines {
+		prevX, prevY, hasPrev := 0, 0, false
+		for i, v := range line.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				hasPrev = false
+				continue
+			}
+			x, y := l.x(i), l.y(v)
+			if hasPrev {
+				drawThickLine(img, prevX, prevY, x, y, line.Color)
+			}
+			prevX, prevY, hasPrev = x, y, true
+		}
+	}
+}
+
+// drawLevels рисует уровни пунктиром с подписью на шкале цены
+func (c *Chart) drawLevels(img *image.RGBA, l *layout) {
+	for _, level := range c.Levels {
+		if level.Price <= 0 {
+			continue
+		}
+		y := l.y(level.Price)
+		drawDashedHLine(img, l.left, l.right, y, level.Color)
+
+		label := levelLabel(level, l.high-l.low)
+		h := textHeight(textScale)
+		fillRect(img, l.labelsRight+4, y-h/2-3, l.labelsRight+12+textWidth(label, textScale), y+h/2+4, level.Color)
+		drawText(img, l.labelsRight+8, y-h/2, label, colorBackground, textScale)
+	}
+}
+
+// drawMarkers рисует отметки сигналов
+func (c *Chart) drawMarkers(img *image.RGBA, l *layout) {
+	size := max(8, min(16, int(l.step)))
+	for _, marker := range c.Markers {
+		i := candleIndex(c.Candles, marker.Time)
+		if i < 0 {
+			continue
+		}
+		x := l.x(i)
+		switch marker.Kind {
+		case MarkerLong:
+			drawTriangle(img, x, l.y(c.Candles[i].Low)+4, size, true, ColorUp)
+		case MarkerShort:
+			drawTriangle(img, x, l.y(c.Candles[i].High)-4, size, false, ColorDown)
+		case MarkerExit:
+			price := marker.Price
+			if price <= 0 {
+				price = c.Candles[i].Close
+			}
+			drawDiamond(img, x, l.y(price), size/2, ColorExit)
+		}
+	}
+}
+
+// drawHeader выводит заголовок и легенду линий
+func (c *Chart) drawHeader(img *image.RGBA, l *layout) {
+	x := l.left
+	drawText(img, x, padding, c.Title, colorText, textScale)
+	x += textWidth(c.Title, textScale) + 4*padding
+
+	h := textHeight(textScale)
+	for _, line := range c.Lines {
+		if line.Name == "" {
+			continue
+		}
+		fillRect(img, x, padding+h/2-2, x+20, padding+h/2+2, line.Color)
+		x += 26
+		drawText(img, x, padding, line.Name, line.Color, textScale)
+		x += textWidth(line.Name, textScale) + 2*padding
+	}
+}
+
+// candleIndex индекс последней свечи, начавшейся не позже t (-1 - такой нет)
+func candleIndex(candles []api.Candle, t time.Time) int {
+	index := -1
+	for i, candle := range candles {
+		if candle.Begin.After(t) {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// levelLabel подпись уровня: имя и цена
+func levelLabel(level Level, priceRange float64) string {
+	if level.Name == "" {
+		return formatPrice(level.Price, priceRange)
+	}
+	return level.Name + " " + formatPrice(level.Price, priceRange)
+}
+
+// formatPrice цена с числом знаков после запятой по диапазону шкалы
+func formatPrice(price, priceRange float64) string {
+	decimals := 0
+	switch {
+	case priceRange < 1:
+		decimals = 4
+	case priceRange < 10:
+		decimals = 3
+	case priceRange < 100:
+		decimals = 2
+	case priceRange < 1000:
+		decimals = 1
+	}
+	return strconv.FormatFloat(price, 'f', decimals, 64)
+}
+
+// niceTicks отметки шкалы с "круглым" шагом 1, 2 или 5 x 10^n
+func niceTicks(low, high float64, count int) []float64 {
+	if high <= low || count < 2 {
+		return nil
+	}
+
+	raw := (high - low) / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 5, 10} {
+		step = m * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	var ticks []float64
+	for v := math.Ceil(low/step) * step; v <= high; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
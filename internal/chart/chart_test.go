@@ -0,0 +1,145 @@
+package chart_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/chart"
+)
+
+// testCandles дневные свечи: n/2 растущих, затем падающих
+func testCandles(n int) []api.Candle {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, api.MSK)
+	candles := make([]api.Candle, n)
+	price := 100.0
+	for i := range candles {
+		open := price
+		if i < n/2 {
+			price += 2
+		} else {
+			price -= 1.5
+		}
+		candles[i] = api.Candle{
+			Begin:  start.AddDate(0, 0, i),
+			Open:   open,
+			High:   math.Max(open, price) + 1,
+			Low:    math.Min(open, price) - 1,
+			Close:  price,
+			Volume: float64(1000 + i*10),
+		}
+	}
+	return candles
+}
+
+// countColor сколько пикселей изображения имеют цвет c
+func countColor(img image.Image, c color.RGBA) int {
+	count := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == c {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestChartPNG(t *testing.T) {
+	candles := testCandles(60)
+
+	sma := make([]float64, len(candles))
+	for i := range sma {
+		sma[i] = math.NaN()
+		if i >= 4 {
+			sma[i] = (candles[i].Close + candles[i-1].Close + candles[i-2].Close + candles[i-3].Close + candles[i-4].Close) / 5
+		}
+	}
+
+	stop := color.RGBA{200, 0, 200, 255}
+	c := &chart.Chart{
+		Title:   "SBER 24",
+		Candles: candles,
+		Lines:   []chart.Line{{Name: "SMA 5", Values: sma, Color: chart.ColorBlue}},
+		Markers: []chart.Marker{
+			{Time: candles[10].Begin, Kind: chart.MarkerLong},
+			{Time: candles[45].Begin.Add(time.Hour), Kind: chart.MarkerShort},
+			{Time: candles[50].Begin, Price: candles[50].Close, Kind: chart.MarkerExit},
+		},
+		Levels: []chart.Level{{Name: "SL", Price: 90, Color: stop}},
+		Width:  800,
+		Height: 500,
+	}
+
+	data, err := c.PNG()
+	if err != nil {
+		t.Fatalf("PNG() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("PNG() = %q..., want PNG signature", data[:8])
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 800 || size.Y != 500 {
+		t.Errorf("size = %v, want 800x500", size)
+	}
+
+	for name, want := range map[string]color.RGBA{
+		"растущие свечи": chart.ColorUp,
+		"падающие свечи": chart.ColorDown,
+		"линия SMA":      chart.ColorBlue,
+		"уровень стопа":  stop,
+		"выход":          chart.ColorExit,
+	} {
+		if countColor(img, want) == 0 {
+			t.Errorf("%s: нет пикселей цвета %v", name, want)
+		}
+	}
+}
+
+func TestChartDefaultSize(t *testing.T) {
+	c := &chart.Chart{Title: "GAZP", Candles: testCandles(10)}
+
+	var buf bytes.Buffer
+	if err := c.Render(&buf); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	config, err := png.DecodeConfig(&buf)
+	if err != nil {
+		t.Fatalf("png.DecodeConfig() error = %v", err)
+	}
+	if config.Width != chart.DefaultWidth || config.Height != chart.DefaultHeight {
+		t.Errorf("size = %dx%d, want %dx%d", config.Width, config.Height, chart.DefaultWidth, chart.DefaultHeight)
+	}
+}
+
+func TestChartErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		chart chart.Chart
+	}{
+		{name: "Нет свечей", chart: chart.Chart{}},
+		{name: "Длина линии не совпадает", chart: chart.Chart{
+			Candles: testCandles(10),
+			Lines:   []chart.Line{{Name: "SMA", Values: make([]float64, 5)}},
+		}},
+		{name: "Слишком мало места", chart: chart.Chart{Candles: testCandles(500), Width: 300, Height: 200}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.chart.PNG(); err == nil {
+				t.Error("PNG() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+)
+
+// fillRect закрашивает прямоугольник [x0, x1) x [y0, y1)
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	rect := image.Rect(x0, y0, x1, y1).Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine рисует отрезок алгоритмом Брезенхэма
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawThickLine рисует отрезок толщиной 2 пикселя
+func drawThickLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	drawLine(img, x0, y0, x1, y1, c)
+	if abs(x1-x0) >= abs(y1-y0) {
+		drawLine(img, x0, y0+1, x1, y1+1, c)
+	} else {
+		drawLine(img, x0+1, y0, x1+1, y1, c)
+	}
+}
+
+// drawDashedHLine рисует горизонтальную пунктирную линию
+func drawDashedHLine(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	const dash, gap = 6, 4
+	for x := x0; x < x1; x += dash + gap {
+		fillRect(img, x, y, min(x+dash, x1), y+2, c)
+	}
+}
+
+// drawTriangle рисует закрашенный треугольник с вершиной в (x, y): up - острием
+// вверх (основание ниже вершины), иначе острием вниз
+func drawTriangle(img *image.RGBA, x, y, size int, up bool, c color.RGBA) {
+	for i := 0; i <= size; i++ {
+		row := y + i
+		if !up {
+			row = y - i
+		}
+		fillRect(img, x-i/2-1, row, x+i/2+2, row+1, c)
+	}
+}
+
+// drawDiamond рисует закрашенный ромб с центром в (x, y)
+func drawDiamond(img *image.RGBA, x, y, radius int, c color.RGBA) {
+	for i := -radius; i <= radius; i++ {
+		half := radius - abs(i)
+		fillRect(img, x-half, y+i, x+half+1, y+i+1, c)
+	}
+}
+
+// lighten смешивает цвет с белым: amount 0 - исходный цвет, 1 - белый
+func lighten(c color.RGBA, amount float64) color.RGBA {
+	mix := func(v uint8) uint8 {
+		return uint8(float64(v) + (255-float64(v))*amount)
+	}
+	return color.RGBA{mix(c.R), mix(c.G), mix(c.B), 255}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -13,13 +13,98 @@ import (
 
 // Config основной конфигурационный файл
 type Config struct {
-	Telegram  TelegramConfig  `yaml:"telegram"`
-	API       APIConfig       `yaml:"api"`
-	Bot       BotConfig       `yaml:"bot"`
-	Strategy  StrategyConfig  `yaml:"strategy"`
-	Technical TechnicalConfig `yaml:"technical"`
-	Logging   LoggingConfig   `yaml:"logging"`
-	Security  SecurityConfig  `yaml:"security"`
+	Telegram   TelegramConfig   `yaml:"telegram"`
+	API        APIConfig        `yaml:"api"`
+	Bot        BotConfig        `yaml:"bot"`
+	Strategy   StrategyConfig   `yaml:"strategy"`
+	Technical  TechnicalConfig  `yaml:"technical"`
+	Logging    LoggingConfig    `yaml:"logging"`
+	Security   SecurityConfig   `yaml:"security"`
+	Cache      CacheConfig      `yaml:"cache"`
+	DataSource DataSourceConfig `yaml:"data_source"`
+	Account    AccountConfig    `yaml:"account"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Scheduler  SchedulerConfig  `yaml:"scheduler"`
+	Alerts     AlertsConfig     `yaml:"alerts"`
+}
+
+// AlertsConfig настройки алертов пользователей (/alert). Условия проверяются по
+// дневным свечам задачей планировщика с учетом торгового календаря.
+type AlertsConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	CheckSchedule string        `yaml:"check_schedule"` // cron-выражение проверки, MSK
+	TradingOnly   bool          `yaml:"trading_only"`   // Проверять только во время торгов: торговые дни и часы биржи
+	Cooldown      time.Duration `yaml:"cooldown"`       // Пауза повторяющегося алерта по умолчанию
+	MaxPerUser    int           `yaml:"max_per_user"`   // Максимум алертов у пользователя (0 - без ограничения)
+}
+
+// SchedulerConfig настройки расписания фонового анализа. Само расписание задач
+// задается в strategy.notifications.analysis_schedule, время - московское.
+type SchedulerConfig struct {
+	TradingDaysOnly bool     `yaml:"trading_days_only"` // Не запускать анализ в выходные и праздники
+	TradingHours    string   `yaml:"trading_hours"`     // Например 10:00-23:50; пусто - весь день
+	Holidays        []string `yaml:"holidays"`          // Неторговые дни биржи, 2006-01-02
+	CatchUp         bool     `yaml:"catch_up"`          // Запускать пропущенный анализ после рестарта
+}
+
+// AccountConfig счет по умолчанию для расчета размера позиции. Пользователь
+// может задать свой счет командой /equity.
+type AccountConfig struct {
+	Equity             float64 `yaml:"equity"`               // Размер счета, ₽
+	MaxPositionPercent float64 `yaml:"max_position_percent"` // Макс. стоимость позиции, % от счета (0 - без ограничения)
+}
+
+// StorageConfig настройки хранилища пользовательских данных и журнала сигналов
+type StorageConfig struct {
+	Path      string        `yaml:"path"`       // JSON файл; пустой путь - хранение только в памяти
+	SignalTTL time.Duration `yaml:"signal_ttl"` // Сигнал, не повторявшийся дольше, считается истекшим (0 - 72h)
+
+	OutcomeTimeout time.Duration `yaml:"outcome_timeout"` // Сколько отслеживается исход сигнала (0 - 720h)
+}
+
+// DataSourceConfig настройки источника рыночных данных
+type DataSourceConfig struct {
+	Provider string           `yaml:"provider"` // fetcher - MOEX Fetcher, iss - напрямую MOEX ISS, file - CSV файлы
+	ISS      ISSConfig        `yaml:"iss"`
+	File     FileSourceConfig `yaml:"file"`
+}
+
+// FileSourceConfig настройки чтения свечей из CSV файлов
+type FileSourceConfig struct {
+	Dir        string            `yaml:"dir"`
+	Preset     string            `yaml:"preset"`      // finam, iss или пусто
+	Pattern    string            `yaml:"pattern"`     // Шаблон имени файла, по умолчанию {ticker}_{timeframe}.csv
+	Delimiter  string            `yaml:"delimiter"`   // Один символ; \t для табуляции
+	DateFormat string            `yaml:"date_format"` // Формат в нотации Go, например 2006-01-02
+	TimeFormat string            `yaml:"time_format"` // Если время в отдельной колонке
+	Columns    FileColumnsConfig `yaml:"columns"`
+}
+
+// FileColumnsConfig названия колонок CSV файла
+type FileColumnsConfig struct {
+	Date   string `yaml:"date"`
+	Time   string `yaml:"time"`
+	Open   string `yaml:"open"`
+	High   string `yaml:"high"`
+	Low    string `yaml:"low"`
+	Close  string `yaml:"close"`
+	Volume string `yaml:"volume"`
+}
+
+// ISSConfig настройки MOEX ISS
+type ISSConfig struct {
+	URL         string        `yaml:"url"`
+	Engine      string        `yaml:"engine"`
+	Market      string        `yaml:"market"`
+	Board       string        `yaml:"board"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Instruments []string      `yaml:"instruments"` // Пусто - все бумаги режима торгов
+}
+
+// CacheConfig настройки локального кэша свечей
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"`
 }
 
 // TelegramConfig настройки Telegram API
@@ -27,15 +112,28 @@ type TelegramConfig struct {
 	Token          string `yaml:"token"`
 	Debug          bool   `yaml:"debug"`
 	UpdatesTimeout int    `yaml:"updates_timeout"`
+	APIEndpoint    string `yaml:"api_endpoint"` // Шаблон URL Bot API (токен и метод через %s); пустой - api.telegram.org
 }
 
 // APIConfig настройки API MOEX Fetcher
 type APIConfig struct {
-	URL        string        `yaml:"url"`
-	Token      string        `yaml:"token"`
-	Timeout    time.Duration `yaml:"timeout"`
-	MaxRetries int           `yaml:"max_retries"`
-	RetryDelay time.Duration `yaml:"retry_delay"`
+	URL           string        `yaml:"url"`
+	Token         string        `yaml:"token"`
+	Timeout       time.Duration `yaml:"timeout"`
+	MaxRetries    int           `yaml:"max_retries"`
+	RetryDelay    time.Duration `yaml:"retry_delay"`
+	RetryMaxDelay time.Duration `yaml:"retry_max_delay"`
+
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig настройки circuit breaker для запросов к API
+type CircuitBreakerConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	OpenTimeout      time.Duration `yaml:"open_timeout"`
+	HalfOpenRequests int           `yaml:"half_open_requests"`
+	ProbeInterval    time.Duration `yaml:"probe_interval"`
 }
 
 // BotConfig настройки бота
@@ -53,6 +151,8 @@ type BotConfig struct {
 type StrategyConfig struct {
 	Turtles       TurtleStrategyConfig `yaml:"turtles"`
 	MACrossover   MAConfig             `yaml:"ma_crossover"`
+	MACD          MACDConfig           `yaml:"macd"`
+	Bollinger     BollingerConfig      `yaml:"bollinger"`
 	Notifications NotificationsConfig  `yaml:"notifications"`
 }
 
@@ -67,6 +167,14 @@ type TurtleStrategyConfig struct {
 	PositionSizing    bool    `yaml:"position_sizing"`
 	AtrPeriod         int     `yaml:"atr_period"`
 	AtrMultiplier     float64 `yaml:"atr_multiplier"`
+
+	// Правила "Черепах": System 1 - entry/exit_breakout_days, System 2 - system2_*
+	System           string  `yaml:"system"` // 1, 2 или both
+	System2EntryDays int     `yaml:"system2_entry_days"`
+	System2ExitDays  int     `yaml:"system2_exit_days"`
+	SkipAfterWin     bool    `yaml:"skip_after_win"` // Пропуск прорыва System 1 после прибыльного
+	MaxUnits         int     `yaml:"max_units"`
+	PyramidStep      float64 `yaml:"pyramid_step"` // Шаг добавления юнита, в N
 }
 
 // MAConfig настройки стратегии Moving Average Crossover
@@ -90,19 +198,68 @@ type MAConfig struct {
 	} `yaml:"crossover_types"`
 
 	Filters struct {
-		TrendFilter   string `yaml:"trend_filter"`
-		RSIFilter     bool   `yaml:"rsi_filter"`
-		RSIOverbought int    `yaml:"rsi_overbought"`
-		RSIOversold   int    `yaml:"rsi_oversold"`
+		TrendFilter    string  `yaml:"trend_filter"` // none, sma50, sma200, ema50, ema_slope, adx или список через запятую
+		RSIFilter      bool    `yaml:"rsi_filter"`
+		RSIOverbought  int     `yaml:"rsi_overbought"`
+		RSIOversold    int     `yaml:"rsi_oversold"`
+		EMASlopePeriod int     `yaml:"ema_slope_period"`
+		EMASlopeBars   int     `yaml:"ema_slope_bars"`
+		ADXPeriod      int     `yaml:"adx_period"`
+		ADXThreshold   float64 `yaml:"adx_threshold"`
 	} `yaml:"filters"`
 }
 
+// MACDConfig настройки стратегии MACD. Нулевые периоды берутся из секции technical
+// (macd_fast, macd_slow, macd_signal).
+type MACDConfig struct {
+	Enabled               bool    `yaml:"enabled"`
+	Timeframe             string  `yaml:"timeframe"`
+	FastPeriod            int     `yaml:"fast_period"`
+	SlowPeriod            int     `yaml:"slow_period"`
+	SignalPeriod          int     `yaml:"signal_period"`
+	AtrPeriod             int     `yaml:"atr_period"`
+	RiskPerTrade          float64 `yaml:"risk_per_trade"`
+	StopLossATRMultiplier float64 `yaml:"stop_loss_atr_multiplier"`
+	TakeProfitRatio       float64 `yaml:"take_profit_ratio"`
+
+	Signals struct {
+		SignalCross        bool `yaml:"signal_cross"`
+		ZeroCross          bool `yaml:"zero_cross"`
+		Divergence         bool `yaml:"divergence"`
+		DivergenceLookback int  `yaml:"divergence_lookback"`
+	} `yaml:"signals"`
+}
+
+// BollingerConfig настройки стратегии на полосах Боллинджера. Нулевые период,
+// ширина полос и период RSI берутся из секции technical.
+type BollingerConfig struct {
+	Enabled               bool    `yaml:"enabled"`
+	Timeframe             string  `yaml:"timeframe"`
+	Mode                  string  `yaml:"mode"` // reversion, squeeze или both
+	Period                int     `yaml:"period"`
+	StdDev                float64 `yaml:"std_dev"`
+	RSIPeriod             int     `yaml:"rsi_period"`
+	RSIOverbought         int     `yaml:"rsi_overbought"`
+	RSIOversold           int     `yaml:"rsi_oversold"`
+	SqueezeLookback       int     `yaml:"squeeze_lookback"`
+	AtrPeriod             int     `yaml:"atr_period"`
+	RiskPerTrade          float64 `yaml:"risk_per_trade"`
+	StopLossATRMultiplier float64 `yaml:"stop_loss_atr_multiplier"`
+	TakeProfitRatio       float64 `yaml:"take_profit_ratio"`
+}
+
 // NotificationsConfig настройки уведомлений
 type NotificationsConfig struct {
-	Enabled         bool  `yaml:"enabled"`
-	SignalChatID    int64 `yaml:"signal_chat_id"`
-	DailyReport     bool  `yaml:"daily_report"`
-	AlertOnBreakout bool  `yaml:"alert_on_breakout"`
+	Enabled                   bool    `yaml:"enabled"`
+	SignalChatID              int64   `yaml:"signal_chat_id"`
+	DailyReport               bool    `yaml:"daily_report"`
+	AlertOnBreakout           bool    `yaml:"alert_on_breakout"`
+	MaxSignalsPerNotification int     `yaml:"max_signals_per_notification"` // 0 - 10 сигналов
+	MinConfidencePercent      float64 `yaml:"min_confidence_percent"`       // 0 - без фильтра
+
+	// Расписание фонового анализа по стратегиям (cron, MSK); не указанные
+	// стратегии анализируются по расписанию по умолчанию
+	AnalysisSchedule map[string]string `yaml:"analysis_schedule"`
 }
 
 // TechnicalConfig настройки технического анализа
@@ -183,6 +340,23 @@ func LoadConfigOrDefault(configPath string) (*Config, error) {
 	return cfg, nil
 }
 
+// defaultMACDConfig настройки стратегии MACD по умолчанию (периоды - из секции technical)
+func defaultMACDConfig() MACDConfig {
+	cfg := MACDConfig{
+		Enabled:               false,
+		Timeframe:             "24",
+		AtrPeriod:             14,
+		RiskPerTrade:          0.02,
+		StopLossATRMultiplier: 2.0,
+		TakeProfitRatio:       2.0,
+	}
+	cfg.Signals.SignalCross = true
+	cfg.Signals.ZeroCross = true
+	cfg.Signals.Divergence = true
+	cfg.Signals.DivergenceLookback = 30
+	return cfg
+}
+
 // DefaultConfig возвращает конфигурацию по умолчанию
 func DefaultConfig() *Config {
 	return &Config{
@@ -191,10 +365,18 @@ func DefaultConfig() *Config {
 			UpdatesTimeout: 60,
 		},
 		API: APIConfig{
-			URL:        "http://localhost:8080",
-			Timeout:    30 * time.Second,
-			MaxRetries: 3,
-			RetryDelay: 2 * time.Second,
+			URL:           "http://localhost:8080",
+			Timeout:       30 * time.Second,
+			MaxRetries:    3,
+			RetryDelay:    2 * time.Second,
+			RetryMaxDelay: 30 * time.Second,
+			CircuitBreaker: CircuitBreakerConfig{
+				Enabled:          true,
+				FailureThreshold: 5,
+				OpenTimeout:      time.Minute,
+				HalfOpenRequests: 1,
+				ProbeInterval:    15 * time.Second,
+			},
 		},
 		Bot: BotConfig{
 			Name:              "MOEX Data Bot",
@@ -215,11 +397,32 @@ func DefaultConfig() *Config {
 				PositionSizing:    true,
 				AtrPeriod:         20,
 				AtrMultiplier:     2.0,
+				System:            "1",
+				System2EntryDays:  55,
+				System2ExitDays:   20,
+				SkipAfterWin:      true,
+				MaxUnits:          4,
+				PyramidStep:       0.5,
+			},
+			MACD: defaultMACDConfig(),
+			Bollinger: BollingerConfig{
+				Enabled:               false,
+				Timeframe:             "24",
+				Mode:                  "both",
+				RSIOverbought:         70,
+				RSIOversold:           30,
+				SqueezeLookback:       120,
+				AtrPeriod:             14,
+				RiskPerTrade:          0.02,
+				StopLossATRMultiplier: 2.0,
+				TakeProfitRatio:       2.0,
 			},
 			Notifications: NotificationsConfig{
-				Enabled:         false,
-				DailyReport:     false,
-				AlertOnBreakout: false,
+				Enabled:                   false,
+				DailyReport:               false,
+				AlertOnBreakout:           false,
+				MaxSignalsPerNotification: 10,
+				MinConfidencePercent:      0,
 			},
 		},
 		Technical: TechnicalConfig{
@@ -243,6 +446,45 @@ func DefaultConfig() *Config {
 		Security: SecurityConfig{
 			EnableAuth: false,
 		},
+		Cache: CacheConfig{
+			Enabled: true,
+			Dir:     "./data/candles",
+		},
+		Account: AccountConfig{
+			Equity:             100000,
+			MaxPositionPercent: 25,
+		},
+		Storage: StorageConfig{
+			Path:      "./data/storage.json",
+			SignalTTL: 72 * time.Hour,
+
+			OutcomeTimeout: 30 * 24 * time.Hour,
+		},
+		Scheduler: SchedulerConfig{
+			TradingDaysOnly: true,
+			CatchUp:         true,
+		},
+		Alerts: AlertsConfig{
+			Enabled:       true,
+			CheckSchedule: "*/5 * * * *",
+			TradingOnly:   true,
+			Cooldown:      time.Hour,
+			MaxPerUser:    20,
+		},
+		DataSource: DataSourceConfig{
+			Provider: "fetcher",
+			ISS: ISSConfig{
+				URL:     "https://iss.moex.com",
+				Engine:  "stock",
+				Market:  "shares",
+				Board:   "TQBR",
+				Timeout: 30 * time.Second,
+			},
+			File: FileSourceConfig{
+				Dir:    "./data/history",
+				Preset: "finam",
+			},
+		},
 	}
 }
 
@@ -257,6 +499,9 @@ func overrideFromEnv(cfg *Config) {
 			cfg.Telegram.Debug = val
 		}
 	}
+	if endpoint := os.Getenv("TELEGRAM_API_ENDPOINT"); endpoint != "" {
+		cfg.Telegram.APIEndpoint = endpoint
+	}
 
 	// API
 	if apiURL := os.Getenv("API_URL"); apiURL != "" {
@@ -280,6 +525,26 @@ func overrideFromEnv(cfg *Config) {
 			cfg.API.RetryDelay = val
 		}
 	}
+	if maxDelay := os.Getenv("API_RETRY_MAX_DELAY"); maxDelay != "" {
+		if val, err := time.ParseDuration(maxDelay); err == nil {
+			cfg.API.RetryMaxDelay = val
+		}
+	}
+	if enabled := os.Getenv("API_CIRCUIT_BREAKER_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.API.CircuitBreaker.Enabled = val
+		}
+	}
+	if threshold := os.Getenv("API_CIRCUIT_BREAKER_THRESHOLD"); threshold != "" {
+		if val, err := strconv.Atoi(threshold); err == nil {
+			cfg.API.CircuitBreaker.FailureThreshold = val
+		}
+	}
+	if timeout := os.Getenv("API_CIRCUIT_BREAKER_OPEN_TIMEOUT"); timeout != "" {
+		if val, err := time.ParseDuration(timeout); err == nil {
+			cfg.API.CircuitBreaker.OpenTimeout = val
+		}
+	}
 
 	// Security
 	if usersStr := os.Getenv("ALLOWED_USERS"); usersStr != "" {
@@ -314,6 +579,27 @@ func overrideFromEnv(cfg *Config) {
 		cfg.Logging.File = file
 	}
 
+	// Data source
+	if provider := os.Getenv("DATA_SOURCE_PROVIDER"); provider != "" {
+		cfg.DataSource.Provider = provider
+	}
+	if issURL := os.Getenv("ISS_URL"); issURL != "" {
+		cfg.DataSource.ISS.URL = issURL
+	}
+	if dir := os.Getenv("DATA_SOURCE_FILE_DIR"); dir != "" {
+		cfg.DataSource.File.Dir = dir
+	}
+
+	// Cache
+	if enabled := os.Getenv("CACHE_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			cfg.Cache.Enabled = val
+		}
+	}
+	if dir := os.Getenv("CACHE_DIR"); dir != "" {
+		cfg.Cache.Dir = dir
+	}
+
 	// Strategy
 	if enabled := os.Getenv("STRATEGY_TURTLES_ENABLED"); enabled != "" {
 		if val, err := strconv.ParseBool(enabled); err == nil {
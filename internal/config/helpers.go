@@ -57,6 +57,9 @@ func (c *Config) PrintConfig() string {
 	sb.WriteString("🤖 Telegram:\n")
 	sb.WriteString(fmt.Sprintf("  • Debug: %v\n", c.Telegram.Debug))
 	sb.WriteString(fmt.Sprintf("  • Updates Timeout: %d\n", c.Telegram.UpdatesTimeout))
+	if c.Telegram.APIEndpoint != "" {
+		sb.WriteString(fmt.Sprintf("  • API Endpoint: %s\n", c.Telegram.APIEndpoint))
+	}
 	sb.WriteString("\n")
 
 	// API
@@ -64,6 +67,13 @@ func (c *Config) PrintConfig() string {
 	sb.WriteString(fmt.Sprintf("  • URL: %s\n", c.API.URL))
 	sb.WriteString(fmt.Sprintf("  • Timeout: %v\n", c.API.Timeout))
 	sb.WriteString(fmt.Sprintf("  • Max Retries: %d\n", c.API.MaxRetries))
+	sb.WriteString(fmt.Sprintf("  • Retry Delay: %v (max %v)\n", c.API.RetryDelay, c.API.RetryMaxDelay))
+	if c.API.CircuitBreaker.Enabled {
+		sb.WriteString(fmt.Sprintf("  • Circuit Breaker: %d ошибок, пауза %v\n",
+			c.API.CircuitBreaker.FailureThreshold, c.API.CircuitBreaker.OpenTimeout))
+	} else {
+		sb.WriteString("  • Circuit Breaker: выключен\n")
+	}
 	sb.WriteString("\n")
 
 	// Bot
@@ -80,8 +90,17 @@ func (c *Config) PrintConfig() string {
 		sb.WriteString(fmt.Sprintf("  • Timeframe: %s\n", c.Strategy.Turtles.Timeframe))
 		sb.WriteString(fmt.Sprintf("  • Lookback Period: %d дней\n", c.Strategy.Turtles.LookbackPeriod))
 		sb.WriteString(fmt.Sprintf("  • Risk per Trade: %.1f%%\n", c.Strategy.Turtles.RiskPerTrade*100))
+		sb.WriteString(fmt.Sprintf("  • System: %s (max units %d)\n", c.Strategy.Turtles.System, c.Strategy.Turtles.MaxUnits))
 	}
+	sb.WriteString(fmt.Sprintf("  • MACD Enabled: %v\n", c.Strategy.MACD.Enabled))
+	sb.WriteString(fmt.Sprintf("  • Bollinger Enabled: %v\n", c.Strategy.Bollinger.Enabled))
 	sb.WriteString(fmt.Sprintf("  • Notifications: %v\n", c.Strategy.Notifications.Enabled))
+	if c.Strategy.Notifications.Enabled {
+		sb.WriteString(fmt.Sprintf("  • Min Confidence: %.0f%% (max %d signals)\n",
+			c.Strategy.Notifications.MinConfidencePercent, c.Strategy.Notifications.MaxSignalsPerNotification))
+		sb.WriteString(fmt.Sprintf("  • Schedule: trading days only %v, hours %q, holidays %d, catch up %v\n",
+			c.Scheduler.TradingDaysOnly, c.Scheduler.TradingHours, len(c.Scheduler.Holidays), c.Scheduler.CatchUp))
+	}
 	sb.WriteString("\n")
 
 	// Security
@@ -93,6 +112,43 @@ func (c *Config) PrintConfig() string {
 	}
 	sb.WriteString("\n")
 
+	// Data source
+	sb.WriteString("📡 Data Source:\n")
+	sb.WriteString(fmt.Sprintf("  • Provider: %s\n", c.DataSource.Provider))
+	if c.DataSource.Provider == "iss" {
+		sb.WriteString(fmt.Sprintf("  • ISS: %s (%s/%s/%s)\n", c.DataSource.ISS.URL,
+			c.DataSource.ISS.Engine, c.DataSource.ISS.Market, c.DataSource.ISS.Board))
+	}
+	if c.DataSource.Provider == "file" {
+		sb.WriteString(fmt.Sprintf("  • Dir: %s (%s)\n", c.DataSource.File.Dir, c.DataSource.File.Preset))
+	}
+	sb.WriteString("\n")
+
+	// Cache
+	sb.WriteString("💾 Cache:\n")
+	sb.WriteString(fmt.Sprintf("  • Enabled: %v\n", c.Cache.Enabled))
+	if c.Cache.Enabled {
+		sb.WriteString(fmt.Sprintf("  • Dir: %s\n", c.Cache.Dir))
+	}
+	sb.WriteString("\n")
+
+	// Account
+	sb.WriteString("💰 Account:\n")
+	sb.WriteString(fmt.Sprintf("  • Equity: %.0f₽\n", c.Account.Equity))
+	sb.WriteString(fmt.Sprintf("  • Max Position: %.0f%%\n", c.Account.MaxPositionPercent))
+	sb.WriteString(fmt.Sprintf("  • Storage: %s\n", c.Storage.Path))
+	sb.WriteString(fmt.Sprintf("  • Signal TTL: %v (outcome timeout %v)\n", c.Storage.SignalTTL, c.Storage.OutcomeTimeout))
+	sb.WriteString("\n")
+
+	// Alerts
+	sb.WriteString("🔔 Alerts:\n")
+	sb.WriteString(fmt.Sprintf("  • Enabled: %v\n", c.Alerts.Enabled))
+	if c.Alerts.Enabled {
+		sb.WriteString(fmt.Sprintf("  • Check Schedule: %s (trading only: %v)\n", c.Alerts.CheckSchedule, c.Alerts.TradingOnly))
+		sb.WriteString(fmt.Sprintf("  • Cooldown: %v (max %d per user)\n", c.Alerts.Cooldown, c.Alerts.MaxPerUser))
+	}
+	sb.WriteString("\n")
+
 	// Logging
 	sb.WriteString("📝 Logging:\n")
 	sb.WriteString(fmt.Sprintf("  • Level: %s\n", c.Logging.Level))
@@ -159,5 +215,12 @@ func (c *Config) EnsureDirectories() error {
 		return fmt.Errorf("ошибка создания директории данных: %w", err)
 	}
 
+	// Создаем директорию кэша свечей
+	if c.Cache.Enabled && c.Cache.Dir != "" {
+		if err := os.MkdirAll(c.Cache.Dir, 0755); err != nil {
+			return fmt.Errorf("ошибка создания директории кэша: %w", err)
+		}
+	}
+
 	return nil
 }
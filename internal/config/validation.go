@@ -6,6 +6,9 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"telegram-bot-moex/internal/scheduler"
 )
 
 // ValidateConfig валидирует конфигурацию
@@ -15,6 +18,11 @@ func ValidateConfig(cfg *Config) error {
 		return fmt.Errorf("неверный Telegram токен: %w", err)
 	}
 
+	// Шаблон адреса Bot API должен содержать места для токена и метода
+	if endpoint := cfg.Telegram.APIEndpoint; endpoint != "" && strings.Count(endpoint, "%s") != 2 {
+		return fmt.Errorf("неверный telegram.api_endpoint: ожидается шаблон вида https://api.telegram.org/bot%%s/%%s")
+	}
+
 	// Валидация URL API
 	if err := validateURL(cfg.API.URL); err != nil {
 		return fmt.Errorf("неверный URL API: %w", err)
@@ -25,6 +33,11 @@ func ValidateConfig(cfg *Config) error {
 		return fmt.Errorf("ошибка таймаутов: %w", err)
 	}
 
+	// Валидация circuit breaker
+	if err := validateCircuitBreaker(cfg.API.CircuitBreaker); err != nil {
+		return fmt.Errorf("ошибка настроек circuit breaker: %w", err)
+	}
+
 	// Валидация настроек логирования
 	if err := validateLogging(cfg.Logging); err != nil {
 		return fmt.Errorf("ошибка настроек логирования: %w", err)
@@ -45,6 +58,32 @@ func ValidateConfig(cfg *Config) error {
 		return fmt.Errorf("ошибка настроек бота: %w", err)
 	}
 
+	// Валидация расписания фонового анализа
+	if err := validateScheduler(cfg); err != nil {
+		return fmt.Errorf("ошибка настроек расписания: %w", err)
+	}
+
+	// Валидация источника данных
+	if err := validateDataSource(cfg.DataSource); err != nil {
+		return fmt.Errorf("ошибка настроек источника данных: %w", err)
+	}
+
+	// Валидация настроек кэша
+	if cfg.Cache.Enabled && strings.TrimSpace(cfg.Cache.Dir) == "" {
+		return fmt.Errorf("ошибка настроек кэша: не указана директория")
+	}
+
+	// Валидация счета
+	if cfg.Account.Equity < 0 {
+		return fmt.Errorf("ошибка настроек счета: размер счета не может быть отрицательным")
+	}
+	if cfg.Account.MaxPositionPercent < 0 || cfg.Account.MaxPositionPercent > 100 {
+		return fmt.Errorf("ошибка настроек счета: max position percent должен быть между 0 и 100")
+	}
+	if cfg.Storage.SignalTTL < 0 || cfg.Storage.OutcomeTimeout < 0 {
+		return fmt.Errorf("ошибка настроек хранилища: signal ttl и outcome timeout не могут быть отрицательными")
+	}
+
 	return nil
 }
 
@@ -120,10 +159,80 @@ func validateTimeouts(cfg *Config) error {
 	if cfg.API.RetryDelay > 30*time.Second {
 		return fmt.Errorf("retry delay не может превышать 30 секунд")
 	}
+	if cfg.API.RetryMaxDelay > 0 && cfg.API.RetryMaxDelay < cfg.API.RetryDelay {
+		return fmt.Errorf("retry max delay не может быть меньше retry delay")
+	}
+	if cfg.API.RetryMaxDelay > 5*time.Minute {
+		return fmt.Errorf("retry max delay не может превышать 5 минут")
+	}
+
+	// Проверка количества повторных попыток
+	if cfg.API.MaxRetries < 0 || cfg.API.MaxRetries > 10 {
+		return fmt.Errorf("max retries должен быть между 0 и 10")
+	}
+
+	return nil
+}
+
+// validateCircuitBreaker проверяет настройки circuit breaker
+func validateCircuitBreaker(cb CircuitBreakerConfig) error {
+	if !cb.Enabled {
+		return nil
+	}
+
+	if cb.FailureThreshold < 1 || cb.FailureThreshold > 100 {
+		return fmt.Errorf("failure threshold должен быть между 1 и 100")
+	}
+	if cb.OpenTimeout < time.Second || cb.OpenTimeout > 30*time.Minute {
+		return fmt.Errorf("open timeout должен быть между 1 секундой и 30 минутами")
+	}
+	if cb.HalfOpenRequests < 1 || cb.HalfOpenRequests > 10 {
+		return fmt.Errorf("half open requests должен быть между 1 и 10")
+	}
+	if cb.ProbeInterval < time.Second || cb.ProbeInterval > 10*time.Minute {
+		return fmt.Errorf("probe interval должен быть между 1 секундой и 10 минутами")
+	}
 
 	return nil
 }
 
+// validateDataSource проверяет настройки источника данных
+func validateDataSource(ds DataSourceConfig) error {
+	switch ds.Provider {
+	case "", "fetcher":
+		return nil
+	case "iss":
+		if err := validateURL(ds.ISS.URL); err != nil {
+			return fmt.Errorf("неверный URL ISS: %w", err)
+		}
+		if ds.ISS.Board == "" {
+			return fmt.Errorf("не указан режим торгов ISS (board)")
+		}
+		if ds.ISS.Timeout < 0 || ds.ISS.Timeout > 5*time.Minute {
+			return fmt.Errorf("ISS timeout должен быть между 0 и 5 минутами")
+		}
+		return nil
+	case "file":
+		if ds.File.Dir == "" {
+			return fmt.Errorf("не указан каталог с CSV файлами")
+		}
+		switch ds.File.Preset {
+		case "", "finam", "iss":
+		default:
+			return fmt.Errorf("неизвестный формат файлов %q (допустимо: finam, iss)", ds.File.Preset)
+		}
+		if ds.File.Preset == "" && ds.File.Columns.Date == "" {
+			return fmt.Errorf("без preset необходимо указать колонки файла")
+		}
+		if d := ds.File.Delimiter; d != "" && d != "\\t" && utf8.RuneCountInString(d) != 1 {
+			return fmt.Errorf("разделитель должен быть одним символом")
+		}
+		return nil
+	default:
+		return fmt.Errorf("неизвестный провайдер %q (допустимо: fetcher, iss, file)", ds.Provider)
+	}
+}
+
 // validateLogging проверяет настройки логирования
 func validateLogging(logging LoggingConfig) error {
 	// Проверка уровня логирования
@@ -205,6 +314,16 @@ func validateSecurity(security SecurityConfig) error {
 
 // validateStrategy проверяет настройки стратегии
 func validateStrategy(strategy StrategyConfig) error {
+	// Допустимые таймфреймы стратегий
+	validTimeframes := map[string]bool{
+		"1":  true, // 1 минута
+		"10": true, // 10 минут
+		"60": true, // 1 час
+		"24": true, // 1 день
+		"7":  true, // 1 неделя
+		"31": true, // 1 месяц
+	}
+
 	// Проверка параметров стратегии "Черепах"
 	if strategy.Turtles.Enabled {
 		if strategy.Turtles.LookbackPeriod <= 0 {
@@ -226,24 +345,129 @@ func validateStrategy(strategy StrategyConfig) error {
 			return fmt.Errorf("atr multiplier должен быть положительным числом")
 		}
 
-		// Проверка допустимых таймфреймов
-		validTimeframes := map[string]bool{
-			"1":  true, // 1 минута
-			"10": true, // 10 минут
-			"60": true, // 1 час
-			"24": true, // 1 день
-			"7":  true, // 1 неделя
-			"31": true, // 1 месяц
-		}
-
 		if !validTimeframes[strategy.Turtles.Timeframe] {
 			return fmt.Errorf("неверный таймфрейм для стратегии: %s", strategy.Turtles.Timeframe)
 		}
+
+		switch strategy.Turtles.System {
+		case "", "1", "2", "both":
+		default:
+			return fmt.Errorf("неизвестная система 'Черепах': %s (допустимо 1, 2, both)", strategy.Turtles.System)
+		}
+		if strategy.Turtles.System2EntryDays < 0 || strategy.Turtles.System2ExitDays < 0 {
+			return fmt.Errorf("дни прорыва System 2 не могут быть отрицательными")
+		}
+		if strategy.Turtles.MaxUnits < 0 || strategy.Turtles.PyramidStep < 0 {
+			return fmt.Errorf("max units и pyramid step не могут быть отрицательными")
+		}
+	}
+
+	// Проверка фильтров стратегии MA Crossover
+	if ma := strategy.MACrossover; ma.Enabled {
+		trendFilter := regexp.MustCompile(`^(none|ema_slope|adx|(sma|ema)\d+)$`)
+		for _, name := range strings.Split(ma.Filters.TrendFilter, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && !trendFilter.MatchString(name) {
+				return fmt.Errorf("неизвестный фильтр тренда MA Crossover: %s", name)
+			}
+		}
+		if ma.Filters.EMASlopePeriod < 0 || ma.Filters.EMASlopeBars < 0 || ma.Filters.ADXPeriod < 0 {
+			return fmt.Errorf("периоды фильтров MA Crossover не могут быть отрицательными")
+		}
+		if ma.Filters.ADXThreshold < 0 || ma.Filters.ADXThreshold > 100 {
+			return fmt.Errorf("порог ADX должен быть между 0 и 100")
+		}
+	}
+
+	// Проверка параметров стратегии MACD
+	if macd := strategy.MACD; macd.Enabled {
+		if macd.FastPeriod < 0 || macd.SlowPeriod < 0 || macd.SignalPeriod < 0 {
+			return fmt.Errorf("периоды MACD не могут быть отрицательными")
+		}
+		if macd.FastPeriod > 0 && macd.SlowPeriod > 0 && macd.FastPeriod >= macd.SlowPeriod {
+			return fmt.Errorf("быстрый период MACD должен быть меньше медленного")
+		}
+		if macd.AtrPeriod <= 0 {
+			return fmt.Errorf("atr period для MACD должен быть положительным числом")
+		}
+		if macd.RiskPerTrade <= 0 || macd.RiskPerTrade > 1 {
+			return fmt.Errorf("risk per trade для MACD должен быть между 0 и 1")
+		}
+		if macd.StopLossATRMultiplier <= 0 || macd.TakeProfitRatio <= 0 {
+			return fmt.Errorf("стоп-лосс и тейк-профит MACD должны быть положительными")
+		}
+		if macd.Signals.Divergence && macd.Signals.DivergenceLookback < 4 {
+			return fmt.Errorf("окно поиска дивергенции MACD должно быть не меньше 4 свечей")
+		}
+		if !validTimeframes[macd.Timeframe] {
+			return fmt.Errorf("неверный таймфрейм для стратегии MACD: %s", macd.Timeframe)
+		}
+	}
+
+	// Проверка параметров стратегии Боллинджера
+	if bollinger := strategy.Bollinger; bollinger.Enabled {
+		switch bollinger.Mode {
+		case "reversion", "squeeze", "both":
+		default:
+			return fmt.Errorf("неверный режим стратегии Боллинджера: %s (reversion, squeeze, both)", bollinger.Mode)
+		}
+		if bollinger.Period < 0 || bollinger.StdDev < 0 || bollinger.RSIPeriod < 0 {
+			return fmt.Errorf("параметры полос Боллинджера не могут быть отрицательными")
+		}
+		if bollinger.RSIOversold <= 0 || bollinger.RSIOverbought >= 100 || bollinger.RSIOversold >= bollinger.RSIOverbought {
+			return fmt.Errorf("уровни RSI для Боллинджера должны удовлетворять 0 < oversold < overbought < 100")
+		}
+		if bollinger.Mode != "reversion" && bollinger.SqueezeLookback <= 1 {
+			return fmt.Errorf("squeeze lookback должен быть больше 1")
+		}
+		if bollinger.AtrPeriod <= 0 {
+			return fmt.Errorf("atr period для Боллинджера должен быть положительным числом")
+		}
+		if bollinger.RiskPerTrade <= 0 || bollinger.RiskPerTrade > 1 {
+			return fmt.Errorf("risk per trade для Боллинджера должен быть между 0 и 1")
+		}
+		if bollinger.StopLossATRMultiplier <= 0 || bollinger.TakeProfitRatio <= 0 {
+			return fmt.Errorf("стоп-лосс и тейк-профит Боллинджера должны быть положительными")
+		}
+		if !validTimeframes[bollinger.Timeframe] {
+			return fmt.Errorf("неверный таймфрейм для стратегии Боллинджера: %s", bollinger.Timeframe)
+		}
+	}
+
+	// Проверка фильтров уведомлений
+	notifications := strategy.Notifications
+	if notifications.MaxSignalsPerNotification < 0 {
+		return fmt.Errorf("max signals per notification не может быть отрицательным")
+	}
+	if notifications.MinConfidencePercent < 0 || notifications.MinConfidencePercent > 100 {
+		return fmt.Errorf("min confidence percent должен быть между 0 и 100")
 	}
 
 	return nil
 }
 
+// validateScheduler проверяет cron-выражения analysis_schedule и проверки алертов,
+// торговый календарь
+func validateScheduler(cfg *Config) error {
+	for name, spec := range cfg.Strategy.Notifications.AnalysisSchedule {
+		if _, err := scheduler.Parse(spec); err != nil {
+			return fmt.Errorf("analysis_schedule.%s: %w", name, err)
+		}
+	}
+
+	if cfg.Alerts.Enabled {
+		if _, err := scheduler.Parse(cfg.Alerts.CheckSchedule); err != nil {
+			return fmt.Errorf("alerts.check_schedule: %w", err)
+		}
+	}
+	if cfg.Alerts.Cooldown < 0 || cfg.Alerts.MaxPerUser < 0 {
+		return fmt.Errorf("alerts: cooldown и max_per_user не могут быть отрицательными")
+	}
+
+	_, err := scheduler.NewCalendar(time.UTC, cfg.Scheduler.TradingDaysOnly, cfg.Scheduler.TradingHours, cfg.Scheduler.Holidays)
+	return err
+}
+
 // validateBotSettings проверяет настройки бота
 func validateBotSettings(bot BotConfig) error {
 	// Проверка имени бота
@@ -0,0 +1,171 @@
+// Package export выгружает таблицы (свечи, сигналы, журнал) в CSV, XLSX и JSON.
+// Файлы формируются в памяти и отправляются ботом как документы Telegram.
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format формат выгрузки
+type Format string
+
+// Поддерживаемые форматы
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	JSON Format = "json"
+)
+
+// Formats все форматы в порядке показа пользователю
+var Formats = []Format{CSV, XLSX, JSON}
+
+// ParseFormat разбирает название формата без учета регистра
+func ParseFormat(s string) (Format, bool) {
+	format := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, f := range Formats {
+		if f == format {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// Extension расширение файла с точкой
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// TimeLayout формат времени в CSV
+const TimeLayout = "2006-01-02 15:04:05"
+
+// Table таблица для выгрузки. Значения ячеек: string, float64, int, int64, bool
+// и time.Time; нулевое время и NaN выгружаются пустыми.
+type Table struct {
+	Name    string   // Имя листа XLSX
+	Columns []string // Заголовки столбцов, они же ключи JSON
+	Rows    [][]any
+}
+
+// Encode кодирует таблицу в формат
+func (t *Table) Encode(format Format) ([]byte, error) {
+	for i, row := range t.Rows {
+		if len(row) != len(t.Columns) {
+			return nil, fmt.Errorf("строка %d: %d значений на %d столбцов", i+1, len(row), len(t.Columns))
+		}
+	}
+
+	switch format {
+	case CSV:
+		return t.csv()
+	case XLSX:
+		return t.xlsx()
+	case JSON:
+		return t.json()
+	default:
+		return nil, fmt.Errorf("неизвестный формат %q", format)
+	}
+}
+
+// csv заголовок и строки через запятую
+func (t *Table) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write(t.Columns)
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, value := range row {
+			record[i] = formatValue(value)
+		}
+		w.Write(record)
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("ошибка записи CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// json массив объектов с ключами в порядке столбцов
+func (t *Table) json() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, row := range t.Rows {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, value := range row {
+			if j > 0 {
+				buf.WriteString(", ")
+			}
+			key, _ := json.Marshal(t.Columns[j])
+			buf.Write(key)
+			buf.WriteString(": ")
+
+			encoded, err := json.Marshal(jsonValue(value))
+			if err != nil {
+				return nil, fmt.Errorf("ошибка записи JSON, столбец %s: %w", t.Columns[j], err)
+			}
+			buf.Write(encoded)
+		}
+		buf.WriteString("}")
+	}
+	if len(t.Rows) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	return buf.Bytes(), nil
+}
+
+// jsonValue значение ячейки для JSON: время в RFC 3339, пустые значения - null
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Format(time.RFC3339)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	}
+	return value
+}
+
+// formatValue значение ячейки в виде текста
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(TimeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
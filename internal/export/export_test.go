@@ -0,0 +1,147 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+	"telegram-bot-moex/internal/export"
+)
+
+func testTable() *export.Table {
+	begin := time.Date(2024, 1, 3, 10, 0, 0, 0, api.MSK)
+	return &export.Table{
+		Name:    "SBER 24",
+		Columns: []string{"begin", "ticker", "close", "volume", "note"},
+		Rows: [][]any{
+			{begin, "SBER", 270.5, int64(1500), "a, \"b\""},
+			{begin.AddDate(0, 0, 1), "SBER", math.NaN(), int64(0), "<&>"},
+			{time.Time{}, "SBER", 272.0, int64(10), ""},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]export.Format{"csv": export.CSV, "XLSX": export.XLSX, " json ": export.JSON} {
+		if got, ok := export.ParseFormat(input); !ok || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", input, got, ok, want)
+		}
+	}
+	if _, ok := export.ParseFormat("xls"); ok {
+		t.Error("ParseFormat(xls) ok = true, want false")
+	}
+	if ext := export.XLSX.Extension(); ext != ".xlsx" {
+		t.Errorf("Extension() = %q, want .xlsx", ext)
+	}
+}
+
+func TestTableCSV(t *testing.T) {
+	data, err := testTable().Encode(export.CSV)
+	if err != nil {
+		t.Fatalf("Encode(csv) error = %v", err)
+	}
+
+	want := "begin,ticker,close,volume,note\n" +
+		"2024-01-03 10:00:00,SBER,270.5,1500,\"a, \"\"b\"\"\"\n" +
+		"2024-01-04 10:00:00,SBER,,0,<&>\n" +
+		",SBER,272,10,\n"
+	if string(data) != want {
+		t.Errorf("csv =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestTableJSON(t *testing.T) {
+	data, err := testTable().Encode(export.JSON)
+	if err != nil {
+		t.Fatalf("Encode(json) error = %v", err)
+	}
+
+	var rows []map[string]any
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, data)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(rows))
+	}
+	if rows[0]["begin"] != "2024-01-03T10:00:00+03:00" || rows[0]["close"] != 270.5 || rows[0]["volume"] != 1500.0 {
+		t.Errorf("row 1 = %v", rows[0])
+	}
+	if rows[1]["close"] != nil || rows[2]["begin"] != nil {
+		t.Errorf("NaN and zero time = %v, %v, want null", rows[1]["close"], rows[2]["begin"])
+	}
+
+	// Ключи в порядке столбцов
+	if i, j := bytes.Index(data, []byte(`"begin"`)), bytes.Index(data, []byte(`"ticker"`)); i < 0 || i > j {
+		t.Errorf("json = %s, want keys in column order", data)
+	}
+
+	empty, err := (&export.Table{Columns: []string{"a"}}).Encode(export.JSON)
+	if err != nil || strings.TrimSpace(string(empty)) != "[]" {
+		t.Errorf("empty json = %q, %v, want []", empty, err)
+	}
+}
+
+func TestTableXLSX(t *testing.T) {
+	data, err := testTable().Encode(export.XLSX)
+	if err != nil {
+		t.Fatalf("Encode(xlsx) error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("xlsx: нет части %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="SBER 24"`) {
+		t.Errorf("workbook = %s, want sheet name", files["xl/workbook.xml"])
+	}
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr" s="2"><is><t xml:space="preserve">begin</t></is></c>`,
+		// 2024-01-03 10:00 MSK: 45294 дня + 10/24
+		`<c r="A2" s="1"><v>45294.41666666666`,
+		`<c r="C2"><v>270.5</v></c>`,
+		`<c r="D2"><v>1500</v></c>`,
+		`<t xml:space="preserve">&lt;&amp;&gt;</t>`,
+		`<row r="4"><c r="B4" t="inlineStr">`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet = %s\nwant %s", sheet, want)
+		}
+	}
+	if strings.Contains(sheet, `r="C3"`) {
+		t.Error("sheet: NaN записан в ячейку C3, want empty")
+	}
+}
+
+func TestTableErrors(t *testing.T) {
+	table := &export.Table{Columns: []string{"a", "b"}, Rows: [][]any{{1}}}
+	if _, err := table.Encode(export.CSV); err == nil {
+		t.Error("Encode() with short row error = nil, want error")
+	}
+	if _, err := testTable().Encode("xml"); err == nil {
+		t.Error("Encode(xml) error = nil, want error")
+	}
+}
@@ -0,0 +1,238 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxXLSXRows строк данных на листе: лимит Excel 1 048 576 строк минус заголовок
+const MaxXLSXRows = 1<<20 - 1
+
+// Стили ячеек из xlsxStyles (индексы cellXfs)
+const (
+	styleDefault = 0
+	styleTime    = 1
+	styleHeader  = 2
+)
+
+// excelEpoch начало отсчета дат Excel (с учетом ошибки 1900 года)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+// xlsxStyles стили: обычная ячейка, дата и время, жирный заголовок
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xlsx книга из одного листа: заголовок закреплен, числа и даты - числовые ячейки
+func (t *Table) xlsx() ([]byte, error) {
+	if len(t.Rows) > MaxXLSXRows {
+		return nil, fmt.Errorf("%d строк больше лимита листа Excel (%d)", len(t.Rows), MaxXLSXRows)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, escapeXML(sheetName(t.Name)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", t.sheetXML()},
+	}
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка записи XLSX: %w", err)
+		}
+		if _, err := w.Write([]byte(file.content)); err != nil {
+			return nil, fmt.Errorf("ошибка записи XLSX: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("ошибка записи XLSX: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sheetXML разметка листа
+func (t *Table) sheetXML() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	sb.WriteString("<cols>")
+	for i, width := range t.columnWidths() {
+		fmt.Fprintf(&sb, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+	}
+	sb.WriteString("</cols>")
+
+	sb.WriteString("<sheetData>")
+	sb.WriteString(`<row r="1">`)
+	for i, column := range t.Columns {
+		writeStringCell(&sb, cellRef(i, 1), column, styleHeader)
+	}
+	sb.WriteString("</row>")
+
+	for r, row := range t.Rows {
+		rowNum := r + 2
+		fmt.Fprintf(&sb, `<row r="%d">`, rowNum)
+		for i, value := range row {
+			ref := cellRef(i, rowNum)
+			switch v := value.(type) {
+			case nil:
+			case string:
+				if v != "" {
+					writeStringCell(&sb, ref, v, styleDefault)
+				}
+			case float64:
+				if !math.IsNaN(v) && !math.IsInf(v, 0) {
+					writeNumberCell(&sb, ref, strconv.FormatFloat(v, 'f', -1, 64), styleDefault)
+				}
+			case int:
+				writeNumberCell(&sb, ref, strconv.Itoa(v), styleDefault)
+			case int64:
+				writeNumberCell(&sb, ref, strconv.FormatInt(v, 10), styleDefault)
+			case bool:
+				b := "0"
+				if v {
+					b = "1"
+				}
+				fmt.Fprintf(&sb, `<c r="%s" t="b"><v>%s</v></c>`, ref, b)
+			case time.Time:
+				if !v.IsZero() {
+					writeNumberCell(&sb, ref, strconv.FormatFloat(excelTime(v), 'f', -1, 64), styleTime)
+				}
+			default:
+				writeStringCell(&sb, ref, fmt.Sprint(v), styleDefault)
+			}
+		}
+		sb.WriteString("</row>")
+	}
+	sb.WriteString("</sheetData></worksheet>")
+
+	return sb.String()
+}
+
+// columnWidths ширина столбцов в символах: по заголовку, для дат - по формату
+func (t *Table) columnWidths() []int {
+	widths := make([]int, len(t.Columns))
+	for i, column := range t.Columns {
+		widths[i] = max(10, len([]rune(column))+2)
+	}
+	if len(t.Rows) > 0 {
+		for i, value := range t.Rows[0] {
+			if _, ok := value.(time.Time); ok {
+				widths[i] = max(widths[i], 18)
+			}
+		}
+	}
+	return widths
+}
+
+func writeStringCell(sb *strings.Builder, ref, value string, style int) {
+	fmt.Fprintf(sb, `<c r="%s" t="inlineStr"`, ref)
+	if style != styleDefault {
+		fmt.Fprintf(sb, ` s="%d"`, style)
+	}
+	sb.WriteString(`><is><t xml:space="preserve">`)
+	sb.WriteString(escapeXML(value))
+	sb.WriteString("</t></is></c>")
+}
+
+func writeNumberCell(sb *strings.Builder, ref, value string, style int) {
+	fmt.Fprintf(sb, `<c r="%s"`, ref)
+	if style != styleDefault {
+		fmt.Fprintf(sb, ` s="%d"`, style)
+	}
+	fmt.Fprintf(sb, "><v>%s</v></c>", value)
+}
+
+// cellRef адрес ячейки: столбец с нуля, строка с единицы (A1, B2, AA10)
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// excelTime время в днях от начала отсчета Excel; часовой пояс не хранится,
+// поэтому берется время на часах в поясе значения (для свечей - MSK)
+func excelTime(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	elapsed := wall.Sub(excelEpoch)
+	days := elapsed / (24 * time.Hour)
+	return float64(days) + (elapsed-days*24*time.Hour).Seconds()/86400
+}
+
+// sheetName имя листа по правилам Excel: до 31 символа, без []:*?/\
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+// escapeXML экранирует текст для XML
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
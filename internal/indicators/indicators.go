@@ -0,0 +1,362 @@
+// Package indicators содержит технические индикаторы для стратегий и команд бота.
+//
+// Все функции возвращают ряды той же длины, что и входные данные. Значения до
+// окончания периода разогрева равны NaN: так их нельзя спутать с нулевой ценой,
+// а сравнения с NaN всегда ложны, поэтому на разогреве сигналы не возникают.
+// Некорректный период (<= 0) или недостаточная длина ряда дают ряд из NaN.
+package indicators
+
+import "math"
+
+// nanSeries возвращает ряд длины n, заполненный NaN
+func nanSeries(n int) []float64 {
+	series := make([]float64, n)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+	return series
+}
+
+// firstValid индекс первого значения, отличного от NaN (len(values), если таких нет)
+func firstValid(values []float64) int {
+	for i, v := range values {
+		if !math.IsNaN(v) {
+			return i
+		}
+	}
+	return len(values)
+}
+
+// Last возвращает последнее значение ряда и признак, что оно рассчитано (не NaN)
+func Last(series []float64) (float64, bool) {
+	if len(series) == 0 || math.IsNaN(series[len(series)-1]) {
+		return 0, false
+	}
+	return series[len(series)-1], true
+}
+
+// SMA простая скользящая средняя. Первое значение - на индексе period-1.
+func SMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return result
+	}
+
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			result[i] = sum / float64(period)
+		}
+	}
+	return result
+}
+
+// EMA экспоненциальная скользящая средняя с коэффициентом 2/(period+1).
+// Первое значение равно SMA первых period значений. Ведущие NaN во входном ряду
+// пропускаются, поэтому EMA можно строить по результату другого индикатора.
+func EMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	start := firstValid(values)
+	if period <= 0 || len(values)-start < period {
+		return result
+	}
+
+	seed := start + period - 1
+	sum := 0.0
+	for i := start; i <= seed; i++ {
+		sum += values[i]
+	}
+	result[seed] = sum / float64(period)
+
+	k := 2.0 / (float64(period) + 1)
+	for i := seed + 1; i < len(values); i++ {
+		result[i] = (values[i]-result[i-1])*k + result[i-1]
+	}
+	return result
+}
+
+// WMA взвешенная скользящая средняя (вес последнего значения - period, первого - 1)
+func WMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return result
+	}
+
+	weights := float64(period*(period+1)) / 2
+	for i := period - 1; i < len(values); i++ {
+		sum := 0.0
+		for j := 0; j < period; j++ {
+			sum += values[i-period+1+j] * float64(j+1)
+		}
+		result[i] = sum / weights
+	}
+	return result
+}
+
+// wilder сглаживание Уайлдера: первое значение - среднее values[from:from+period],
+// далее avg = (avg*(period-1) + value) / period
+func wilder(values []float64, from, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values)-from < period {
+		return result
+	}
+
+	seed := from + period - 1
+	sum := 0.0
+	for i := from; i <= seed; i++ {
+		sum += values[i]
+	}
+	result[seed] = sum / float64(period)
+
+	for i := seed + 1; i < len(values); i++ {
+		result[i] = (result[i-1]*float64(period-1) + values[i]) / float64(period)
+	}
+	return result
+}
+
+// TrueRange истинный диапазон свечи. Для первой свечи - high-low.
+func TrueRange(highs, lows, closes []float64) []float64 {
+	n := minLen(highs, lows, closes)
+	tr := make([]float64, n)
+	for i := 0; i < n; i++ {
+		tr[i] = highs[i] - lows[i]
+		if i > 0 {
+			tr[i] = math.Max(tr[i], math.Max(
+				math.Abs(highs[i]-closes[i-1]),
+				math.Abs(lows[i]-closes[i-1])))
+		}
+	}
+	return tr
+}
+
+// ATR средний истинный диапазон по Уайлдеру. Первое значение - на индексе period
+// (среднее TR свечей 1..period, первая свеча без предыдущего закрытия не участвует).
+func ATR(highs, lows, closes []float64, period int) []float64 {
+	return wilder(TrueRange(highs, lows, closes), 1, period)
+}
+
+// RSI индекс относительной силы по Уайлдеру. Первое значение - на индексе period.
+// Если за период не было падений, RSI = 100.
+func RSI(closes []float64, period int) []float64 {
+	result := nanSeries(len(closes))
+	if period <= 0 || len(closes) <= period {
+		return result
+	}
+
+	gains := make([]float64, len(closes))
+	losses := make([]float64, len(closes))
+	for i := 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		if change > 0 {
+			gains[i] = change
+		} else {
+			losses[i] = -change
+		}
+	}
+
+	avgGain := wilder(gains, 1, period)
+	avgLoss := wilder(losses, 1, period)
+	for i := period; i < len(closes); i++ {
+		if avgLoss[i] == 0 {
+			result[i] = 100
+			continue
+		}
+		result[i] = 100 - 100/(1+avgGain[i]/avgLoss[i])
+	}
+	return result
+}
+
+// MACD линия MACD (EMA fast - EMA slow), сигнальная линия (EMA signal от MACD)
+// и гистограмма. Линия MACD рассчитана с индекса slow-1, сигнальная и гистограмма -
+// с индекса slow+signal-2.
+func MACD(closes []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	macd = nanSeries(len(closes))
+	histogram = nanSeries(len(closes))
+
+	fastEMA := EMA(closes, fast)
+	slowEMA := EMA(closes, slow)
+	for i := range closes {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+
+	signalLine = EMA(macd, signal)
+	for i := range closes {
+		histogram[i] = macd[i] - signalLine[i]
+	}
+	return macd, signalLine, histogram
+}
+
+// Bollinger полосы Боллинджера: SMA period и отклонение на k стандартных
+// отклонений (генеральная совокупность) вверх и вниз
+func Bollinger(closes []float64, period int, k float64) (upper, middle, lower []float64) {
+	middle = SMA(closes, period)
+	upper = nanSeries(len(closes))
+	lower = nanSeries(len(closes))
+
+	for i := range closes {
+		if math.IsNaN(middle[i]) {
+			continue
+		}
+		variance := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			d := closes[j] - middle[i]
+			variance += d * d
+		}
+		std := math.Sqrt(variance / float64(period))
+		upper[i] = middle[i] + k*std
+		lower[i] = middle[i] - k*std
+	}
+	return upper, middle, lower
+}
+
+// Donchian канал Дончиана: максимум и минимум за period свечей, включая текущую,
+// и середина канала
+func Donchian(highs, lows []float64, period int) (upper, middle, lower []float64) {
+	n := minLen(highs, lows)
+	upper = nanSeries(n)
+	middle = nanSeries(n)
+	lower = nanSeries(n)
+	if period <= 0 || n < period {
+		return upper, middle, lower
+	}
+
+	for i := period - 1; i < n; i++ {
+		hi, lo := highs[i], lows[i]
+		for j := i - period + 1; j < i; j++ {
+			hi = math.Max(hi, highs[j])
+			lo = math.Min(lo, lows[j])
+		}
+		upper[i], lower[i] = hi, lo
+		middle[i] = (hi + lo) / 2
+	}
+	return upper, middle, lower
+}
+
+// ADX индекс направленного движения по Уайлдеру вместе с +DI и -DI.
+// +DI и -DI рассчитаны с индекса period, ADX - с индекса 2*period-1.
+func ADX(highs, lows, closes []float64, period int) (adx, plusDI, minusDI []float64) {
+	n := minLen(highs, lows, closes)
+	adx = nanSeries(n)
+	plusDI = nanSeries(n)
+	minusDI = nanSeries(n)
+	if period <= 0 || n < 2*period {
+		return adx, plusDI, minusDI
+	}
+
+	plusDM := make([]float64, n)
+	minusDM := make([]float64, n)
+	for i := 1; i < n; i++ {
+		up := highs[i] - highs[i-1]
+		down := lows[i-1] - lows[i]
+		if up > down && up > 0 {
+			plusDM[i] = up
+		}
+		if down > up && down > 0 {
+			minusDM[i] = down
+		}
+	}
+
+	tr := wilder(TrueRange(highs, lows, closes), 1, period)
+	smoothPlus := wilder(plusDM, 1, period)
+	smoothMinus := wilder(minusDM, 1, period)
+
+	dx := nanSeries(n)
+	for i := period; i < n; i++ {
+		if tr[i] == 0 {
+			plusDI[i], minusDI[i], dx[i] = 0, 0, 0
+			continue
+		}
+		plusDI[i] = 100 * smoothPlus[i] / tr[i]
+		minusDI[i] = 100 * smoothMinus[i] / tr[i]
+		if sum := plusDI[i] + minusDI[i]; sum > 0 {
+			dx[i] = 100 * math.Abs(plusDI[i]-minusDI[i]) / sum
+		} else {
+			dx[i] = 0
+		}
+	}
+
+	adx = wilder(dx, period, period)
+	return adx, plusDI, minusDI
+}
+
+// Stochastic стохастический осциллятор: %K за kPeriod свечей и %D - SMA dPeriod от %K.
+// Если за период high = low, %K = 50.
+func Stochastic(highs, lows, closes []float64, kPeriod, dPeriod int) (k, d []float64) {
+	n := minLen(highs, lows, closes)
+	k = nanSeries(n)
+	if kPeriod <= 0 || n < kPeriod {
+		return k, nanSeries(n)
+	}
+
+	upper, _, lower := Donchian(highs[:n], lows[:n], kPeriod)
+	for i := kPeriod - 1; i < n; i++ {
+		if rng := upper[i] - lower[i]; rng > 0 {
+			k[i] = 100 * (closes[i] - lower[i]) / rng
+		} else {
+			k[i] = 50
+		}
+	}
+
+	d = nanSeries(n)
+	if dPeriod <= 0 {
+		return k, d
+	}
+	smoothed := SMA(k[kPeriod-1:], dPeriod)
+	copy(d[kPeriod-1:], smoothed)
+	return k, d
+}
+
+// OBV балансовый объем. Первое значение равно объему первой свечи.
+func OBV(closes, volumes []float64) []float64 {
+	n := minLen(closes, volumes)
+	obv := make([]float64, n)
+	for i := 0; i < n; i++ {
+		switch {
+		case i == 0:
+			obv[i] = volumes[i]
+		case closes[i] > closes[i-1]:
+			obv[i] = obv[i-1] + volumes[i]
+		case closes[i] < closes[i-1]:
+			obv[i] = obv[i-1] - volumes[i]
+		default:
+			obv[i] = obv[i-1]
+		}
+	}
+	return obv
+}
+
+// VWAP средневзвешенная по объему цена (по типичной цене (high+low+close)/3),
+// накопленная с начала ряда. Для дневного VWAP передавайте свечи одной сессии.
+func VWAP(highs, lows, closes, volumes []float64) []float64 {
+	n := minLen(highs, lows, closes, volumes)
+	vwap := nanSeries(n)
+
+	var cumPV, cumVolume float64
+	for i := 0; i < n; i++ {
+		typical := (highs[i] + lows[i] + closes[i]) / 3
+		cumPV += typical * volumes[i]
+		cumVolume += volumes[i]
+		if cumVolume > 0 {
+			vwap[i] = cumPV / cumVolume
+		}
+	}
+	return vwap
+}
+
+// minLen минимальная длина из переданных рядов
+func minLen(series ...[]float64) int {
+	n := -1
+	for _, s := range series {
+		if n < 0 || len(s) < n {
+			n = len(s)
+		}
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
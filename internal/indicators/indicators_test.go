@@ -0,0 +1,211 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 0.01
+
+// assertSeries сравнивает ряд с эталоном; NaN в эталоне означает разогрев
+func assertSeries(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: len = %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		switch {
+		case math.IsNaN(want[i]):
+			if !math.IsNaN(got[i]) {
+				t.Errorf("%s[%d] = %.4f, want NaN (warm-up)", name, i, got[i])
+			}
+		case math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > tolerance:
+			t.Errorf("%s[%d] = %.4f, want %.4f", name, i, got[i], want[i])
+		}
+	}
+}
+
+var nan = math.NaN()
+
+// wilderCloses классический пример расчета RSI (StockCharts, RSI 14)
+var wilderCloses = []float64{
+	44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+	45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439,
+}
+
+func TestSMA(t *testing.T) {
+	assertSeries(t, "SMA", SMA([]float64{1, 2, 3, 4, 5}, 3), []float64{nan, nan, 2, 3, 4})
+	assertSeries(t, "SMA short", SMA([]float64{1, 2}, 3), []float64{nan, nan})
+	assertSeries(t, "SMA zero period", SMA([]float64{1, 2}, 0), []float64{nan, nan})
+}
+
+func TestEMA(t *testing.T) {
+	// k = 0.5, первое значение - SMA(1,2,3) = 2
+	assertSeries(t, "EMA", EMA([]float64{1, 2, 3, 4, 5}, 3), []float64{nan, nan, 2, 3, 4})
+	assertSeries(t, "EMA step", EMA([]float64{2, 2, 2, 10}, 3), []float64{nan, nan, 2, 6})
+	// Ведущие NaN пропускаются
+	assertSeries(t, "EMA after NaN", EMA([]float64{nan, 1, 2, 3, 4}, 3), []float64{nan, nan, nan, 2, 3})
+}
+
+func TestWMA(t *testing.T) {
+	assertSeries(t, "WMA", WMA([]float64{1, 2, 3, 4}, 3), []float64{nan, nan, 14.0 / 6, 20.0 / 6})
+}
+
+func TestTrueRangeAndATR(t *testing.T) {
+	highs := []float64{10, 12, 11, 15, 14}
+	lows := []float64{8, 9, 9, 12, 11}
+	closes := []float64{9, 11, 10, 14, 12}
+
+	// TR: 2, max(3, 3, 0)=3, max(2, 0, 2)=2, max(3, 5, 2)=5, max(3, 0, 3)=3
+	assertSeries(t, "TR", TrueRange(highs, lows, closes), []float64{2, 3, 2, 5, 3})
+
+	// ATR 2: на индексе 2 - среднее TR[1..2] = 2.5, далее (2.5*1+5)/2 = 3.75, (3.75+3)/2 = 3.375
+	assertSeries(t, "ATR", ATR(highs, lows, closes, 2), []float64{nan, nan, 2.5, 3.75, 3.375})
+}
+
+func TestRSIReference(t *testing.T) {
+	want := make([]float64, len(wilderCloses))
+	for i := range want {
+		want[i] = nan
+	}
+	copy(want[14:], []float64{70.53, 66.32, 66.55, 69.41, 66.36, 57.97})
+
+	assertSeries(t, "RSI", RSI(wilderCloses, 14), want)
+}
+
+func TestRSIOnlyGains(t *testing.T) {
+	rsi := RSI([]float64{1, 2, 3, 4}, 2)
+	assertSeries(t, "RSI", rsi, []float64{nan, nan, 100, 100})
+}
+
+func TestMACD(t *testing.T) {
+	closes := make([]float64, 40)
+	for i := range closes {
+		closes[i] = 100 + float64(i%7) + float64(i)/3
+	}
+
+	macd, signal, hist := MACD(closes, 12, 26, 9)
+	fast, slow := EMA(closes, 12), EMA(closes, 26)
+	signalRef := EMA(macd, 9)
+
+	for i := range closes {
+		if i < 25 {
+			if !math.IsNaN(macd[i]) {
+				t.Errorf("MACD[%d] = %.4f, want NaN", i, macd[i])
+			}
+			continue
+		}
+		if math.Abs(macd[i]-(fast[i]-slow[i])) > 1e-9 {
+			t.Errorf("MACD[%d] = %.4f, want %.4f", i, macd[i], fast[i]-slow[i])
+		}
+		if i < 33 {
+			if !math.IsNaN(signal[i]) || !math.IsNaN(hist[i]) {
+				t.Errorf("signal[%d] should be NaN during warm-up", i)
+			}
+			continue
+		}
+		if math.Abs(signal[i]-signalRef[i]) > 1e-9 || math.Abs(hist[i]-(macd[i]-signal[i])) > 1e-9 {
+			t.Errorf("signal/hist[%d] = %.4f/%.4f", i, signal[i], hist[i])
+		}
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	// Среднее 5, стандартное отклонение 2
+	closes := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	upper, middle, lower := Bollinger(closes, 8, 2)
+
+	if v, ok := Last(middle); !ok || v != 5 {
+		t.Errorf("middle = %v, want 5", v)
+	}
+	if v, _ := Last(upper); math.Abs(v-9) > tolerance {
+		t.Errorf("upper = %v, want 9", v)
+	}
+	if v, _ := Last(lower); math.Abs(v-1) > tolerance {
+		t.Errorf("lower = %v, want 1", v)
+	}
+	if !math.IsNaN(upper[6]) {
+		t.Error("upper should be NaN during warm-up")
+	}
+}
+
+func TestDonchian(t *testing.T) {
+	highs := []float64{5, 7, 6, 9, 8}
+	lows := []float64{3, 4, 2, 5, 6}
+
+	upper, middle, lower := Donchian(highs, lows, 3)
+	assertSeries(t, "upper", upper, []float64{nan, nan, 7, 9, 9})
+	assertSeries(t, "lower", lower, []float64{nan, nan, 2, 2, 2})
+	assertSeries(t, "middle", middle, []float64{nan, nan, 4.5, 5.5, 5.5})
+}
+
+func TestADXTrend(t *testing.T) {
+	const n, period = 60, 14
+	highs, lows, closes := make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		closes[i] = 100 + float64(i)
+		highs[i] = closes[i] + 1
+		lows[i] = closes[i] - 1
+	}
+
+	adx, plusDI, minusDI := ADX(highs, lows, closes, period)
+
+	if !math.IsNaN(plusDI[period-1]) || math.IsNaN(plusDI[period]) {
+		t.Errorf("+DI warm-up: [%d]=%v [%d]=%v", period-1, plusDI[period-1], period, plusDI[period])
+	}
+	if !math.IsNaN(adx[2*period-2]) || math.IsNaN(adx[2*period-1]) {
+		t.Errorf("ADX warm-up: [%d]=%v [%d]=%v", 2*period-2, adx[2*period-2], 2*period-1, adx[2*period-1])
+	}
+
+	last, _ := Last(adx)
+	if last < 90 || last > 100 {
+		t.Errorf("ADX = %.2f in steady uptrend, want near 100", last)
+	}
+	if p, m := plusDI[n-1], minusDI[n-1]; p <= m || m != 0 {
+		t.Errorf("+DI/-DI = %.2f/%.2f, want +DI dominant and -DI zero", p, m)
+	}
+}
+
+func TestStochastic(t *testing.T) {
+	highs := []float64{10, 12, 14, 13}
+	lows := []float64{8, 9, 10, 9}
+	closes := []float64{9, 11, 14, 10}
+
+	k, d := Stochastic(highs, lows, closes, 3, 2)
+	// K[2]: (14-8)/(14-8) = 100, K[3]: (10-9)/(14-9) = 20
+	assertSeries(t, "K", k, []float64{nan, nan, 100, 20})
+	assertSeries(t, "D", d, []float64{nan, nan, nan, 60})
+
+	flat, _ := Stochastic([]float64{5, 5}, []float64{5, 5}, []float64{5, 5}, 2, 1)
+	assertSeries(t, "K flat", flat, []float64{nan, 50})
+}
+
+func TestOBV(t *testing.T) {
+	closes := []float64{10, 11, 11, 9, 12}
+	volumes := []float64{100, 200, 300, 400, 500}
+	assertSeries(t, "OBV", OBV(closes, volumes), []float64{100, 300, 300, -100, 400})
+}
+
+func TestVWAP(t *testing.T) {
+	highs := []float64{11, 13}
+	lows := []float64{9, 11}
+	closes := []float64{10, 12}
+	volumes := []float64{100, 300}
+
+	// Типичные цены 10 и 12: (10*100 + 12*300) / 400 = 11.5
+	assertSeries(t, "VWAP", VWAP(highs, lows, closes, volumes), []float64{10, 11.5})
+	assertSeries(t, "VWAP no volume", VWAP(highs, lows, closes, []float64{0, 0}), []float64{nan, nan})
+}
+
+func TestLast(t *testing.T) {
+	if _, ok := Last(nil); ok {
+		t.Error("Last(nil) should not be ok")
+	}
+	if _, ok := Last([]float64{1, nan}); ok {
+		t.Error("Last() of NaN should not be ok")
+	}
+	if v, ok := Last([]float64{nan, 3}); !ok || v != 3 {
+		t.Errorf("Last() = %v, %v, want 3", v, ok)
+	}
+}
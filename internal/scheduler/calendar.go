@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar торговый календарь Московской биржи: выходные, праздничные дни и
+// торговые часы. Нулевой Calendar разрешает запуск в любое время.
+type Calendar struct {
+	loc             *time.Location
+	tradingDaysOnly bool
+	holidays        map[string]bool // Даты в формате 2006-01-02
+
+	// Торговые часы в минутах от начала дня; open == close - весь день
+	open, close int
+}
+
+// NewCalendar создает календарь. hours - торговые часы в формате 10:00-23:50
+// (пусто - весь день), holidays - неторговые дни в формате 2006-01-02.
+func NewCalendar(loc *time.Location, tradingDaysOnly bool, hours string, holidays []string) (*Calendar, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	calendar := &Calendar{
+		loc:             loc,
+		tradingDaysOnly: tradingDaysOnly,
+		holidays:        make(map[string]bool, len(holidays)),
+	}
+
+	for _, holiday := range holidays {
+		day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(holiday), loc)
+		if err != nil {
+			return nil, fmt.Errorf("неверная дата праздника %q: ожидается формат 2006-01-02", holiday)
+		}
+		calendar.holidays[day.Format("2006-01-02")] = true
+	}
+
+	if hours = strings.TrimSpace(hours); hours != "" {
+		bounds := strings.Split(hours, "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("неверные торговые часы %q: ожидается формат 10:00-23:50", hours)
+		}
+		open, err := parseClock(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("неверные торговые часы %q: %w", hours, err)
+		}
+		close, err := parseClock(bounds[1])
+		if err != nil {
+			return nil, fmt.Errorf("неверные торговые часы %q: %w", hours, err)
+		}
+		if open >= close {
+			return nil, fmt.Errorf("неверные торговые часы %q: начало должно быть раньше окончания", hours)
+		}
+		calendar.open, calendar.close = open, close
+	}
+
+	return calendar, nil
+}
+
+// parseClock разбирает время HH:MM в минуты от начала дня
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("неверное время %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// IsTradingDay торговый ли день: не суббота, не воскресенье и не праздник
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	t = t.In(c.loc)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays[t.Format("2006-01-02")]
+}
+
+// IsOpen разрешен ли запуск в момент t: торговый день (если включен
+// trading_days_only) и торговые часы (если заданы)
+func (c *Calendar) IsOpen(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	if c.tradingDaysOnly && !c.IsTradingDay(t) {
+		return false
+	}
+	if c.open == c.close {
+		return true
+	}
+
+	t = t.In(c.loc)
+	minute := t.Hour()*60 + t.Minute()
+	return minute >= c.open && minute < c.close
+}
+
+// NextOpen ближайший момент не раньше t, когда запуск разрешен
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	if c.IsOpen(t) {
+		return t
+	}
+
+	t = t.In(c.loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+	// Праздники идут подряд не больше пары недель, год - с большим запасом
+	for i := 0; i <= 366; i++ {
+		start := day.Add(time.Duration(c.open) * time.Minute)
+		if start.Before(t) {
+			start = t
+		}
+		if c.IsOpen(start) {
+			return start
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}
+}
+
+// HasHolidaysIn заданы ли праздники на год year. Список праздников MOEX
+// публикуется на каждый год, без него праздничные дни считаются торговыми.
+func (c *Calendar) HasHolidaysIn(year int) bool {
+	if c == nil {
+		return false
+	}
+	prefix := fmt.Sprintf("%04d-", year)
+	for day := range c.holidays {
+		if strings.HasPrefix(day, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Location часовой пояс календаря
+func (c *Calendar) Location() *time.Location {
+	if c == nil {
+		return time.UTC
+	}
+	return c.loc
+}
+
+// String краткое описание календаря для пользователя
+func (c *Calendar) String() string {
+	if c == nil || (!c.tradingDaysOnly && c.open == c.close) {
+		return "без ограничений"
+	}
+
+	var parts []string
+	if c.tradingDaysOnly {
+		parts = append(parts, fmt.Sprintf("только торговые дни (праздников: %d)", len(c.holidays)))
+	}
+	if c.open != c.close {
+		parts = append(parts, fmt.Sprintf("%02d:%02d-%02d:%02d", c.open/60, c.open%60, c.close/60, c.close%60))
+	}
+	return strings.Join(parts, ", ")
+}
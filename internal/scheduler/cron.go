@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule разобранное cron-выражение из пяти полей: минута, час, день месяца,
+// месяц, день недели. Поддерживаются *, числа, диапазоны a-b, списки через
+// запятую и шаг /n (*/2, 10-18/2). День недели 0-7, 0 и 7 - воскресенье.
+type Schedule struct {
+	spec string
+
+	minute, hour, dom, month, dow uint64 // Битовые маски допустимых значений
+	domAny, dowAny                bool   // Поле задано через * (без ограничения)
+}
+
+// cronField границы поля cron-выражения
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"минута", 0, 59},
+	{"час", 0, 23},
+	{"день месяца", 1, 31},
+	{"месяц", 1, 12},
+	{"день недели", 0, 7},
+}
+
+// maxSearchYears на сколько лет вперед ищется следующий запуск (31 февраля не наступит никогда)
+const maxSearchYears = 5
+
+// Parse разбирает cron-выражение
+func Parse(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron-выражение %q: ожидается 5 полей (минута час день месяц день_недели), получено %d", spec, len(fields))
+	}
+
+	var masks [5]uint64
+	for i, field := range fields {
+		mask, err := parseField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron-выражение %q: %w", spec, err)
+		}
+		masks[i] = mask
+	}
+
+	// 7 - тоже воскресенье
+	if masks[4]&(1<<7) != 0 {
+		masks[4] = masks[4]&^(1<<7) | 1
+	}
+
+	schedule := &Schedule{
+		spec:   strings.Join(fields, " "),
+		minute: masks[0],
+		hour:   masks[1],
+		dom:    masks[2],
+		month:  masks[3],
+		dow:    masks[4],
+		domAny: strings.HasPrefix(fields[2], "*"),
+		dowAny: strings.HasPrefix(fields[4], "*"),
+	}
+
+	// Выражение, которое никогда не сработает, например 0 0 31 2 *
+	if schedule.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron-выражение %q никогда не срабатывает", spec)
+	}
+
+	return schedule, nil
+}
+
+// parseField разбирает одно поле в битовую маску
+func parseField(field string, bounds cronField) (uint64, error) {
+	var mask uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("поле %s: неверный шаг в %q", bounds.name, part)
+			}
+			step = n
+		}
+
+		low, high := bounds.min, bounds.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			ends := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(ends[0]); err != nil {
+				return 0, fmt.Errorf("поле %s: неверный диапазон %q", bounds.name, part)
+			}
+			if high, err = strconv.Atoi(ends[1]); err != nil {
+				return 0, fmt.Errorf("поле %s: неверный диапазон %q", bounds.name, part)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("поле %s: неверное значение %q", bounds.name, part)
+			}
+			low = n
+			// 5/15 - с 5 до конца диапазона с шагом 15
+			if !strings.Contains(part, "/") {
+				high = n
+			}
+		}
+
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, fmt.Errorf("поле %s: %q вне диапазона %d-%d", bounds.name, part, bounds.min, bounds.max)
+		}
+
+		for v := low; v <= high; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+
+	return mask, nil
+}
+
+// String исходное cron-выражение
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Next время первого срабатывания строго после after в часовом поясе after.
+// Нулевое время - срабатываний нет.
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches подходит ли день. Как в классическом cron, если ограничены и день
+// месяца, и день недели, достаточно совпадения одного из них.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
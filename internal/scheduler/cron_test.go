@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// Среда, 10:20
+	after := time.Date(2024, 3, 6, 10, 20, 30, 0, msk)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"0 * * * *", time.Date(2024, 3, 6, 11, 0, 0, 0, msk)},
+		{"0 */2 * * *", time.Date(2024, 3, 6, 12, 0, 0, 0, msk)},
+		{"*/15 * * * *", time.Date(2024, 3, 6, 10, 30, 0, 0, msk)},
+		{"5/15 * * * *", time.Date(2024, 3, 6, 10, 35, 0, 0, msk)},
+		{"30 9-18/3 * * *", time.Date(2024, 3, 6, 12, 30, 0, 0, msk)},
+		{"0 10 * * 1-5", time.Date(2024, 3, 7, 10, 0, 0, 0, msk)},
+		{"0 10 * * 7", time.Date(2024, 3, 10, 10, 0, 0, 0, msk)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, msk)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, msk)},
+		// День месяца или день недели: 15-е число или ближайшее воскресенье
+		{"0 12 15 * 0", time.Date(2024, 3, 10, 12, 0, 0, 0, msk)},
+		{"0,30 10,23 * * *", time.Date(2024, 3, 6, 10, 30, 0, 0, msk)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if next := schedule.Next(after); !next.Equal(tt.next) {
+				t.Errorf("Next() = %v, want %v", next, tt.next)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"0 0 31 2 *",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q): want error", spec)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Logger интерфейс для логирования
+type Logger interface {
+	Info(msg string, fields ...interface{})
+	Warn(msg string, fields ...interface{})
+	Debug(msg string, fields ...interface{})
+}
+
+// StateStore хранит время последнего запуска задач между перезапусками бота
+type StateStore interface {
+	LastRun(job string) (time.Time, bool)
+	SetLastRun(job string, t time.Time) error
+}
+
+// Options настройки планировщика
+type Options struct {
+	Calendar *Calendar // nil - запуск в любое время
+	State    StateStore
+	// CatchUp запускать задачу сразу после старта, если запуск по расписанию был
+	// пропущен, пока бот не работал (или задача еще ни разу не запускалась)
+	CatchUp bool
+}
+
+// job задача планировщика
+type job struct {
+	name     string
+	schedule *Schedule
+	run      func(ctx context.Context)
+
+	running bool
+	lastRun time.Time
+	next    time.Time
+	skipped int // Запусков пропущено из-за того, что предыдущий еще выполнялся
+}
+
+// Entry состояние задачи для вывода пользователю
+type Entry struct {
+	Name     string
+	Spec     string
+	Next     time.Time // Следующий запуск (нулевое - запусков больше нет)
+	LastRun  time.Time
+	Running  bool
+	Skipped  int
+	Upcoming []time.Time // Ближайшие запуски с учетом календаря
+}
+
+// Scheduler запускает задачи по cron-выражениям с учетом торгового календаря.
+// Задача не запускается повторно, пока выполняется предыдущий запуск.
+type Scheduler struct {
+	opts   Options
+	logger Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	order   []string
+	started bool
+	wg      sync.WaitGroup
+}
+
+// New создает планировщик
+func New(opts Options, logger Logger) *Scheduler {
+	return &Scheduler{
+		opts:   opts,
+		logger: logger,
+		now:    time.Now,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// Add добавляет задачу. Задачи добавляются до Start.
+func (s *Scheduler) Add(name, spec string, run func(ctx context.Context)) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("планировщик уже запущен")
+	}
+	if _, exists := s.jobs[name]; exists {
+		return fmt.Errorf("задача %s уже добавлена", name)
+	}
+
+	j := &job{name: name, schedule: schedule, run: run}
+	if s.opts.State != nil {
+		j.lastRun, _ = s.opts.State.LastRun(name)
+	}
+	s.jobs[name] = j
+	s.order = append(s.order, name)
+	return nil
+}
+
+// Start запускает задачи и возвращает управление. Задачи останавливаются при
+// отмене ctx, Wait дожидается завершения выполняющихся запусков.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	jobs := make([]*job, 0, len(s.order))
+	for _, name := range s.order {
+		jobs = append(jobs, s.jobs[name])
+	}
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Wait ожидает остановки всех задач
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// loop цикл одной задачи
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	now := s.now()
+
+	s.mu.Lock()
+	lastRun := j.lastRun
+	s.mu.Unlock()
+
+	// Пропущенный запуск (или первый запуск) выполняется сразу, если сейчас
+	// разрешено календарем
+	if s.opts.CatchUp && s.opts.Calendar.IsOpen(now) {
+		if lastRun.IsZero() {
+			s.logger.Info("Первый запуск задачи", "job", j.name)
+			s.fire(ctx, j, now)
+		} else if missed := s.nextRun(j, lastRun); !missed.IsZero() && missed.Before(now) {
+			s.logger.Info("Запуск пропущенной задачи",
+				"job", j.name,
+				"missed", missed,
+				"last_run", lastRun)
+			s.fire(ctx, j, now)
+		}
+	}
+
+	for {
+		next := s.nextRun(j, now)
+
+		s.mu.Lock()
+		j.next = next
+		s.mu.Unlock()
+
+		if next.IsZero() {
+			s.logger.Warn("У задачи нет запусков по расписанию", "job", j.name)
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.fire(ctx, j, next)
+		now = next
+	}
+}
+
+// fire запускает задачу, если предыдущий запуск уже завершен
+func (s *Scheduler) fire(ctx context.Context, j *job, at time.Time) {
+	s.mu.Lock()
+	if j.running {
+		j.skipped++
+		s.mu.Unlock()
+		s.logger.Warn("Предыдущий запуск задачи еще выполняется, запуск пропущен",
+			"job", j.name,
+			"at", at)
+		return
+	}
+	j.running = true
+	j.lastRun = at
+	s.mu.Unlock()
+
+	if s.opts.State != nil {
+		if err := s.opts.State.SetLastRun(j.name, at); err != nil {
+			s.logger.Warn("Ошибка сохранения времени запуска задачи",
+				"job", j.name,
+				"error", err)
+		}
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			j.running = false
+			s.mu.Unlock()
+		}()
+
+		startTime := s.now()
+		s.logger.Debug("Запуск задачи", "job", j.name)
+		j.run(ctx)
+		s.logger.Debug("Задача завершена",
+			"job", j.name,
+			"duration", s.now().Sub(startTime))
+	}()
+}
+
+// maxCalendarSkips сколько времен по расписанию можно пропустить из-за календаря
+const maxCalendarSkips = 1000
+
+// nextRun следующее время запуска задачи после after с учетом календаря
+func (s *Scheduler) nextRun(j *job, after time.Time) time.Time {
+	calendar := s.opts.Calendar
+	t := after.In(calendar.Location())
+
+	for i := 0; i < maxCalendarSkips; i++ {
+		t = j.schedule.Next(t)
+		if t.IsZero() || calendar.IsOpen(t) {
+			return t
+		}
+		// Переходим к открытию: следующий запуск ищется с этого момента
+		open := calendar.NextOpen(t)
+		if open.IsZero() {
+			return time.Time{}
+		}
+		t = open.Add(-time.Second)
+	}
+
+	return time.Time{}
+}
+
+// upcomingCount сколько ближайших запусков показывает Entries
+const upcomingCount = 3
+
+// Entries состояние задач в порядке добавления
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entries := make([]Entry, 0, len(s.order))
+	for _, name := range s.order {
+		j := s.jobs[name]
+		entry := Entry{
+			Name:    j.name,
+			Spec:    j.schedule.String(),
+			Next:    j.next,
+			LastRun: j.lastRun,
+			Running: j.running,
+			Skipped: j.skipped,
+		}
+
+		t := now
+		for len(entry.Upcoming) < upcomingCount {
+			t = s.nextRun(j, t)
+			if t.IsZero() {
+				break
+			}
+			entry.Upcoming = append(entry.Upcoming, t)
+		}
+		if entry.Next.IsZero() && len(entry.Upcoming) > 0 {
+			entry.Next = entry.Upcoming[0]
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// Calendar торговый календарь планировщика
+func (s *Scheduler) Calendar() *Calendar {
+	return s.opts.Calendar
+}
+
+// CatchUp включен ли запуск пропущенных задач
+func (s *Scheduler) CatchUp() bool {
+	return s.opts.CatchUp
+}
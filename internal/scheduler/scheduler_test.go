@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+var msk = time.FixedZone("MSK", 3*60*60)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Debug(string, ...interface{}) {}
+
+// memoryState хранит время запусков в памяти
+type memoryState struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func (m *memoryState) LastRun(job string) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.runs[job]
+	return t, ok
+}
+
+func (m *memoryState) SetLastRun(job string, t time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[job] = t
+	return nil
+}
+
+func TestCalendar(t *testing.T) {
+	calendar, err := NewCalendar(msk, true, "10:00-18:45", []string{"2024-03-08"})
+	if err != nil {
+		t.Fatalf("NewCalendar() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		open bool
+	}{
+		{"Trading hours", time.Date(2024, 3, 6, 12, 0, 0, 0, msk), true},
+		{"Before open", time.Date(2024, 3, 6, 9, 59, 0, 0, msk), false},
+		{"At close", time.Date(2024, 3, 6, 18, 45, 0, 0, msk), false},
+		{"Holiday", time.Date(2024, 3, 8, 12, 0, 0, 0, msk), false},
+		{"Saturday", time.Date(2024, 3, 9, 12, 0, 0, 0, msk), false},
+	}
+	for _, tt := range tests {
+		if open := calendar.IsOpen(tt.at); open != tt.open {
+			t.Errorf("%s: IsOpen(%v) = %v, want %v", tt.name, tt.at, open, tt.open)
+		}
+	}
+
+	// После закрытия в четверг: пятница - праздник, затем выходные
+	next := calendar.NextOpen(time.Date(2024, 3, 7, 19, 0, 0, 0, msk))
+	if want := time.Date(2024, 3, 11, 10, 0, 0, 0, msk); !next.Equal(want) {
+		t.Errorf("NextOpen() = %v, want %v", next, want)
+	}
+
+	if !calendar.HasHolidaysIn(2024) || calendar.HasHolidaysIn(2025) {
+		t.Error("HasHolidaysIn: праздники заданы только на 2024 год")
+	}
+
+	for _, hours := range []string{"10:00", "18:00-10:00", "25:00-26:00"} {
+		if _, err := NewCalendar(msk, true, hours, nil); err == nil {
+			t.Errorf("NewCalendar(%q): want error", hours)
+		}
+	}
+	if _, err := NewCalendar(msk, true, "", []string{"08.03.2024"}); err == nil {
+		t.Error("NewCalendar() with invalid holiday: want error")
+	}
+}
+
+func TestSchedulerSkipsClosedTimes(t *testing.T) {
+	calendar, _ := NewCalendar(msk, true, "10:00-19:00", nil)
+	s := New(Options{Calendar: calendar}, nopLogger{})
+	if err := s.Add("turtle", "0 */4 * * *", func(context.Context) {}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	// Пятница, 17:30
+	s.now = func() time.Time { return time.Date(2024, 3, 8, 17, 30, 0, 0, msk) }
+
+	entries := s.Entries()
+	want := []time.Time{
+		time.Date(2024, 3, 11, 12, 0, 0, 0, msk),
+		time.Date(2024, 3, 11, 16, 0, 0, 0, msk),
+		time.Date(2024, 3, 12, 12, 0, 0, 0, msk),
+	}
+	if len(entries) != 1 || len(entries[0].Upcoming) != len(want) {
+		t.Fatalf("entries = %+v, want one job with %d runs", entries, len(want))
+	}
+	for i, run := range entries[0].Upcoming {
+		if !run.Equal(want[i]) {
+			t.Errorf("run %d = %v, want %v", i, run, want[i])
+		}
+	}
+	if !entries[0].Next.Equal(want[0]) {
+		t.Errorf("Next = %v, want %v", entries[0].Next, want[0])
+	}
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		catchUp bool
+		lastRun time.Time // Нулевое - задача еще не запускалась
+		runs    bool
+	}{
+		{"First run", true, time.Time{}, true},
+		{"Missed run", true, now.Add(-3 * time.Hour), true},
+		{"Nothing missed", true, now, false},
+		{"Catch up disabled", false, now.Add(-3 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &memoryState{runs: make(map[string]time.Time)}
+			if !tt.lastRun.IsZero() {
+				state.runs["turtle"] = tt.lastRun
+			}
+
+			s := New(Options{State: state, CatchUp: tt.catchUp}, nopLogger{})
+			ran := make(chan struct{}, 1)
+			if err := s.Add("turtle", "0 * * * *", func(context.Context) { ran <- struct{}{} }); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			s.Start(ctx)
+			defer func() {
+				cancel()
+				s.Wait()
+			}()
+
+			select {
+			case <-ran:
+				if !tt.runs {
+					t.Fatal("job ran, want wait for schedule")
+				}
+				if last, ok := state.LastRun("turtle"); !ok || !last.After(now.Add(-time.Second)) {
+					t.Errorf("LastRun = %v, want saved run time", last)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.runs {
+					t.Fatal("job did not run on start")
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerPreventsOverlap(t *testing.T) {
+	s := New(Options{}, nopLogger{})
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	if err := s.Add("turtle", "* * * * *", func(context.Context) {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	j := s.jobs["turtle"]
+	s.fire(context.Background(), j, time.Now())
+	s.fire(context.Background(), j, time.Now())
+
+	entries := s.Entries()
+	if !entries[0].Running || entries[0].Skipped != 1 {
+		t.Errorf("entry = %+v, want running job with one skipped run", entries[0])
+	}
+
+	close(release)
+	s.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 1 {
+		t.Errorf("runs = %d, want 1", runs)
+	}
+	if s.Entries()[0].Running {
+		t.Error("job still running after completion")
+	}
+}
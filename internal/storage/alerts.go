@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"sort"
+	"time"
+)
+
+// Alert алерт пользователя: условие по инструменту, которое проверяется в фоне.
+// Алерт срабатывает на пересечении порога - когда условие, не выполнявшееся
+// при предыдущей проверке, становится выполненным.
+type Alert struct {
+	ID         int64         `json:"id"`
+	UserID     int64         `json:"user_id"`
+	Instrument string        `json:"instrument"`
+	Condition  string        `json:"condition"`          // Условие в каноническом виде: "price > 300", "rsi14 < 30"
+	Recurring  bool          `json:"recurring"`          // Повторяющийся алерт (иначе удаляется после срабатывания)
+	Cooldown   time.Duration `json:"cooldown,omitempty"` // Пауза между срабатываниями повторяющегося алерта
+	CreatedAt  time.Time     `json:"created_at"`
+	Met        *bool         `json:"met,omitempty"` // Выполнялось ли условие при последней проверке (nil - еще не проверялось)
+
+	LastTriggered time.Time `json:"last_triggered,omitempty"`
+	LastValue     float64   `json:"last_value,omitempty"` // Значение показателя при последнем срабатывании
+	Triggers      int       `json:"triggers,omitempty"`   // Сколько раз сработал
+}
+
+// Ready может ли алерт сработать в момент now: повторяющийся - не раньше, чем
+// через Cooldown после предыдущего срабатывания. Пересечения во время паузы
+// не уведомляются.
+func (a Alert) Ready(now time.Time) bool {
+	return a.LastTriggered.IsZero() || !now.Before(a.LastTriggered.Add(a.Cooldown))
+}
+
+// AddAlert сохраняет новый алерт и возвращает его с присвоенным ID
+func (s *Store) AddAlert(alert Alert) (Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.NextAlertID++
+	alert.ID = s.data.NextAlertID
+	s.data.Alerts = append(s.data.Alerts, alert)
+
+	return alert, s.save()
+}
+
+// Alerts алерты пользователя в порядке создания (userID 0 - алерты всех пользователей)
+func (s *Store) Alerts(userID int64) []Alert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var alerts []Alert
+	for _, alert := range s.data.Alerts {
+		if userID == 0 || alert.UserID == userID {
+			alerts = append(alerts, alert)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+	return alerts
+}
+
+// DeleteAlerts удаляет алерты пользователя с указанными ID (без ID - все алерты
+// пользователя) и возвращает число удаленных
+func (s *Store) DeleteAlerts(userID int64, ids ...int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.data.Alerts[:0]
+	deleted := 0
+	for _, alert := range s.data.Alerts {
+		if alert.UserID == userID && (len(ids) == 0 || containsID(ids, alert.ID)) {
+			deleted++
+			continue
+		}
+		kept = append(kept, alert)
+	}
+	s.data.Alerts = kept
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	return deleted, s.save()
+}
+
+// Crossed пересек ли показатель порог: условие выполнено сейчас и не
+// выполнялось при предыдущей проверке
+func (a Alert) Crossed(met bool) bool {
+	return met && a.Met != nil && !*a.Met
+}
+
+// ObserveAlert запоминает, выполнялось ли условие алерта при проверке.
+// Хранилище сохраняется только при изменении состояния.
+func (s *Store) ObserveAlert(id int64, met bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, alert := range s.data.Alerts {
+		if alert.ID != id {
+			continue
+		}
+		if alert.Met != nil && *alert.Met == met {
+			return nil
+		}
+		s.data.Alerts[i].Met = &met
+		return s.save()
+	}
+
+	return nil
+}
+
+// TriggerAlert отмечает срабатывание алерта: разовый удаляется, у повторяющегося
+// запоминается время и значение показателя, условие считается выполненным
+func (s *Store) TriggerAlert(id int64, value float64, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, alert := range s.data.Alerts {
+		if alert.ID != id {
+			continue
+		}
+		if !alert.Recurring {
+			s.data.Alerts = append(s.data.Alerts[:i], s.data.Alerts[i+1:]...)
+		} else {
+			s.data.Alerts[i].LastTriggered = now
+			s.data.Alerts[i].LastValue = value
+			s.data.Alerts[i].Triggers++
+			met := true
+			s.data.Alerts[i].Met = &met
+		}
+		return s.save()
+	}
+
+	return nil
+}
+
+// containsID есть ли ID в списке
+func containsID(ids []int64, id int64) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/storage"
+)
+
+func TestStoreAlerts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	once, err := store.AddAlert(storage.Alert{UserID: 42, Instrument: "SBER", Condition: "price > 300", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("AddAlert() error = %v", err)
+	}
+	repeat, err := store.AddAlert(storage.Alert{UserID: 42, Instrument: "GAZP", Condition: "rsi14 < 30", Recurring: true, Cooldown: time.Hour, CreatedAt: now})
+	if err != nil {
+		t.Fatalf("AddAlert() error = %v", err)
+	}
+	if _, err := store.AddAlert(storage.Alert{UserID: 7, Instrument: "LKOH", Condition: "change1d > 5%", CreatedAt: now}); err != nil {
+		t.Fatalf("AddAlert() error = %v", err)
+	}
+	if once.ID == 0 || repeat.ID == once.ID {
+		t.Fatalf("alert IDs = %d, %d, want unique non-zero", once.ID, repeat.ID)
+	}
+
+	// Алерты сохраняются после перезапуска
+	store, err = storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if alerts := store.Alerts(42); len(alerts) != 2 || alerts[0].Condition != "price > 300" {
+		t.Fatalf("Alerts(42) = %+v, want 2 alerts", alerts)
+	}
+	if alerts := store.Alerts(0); len(alerts) != 3 {
+		t.Errorf("Alerts(0) = %d alerts, want 3", len(alerts))
+	}
+
+	// Разовый алерт удаляется после срабатывания
+	if err := store.TriggerAlert(once.ID, 301, now); err != nil {
+		t.Fatalf("TriggerAlert() error = %v", err)
+	}
+	// Повторяющийся срабатывает снова только после паузы
+	if err := store.TriggerAlert(repeat.ID, 28, now); err != nil {
+		t.Fatalf("TriggerAlert() error = %v", err)
+	}
+	alerts := store.Alerts(42)
+	if len(alerts) != 1 || alerts[0].ID != repeat.ID {
+		t.Fatalf("Alerts(42) = %+v, want only recurring alert", alerts)
+	}
+	if alerts[0].Triggers != 1 || alerts[0].LastValue != 28 {
+		t.Errorf("recurring alert = %+v, want 1 trigger with value 28", alerts[0])
+	}
+	if alerts[0].Ready(now.Add(30 * time.Minute)) {
+		t.Error("Ready() = true during cooldown")
+	}
+	if !alerts[0].Ready(now.Add(time.Hour)) {
+		t.Error("Ready() = false after cooldown")
+	}
+
+	// Срабатывание - только переход условия из невыполненного в выполненное
+	if alerts[0].Met == nil || !*alerts[0].Met || alerts[0].Crossed(true) {
+		t.Errorf("recurring alert after trigger: met = %v, want met without crossing", alerts[0].Met)
+	}
+	if err := store.ObserveAlert(repeat.ID, false); err != nil {
+		t.Fatalf("ObserveAlert() error = %v", err)
+	}
+	alerts = store.Alerts(42)
+	if !alerts[0].Crossed(true) || alerts[0].Crossed(false) {
+		t.Error("Crossed() after condition reset, want crossing only when met")
+	}
+	if (storage.Alert{}).Crossed(true) {
+		t.Error("Crossed() without previous check = true, want false")
+	}
+
+	// Удалять можно только свои алерты
+	if deleted, err := store.DeleteAlerts(7, repeat.ID); err != nil || deleted != 0 {
+		t.Errorf("DeleteAlerts(other user) = %d, %v, want 0", deleted, err)
+	}
+	if deleted, err := store.DeleteAlerts(42); err != nil || deleted != 1 {
+		t.Errorf("DeleteAlerts(42) = %d, %v, want 1", deleted, err)
+	}
+	if alerts := store.Alerts(0); len(alerts) != 1 || alerts[0].UserID != 7 {
+		t.Errorf("Alerts(0) = %+v, want only alert of user 7", alerts)
+	}
+}
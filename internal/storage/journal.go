@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SignalStatus состояние сигнала в журнале
+type SignalStatus string
+
+// Состояния сигнала: new - найден, уведомление еще не отправлено; active -
+// уведомление отправлено, сигнал повторяется при сканировании; exited - получен
+// сигнал выхода или противоположный вход; expired - сигнал перестал повторяться.
+const (
+	SignalNew     SignalStatus = "new"
+	SignalActive  SignalStatus = "active"
+	SignalExited  SignalStatus = "exited"
+	SignalExpired SignalStatus = "expired"
+)
+
+// journalLimit сколько записей журнала хранится; старые закрытые записи с
+// определенным исходом удаляются первыми
+const journalLimit = 1000
+
+// SignalRecord запись журнала сигналов на вход
+type SignalRecord struct {
+	ID         int64        `json:"id"`
+	Strategy   string       `json:"strategy"`
+	Instrument string       `json:"instrument"`
+	SignalType string       `json:"signal_type"` // entry_long или entry_short
+	BarTime    time.Time    `json:"bar_time"`    // Свеча, на которой появился сигнал
+	Price      float64      `json:"price"`
+	StopLoss   float64      `json:"stop_loss,omitempty"`
+	TakeProfit float64      `json:"take_profit,omitempty"`
+	Confidence float64      `json:"confidence"`
+	Status     SignalStatus `json:"status"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastSeen   time.Time    `json:"last_seen"` // Последнее сканирование, в котором сигнал повторился
+	ClosedAt   time.Time    `json:"closed_at,omitempty"`
+	ExitPrice  float64      `json:"exit_price,omitempty"`
+	ExitTime   time.Time    `json:"exit_time,omitempty"` // Свеча выхода
+
+	Outcome   string  `json:"outcome,omitempty"`    // Исход сделки (пусто - еще отслеживается)
+	RMultiple float64 `json:"r_multiple,omitempty"` // Результат в единицах начального риска
+}
+
+// Open открыт ли сигнал (new или active)
+func (r SignalRecord) Open() bool {
+	return r.Status == SignalNew || r.Status == SignalActive
+}
+
+// Key ключ сигнала: стратегия, инструмент, тип и свеча
+func (r SignalRecord) Key() string {
+	return r.Strategy + "|" + r.Instrument + "|" + r.SignalType + "|" + r.BarTime.UTC().Format(time.RFC3339)
+}
+
+// ObserveSignals обновляет журнал по сигналам одного сканирования стратегии и
+// возвращает сигналы на вход, о которых еще не отправлено уведомление.
+//
+// Сигнал на вход, повторяющийся в следующих сканированиях (в том числе на новых
+// свечах, пока цена остается за уровнем пробоя), не создает новую запись - у
+// открытой записи обновляется LastSeen. Сигнал выхода (exit_long/exit_short) или
+// противоположный вход закрывает открытую запись, а запись, которая не
+// повторялась дольше ttl, истекает.
+func (s *Store) ObserveSignals(strategy string, observed []SignalRecord, now time.Time, ttl time.Duration) ([]SignalRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := make(map[string]bool, len(s.data.Signals))
+	for _, record := range s.data.Signals {
+		known[record.Key()] = true
+	}
+
+	var pending []int
+	for _, signal := range observed {
+		signal.Strategy = strategy
+
+		if closes := closedEntryType(signal.SignalType); closes != "" {
+			s.closeSignals(strategy, signal.Instrument, closes, signal.Price, signal.BarTime, now)
+		}
+		if signal.SignalType != "entry_long" && signal.SignalType != "entry_short" {
+			continue
+		}
+
+		if idx := s.openSignal(strategy, signal.Instrument, signal.SignalType); idx >= 0 {
+			record := &s.data.Signals[idx]
+			record.LastSeen = now
+			if record.Status == SignalNew {
+				record.Price, record.StopLoss, record.TakeProfit = signal.Price, signal.StopLoss, signal.TakeProfit
+				record.Confidence = signal.Confidence
+				pending = appendIndex(pending, idx)
+			}
+			continue
+		}
+
+		// Закрытый на этой же свече сигнал не открывается повторно
+		if known[signal.Key()] {
+			continue
+		}
+
+		s.data.NextSignalID++
+		signal.ID = s.data.NextSignalID
+		signal.Status = SignalNew
+		signal.CreatedAt = now
+		signal.LastSeen = now
+		s.data.Signals = append(s.data.Signals, signal)
+		known[signal.Key()] = true
+		pending = appendIndex(pending, len(s.data.Signals)-1)
+	}
+
+	// Истекают открытые сигналы стратегии, которые давно не повторялись
+	for i := range s.data.Signals {
+		record := &s.data.Signals[i]
+		if record.Strategy == strategy && record.Open() && ttl > 0 && now.Sub(record.LastSeen) > ttl {
+			record.Status = SignalExpired
+			record.ClosedAt = now
+		}
+	}
+
+	result := make([]SignalRecord, 0, len(pending))
+	for _, idx := range pending {
+		if s.data.Signals[idx].Status == SignalNew {
+			result = append(result, s.data.Signals[idx])
+		}
+	}
+
+	s.trimJournal()
+
+	return result, s.save()
+}
+
+// MarkSignalsActive переводит сигналы в состояние active после отправки уведомления
+func (s *Store) MarkSignalsActive(ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marked := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		marked[id] = true
+	}
+	for i := range s.data.Signals {
+		if marked[s.data.Signals[i].ID] && s.data.Signals[i].Status == SignalNew {
+			s.data.Signals[i].Status = SignalActive
+		}
+	}
+
+	return s.save()
+}
+
+// SignalHistory возвращает последние limit записей журнала, новые первыми.
+// Пустые strategy и instrument не ограничивают выборку.
+func (s *Store) SignalHistory(strategy, instrument string, limit int) []SignalRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var history []SignalRecord
+	for i := len(s.data.Signals) - 1; i >= 0 && (limit <= 0 || len(history) < limit); i-- {
+		record := s.data.Signals[i]
+		if (strategy == "" || record.Strategy == strategy) && (instrument == "" || record.Instrument == instrument) {
+			history = append(history, record)
+		}
+	}
+	return history
+}
+
+// openSignal индекс открытой записи стратегии по инструменту и типу (-1, если ее нет).
+// Вызывается под s.mu.
+func (s *Store) openSignal(strategy, instrument, signalType string) int {
+	for i, record := range s.data.Signals {
+		if record.Open() && record.Strategy == strategy && record.Instrument == instrument && record.SignalType == signalType {
+			return i
+		}
+	}
+	return -1
+}
+
+// closeSignals закрывает открытые записи стратегии по инструменту и типу.
+// Вызывается под s.mu.
+func (s *Store) closeSignals(strategy, instrument, signalType string, price float64, exitTime, now time.Time) {
+	for i := range s.data.Signals {
+		record := &s.data.Signals[i]
+		if record.Open() && record.Strategy == strategy && record.Instrument == instrument && record.SignalType == signalType {
+			record.Status = SignalExited
+			record.ClosedAt = now
+			record.ExitPrice = price
+			record.ExitTime = exitTime
+		}
+	}
+}
+
+// TrackedSignals возвращает записи стратегии, исход которых еще не определен
+func (s *Store) TrackedSignals(strategy string) []SignalRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tracked []SignalRecord
+	for _, record := range s.data.Signals {
+		if record.Strategy == strategy && record.Outcome == "" {
+			tracked = append(tracked, record)
+		}
+	}
+	return tracked
+}
+
+// SetOutcome записывает исход сигнала. Открытый сигнал при этом закрывается.
+func (s *Store) SetOutcome(id int64, outcome string, exitPrice float64, exitTime time.Time, rMultiple float64, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.data.Signals {
+		record := &s.data.Signals[i]
+		if record.ID != id {
+			continue
+		}
+		if record.Open() {
+			record.Status = SignalExited
+			record.ClosedAt = now
+		}
+		record.Outcome = outcome
+		record.ExitPrice = exitPrice
+		record.ExitTime = exitTime
+		record.RMultiple = rMultiple
+		return s.save()
+	}
+
+	return fmt.Errorf("сигнал %d не найден в журнале", id)
+}
+
+// OutcomeStats статистика исходов сигналов стратегии
+type OutcomeStats struct {
+	Signals  int            // Всего сигналов в журнале
+	Tracked  int            // Исход еще не определен
+	Closed   int            // Исход определен
+	Outcomes map[string]int // Число сигналов по исходам
+	Wins     int            // Закрыты с R > 0
+	TotalR   float64        // Сумма R
+	BestR    float64
+	WorstR   float64
+}
+
+// WinRate доля прибыльных среди закрытых сигналов, %
+func (st OutcomeStats) WinRate() float64 {
+	if st.Closed == 0 {
+		return 0
+	}
+	return float64(st.Wins) / float64(st.Closed) * 100
+}
+
+// AvgR средний результат закрытого сигнала в R (математическое ожидание)
+func (st OutcomeStats) AvgR() float64 {
+	if st.Closed == 0 {
+		return 0
+	}
+	return st.TotalR / float64(st.Closed)
+}
+
+// SignalOutcomeStats считает статистику исходов сигналов стратегии
+func (s *Store) SignalOutcomeStats(strategy string) OutcomeStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := OutcomeStats{Outcomes: make(map[string]int)}
+	for _, record := range s.data.Signals {
+		if record.Strategy != strategy {
+			continue
+		}
+		stats.Signals++
+		if record.Outcome == "" {
+			stats.Tracked++
+			continue
+		}
+
+		if stats.Closed == 0 || record.RMultiple > stats.BestR {
+			stats.BestR = record.RMultiple
+		}
+		if stats.Closed == 0 || record.RMultiple < stats.WorstR {
+			stats.WorstR = record.RMultiple
+		}
+		stats.Closed++
+		stats.Outcomes[record.Outcome]++
+		stats.TotalR += record.RMultiple
+		if record.RMultiple > 0 {
+			stats.Wins++
+		}
+	}
+	return stats
+}
+
+// trimJournal удаляет самые старые закрытые записи сверх journalLimit.
+// Вызывается под s.mu.
+func (s *Store) trimJournal() {
+	excess := len(s.data.Signals) - journalLimit
+	if excess <= 0 {
+		return
+	}
+
+	kept := s.data.Signals[:0]
+	for _, record := range s.data.Signals {
+		if excess > 0 && !record.Open() && record.Outcome != "" {
+			excess--
+			continue
+		}
+		kept = append(kept, record)
+	}
+	s.data.Signals = kept
+}
+
+// closedEntryType тип входа, который закрывает сигнал: выход из позиции или
+// противоположный вход
+func closedEntryType(signalType string) string {
+	switch signalType {
+	case "exit_long", "entry_short":
+		return "entry_long"
+	case "exit_short", "entry_long":
+		return "entry_short"
+	default:
+		return ""
+	}
+}
+
+// appendIndex добавляет индекс, если его еще нет
+func appendIndex(indexes []int, idx int) []int {
+	for _, existing := range indexes {
+		if existing == idx {
+			return indexes
+		}
+	}
+	indexes = append(indexes, idx)
+	sort.Ints(indexes)
+	return indexes
+}
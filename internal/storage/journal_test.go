@@ -0,0 +1,150 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/storage"
+)
+
+func entry(instrument, signalType string, bar time.Time) storage.SignalRecord {
+	return storage.SignalRecord{Instrument: instrument, SignalType: signalType, BarTime: bar, Price: 100}
+}
+
+func TestJournalDeduplicatesSignals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	store, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	bar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	ttl := 72 * time.Hour
+
+	pending, err := store.ObserveSignals("turtle", []storage.SignalRecord{entry("SBER", "entry_long", bar)}, now, ttl)
+	if err != nil {
+		t.Fatalf("ObserveSignals() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Status != storage.SignalNew {
+		t.Fatalf("pending = %+v, want one new signal", pending)
+	}
+	if err := store.MarkSignalsActive([]int64{pending[0].ID}); err != nil {
+		t.Fatalf("MarkSignalsActive() error = %v", err)
+	}
+
+	// Тот же сигнал через час и на следующей свече не требует уведомления
+	for i, repeat := range []time.Time{bar, bar.AddDate(0, 0, 1)} {
+		pending, err = store.ObserveSignals("turtle", []storage.SignalRecord{entry("SBER", "entry_long", repeat)}, now.Add(time.Duration(i+1)*time.Hour), ttl)
+		if err != nil {
+			t.Fatalf("ObserveSignals() error = %v", err)
+		}
+		if len(pending) != 0 {
+			t.Fatalf("repeat %d: pending = %+v, want none", i, pending)
+		}
+	}
+
+	// Тот же тикер в другой стратегии - отдельный сигнал
+	pending, _ = store.ObserveSignals("ma_crossover", []storage.SignalRecord{entry("SBER", "entry_long", bar)}, now, ttl)
+	if len(pending) != 1 {
+		t.Fatalf("other strategy: pending = %+v, want one", pending)
+	}
+
+	// Выход закрывает сигнал, журнал переживает перезапуск
+	if _, err := store.ObserveSignals("turtle", []storage.SignalRecord{entry("SBER", "exit_long", bar.AddDate(0, 0, 5))}, now.Add(5*24*time.Hour), ttl); err != nil {
+		t.Fatalf("ObserveSignals() error = %v", err)
+	}
+
+	reopened, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	history := reopened.SignalHistory("turtle", "SBER", 10)
+	if len(history) != 1 || history[0].Status != storage.SignalExited {
+		t.Fatalf("history = %+v, want one exited signal", history)
+	}
+
+	// После выхода новый пробой - новый сигнал
+	pending, _ = reopened.ObserveSignals("turtle", []storage.SignalRecord{entry("SBER", "entry_long", bar.AddDate(0, 0, 10))}, now.Add(10*24*time.Hour), ttl)
+	if len(pending) != 1 {
+		t.Fatalf("after exit: pending = %+v, want one", pending)
+	}
+}
+
+func TestJournalExpiresSignals(t *testing.T) {
+	store, err := storage.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	bar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	store.ObserveSignals("macd", []storage.SignalRecord{entry("GAZP", "entry_short", bar)}, now, 24*time.Hour)
+
+	// Сигнал не повторялся дольше ttl
+	store.ObserveSignals("macd", nil, now.Add(25*time.Hour), 24*time.Hour)
+
+	history := store.SignalHistory("", "", 0)
+	if len(history) != 1 || history[0].Status != storage.SignalExpired || history[0].ClosedAt.IsZero() {
+		t.Fatalf("history = %+v, want expired signal", history)
+	}
+
+	// Сигнал, не получивший уведомления, остается в ожидании до истечения
+	pending, _ := store.ObserveSignals("macd", []storage.SignalRecord{entry("LKOH", "entry_long", bar)}, now, 24*time.Hour)
+	again, _ := store.ObserveSignals("macd", []storage.SignalRecord{entry("LKOH", "entry_long", bar)}, now.Add(time.Hour), 24*time.Hour)
+	if len(pending) != 1 || len(again) != 1 || again[0].ID != pending[0].ID {
+		t.Fatalf("pending = %+v, again = %+v, want same unnotified signal", pending, again)
+	}
+}
+
+func TestJournalOutcomeStats(t *testing.T) {
+	store, err := storage.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	bar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	signals := []storage.SignalRecord{
+		entry("SBER", "entry_long", bar),
+		entry("GAZP", "entry_long", bar),
+		entry("LKOH", "entry_short", bar),
+	}
+	pending, err := store.ObserveSignals("turtle", signals, now, 72*time.Hour)
+	if err != nil || len(pending) != 3 {
+		t.Fatalf("ObserveSignals() = %+v, %v, want three signals", pending, err)
+	}
+
+	if err := store.SetOutcome(pending[0].ID, "take_profit", 110, bar.AddDate(0, 0, 3), 2, now); err != nil {
+		t.Fatalf("SetOutcome() error = %v", err)
+	}
+	if err := store.SetOutcome(pending[1].ID, "stop_loss", 95, bar.AddDate(0, 0, 2), -1, now); err != nil {
+		t.Fatalf("SetOutcome() error = %v", err)
+	}
+	if err := store.SetOutcome(999, "timeout", 100, bar, 0, now); err == nil {
+		t.Error("SetOutcome() for unknown signal: want error")
+	}
+
+	// Сигнал с исходом закрывается и больше не отслеживается
+	tracked := store.TrackedSignals("turtle")
+	if len(tracked) != 1 || tracked[0].Instrument != "LKOH" {
+		t.Fatalf("tracked = %+v, want only LKOH", tracked)
+	}
+	if history := store.SignalHistory("turtle", "SBER", 1); history[0].Status != storage.SignalExited || history[0].Outcome != "take_profit" {
+		t.Errorf("history = %+v, want exited with take_profit", history)
+	}
+
+	stats := store.SignalOutcomeStats("turtle")
+	if stats.Signals != 3 || stats.Tracked != 1 || stats.Closed != 2 || stats.Wins != 1 {
+		t.Fatalf("stats = %+v, want 3 signals, 1 tracked, 2 closed, 1 win", stats)
+	}
+	if stats.WinRate() != 50 || stats.AvgR() != 0.5 || stats.BestR != 2 || stats.WorstR != -1 {
+		t.Errorf("stats = %+v, win rate %.1f, avg %.2fR", stats, stats.WinRate(), stats.AvgR())
+	}
+	if other := store.SignalOutcomeStats("macd"); other.Signals != 0 {
+		t.Errorf("macd stats = %+v, want empty", other)
+	}
+}
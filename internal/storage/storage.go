@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// UserSettings пользовательские настройки бота
+type UserSettings struct {
+	Equity             float64 `json:"equity,omitempty"`               // Размер счета, ₽ (0 - из конфигурации)
+	MaxPositionPercent float64 `json:"max_position_percent,omitempty"` // Макс. доля счета в позиции, % (0 - из конфигурации)
+
+	WatchlistScans  bool `json:"watchlist_scans,omitempty"`  // Сканировать только список наблюдения
+	WatchlistAlerts bool `json:"watchlist_alerts,omitempty"` // Уведомления фонового анализа по списку наблюдения
+}
+
+// storeData содержимое файла хранилища
+type storeData struct {
+	Users        map[int64]UserSettings `json:"users"`
+	Watchlists   map[int64][]string     `json:"watchlists,omitempty"` // Списки наблюдения пользователей
+	Signals      []SignalRecord         `json:"signals,omitempty"`    // Журнал сигналов
+	NextSignalID int64                  `json:"next_signal_id,omitempty"`
+	Alerts       []Alert                `json:"alerts,omitempty"` // Алерты пользователей
+	NextAlertID  int64                  `json:"next_alert_id,omitempty"`
+	Schedule     map[string]time.Time   `json:"schedule,omitempty"` // Последний запуск задач планировщика
+}
+
+// Store хранилище пользовательских данных и журнала сигналов в JSON файле. Файл перезаписывается
+// атомарно при каждом изменении. С пустым путем данные хранятся только в памяти.
+type Store struct {
+	path string
+
+	mu   sync.RWMutex
+	data storeData
+}
+
+// NewStore открывает хранилище в файле path, создавая директорию при необходимости
+func NewStore(path string) (*Store, error) {
+	store := &Store{
+		path: path,
+		data: storeData{Users: make(map[int64]UserSettings)},
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("ошибка создания директории хранилища: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения хранилища: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.data); err != nil {
+		return nil, fmt.Errorf("ошибка разбора хранилища %s: %w", path, err)
+	}
+	if store.data.Users == nil {
+		store.data.Users = make(map[int64]UserSettings)
+	}
+
+	return store, nil
+}
+
+// User возвращает настройки пользователя (нулевые, если их нет)
+func (s *Store) User(userID int64) UserSettings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.Users[userID]
+}
+
+// UpdateUser изменяет настройки пользователя и сохраняет хранилище.
+// Если сохранить не удалось, изменение отменяется и в памяти.
+func (s *Store) UpdateUser(userID int64, update func(settings *UserSettings)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.data.Users[userID]
+	settings := previous
+	update(&settings)
+	if settings == (UserSettings{}) {
+		delete(s.data.Users, userID)
+	} else {
+		s.data.Users[userID] = settings
+	}
+
+	if err := s.save(); err != nil {
+		if existed {
+			s.data.Users[userID] = previous
+		} else {
+			delete(s.data.Users, userID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// LastRun время последнего запуска задачи планировщика
+func (s *Store) LastRun(job string) (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.data.Schedule[job]
+	return t, ok
+}
+
+// SetLastRun сохраняет время последнего запуска задачи планировщика
+func (s *Store) SetLastRun(job string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data.Schedule == nil {
+		s.data.Schedule = make(map[string]time.Time)
+	}
+	s.data.Schedule[job] = t
+
+	return s.save()
+}
+
+// save атомарно записывает хранилище на диск. Вызывается под s.mu.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации хранилища: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".storage-*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка записи хранилища: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи хранилища: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка записи хранилища: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("ошибка записи хранилища: %w", err)
+	}
+
+	return nil
+}
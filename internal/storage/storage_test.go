@@ -0,0 +1,133 @@
+package storage_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/storage"
+)
+
+func TestStorePersistsUserSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "storage.json")
+
+	store, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if settings := store.User(42); settings != (storage.UserSettings{}) {
+		t.Fatalf("User() = %+v, want empty settings", settings)
+	}
+
+	if err := store.UpdateUser(42, func(settings *storage.UserSettings) {
+		settings.Equity = 500000
+		settings.MaxPositionPercent = 20
+	}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+
+	// Настройки сохраняются после перезапуска
+	reopened, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if settings := reopened.User(42); settings.Equity != 500000 || settings.MaxPositionPercent != 20 {
+		t.Errorf("User() = %+v, want equity 500000 and 20%%", settings)
+	}
+
+	// Сброс настроек удаляет пользователя
+	if err := reopened.UpdateUser(42, func(settings *storage.UserSettings) {
+		*settings = storage.UserSettings{}
+	}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if settings := reopened.User(42); settings != (storage.UserSettings{}) {
+		t.Errorf("User() = %+v after reset, want empty", settings)
+	}
+}
+
+func TestStoreUpdateUserRollback(t *testing.T) {
+	store, err := storage.NewStore(filepath.Join(t.TempDir(), "storage.json"))
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.UpdateUser(42, func(settings *storage.UserSettings) {
+		settings.Equity = 500000
+	}); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+
+	// Значение, которое нельзя сохранить, не остается в памяти
+	if err := store.UpdateUser(42, func(settings *storage.UserSettings) {
+		settings.Equity = math.NaN()
+	}); err == nil {
+		t.Fatal("UpdateUser(NaN) error = nil, want serialization error")
+	}
+	if settings := store.User(42); settings.Equity != 500000 {
+		t.Errorf("User() = %+v after failed update, want equity 500000", settings)
+	}
+	if err := store.UpdateUser(7, func(settings *storage.UserSettings) {
+		settings.MaxPositionPercent = math.Inf(1)
+	}); err == nil {
+		t.Fatal("UpdateUser(Inf) error = nil, want serialization error")
+	}
+	if settings := store.User(7); settings != (storage.UserSettings{}) {
+		t.Errorf("User() = %+v after failed update, want empty", settings)
+	}
+
+	// Последующие изменения сохраняются
+	if err := store.SetLastRun("turtle", time.Now()); err != nil {
+		t.Errorf("SetLastRun() error = %v after failed update", err)
+	}
+}
+
+func TestStorePersistsLastRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, ok := store.LastRun("turtle"); ok {
+		t.Fatal("LastRun() on empty store: want no run")
+	}
+
+	run := time.Date(2024, 3, 6, 11, 0, 0, 0, time.UTC)
+	if err := store.SetLastRun("turtle", run); err != nil {
+		t.Fatalf("SetLastRun() error = %v", err)
+	}
+
+	reopened, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if last, ok := reopened.LastRun("turtle"); !ok || !last.Equal(run) {
+		t.Errorf("LastRun() = %v, %v, want %v after reopen", last, ok, run)
+	}
+}
+
+func TestStoreInMemory(t *testing.T) {
+	store, err := storage.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.UpdateUser(1, func(settings *storage.UserSettings) { settings.Equity = 1000 }); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if store.User(1).Equity != 1000 {
+		t.Errorf("Equity = %.0f, want 1000", store.User(1).Equity)
+	}
+}
+
+func TestStoreCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+	if err := os.WriteFile(path, []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storage.NewStore(path); err == nil {
+		t.Fatal("NewStore() should fail on corrupted file")
+	}
+}
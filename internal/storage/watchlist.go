@@ -0,0 +1,102 @@
+package storage
+
+import "fmt"
+
+// MaxWatchlistSize сколько инструментов может быть в списке наблюдения
+const MaxWatchlistSize = 50
+
+// Watchlist список наблюдения пользователя в порядке добавления
+func (s *Store) Watchlist(userID int64) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.data.Watchlists[userID]...)
+}
+
+// Watch добавляет инструменты в список наблюдения и возвращает добавленные
+// (уже отслеживаемые пропускаются). При превышении MaxWatchlistSize
+// сохраняются поместившиеся инструменты и возвращается ошибка.
+func (s *Store) Watch(userID int64, instruments []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.data.Watchlists[userID]
+	var added []string
+	var limitErr error
+	for _, instrument := range instruments {
+		if contains(list, instrument) {
+			continue
+		}
+		if len(list) >= MaxWatchlistSize {
+			limitErr = fmt.Errorf("в списке наблюдения не больше %d инструментов", MaxWatchlistSize)
+			break
+		}
+		list = append(list, instrument)
+		added = append(added, instrument)
+	}
+	if len(added) == 0 {
+		return nil, limitErr
+	}
+
+	if s.data.Watchlists == nil {
+		s.data.Watchlists = make(map[int64][]string)
+	}
+	s.data.Watchlists[userID] = list
+
+	if err := s.save(); err != nil {
+		return added, err
+	}
+	return added, limitErr
+}
+
+// Unwatch удаляет инструменты из списка наблюдения (пустой список instruments -
+// все) и возвращает удаленные
+func (s *Store) Unwatch(userID int64, instruments []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.data.Watchlists[userID]
+	var kept, removed []string
+	for _, instrument := range list {
+		if len(instruments) == 0 || contains(instruments, instrument) {
+			removed = append(removed, instrument)
+			continue
+		}
+		kept = append(kept, instrument)
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	if len(kept) == 0 {
+		delete(s.data.Watchlists, userID)
+	} else {
+		s.data.Watchlists[userID] = kept
+	}
+
+	return removed, s.save()
+}
+
+// WatchlistSubscribers списки наблюдения пользователей, включивших уведомления
+// фонового анализа по своему списку
+func (s *Store) WatchlistSubscribers() map[int64][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscribers := make(map[int64][]string)
+	for userID, settings := range s.data.Users {
+		if list := s.data.Watchlists[userID]; settings.WatchlistAlerts && len(list) > 0 {
+			subscribers[userID] = append([]string(nil), list...)
+		}
+	}
+	return subscribers
+}
+
+// contains есть ли значение в списке
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
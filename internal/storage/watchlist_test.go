@@ -0,0 +1,117 @@
+package storage_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"telegram-bot-moex/internal/storage"
+)
+
+func TestStoreWatchlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.json")
+
+	store, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	added, err := store.Watch(42, []string{"SBER", "GAZP"})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if !reflect.DeepEqual(added, []string{"SBER", "GAZP"}) {
+		t.Errorf("Watch() = %v, want [SBER GAZP]", added)
+	}
+
+	// Уже отслеживаемые инструменты не дублируются
+	added, err = store.Watch(42, []string{"GAZP", "LKOH"})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if !reflect.DeepEqual(added, []string{"LKOH"}) {
+		t.Errorf("Watch() = %v, want [LKOH]", added)
+	}
+
+	// Список сохраняется после перезапуска
+	reopened, err := storage.NewStore(path)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if list := reopened.Watchlist(42); !reflect.DeepEqual(list, []string{"SBER", "GAZP", "LKOH"}) {
+		t.Errorf("Watchlist() = %v, want [SBER GAZP LKOH]", list)
+	}
+	if list := reopened.Watchlist(7); len(list) != 0 {
+		t.Errorf("Watchlist() другого пользователя = %v, want empty", list)
+	}
+
+	removed, err := reopened.Unwatch(42, []string{"GAZP", "VTBR"})
+	if err != nil {
+		t.Fatalf("Unwatch() error = %v", err)
+	}
+	if !reflect.DeepEqual(removed, []string{"GAZP"}) {
+		t.Errorf("Unwatch() = %v, want [GAZP]", removed)
+	}
+
+	// Пустой список удаляет все инструменты
+	removed, err = reopened.Unwatch(42, nil)
+	if err != nil {
+		t.Fatalf("Unwatch() error = %v", err)
+	}
+	if !reflect.DeepEqual(removed, []string{"SBER", "LKOH"}) {
+		t.Errorf("Unwatch() = %v, want [SBER LKOH]", removed)
+	}
+	if list := reopened.Watchlist(42); len(list) != 0 {
+		t.Errorf("Watchlist() = %v, want empty", list)
+	}
+}
+
+func TestStoreWatchlistLimit(t *testing.T) {
+	store, err := storage.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	instruments := make([]string, storage.MaxWatchlistSize+2)
+	for i := range instruments {
+		instruments[i] = fmt.Sprintf("T%03d", i)
+	}
+
+	added, err := store.Watch(42, instruments)
+	if err == nil {
+		t.Fatal("Watch() error = nil, want limit error")
+	}
+	if len(added) != storage.MaxWatchlistSize {
+		t.Errorf("Watch() added %d, want %d", len(added), storage.MaxWatchlistSize)
+	}
+	if list := store.Watchlist(42); len(list) != storage.MaxWatchlistSize {
+		t.Errorf("Watchlist() has %d instruments, want %d", len(list), storage.MaxWatchlistSize)
+	}
+}
+
+func TestStoreWatchlistSubscribers(t *testing.T) {
+	store, err := storage.NewStore("")
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	for _, userID := range []int64{1, 2, 3} {
+		if _, err := store.Watch(userID, []string{"SBER"}); err != nil {
+			t.Fatalf("Watch() error = %v", err)
+		}
+	}
+	// 1 - уведомления включены, 2 - выключены, 4 - включены, но список пуст
+	for _, userID := range []int64{1, 4} {
+		if err := store.UpdateUser(userID, func(settings *storage.UserSettings) {
+			settings.WatchlistAlerts = true
+		}); err != nil {
+			t.Fatalf("UpdateUser() error = %v", err)
+		}
+	}
+
+	subscribers := store.WatchlistSubscribers()
+	if !reflect.DeepEqual(subscribers, map[int64][]string{1: {"SBER"}}) {
+		t.Errorf("WatchlistSubscribers() = %v, want map[1:[SBER]]", subscribers)
+	}
+}
@@ -0,0 +1,657 @@
+// Package fakefetcher реализует in-process заглушку MOEX Fetcher для тестов.
+//
+// Сервер поднимается через httptest, отдает детерминированные (синтетические
+// или заданные в тесте) свечи и позволяет моделировать сбои: задержку ответа,
+// ошибки 5xx и поврежденный JSON.
+package fakefetcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"telegram-bot-moex/internal/api"
+)
+
+// dateLayout формат дат в параметрах запросов свечей
+const dateLayout = "2006-01-02"
+
+// Request запрос, полученный сервером
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// Option настройка сервера
+type Option func(*Server)
+
+// Server заглушка MOEX Fetcher
+type Server struct {
+	URL string
+
+	server  *httptest.Server
+	token   string
+	version string
+	started time.Time
+
+	mu          sync.Mutex
+	instruments map[string]*instrument
+	latency     time.Duration
+	failNext    int
+	failStatus  int
+	malformed   int
+	requests    []Request
+	fetches     int
+}
+
+// instrument данные инструмента
+type instrument struct {
+	name    string
+	lotSize int
+	candles map[string][]api.Candle // таймфрейм -> свечи
+	updated time.Time
+}
+
+// WithToken требует заголовок X-API-Key с указанным токеном
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithLatency задает задержку каждого ответа
+func WithLatency(latency time.Duration) Option {
+	return func(s *Server) {
+		s.latency = latency
+	}
+}
+
+// WithCandles добавляет свечи инструмента на таймфрейме
+func WithCandles(ticker, timeframe string, candles []api.Candle) Option {
+	return func(s *Server) {
+		s.setCandles(ticker, timeframe, candles)
+	}
+}
+
+// WithSynthetic добавляет инструмент с синтетическими дневными свечами
+// за days торговых дней, построенными генератором с зерном seed
+func WithSynthetic(ticker string, seed int64, days int, price float64) Option {
+	return WithCandles(ticker, "24", Synthetic(seed, days, price))
+}
+
+// WithLotSize задает размер лота инструмента
+func WithLotSize(ticker string, lotSize int) Option {
+	return func(s *Server) {
+		s.instrumentFor(ticker).lotSize = lotSize
+	}
+}
+
+// New запускает сервер; он останавливается автоматически по завершении теста.
+// Без опций сервер содержит инструменты SBER, GAZP и LKOH с синтетическими свечами.
+func New(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s := &Server{
+		version:     "fake-1.0.0",
+		started:     time.Now(),
+		instruments: make(map[string]*instrument),
+	}
+
+	if len(opts) == 0 {
+		opts = []Option{
+			WithSynthetic("SBER", 1, 250, 270),
+			WithSynthetic("GAZP", 2, 250, 160),
+			WithSynthetic("LKOH", 3, 250, 7000),
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// Close останавливает сервер
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetLatency задает задержку каждого ответа
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+// FailNext отвечает статусом status на следующие n запросов
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+	s.failStatus = status
+}
+
+// MalformedNext отвечает поврежденным JSON на следующие n запросов
+func (s *Server) MalformedNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed = n
+}
+
+// SetCandles заменяет свечи инструмента на таймфрейме
+func (s *Server) SetCandles(ticker, timeframe string, candles []api.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setCandles(ticker, timeframe, candles)
+}
+
+// Requests возвращает копию журнала запросов
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestCount возвращает число запросов к пути path
+func (s *Server) RequestCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, r := range s.requests {
+		if r.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// Fetches возвращает число запусков загрузки через POST /api/fetch
+func (s *Server) Fetches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+// setCandles сохраняет свечи; вызывается под мьютексом или до запуска сервера
+func (s *Server) setCandles(ticker, timeframe string, candles []api.Candle) {
+	inst := s.instrumentFor(ticker)
+	sorted := append([]api.Candle(nil), candles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Begin.Before(sorted[j].Begin)
+	})
+	inst.candles[timeframe] = sorted
+	if len(sorted) > 0 {
+		inst.updated = sorted[len(sorted)-1].Begin
+	}
+}
+
+// instrumentFor возвращает инструмент, создавая его при необходимости
+func (s *Server) instrumentFor(ticker string) *instrument {
+	ticker = strings.ToUpper(ticker)
+	inst, ok := s.instruments[ticker]
+	if !ok {
+		inst = &instrument{
+			name:    ticker,
+			lotSize: 1,
+			candles: make(map[string][]api.Candle),
+		}
+		s.instruments[ticker] = inst
+	}
+	return inst
+}
+
+// tickers возвращает отсортированный список инструментов
+func (s *Server) tickers() []string {
+	tickers := make([]string, 0, len(s.instruments))
+	for ticker := range s.instruments {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	return tickers
+}
+
+// serveHTTP применяет сценарии сбоев и передает запрос обработчику
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(r.Body)
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Body:   string(body),
+	})
+	latency := s.latency
+	failStatus := 0
+	if s.failNext > 0 {
+		s.failNext--
+		failStatus = s.failStatus
+	}
+	malformed := false
+	if failStatus == 0 && s.malformed > 0 {
+		s.malformed--
+		malformed = true
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if s.token != "" && r.Header.Get("X-API-Key") != s.token {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "unauthorized"})
+		return
+	}
+
+	if failStatus != 0 {
+		writeJSON(w, failStatus, map[string]interface{}{"error": http.StatusText(failStatus)})
+		return
+	}
+
+	if malformed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"candles": [{"begin": "2024-01-`)
+		return
+	}
+
+	s.route(w, r, body)
+}
+
+// route обрабатывает эндпоинты MOEX Fetcher
+func (s *Server) route(w http.ResponseWriter, r *http.Request, body []byte) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && path == "/health":
+		s.handleHealth(w)
+	case r.Method == http.MethodGet && path == "/api/stats":
+		s.handleStats(w)
+	case r.Method == http.MethodGet && path == "/api/instruments":
+		s.mu.Lock()
+		tickers := s.tickers()
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"instruments": tickers})
+	case r.Method == http.MethodGet && path == "/api/candles":
+		query := r.URL.Query()
+		s.handleCandles(w, query.Get("instrument"), query.Get("timeframe"), query.Get("from"), query.Get("to"), 0)
+	case r.Method == http.MethodGet && path == "/api/timeframes":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"timeframes": timeframeList([]string{"1", "10", "60", "24", "7", "31"}, nil)})
+	case r.Method == http.MethodGet && path == "/api/tables":
+		s.handleTables(w)
+	case r.Method == http.MethodPost && path == "/api/fetch":
+		s.mu.Lock()
+		s.fetches++
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "started", "message": "Загрузка данных запущена"})
+	case r.Method == http.MethodPost && path == "/api/refresh-instruments":
+		s.mu.Lock()
+		count := len(s.instruments)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "instruments_count": count})
+	case r.Method == http.MethodPost && path == "/api/instruments/add":
+		s.handleAddInstrument(w, body)
+	case r.Method == http.MethodPost && path == "/api/tables/cleanup":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ok", "message": "Удалено таблиц: 0"})
+	case len(parts) == 3 && parts[0] == "api" && parts[1] == "instruments" && r.Method == http.MethodGet:
+		s.handleInstrumentInfo(w, parts[2])
+	case len(parts) == 3 && parts[0] == "api" && parts[1] == "instruments" && r.Method == http.MethodDelete:
+		s.handleRemoveInstrument(w, parts[2])
+	case len(parts) == 4 && parts[0] == "api" && parts[1] == "instruments" && parts[3] == "timeframes":
+		s.handleInstrumentTimeframes(w, parts[2])
+	case len(parts) == 4 && parts[0] == "api" && parts[1] == "tables" && r.Method == http.MethodGet:
+		query := r.URL.Query()
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		s.handleCandles(w, parts[2], parts[3], query.Get("from"), query.Get("to"), limit)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "not found"})
+	}
+}
+
+// handleHealth отвечает на GET /health
+func (s *Server) handleHealth(w http.ResponseWriter) {
+	tables, candles := s.totals()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "healthy",
+		"uptime":    time.Since(s.started).Round(time.Second).String(),
+		"version":   s.version,
+		"timestamp": time.Now().In(api.MSK).Format(time.RFC3339),
+		"stats": map[string]interface{}{
+			"tables_count":  tables,
+			"total_candles": candles,
+		},
+	})
+}
+
+// handleStats отвечает на GET /api/stats
+func (s *Server) handleStats(w http.ResponseWriter) {
+	tables, candles := s.totals()
+
+	s.mu.Lock()
+	count := len(s.instruments)
+	fetches := s.fetches
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"database": map[string]interface{}{
+			"tables_count":  tables,
+			"total_candles": candles,
+			"last_update":   time.Now().In(api.MSK).Format("2006-01-02 15:04:05"),
+		},
+		"fetcher": map[string]interface{}{
+			"instruments_count": count,
+			"fetch_runs":        fetches,
+		},
+	})
+}
+
+// handleCandles отвечает свечами инструмента за период [from, to]
+func (s *Server) handleCandles(w http.ResponseWriter, ticker, timeframe, from, to string, limit int) {
+	fromDate, toEnd, err := parsePeriod(from, to)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	inst, ok := s.instruments[strings.ToUpper(ticker)]
+	var series []api.Candle
+	if ok {
+		series = inst.candles[timeframe]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "instrument not found"})
+		return
+	}
+
+	candles := make([]api.Candle, 0, len(series))
+	for _, candle := range series {
+		if !fromDate.IsZero() && candle.Begin.Before(fromDate) {
+			continue
+		}
+		if !toEnd.IsZero() && !candle.Begin.Before(toEnd) {
+			continue
+		}
+		candles = append(candles, candle)
+	}
+	if limit > 0 && len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"candles": candles})
+}
+
+// handleTables отвечает на GET /api/tables
+func (s *Server) handleTables(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tables := []map[string]interface{}{}
+	for _, ticker := range s.tickers() {
+		inst := s.instruments[ticker]
+		timeframes := make([]string, 0, len(inst.candles))
+		for tf := range inst.candles {
+			timeframes = append(timeframes, tf)
+		}
+		sort.Strings(timeframes)
+
+		for _, tf := range timeframes {
+			tables = append(tables, map[string]interface{}{
+				"table_name":  fmt.Sprintf("candles_%s_%s", strings.ToLower(ticker), tf),
+				"instrument":  ticker,
+				"timeframe":   tf,
+				"row_count":   len(inst.candles[tf]),
+				"last_update": inst.updated.Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tables": tables})
+}
+
+// handleInstrumentInfo отвечает на GET /api/instruments/{ticker}
+func (s *Server) handleInstrumentInfo(w http.ResponseWriter, ticker string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instruments[strings.ToUpper(ticker)]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "instrument not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"instrument": strings.ToUpper(ticker),
+		"name":       inst.name,
+		"lot_size":   inst.lotSize,
+		"timeframes": inst.timeframes(),
+	})
+}
+
+// handleInstrumentTimeframes отвечает на GET /api/instruments/{ticker}/timeframes
+func (s *Server) handleInstrumentTimeframes(w http.ResponseWriter, ticker string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instruments[strings.ToUpper(ticker)]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "instrument not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"timeframes": inst.timeframes()})
+}
+
+// handleAddInstrument отвечает на POST /api/instruments/add
+func (s *Server) handleAddInstrument(w http.ResponseWriter, body []byte) {
+	var req struct {
+		Instrument string `json:"instrument"`
+		Source     string `json:"source"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Instrument == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "instrument is required"})
+		return
+	}
+
+	s.mu.Lock()
+	_, exists := s.instruments[strings.ToUpper(req.Instrument)]
+	s.instrumentFor(req.Instrument)
+	s.mu.Unlock()
+
+	status := "added"
+	if exists {
+		status = "exists"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  status,
+		"message": fmt.Sprintf("Инструмент %s добавлен", strings.ToUpper(req.Instrument)),
+	})
+}
+
+// handleRemoveInstrument отвечает на DELETE /api/instruments/{ticker}
+func (s *Server) handleRemoveInstrument(w http.ResponseWriter, ticker string) {
+	s.mu.Lock()
+	_, ok := s.instruments[strings.ToUpper(ticker)]
+	delete(s.instruments, strings.ToUpper(ticker))
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error": "instrument not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "removed"})
+}
+
+// totals возвращает число таблиц и свечей
+func (s *Server) totals() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tables, candles := 0, 0
+	for _, inst := range s.instruments {
+		for _, series := range inst.candles {
+			tables++
+			candles += len(series)
+		}
+	}
+	return tables, candles
+}
+
+// timeframes возвращает описания таймфреймов инструмента
+func (inst *instrument) timeframes() []map[string]interface{} {
+	codes := make([]string, 0, len(inst.candles))
+	for tf := range inst.candles {
+		codes = append(codes, tf)
+	}
+	sort.Strings(codes)
+	return timeframeList(codes, inst.candles)
+}
+
+// timeframeList формирует список таймфреймов в формате MOEX Fetcher
+func timeframeList(codes []string, candles map[string][]api.Candle) []map[string]interface{} {
+	names := map[string]string{
+		"1": "1 минута", "10": "10 минут", "60": "1 час",
+		"24": "1 день", "7": "1 неделя", "31": "1 месяц", "4": "1 квартал",
+	}
+
+	list := make([]map[string]interface{}, 0, len(codes))
+	for _, code := range codes {
+		tf := map[string]interface{}{
+			"timeframe":    code,
+			"display_name": names[code],
+		}
+		if series := candles[code]; len(series) > 0 {
+			tf["last_candle"] = map[string]interface{}{
+				"date": series[len(series)-1].Begin.Format("2006-01-02 15:04:05"),
+			}
+		}
+		list = append(list, tf)
+	}
+	return list
+}
+
+// Synthetic строит days дневных свечей случайного блуждания от цены price.
+// Значения определяются только зерном seed; последняя свеча приходится на
+// последний рабочий день не позже сегодняшнего, выходные пропускаются.
+func Synthetic(seed int64, days int, price float64) []api.Candle {
+	rng := rand.New(rand.NewSource(seed))
+
+	closes := make([]float64, days)
+	current := price
+	for i := range closes {
+		current *= 1 + rng.NormFloat64()*0.015
+		closes[i] = round(current)
+	}
+
+	candles := Series(closes)
+	for i := range candles {
+		spread := candles[i].Close * rng.Float64() * 0.01
+		candles[i].High = round(candles[i].High + spread)
+		candles[i].Low = round(candles[i].Low - spread)
+		candles[i].Volume = float64(100000 + rng.Intn(900000))
+		candles[i].Value = round(candles[i].Volume * candles[i].Close)
+	}
+
+	return candles
+}
+
+// Series строит дневные свечи по ценам закрытия, заканчивая последним рабочим днем.
+// Открытие равно предыдущему закрытию, максимум и минимум - границам тела свечи.
+func Series(closes []float64) []api.Candle {
+	dates := tradingDays(len(closes))
+
+	candles := make([]api.Candle, len(closes))
+	for i, closePrice := range closes {
+		open := closePrice
+		if i > 0 {
+			open = closes[i-1]
+		}
+		candles[i] = api.Candle{
+			Begin:  dates[i],
+			End:    dates[i].Add(23*time.Hour + 59*time.Minute + 59*time.Second),
+			Open:   open,
+			High:   math.Max(open, closePrice),
+			Low:    math.Min(open, closePrice),
+			Close:  closePrice,
+			Volume: 1000,
+			Value:  round(1000 * closePrice),
+		}
+	}
+
+	return candles
+}
+
+// tradingDays возвращает n последних рабочих дней по московскому времени
+func tradingDays(n int) []time.Time {
+	now := time.Now().In(api.MSK)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, api.MSK)
+
+	dates := make([]time.Time, n)
+	for i := n - 1; i >= 0; i-- {
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, -1)
+		}
+		dates[i] = day
+		day = day.AddDate(0, 0, -1)
+	}
+	return dates
+}
+
+// parsePeriod разбирает границы периода; пустые границы не ограничивают выборку
+func parsePeriod(from, to string) (time.Time, time.Time, error) {
+	var fromDate, toEnd time.Time
+	if from != "" {
+		t, err := time.ParseInLocation(dateLayout, from, api.MSK)
+		if err != nil {
+			return fromDate, toEnd, fmt.Errorf("invalid from: %w", err)
+		}
+		fromDate = t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation(dateLayout, to, api.MSK)
+		if err != nil {
+			return fromDate, toEnd, fmt.Errorf("invalid to: %w", err)
+		}
+		toEnd = t.AddDate(0, 0, 1)
+	}
+	return fromDate, toEnd, nil
+}
+
+// writeJSON пишет JSON ответ
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// round округляет цену до копеек
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,440 @@
+// Package faketelegram реализует in-process заглушку Telegram Bot API для тестов.
+//
+// Бот подключается к заглушке через telegram.api_endpoint, получает от нее
+// подготовленные в тесте обновления (сообщения и нажатия кнопок) через
+// getUpdates, а все исходящие вызовы (sendMessage, editMessageText,
+// answerCallbackQuery, setMyCommands и др.) записываются для проверок.
+package faketelegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DefaultTimeout время ожидания ответа бота в WaitMessage
+const DefaultTimeout = 5 * time.Second
+
+// BotUserName имя бота, которое возвращает getMe
+const BotUserName = "moex_test_bot"
+
+// Sent исходящий вызов бота
+type Sent struct {
+	Method      string
+	ChatID      int64
+	MessageID   int    // ID созданного или редактируемого сообщения
+	Text        string // Текст без экранирования MarkdownV2
+	ParseMode   string
+	ReplyMarkup string // JSON клавиатуры
+	FileName    string // Имя отправленного файла для sendDocument/sendPhoto
+	Params      map[string]string
+}
+
+// Buttons возвращает callback данные кнопок inline клавиатуры сообщения
+func (s Sent) Buttons() []string {
+	if s.ReplyMarkup == "" {
+		return nil
+	}
+
+	var markup tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(s.ReplyMarkup), &markup); err != nil {
+		return nil
+	}
+
+	var data []string
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != nil {
+				data = append(data, *button.CallbackData)
+			}
+		}
+	}
+	return data
+}
+
+// HasButton проверяет наличие кнопки с callback данными data
+func (s Sent) HasButton(data string) bool {
+	for _, button := range s.Buttons() {
+		if button == data {
+			return true
+		}
+	}
+	return false
+}
+
+// Server заглушка Telegram Bot API
+type Server struct {
+	server *httptest.Server
+	done   chan struct{}
+
+	mu        sync.Mutex
+	updates   []tgbotapi.Update
+	nextID    int
+	messageID int
+	sent      []Sent
+	cursor    int
+	commands  []tgbotapi.BotCommand
+	notify    chan struct{}
+}
+
+// New запускает сервер; он останавливается автоматически по завершении теста
+func New(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		done:      make(chan struct{}),
+		nextID:    1,
+		messageID: 1000,
+		notify:    make(chan struct{}),
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(func() {
+		close(s.done)
+		s.server.Close()
+	})
+
+	return s
+}
+
+// Endpoint возвращает шаблон адреса Bot API для telegram.api_endpoint
+func (s *Server) Endpoint() string {
+	return s.server.URL + "/bot%s/%s"
+}
+
+// SendText отправляет боту текстовое сообщение от пользователя userID в личном чате.
+// Текст, начинающийся с "/", передается как команда. Возвращает ID сообщения.
+func (s *Server) SendText(userID int64, text string) int {
+	return s.send(&tgbotapi.Chat{ID: userID, Type: "private"}, userID, text)
+}
+
+// SendGroupText отправляет сообщение от пользователя userID в групповом чате chatID
+func (s *Server) SendGroupText(chatID, userID int64, text string) int {
+	return s.send(&tgbotapi.Chat{ID: chatID, Type: "group"}, userID, text)
+}
+
+func (s *Server) send(chat *tgbotapi.Chat, userID int64, text string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messageID++
+	message := &tgbotapi.Message{
+		MessageID: s.messageID,
+		From:      user(userID),
+		Chat:      chat,
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		length := len(text)
+		if i := strings.IndexByte(text, ' '); i > 0 {
+			length = i
+		}
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: length}}
+	}
+
+	s.push(tgbotapi.Update{Message: message})
+	return message.MessageID
+}
+
+// Press нажимает inline кнопку с данными data под сообщением messageID
+func (s *Server) Press(userID int64, messageID int, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.push(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   strconv.Itoa(s.nextID),
+		From: user(userID),
+		Message: &tgbotapi.Message{
+			MessageID: messageID,
+			Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		},
+		Data: data,
+	}})
+}
+
+// Sent возвращает копию всех исходящих вызовов
+func (s *Server) Sent() []Sent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Sent(nil), s.sent...)
+}
+
+// Commands возвращает команды, установленные через setMyCommands
+func (s *Server) Commands() []tgbotapi.BotCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]tgbotapi.BotCommand(nil), s.commands...)
+}
+
+// WaitMessage ждет новое сообщение (sendMessage, editMessageText или файл), текст
+// которого содержит substr. Сообщения до найденного считаются прочитанными.
+func (s *Server) WaitMessage(t testing.TB, substr string) Sent {
+	t.Helper()
+
+	deadline := time.NewTimer(DefaultTimeout)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		for i := s.cursor; i < len(s.sent); i++ {
+			sent := s.sent[i]
+			if isMessage(sent.Method) && strings.Contains(sent.Text, substr) {
+				s.cursor = i + 1
+				s.mu.Unlock()
+				return sent
+			}
+		}
+		notify := s.notify
+		s.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-deadline.C:
+			t.Fatalf("бот не отправил сообщение с %q за %v; отправлено:\n%s", substr, DefaultTimeout, s.dump())
+			return Sent{}
+		}
+	}
+}
+
+// push добавляет обновление в очередь; вызывается под мьютексом
+func (s *Server) push(update tgbotapi.Update) {
+	update.UpdateID = s.nextID
+	s.nextID++
+	s.updates = append(s.updates, update)
+	s.broadcast()
+}
+
+// broadcast будит ожидающих; вызывается под мьютексом
+func (s *Server) broadcast() {
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// dump возвращает тексты отправленных сообщений для диагностики
+func (s *Server) dump() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sb strings.Builder
+	for _, sent := range s.sent {
+		if isMessage(sent.Method) {
+			fmt.Fprintf(&sb, "  [%s] %q\n", sent.Method, sent.Text)
+		}
+	}
+	return sb.String()
+}
+
+// serveHTTP обрабатывает методы Bot API вида /bot<token>/<method>
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	i := strings.LastIndexByte(r.URL.Path, '/')
+	if i < 0 || !strings.HasPrefix(r.URL.Path, "/bot") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	method := r.URL.Path[i+1:]
+
+	params, fileName, err := readParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, tgbotapi.User{ID: 1, IsBot: true, FirstName: "MOEX", UserName: BotUserName})
+	case "getUpdates":
+		s.handleGetUpdates(w, params)
+	case "setMyCommands":
+		var commands []tgbotapi.BotCommand
+		json.Unmarshal([]byte(params["commands"]), &commands)
+		s.mu.Lock()
+		s.commands = commands
+		s.record(method, params, "", 0)
+		s.mu.Unlock()
+		writeResult(w, true)
+	case "sendMessage", "sendDocument", "sendPhoto":
+		s.mu.Lock()
+		s.messageID++
+		id := s.messageID
+		s.record(method, params, fileName, id)
+		s.mu.Unlock()
+
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		text := params["text"]
+		if text == "" {
+			text = params["caption"]
+		}
+		writeResult(w, tgbotapi.Message{
+			MessageID: id,
+			From:      &tgbotapi.User{ID: 1, IsBot: true, UserName: BotUserName},
+			Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+			Date:      int(time.Now().Unix()),
+			Text:      text,
+		})
+	case "editMessageText", "editMessageReplyMarkup":
+		id, _ := strconv.Atoi(params["message_id"])
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		s.mu.Lock()
+		s.record(method, params, "", id)
+		s.mu.Unlock()
+		writeResult(w, tgbotapi.Message{
+			MessageID: id,
+			Chat:      &tgbotapi.Chat{ID: chatID, Type: "private"},
+			Date:      int(time.Now().Unix()),
+			Text:      params["text"],
+		})
+	default:
+		// answerCallbackQuery, deleteMessage, sendChatAction и прочие методы без результата
+		s.mu.Lock()
+		s.record(method, params, "", 0)
+		s.mu.Unlock()
+		writeResult(w, true)
+	}
+}
+
+// handleGetUpdates отдает накопленные обновления или ждет новые до истечения timeout
+func (s *Server) handleGetUpdates(w http.ResponseWriter, params map[string]string) {
+	offset, _ := strconv.Atoi(params["offset"])
+	timeout, _ := strconv.Atoi(params["timeout"])
+
+	deadline := time.NewTimer(time.Duration(timeout) * time.Second)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+		var pending []tgbotapi.Update
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				pending = append(pending, update)
+			}
+		}
+		notify := s.notify
+		s.mu.Unlock()
+
+		if len(pending) > 0 || timeout <= 0 {
+			writeResult(w, pending)
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-deadline.C:
+			writeResult(w, []tgbotapi.Update{})
+			return
+		case <-s.done:
+			writeResult(w, []tgbotapi.Update{})
+			return
+		}
+	}
+}
+
+// record сохраняет исходящий вызов; вызывается под мьютексом
+func (s *Server) record(method string, params map[string]string, fileName string, messageID int) {
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	text := params["text"]
+	if text == "" {
+		text = params["caption"]
+	}
+
+	if params["parse_mode"] == "MarkdownV2" {
+		text = unescapeMarkdownV2(text)
+	}
+
+	s.sent = append(s.sent, Sent{
+		Method:      method,
+		ChatID:      chatID,
+		MessageID:   messageID,
+		Text:        text,
+		ParseMode:   params["parse_mode"],
+		ReplyMarkup: params["reply_markup"],
+		FileName:    fileName,
+		Params:      params,
+	})
+	s.broadcast()
+}
+
+// readParams читает параметры запроса (form или multipart) и имя загруженного файла
+func readParams(r *http.Request) (map[string]string, string, error) {
+	params := make(map[string]string)
+	fileName := ""
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, "", err
+		}
+		for key, values := range r.MultipartForm.Value {
+			params[key] = values[0]
+		}
+		for _, files := range r.MultipartForm.File {
+			if len(files) > 0 {
+				fileName = files[0].Filename
+			}
+		}
+		return params, fileName, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, "", err
+	}
+	for key, values := range r.Form {
+		params[key] = values[0]
+	}
+	return params, fileName, nil
+}
+
+// unescapeMarkdownV2 убирает экранирование спецсимволов MarkdownV2
+func unescapeMarkdownV2(text string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range text {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// isMessage проверяет, что метод создает или изменяет видимое сообщение
+func isMessage(method string) bool {
+	switch method {
+	case "sendMessage", "editMessageText", "sendDocument", "sendPhoto":
+		return true
+	}
+	return false
+}
+
+// user возвращает пользователя Telegram с указанным ID
+func user(id int64) *tgbotapi.User {
+	return &tgbotapi.User{ID: id, FirstName: "Test", UserName: fmt.Sprintf("user%d", id)}
+}
+
+// writeResult пишет успешный ответ Bot API
+func writeResult(w http.ResponseWriter, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: data})
+}
+
+// writeError пишет ответ Bot API с ошибкой
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: code, Description: description})
+}